@@ -36,3 +36,27 @@ type NamespaceCache interface {
 	GetNamespace(namespaceAddr crdt.IPFSPath) (crdt.Namespace, error)
 	SetNamespace(namespaceAddr crdt.IPFSPath, namespace crdt.Namespace) error
 }
+
+// VerifiedNamespaceCache remembers namespaces in which every point was verified, so they need not
+// be verified again when loaded.  The digest names the keys that verified them, as made by
+// crypto.TrustDigest.
+type VerifiedNamespaceCache interface {
+	IsVerifiedNamespace(namespaceAddr crdt.IPFSPath, digest string) (bool, error)
+	SetVerifiedNamespace(namespaceAddr crdt.IPFSPath, digest string) error
+}
+
+// CacheStats counts the work done by a cache.
+type CacheStats struct {
+	Name      string
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Items     int64
+	Bytes     int64
+	MaxBytes  int64
+}
+
+// StatisticsCache is implemented by caches that keep CacheStats.
+type StatisticsCache interface {
+	GetCacheStats() []CacheStats
+}
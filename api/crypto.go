@@ -11,4 +11,19 @@ type KeyStore interface {
 	GetAllPublicKeys() []crypto.PublicKey
 	PutPublicKey(pub crypto.PublicKey) error
 	GetPublicKey(hash crypto.PublicKeyHash) (crypto.PublicKey, error)
+	// DeleteKey forgets the public key and its private key, if the KeyStore holds it.
+	DeleteKey(hash crypto.PublicKeyHash) error
+	PutAuthorityKey(pub crypto.PublicKey) error
+	GetAllAuthorityKeys() []crypto.PublicKey
+	PutRevocation(revocation crypto.Revocation) error
+	GetAllRevocations() []crypto.Revocation
+	IsRevoked(pub crypto.PublicKey) bool
+	// GetTrustedPublicKeys lists the public keys that have not been revoked.  Use these to verify signatures.
+	GetTrustedPublicKeys() []crypto.PublicKey
+	PutDelegation(delegation crypto.Delegation) error
+	GetAllDelegations() []crypto.Delegation
+	// GetDelegatedPublicKeys lists the trusted public keys, and the keys they delegate to for the table.
+	GetDelegatedPublicKeys(table string) []crypto.PublicKey
+	PutTablePolicy(table string, policy crypto.TablePolicy) error
+	GetAllTablePolicies() map[string]crypto.TablePolicy
 }
@@ -2,6 +2,7 @@ package api
 
 import (
 	"io"
+	"time"
 )
 
 type DataPeer interface {
@@ -13,6 +14,13 @@ type DataPeer interface {
 	PingablePeer
 }
 
+// PinningDataPeer is a DataPeer that can also control which blocks survive
+// garbage collection on the underlying store.
+type PinningDataPeer interface {
+	DataPeer
+	PinningPeer
+}
+
 type PubSubber interface {
 	PubSubPublisher
 	PubSubSubscriber
@@ -35,6 +43,41 @@ type ContentAddressableStorage interface {
 	Add(r io.Reader) (string, error)
 }
 
+// HashingStorage finds the hash that Add would give some data, without storing it.
+type HashingStorage interface {
+	Hash(r io.Reader) (string, error)
+}
+
+// DagStorage holds structured nodes whose links IPFS can follow, as in the IPFS dag API.
+type DagStorage interface {
+	DagPut(data interface{}, inputEncoding, kind string) (string, error)
+	// DagGet decodes the JSON form of the node at ref into out.
+	DagGet(ref string, out interface{}) error
+}
+
+// PinningPeer protects blocks from garbage collection by the remote store.
+type PinningPeer interface {
+	Pin(hash string) error
+	Unpin(hash string) error
+	// Pins lists the hashes that are pinned directly or recursively.
+	Pins() ([]string, error)
+}
+
+// PinLedger records the blocks that godless added or pinned, so that garbage collection
+// never unpins data that belongs to someone else.
+type PinLedger interface {
+	RecordPin(hash string) error
+	// ForgetPin drops the record of hash, unless it was recorded again at or after before.
+	ForgetPin(hash string, before time.Time) error
+	PinRecords() ([]PinRecord, error)
+}
+
+type PinRecord struct {
+	Hash string
+	// Added is the time the hash was last recorded.
+	Added time.Time
+}
+
 type PubSubPublisher interface {
 	PubSubPublish(topic, data string) error
 }
@@ -42,15 +42,42 @@ func genQueryResponse(rand *rand.Rand, size int, gen *Response) {
 
 func genReflectResponse(rand *rand.Rand, size int, gen *Response) {
 	branch := rand.Float32()
-	if branch < 0.333 {
+	if branch < 0.2 {
 		gen.Path = genResponsePath(rand, size)
-	} else if branch < 0.666 {
+	} else if branch < 0.4 {
 		gen.Namespace = crdt.GenNamespace(rand, size)
-	} else {
+	} else if branch < 0.6 {
 		gen.Index = crdt.GenIndex(rand, size)
+	} else if branch < 0.7 {
+		gen.CacheStats = genCacheStats(rand, size)
+	} else if branch < 0.8 {
+		gen.JournalDepth = rand.Intn(size + 1)
+	} else {
+		gen.Diff = crdt.NamespaceDiff{
+			Added:   crdt.GenNamespace(rand, size),
+			Removed: crdt.GenNamespace(rand, size),
+		}
 	}
 }
 
+func genCacheStats(rand *rand.Rand, size int) []CacheStats {
+	stats := make([]CacheStats, rand.Intn(size)+1)
+
+	for i := range stats {
+		stats[i] = CacheStats{
+			Name:      testutil.RandLettersRange(rand, 1, size),
+			Hits:      uint64(rand.Int63()),
+			Misses:    uint64(rand.Int63()),
+			Evictions: uint64(rand.Int63()),
+			Items:     rand.Int63(),
+			Bytes:     rand.Int63(),
+			MaxBytes:  rand.Int63(),
+		}
+	}
+
+	return stats
+}
+
 func genResponsePath(rand *rand.Rand, size int) crdt.IPFSPath {
 	return crdt.IPFSPath(testutil.RandLettersRange(rand, 1, size))
 }
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/johnny-morrice/godless/crdt"
+)
+
+// JoinJournal is a write-ahead log of joins that are waiting for the RemoteStore to become
+// reachable.
+type JoinJournal interface {
+	AppendJoin(namespace crdt.Namespace) (JournalEntry, error)
+	// GetJoins lists the journal in the order that joins were made.
+	GetJoins() ([]JournalEntry, error)
+	RemoveJoin(id uint64) error
+	JournalDepth() (int, error)
+}
+
+type JournalEntry struct {
+	ID        uint64
+	Namespace crdt.Namespace
+}
+
+// Path is the provisional path given to the journaled join until it is written to the RemoteStore.
+func (entry JournalEntry) Path() crdt.IPFSPath {
+	return crdt.IPFSPath(fmt.Sprintf("%s%d", __PROVISIONAL_PATH_PREFIX, entry.ID))
+}
+
+func IsProvisionalPath(path crdt.IPFSPath) bool {
+	_, err := ParseProvisionalPath(path)
+	return err == nil
+}
+
+// ParseProvisionalPath finds the JournalEntry ID for a provisional path.
+func ParseProvisionalPath(path crdt.IPFSPath) (uint64, error) {
+	text := string(path)
+
+	if !strings.HasPrefix(text, __PROVISIONAL_PATH_PREFIX) {
+		return 0, fmt.Errorf("Not a provisional path: %s", path)
+	}
+
+	return strconv.ParseUint(strings.TrimPrefix(text, __PROVISIONAL_PATH_PREFIX), 10, 64)
+}
+
+const __PROVISIONAL_PATH_PREFIX = "journal:"
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// TableWritePolicy is a crypto.TablePolicy with its signers resolved to public keys.
+type TableWritePolicy struct {
+	Signers       []crypto.PublicKey
+	MinSignatures int
+}
+
+// Allows is true if enough of the Signers signed the link.
+func (policy TableWritePolicy) Allows(link crdt.Link) bool {
+	threshold := policy.MinSignatures
+
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return link.CountVerifiedBy(policy.Signers) >= threshold
+}
+
+// WritePolicy limits the keys that may write each table.  Tables without a policy may be written
+// by any key that is otherwise trusted.
+type WritePolicy map[crdt.TableName]TableWritePolicy
+
+// MakeWritePolicy resolves the table policies in the KeyStore.  Signers must be trusted by the
+// KeyStore, either directly or through a delegation for the table, or they are ignored.
+func MakeWritePolicy(keyStore KeyStore) WritePolicy {
+	policies := keyStore.GetAllTablePolicies()
+	writePolicy := make(WritePolicy, len(policies))
+
+	for table, policy := range policies {
+		keys := keyStore.GetDelegatedPublicKeys(table)
+
+		writePolicy[crdt.TableName(table)] = TableWritePolicy{
+			Signers:       policy.FilterSigners(keys),
+			MinSignatures: policy.Threshold(),
+		}
+	}
+
+	return writePolicy
+}
+
+// Allows is true if the table has no policy, or its policy allows the link.
+func (policy WritePolicy) Allows(table crdt.TableName, link crdt.Link) bool {
+	tablePolicy, present := policy[table]
+
+	if !present {
+		return true
+	}
+
+	return tablePolicy.Allows(link)
+}
+
+// FilterIndex removes the table links that the policy does not allow.
+func (policy WritePolicy) FilterIndex(index crdt.Index) crdt.Index {
+	if len(policy) == 0 {
+		return index
+	}
+
+	filtered := crdt.EmptyIndex()
+
+	for _, table := range index.AllTables() {
+		index.ForTable(table, func(link crdt.Link) {
+			if !policy.Allows(table, link) {
+				log.Warn("Write policy rejected link for table %s: %s", table, link.Path())
+				return
+			}
+
+			filtered = filtered.JoinTable(table, link)
+		})
+	}
+
+	return filtered
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestSignedTableSearcherPolicy(t *testing.T) {
+	keyStore := &crypto.KeyStore{}
+	privs := make([]crypto.PrivateKey, 3)
+
+	for i := range privs {
+		priv, pub, err := crypto.GenerateKey()
+		testutil.AssertNil(t, err)
+		testutil.AssertNil(t, keyStore.PutPublicKey(pub))
+		privs[i] = priv
+	}
+
+	writer, coWriter, commenter := privs[0], privs[1], privs[2]
+
+	policy := crypto.TablePolicy{MinSignatures: 2}
+	for _, priv := range []crypto.PrivateKey{writer, coWriter} {
+		hash, err := priv.GetPublicKey().Hash()
+		testutil.AssertNil(t, err)
+		policy.Signers = append(policy.Signers, hash)
+	}
+	testutil.AssertNil(t, keyStore.PutTablePolicy("prices", policy))
+
+	makeLink := func(path crdt.IPFSPath, keys ...crypto.PrivateKey) crdt.Link {
+		link, err := crdt.SignedLink(path, keys)
+		testutil.AssertNil(t, err)
+		return link
+	}
+
+	allowed := makeLink("allowed", writer, coWriter)
+	index := crdt.EmptyIndex()
+	index = index.JoinTable("prices", allowed)
+	index = index.JoinTable("prices", makeLink("toofew", writer))
+	index = index.JoinTable("prices", makeLink("outsider", writer, commenter))
+	index = index.JoinTable("comments", makeLink("comment", commenter))
+
+	searcher := SignedTableSearcher{
+		Tables: []crdt.TableName{"prices", "comments"},
+		Policy: MakeWritePolicy(keyStore),
+	}
+
+	found := searcher.Search(index)
+	testutil.AssertLenEquals(t, 2, found)
+
+	searcher.Keys = []crypto.PublicKey{commenter.GetPublicKey()}
+	found = searcher.Search(index)
+	testutil.AssertLenEquals(t, 1, found)
+	testutil.AssertEquals(t, "Unexpected link", crdt.IPFSPath("comment"), found[0].Path())
+
+	searcher.Keys = []crypto.PublicKey{writer.GetPublicKey(), coWriter.GetPublicKey(), commenter.GetPublicKey()}
+	searcher.Threshold = 2
+	found = searcher.Search(index)
+	testutil.AssertLenEquals(t, 1, found)
+	testutil.AssertEquals(t, "Unexpected link", crdt.IPFSPath("allowed"), found[0].Path())
+
+	filtered := searcher.Policy.FilterIndex(index)
+	prices, err := filtered.GetTableAddrs("prices")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, prices)
+	testutil.Assert(t, "Unexpected link", allowed.Equals(prices[0]))
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/johnny-morrice/godless/crdt"
 	"github.com/johnny-morrice/godless/crypto"
 )
@@ -10,6 +12,25 @@ type RemoteNamespace interface {
 	LoadTraverse(searcher NamespaceSearcher) error
 }
 
+// BlobNamespace is a RemoteNamespace that stores large point values out of line.
+type BlobNamespace interface {
+	RemoteNamespace
+	// IsLargeValue is true for point text that should be stored as a blob.
+	IsLargeValue(text crdt.PointText) bool
+	AddBlob(text crdt.PointText) (crdt.BlobReference, error)
+	CatBlob(ref crdt.BlobReference) (crdt.PointText, error)
+}
+
+// NamespaceSigningRemote is a RemoteNamespace that can sign joined namespaces as a whole, instead
+// of each point.
+type NamespaceSigningRemote interface {
+	RemoteNamespace
+	// IsSigningNamespaces is true if joins should use JoinSignedTable.
+	IsSigningNamespaces() bool
+	// JoinSignedTable is like JoinTable, but the namespace is signed once by each key.
+	JoinSignedTable(tableKey crdt.TableName, table crdt.Table, keys []crypto.PrivateKey) (crdt.IPFSPath, error)
+}
+
 type RemoteNamespaceCore interface {
 	Core
 	RemoteNamespace
@@ -19,6 +40,11 @@ type SearchResult struct {
 	Namespace            crdt.Namespace
 	NamespaceLoadFailure bool
 	IndexLoadFailure     bool
+	// Path is optional.  It is set when Namespace is the whole namespace stored at Path.
+	Path crdt.IPFSPath
+	// Source is optional.  It is set when Namespace is one row of the namespace stored at Source.
+	// A NamespaceLoadFailure with a Source means the rows already read from Source must be dropped.
+	Source crdt.IPFSPath
 }
 
 type SearchResultTraverser interface {
@@ -43,6 +69,15 @@ type SignedTableSearcher struct {
 	Reader SearchResultTraverser
 	Tables []crdt.TableName
 	Keys   []crypto.PublicKey
+	// Revocations is optional.  Links signed only by revoked Keys are not found.
+	Revocations []crypto.Revocation
+	// Delegations is optional.  Links signed by keys that the Keys delegate to for the table are found.
+	Delegations []crypto.Delegation
+	// Policy is optional.  Links to tables with a policy are found only if the policy allows them.
+	Policy WritePolicy
+	// Threshold is optional.  Links must be signed by at least this many distinct Keys.  A key and
+	// the keys it delegates to count once.
+	Threshold int
 }
 
 func (searcher SignedTableSearcher) ReadSearchResult(result SearchResult) TraversalUpdate {
@@ -54,14 +89,22 @@ func (searcher SignedTableSearcher) Search(index crdt.Index) []crdt.Link {
 
 	needSignature := len(searcher.Keys) > 0
 
+	now := time.Now()
+
 	for _, t := range searcher.Tables {
+		groups := crypto.DelegatedKeyGroups(searcher.Keys, searcher.Delegations, searcher.Revocations, string(t), now)
+
 		index.ForTable(t, func(link crdt.Link) {
+			if !searcher.Policy.Allows(t, link) {
+				return
+			}
+
 			if !needSignature {
 				verified = append(verified, link)
 				return
 			}
 
-			if link.IsVerifiedByAny(searcher.Keys) {
+			if link.IsVerifiedByGroups(groups, searcher.Threshold) {
 				verified = append(verified, link)
 			}
 		})
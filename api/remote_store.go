@@ -1,6 +1,10 @@
 package api
 
-import "github.com/johnny-morrice/godless/crdt"
+import (
+	"io"
+
+	"github.com/johnny-morrice/godless/crdt"
+)
 
 type PubSubTopic string
 
@@ -14,3 +18,28 @@ type RemoteStore interface {
 	PublishAddr(addr crdt.Link, topics []PubSubTopic) error
 	Disconnect() error
 }
+
+// SummaryStore is a RemoteStore that can exchange Merkle summaries of an Index,
+// so that peers need only fetch the tables that differ.
+type SummaryStore interface {
+	RemoteStore
+	SubscribeSummaryStream(topic PubSubTopic) (<-chan crdt.IndexSummary, <-chan error)
+	PublishSummary(summary crdt.IndexSummary, topics []PubSubTopic) error
+}
+
+// NamespaceStreamStore is a RemoteStore that can decode a namespace row by row,
+// while the encoded namespace is still being read.
+type NamespaceStreamStore interface {
+	RemoteStore
+	// CatNamespaceRows calls f with a namespace holding each row in turn, until f returns false.
+	CatNamespaceRows(addr crdt.IPFSPath, f func(row crdt.Namespace) bool) error
+}
+
+// BlobStore is a RemoteStore that can hold large values out of line, split into chunks.
+type BlobStore interface {
+	RemoteStore
+	AddBlob(r io.Reader) (crdt.IPFSPath, error)
+	CatBlob(addr crdt.IPFSPath) (io.ReadCloser, error)
+	// BlobChunks lists the chunks in the manifest at addr.
+	BlobChunks(addr crdt.IPFSPath) ([]crdt.IPFSPath, error)
+}
@@ -19,6 +19,7 @@ type Request struct {
 	Reflection ReflectionType
 	Query      *query.Query
 	Replicate  []crdt.Link
+	Diff       []crdt.IPFSPath
 }
 
 func MakeQueryRequest(query *query.Query) Request {
@@ -35,6 +36,15 @@ func MakeReflectRequest(reflection ReflectionType) Request {
 	}
 }
 
+// MakeDiffRequest asks for the changes between two indices.
+func MakeDiffRequest(older, newer crdt.IPFSPath) Request {
+	return Request{
+		Type:       API_REFLECT,
+		Reflection: REFLECT_DIFF,
+		Diff:       []crdt.IPFSPath{older, newer},
+	}
+}
+
 func MakeReplicateRequest(replicate []crdt.Link) Request {
 	return Request{
 		Type:      API_REPLICATE,
@@ -59,12 +69,19 @@ func (request Request) Equals(other Request) bool {
 	ok := request.Type == other.Type
 	ok = ok && request.Reflection == other.Reflection
 	ok = ok && len(request.Replicate) == len(other.Replicate)
+	ok = ok && len(request.Diff) == len(other.Diff)
 	ok = ok && (request.Query == nil) == (other.Query == nil)
 
 	if !ok {
 		return false
 	}
 
+	for i, myPath := range request.Diff {
+		if myPath != other.Diff[i] {
+			return false
+		}
+	}
+
 	for i, myLink := range request.Replicate {
 		otherLink := other.Replicate[i]
 
@@ -112,6 +129,12 @@ func (request Request) validateReflect() error {
 	case REFLECT_HEAD_PATH:
 	case REFLECT_DUMP_NAMESPACE:
 	case REFLECT_INDEX:
+	case REFLECT_CACHE_STATS:
+	case REFLECT_JOURNAL:
+	case REFLECT_DIFF:
+		if len(request.Diff) != 2 {
+			return fmt.Errorf("Expected 2 diff paths but got %d", len(request.Diff))
+		}
 	default:
 		return fmt.Errorf("Invalid ReflectionType: %v", request.Reflection)
 	}
@@ -181,12 +204,22 @@ func generateReflectRequest(rand *rand.Rand, size int, gen *Request) {
 
 	chooseType := rand.Float32()
 
-	if chooseType < 0.3 {
+	if chooseType < 0.2 {
 		gen.Reflection = REFLECT_HEAD_PATH
-	} else if chooseType < 0.6 {
+	} else if chooseType < 0.4 {
 		gen.Reflection = REFLECT_INDEX
-	} else {
+	} else if chooseType < 0.6 {
 		gen.Reflection = REFLECT_DUMP_NAMESPACE
+	} else if chooseType < 0.7 {
+		gen.Reflection = REFLECT_CACHE_STATS
+	} else if chooseType < 0.8 {
+		gen.Reflection = REFLECT_JOURNAL
+	} else {
+		gen.Reflection = REFLECT_DIFF
+		gen.Diff = []crdt.IPFSPath{
+			crdt.GenLink(rand, size).Path(),
+			crdt.GenLink(rand, size).Path(),
+		}
 	}
 }
 
@@ -207,6 +240,9 @@ const (
 	REFLECT_HEAD_PATH
 	REFLECT_DUMP_NAMESPACE
 	REFLECT_INDEX
+	REFLECT_DIFF
+	REFLECT_CACHE_STATS
+	REFLECT_JOURNAL
 )
 
 type MessageType uint8
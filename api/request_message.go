@@ -30,6 +30,10 @@ func MakeRequestMessage(request Request) *proto.APIRequestMessage {
 		message.Query = query.MakeQueryMessage(request.Query)
 	}
 
+	for _, path := range request.Diff {
+		message.Diff = append(message.Diff, string(path))
+	}
+
 	return message
 }
 
@@ -52,6 +56,10 @@ func ReadRequestMessage(message *proto.APIRequestMessage) Request {
 		}
 	}
 
+	for _, path := range message.Diff {
+		request.Diff = append(request.Diff, crdt.IPFSPath(path))
+	}
+
 	if message.Query != nil {
 		query, err := query.ReadQueryMessage(message.Query)
 
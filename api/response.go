@@ -12,12 +12,19 @@ import (
 )
 
 type Response struct {
-	Msg       string
-	Err       error
-	Type      MessageType
-	Path      crdt.IPFSPath
-	Namespace crdt.Namespace
-	Index     crdt.Index
+	Msg        string
+	Err        error
+	Type       MessageType
+	Path       crdt.IPFSPath
+	Namespace  crdt.Namespace
+	Index      crdt.Index
+	Diff       crdt.NamespaceDiff
+	CacheStats []CacheStats
+	// JournalDepth is the number of joins waiting to be written to the RemoteStore.
+	JournalDepth int
+	// Head is the HEAD of the server when the Response was made.  It is not encoded, but a signed
+	// Response carries it in the signature.
+	Head crdt.IPFSPath
 }
 
 func (resp Response) IsEmpty() bool {
@@ -41,11 +48,19 @@ func (resp Response) Equals(other Response) bool {
 	ok := resp.Msg == other.Msg
 	ok = ok && resp.Type == other.Type
 	ok = ok && resp.Path == other.Path
+	ok = ok && len(resp.CacheStats) == len(other.CacheStats)
+	ok = ok && resp.JournalDepth == other.JournalDepth
 
 	if !ok {
 		return false
 	}
 
+	for i, stats := range resp.CacheStats {
+		if stats != other.CacheStats[i] {
+			return false
+		}
+	}
+
 	if resp.Err != nil {
 		if other.Err == nil {
 			return false
@@ -62,6 +77,10 @@ func (resp Response) Equals(other Response) bool {
 		return false
 	}
 
+	if !resp.Diff.Equals(other.Diff) {
+		return false
+	}
+
 	return true
 }
 
@@ -123,6 +142,9 @@ func DecodeResponseText(r io.Reader) (Response, error) {
 
 var RESPONSE_FAIL_MSG = "error"
 var RESPONSE_OK_MSG = "ok"
+
+// RESPONSE_PROVISIONAL_MSG is sent for a join that is journaled until the RemoteStore is reachable.
+var RESPONSE_PROVISIONAL_MSG = "ok (provisional)"
 var RESPONSE_OK Response = Response{Msg: RESPONSE_OK_MSG}
 var RESPONSE_FAIL Response = Response{Msg: RESPONSE_FAIL_MSG}
 var RESPONSE_QUERY Response = Response{Msg: RESPONSE_OK_MSG, Type: API_QUERY}
@@ -14,6 +14,8 @@ func MakeAPIResponseMessage(resp Response) *proto.APIResponseMessage {
 		Path:    string(resp.Path),
 	}
 
+	message.JournalDepth = uint64(resp.JournalDepth)
+
 	if resp.Err != nil {
 		message.Error = resp.Err.Error()
 		return message
@@ -28,9 +30,43 @@ func MakeAPIResponseMessage(resp Response) *proto.APIResponseMessage {
 
 	logInvalidIndex(indexInvalid)
 
+	if !resp.Diff.IsEmpty() {
+		diffMsg, diffInvalid := crdt.MakeNamespaceDiffMessage(resp.Diff)
+		message.Diff = diffMsg
+		logInvalidNamespace(diffInvalid)
+	}
+
+	for _, stats := range resp.CacheStats {
+		message.CacheStats = append(message.CacheStats, makeCacheStatsMessage(stats))
+	}
+
 	return message
 }
 
+func makeCacheStatsMessage(stats CacheStats) *proto.CacheStatsMessage {
+	return &proto.CacheStatsMessage{
+		Name:      stats.Name,
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Evictions: stats.Evictions,
+		Items:     stats.Items,
+		Bytes:     stats.Bytes,
+		MaxBytes:  stats.MaxBytes,
+	}
+}
+
+func readCacheStatsMessage(message *proto.CacheStatsMessage) CacheStats {
+	return CacheStats{
+		Name:      message.Name,
+		Hits:      message.Hits,
+		Misses:    message.Misses,
+		Evictions: message.Evictions,
+		Items:     message.Items,
+		Bytes:     message.Bytes,
+		MaxBytes:  message.MaxBytes,
+	}
+}
+
 func ReadAPIResponseMessage(message *proto.APIResponseMessage) Response {
 	resp := Response{
 		Msg:  message.Message,
@@ -38,6 +74,8 @@ func ReadAPIResponseMessage(message *proto.APIResponseMessage) Response {
 		Path: crdt.IPFSPath(message.Path),
 	}
 
+	resp.JournalDepth = int(message.JournalDepth)
+
 	if message.Error != "" {
 		resp.Err = errors.New(message.Error)
 		return resp
@@ -55,6 +93,16 @@ func ReadAPIResponseMessage(message *proto.APIResponseMessage) Response {
 		logInvalidIndex(indexInvalid)
 	}
 
+	if message.Diff != nil {
+		diff, diffInvalid := crdt.ReadNamespaceDiffMessage(message.Diff)
+		resp.Diff = diff
+		logInvalidNamespace(diffInvalid)
+	}
+
+	for _, stats := range message.CacheStats {
+		resp.CacheStats = append(resp.CacheStats, readCacheStatsMessage(stats))
+	}
+
 	return resp
 }
 
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	pb "github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/util"
+	"github.com/johnny-morrice/godless/proto"
+)
+
+// ResponseSigning says what a signed Response answers, and who signs it.
+type ResponseSigning struct {
+	// RequestHash is the RequestHash of the encoded Request.
+	RequestHash string
+	// Head is the HEAD of the server when the Response was made.
+	Head crdt.IPFSPath
+	Key  crypto.PrivateKey
+}
+
+// RequestHash identifies an encoded Request.
+func RequestHash(encodedRequest []byte) string {
+	hash := sha256.Sum256(encodedRequest)
+	return hex.EncodeToString(hash[:])
+}
+
+// EncodeSignedResponse is like EncodeResponse, but signs the response message.  The signature
+// covers the whole message, including the request hash, the HEAD and the namespace, so the Response
+// may be relayed by untrusted peers.
+func EncodeSignedResponse(resp Response, signing ResponseSigning, w io.Writer) error {
+	const failMsg = "EncodeSignedResponse failed"
+
+	message := MakeAPIResponseMessage(resp)
+	err := SignResponseMessage(message, signing)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	err = util.Encode(message, w)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return nil
+}
+
+// DecodeVerifiedResponse is like DecodeResponse, but fails unless the response message answers
+// the request and is signed by the key.
+func DecodeVerifiedResponse(r io.Reader, requestHash string, pub crypto.PublicKey) (Response, error) {
+	const failMsg = "DecodeVerifiedResponse failed"
+
+	message := &proto.APIResponseMessage{}
+
+	err := util.Decode(message, r)
+
+	if err != nil {
+		return RESPONSE_FAIL, errors.Wrap(err, failMsg)
+	}
+
+	err = VerifyResponseMessage(message, requestHash, pub)
+
+	if err != nil {
+		return RESPONSE_FAIL, errors.Wrap(err, failMsg)
+	}
+
+	return ReadAPIResponseMessage(message), nil
+}
+
+func SignResponseMessage(message *proto.APIResponseMessage, signing ResponseSigning) error {
+	const failMsg = "SignResponseMessage failed"
+
+	message.RequestHash = signing.RequestHash
+	message.Head = string(signing.Head)
+	message.Signature = ""
+
+	bs, err := pb.Marshal(message)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	sig, err := crypto.Sign(signing.Key, bs)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	sigText, err := crypto.PrintSignature(sig)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	message.Signature = string(sigText)
+	return nil
+}
+
+func VerifyResponseMessage(message *proto.APIResponseMessage, requestHash string, pub crypto.PublicKey) error {
+	const failMsg = "VerifyResponseMessage failed"
+
+	if message.Signature == "" {
+		return errors.New("Response is not signed")
+	}
+
+	if message.RequestHash != requestHash {
+		return errors.New("Response answers a different request")
+	}
+
+	sig, err := crypto.ParseSignature(crypto.SignatureText(message.Signature))
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	unsigned := *message
+	unsigned.Signature = ""
+
+	bs, err := pb.Marshal(&unsigned)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	ok, err := crypto.Verify(pub, bs, sig)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if !ok {
+		return errors.New("Response signature verification failed")
+	}
+
+	return nil
+}
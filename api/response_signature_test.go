@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/testutil"
+	"github.com/johnny-morrice/godless/internal/util"
+	"github.com/johnny-morrice/godless/proto"
+)
+
+func TestSignedResponse(t *testing.T) {
+	priv, pub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	_, otherPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	const size = 20
+	expected := GenResponse(testutil.Rand(), size)
+	expected.Err = nil
+	expected.Namespace = crdt.GenNamespace(testutil.Rand(), size)
+
+	requestHash := RequestHash([]byte("Request"))
+	signing := ResponseSigning{
+		RequestHash: requestHash,
+		Head:        "Head",
+		Key:         priv,
+	}
+
+	buff := &bytes.Buffer{}
+	testutil.AssertNil(t, EncodeSignedResponse(expected, signing, buff))
+	encoded := buff.Bytes()
+
+	actual, err := DecodeVerifiedResponse(bytes.NewReader(encoded), requestHash, pub)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected response", expected.Equals(actual))
+
+	_, err = DecodeVerifiedResponse(bytes.NewReader(encoded), requestHash, otherPub)
+	testutil.AssertNonNil(t, err)
+
+	_, err = DecodeVerifiedResponse(bytes.NewReader(encoded), RequestHash([]byte("Other")), pub)
+	testutil.AssertNonNil(t, err)
+
+	tamper := func(f func(message *proto.APIResponseMessage)) []byte {
+		message := &proto.APIResponseMessage{}
+		testutil.AssertNil(t, util.Decode(message, bytes.NewReader(encoded)))
+		f(message)
+		tampered := &bytes.Buffer{}
+		testutil.AssertNil(t, util.Encode(message, tampered))
+		return tampered.Bytes()
+	}
+
+	forgeries := [][]byte{
+		tamper(func(message *proto.APIResponseMessage) { message.Head = "Other Head" }),
+		tamper(func(message *proto.APIResponseMessage) { message.Namespace = nil }),
+		tamper(func(message *proto.APIResponseMessage) { message.Signature = "" }),
+	}
+
+	for _, forgery := range forgeries {
+		_, err = DecodeVerifiedResponse(bytes.NewReader(forgery), requestHash, pub)
+		testutil.AssertNonNil(t, err)
+	}
+}
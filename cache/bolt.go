@@ -3,6 +3,7 @@ package cache
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/boltdb/bolt"
 	"github.com/johnny-morrice/godless/api"
@@ -19,6 +20,13 @@ type BoltOptions struct {
 	FilePath  string
 	Mode      os.FileMode
 	Db        *bolt.DB
+	// MaxBytes is optional.  If positive, cached indices and namespaces are evicted to keep within
+	// this many bytes.
+	MaxBytes int64
+	// EvictionPolicy is optional.  Defaults to EVICT_LRU.
+	EvictionPolicy EvictionPolicy
+	// SweepInterval is optional.
+	SweepInterval time.Duration
 }
 
 type BoltFactory struct {
@@ -28,7 +36,10 @@ type BoltFactory struct {
 func (factory BoltFactory) MakeCache() (api.Cache, error) {
 	const failMsg = "BoltFactory.MakeCache failed"
 
-	cache := boltCache{db: factory.Db}
+	cache := boltCache{
+		db:      factory.Db,
+		tracker: makeBoltAccessTracker(factory.BoltOptions),
+	}
 
 	err := cache.initBuckets()
 
@@ -36,6 +47,10 @@ func (factory BoltFactory) MakeCache() (api.Cache, error) {
 		return nil, errors.Wrap(err, failMsg)
 	}
 
+	if factory.MaxBytes > 0 {
+		go cache.sweepForever()
+	}
+
 	return cache, nil
 }
 
@@ -71,11 +86,12 @@ func MakeBoltCacheFactory(options BoltOptions) (BoltFactory, error) {
 }
 
 type boltCache struct {
-	db *bolt.DB
+	db      *bolt.DB
+	tracker *boltAccessTracker
 }
 
 func (cache boltCache) initBuckets() error {
-	return createAllBucketsIfNotExists(cache.db, BOLT_NAMESPACE_CACHE_BUCKET, BOLT_INDEX_CACHE_BUCKET, BOLT_HEAD_CACHE_BUCKET)
+	return createAllBucketsIfNotExists(cache.db, BOLT_NAMESPACE_CACHE_BUCKET, BOLT_INDEX_CACHE_BUCKET, BOLT_HEAD_CACHE_BUCKET, BOLT_ACCESS_BUCKET, BOLT_VERIFIED_NAMESPACE_BUCKET)
 }
 
 func (cache boltCache) GetHead() (crdt.IPFSPath, error) {
@@ -130,6 +146,8 @@ func (cache boltCache) GetIndex(indexAddr crdt.IPFSPath) (crdt.Index, error) {
 		return crdt.EmptyIndex(), errors.Wrap(err, failMsg)
 	}
 
+	cache.tracker.touch(BOLT_INDEX_CACHE_BUCKET, key)
+
 	// TODO handle the invalid entries.
 	index, _ := crdt.ReadIndexMessage(indexMessage)
 
@@ -145,8 +163,8 @@ func (cache boltCache) SetIndex(indexAddr crdt.IPFSPath, index crdt.Index) error
 	indexMessage, _ := crdt.MakeIndexMessage(index)
 	key := []byte(indexAddr)
 
-	err := cache.updateIndex(func(bucket *bolt.Bucket) error {
-		return putMessage(bucket, key, indexMessage)
+	err := cache.db.Update(func(transaction *bolt.Tx) error {
+		return cache.putCached(transaction, BOLT_INDEX_CACHE_BUCKET, key, indexMessage)
 	})
 
 	if err != nil {
@@ -171,6 +189,8 @@ func (cache boltCache) GetNamespace(namespaceAddr crdt.IPFSPath) (crdt.Namespace
 		return crdt.EmptyNamespace(), errors.Wrap(err, failMsg)
 	}
 
+	cache.tracker.touch(BOLT_NAMESPACE_CACHE_BUCKET, key)
+
 	namespace, invalid := crdt.ReadNamespaceMessage(namespaceMessage)
 
 	if len(invalid) > 0 {
@@ -189,8 +209,8 @@ func (cache boltCache) SetNamespace(namespaceAddr crdt.IPFSPath, namespace crdt.
 	namespaceMessage, _ := crdt.MakeNamespaceMessage(namespace)
 	key := []byte(namespaceAddr)
 
-	err := cache.updateNamespace(func(bucket *bolt.Bucket) error {
-		return putMessage(bucket, key, namespaceMessage)
+	err := cache.db.Update(func(transaction *bolt.Tx) error {
+		return cache.putCached(transaction, BOLT_NAMESPACE_CACHE_BUCKET, key, namespaceMessage)
 	})
 
 	if err != nil {
@@ -202,33 +222,70 @@ func (cache boltCache) SetNamespace(namespaceAddr crdt.IPFSPath, namespace crdt.
 	return nil
 }
 
-func (cache boltCache) viewNamespace(viewer func(bucket *bolt.Bucket) error) error {
-	return cache.db.View(func(transaction *bolt.Tx) error {
-		bucket, err := getBucket(transaction, BOLT_NAMESPACE_CACHE_BUCKET)
+// IsVerifiedNamespace finds a marker set by SetVerifiedNamespace.  Namespaces are immutable, so
+// markers are kept when the namespace itself is evicted.  Markers are evicted by the sweep like
+// any other cached item.
+func (cache boltCache) IsVerifiedNamespace(namespaceAddr crdt.IPFSPath, digest string) (bool, error) {
+	const failMsg = "boltCache.IsVerifiedNamespace failed"
+
+	key := makeVerifiedKey(namespaceAddr, digest)
+	isVerified := false
+	err := cache.db.View(func(transaction *bolt.Tx) error {
+		bucket, err := getBucket(transaction, BOLT_VERIFIED_NAMESPACE_BUCKET)
 
 		if err != nil {
 			return err
 		}
 
-		return viewer(bucket)
+		isVerified = bucket.Get(key) != nil
+		return nil
 	})
+
+	if err != nil {
+		return false, errors.Wrap(err, failMsg)
+	}
+
+	if isVerified {
+		cache.tracker.touch(BOLT_VERIFIED_NAMESPACE_BUCKET, key)
+	}
+
+	return isVerified, nil
 }
 
-func (cache boltCache) updateNamespace(updater func(bucket *bolt.Bucket) error) error {
-	return cache.db.Update(func(transaction *bolt.Tx) error {
-		bucket, err := getBucket(transaction, BOLT_NAMESPACE_CACHE_BUCKET)
+func (cache boltCache) SetVerifiedNamespace(namespaceAddr crdt.IPFSPath, digest string) error {
+	const failMsg = "boltCache.SetVerifiedNamespace failed"
+
+	err := cache.db.Update(func(transaction *bolt.Tx) error {
+		bucket, err := getBucket(transaction, BOLT_VERIFIED_NAMESPACE_BUCKET)
 
 		if err != nil {
 			return err
 		}
 
-		return updater(bucket)
+		key := makeVerifiedKey(namespaceAddr, digest)
+		err = bucket.Put(key, []byte{1})
+
+		if err != nil {
+			return err
+		}
+
+		return recordAccess(transaction, BOLT_VERIFIED_NAMESPACE_BUCKET, key)
 	})
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return nil
 }
 
-func (cache boltCache) viewIndex(viewer func(bucket *bolt.Bucket) error) error {
+func makeVerifiedKey(namespaceAddr crdt.IPFSPath, digest string) []byte {
+	return []byte(string(namespaceAddr) + "\x00" + digest)
+}
+
+func (cache boltCache) viewNamespace(viewer func(bucket *bolt.Bucket) error) error {
 	return cache.db.View(func(transaction *bolt.Tx) error {
-		bucket, err := getBucket(transaction, BOLT_INDEX_CACHE_BUCKET)
+		bucket, err := getBucket(transaction, BOLT_NAMESPACE_CACHE_BUCKET)
 
 		if err != nil {
 			return err
@@ -238,15 +295,15 @@ func (cache boltCache) viewIndex(viewer func(bucket *bolt.Bucket) error) error {
 	})
 }
 
-func (cache boltCache) updateIndex(updater func(bucket *bolt.Bucket) error) error {
-	return cache.db.Update(func(transaction *bolt.Tx) error {
+func (cache boltCache) viewIndex(viewer func(bucket *bolt.Bucket) error) error {
+	return cache.db.View(func(transaction *bolt.Tx) error {
 		bucket, err := getBucket(transaction, BOLT_INDEX_CACHE_BUCKET)
 
 		if err != nil {
 			return err
 		}
 
-		return updater(bucket)
+		return viewer(bucket)
 	})
 }
 
@@ -275,6 +332,7 @@ func (cache boltCache) updateHead(updater func(bucket *bolt.Bucket) error) error
 }
 
 func (cache boltCache) CloseCache() error {
+	cache.tracker.stop()
 	err := cache.db.Close()
 	log.Info("Closed boltCache")
 	return err
@@ -454,3 +512,5 @@ var BOLT_NAMESPACE_CACHE_BUCKET = []byte("namespace_cache")
 var BOLT_INDEX_CACHE_BUCKET = []byte("index_cache")
 var BOLT_MEMORY_IMAGE_INDEX_KEY = []byte("current_index")
 var BOLT_MEMORY_IMAGE_BUCKET = []byte("memory_image")
+var BOLT_ACCESS_BUCKET = []byte("cache_access")
+var BOLT_VERIFIED_NAMESPACE_BUCKET = []byte("verified_namespace")
@@ -0,0 +1,337 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/johnny-morrice/godless/log"
+	"github.com/pkg/errors"
+
+	pb "github.com/gogo/protobuf/proto"
+)
+
+// EvictionPolicy chooses which cached items are dropped first when the bolt cache is over budget.
+type EvictionPolicy uint8
+
+const (
+	// EVICT_LRU drops the least recently used items first.
+	EVICT_LRU = EvictionPolicy(iota)
+	// EVICT_LFU drops the least frequently used items first.
+	EVICT_LFU
+)
+
+// boltAccessTracker records reads in memory, so that a cache hit does not need a write transaction.
+// The records are written to BOLT_ACCESS_BUCKET before each sweep.
+type boltAccessTracker struct {
+	sync.Mutex
+	policy   EvictionPolicy
+	maxBytes int64
+	interval time.Duration
+	pending  map[string]boltAccess
+	stopper  chan struct{}
+	stopOnce sync.Once
+}
+
+type boltAccess struct {
+	lastAccess int64
+	hits       uint64
+}
+
+func makeBoltAccessTracker(options BoltOptions) *boltAccessTracker {
+	interval := options.SweepInterval
+
+	if interval <= 0 {
+		interval = __DEFAULT_SWEEP_INTERVAL
+	}
+
+	return &boltAccessTracker{
+		policy:   options.EvictionPolicy,
+		maxBytes: options.MaxBytes,
+		interval: interval,
+		pending:  map[string]boltAccess{},
+		stopper:  make(chan struct{}),
+	}
+}
+
+func (tracker *boltAccessTracker) touch(bucketName, key []byte) {
+	tracker.Lock()
+	defer tracker.Unlock()
+
+	accessKey := string(makeAccessKey(bucketName, key))
+	access := tracker.pending[accessKey]
+	access.lastAccess = time.Now().UnixNano()
+	access.hits++
+	tracker.pending[accessKey] = access
+}
+
+func (tracker *boltAccessTracker) takePending() map[string]boltAccess {
+	tracker.Lock()
+	defer tracker.Unlock()
+
+	pending := tracker.pending
+	tracker.pending = map[string]boltAccess{}
+	return pending
+}
+
+func (tracker *boltAccessTracker) stop() {
+	tracker.stopOnce.Do(func() {
+		close(tracker.stopper)
+	})
+}
+
+// putCached writes a message to a cache bucket, and counts the write as an access.
+func (cache boltCache) putCached(transaction *bolt.Tx, bucketName, key []byte, message pb.Message) error {
+	bucket, err := getBucket(transaction, bucketName)
+
+	if err != nil {
+		return err
+	}
+
+	err = putMessage(bucket, key, message)
+
+	if err != nil {
+		return err
+	}
+
+	return recordAccess(transaction, bucketName, key)
+}
+
+// recordAccess counts a write to a cache bucket as an access.
+func recordAccess(transaction *bolt.Tx, bucketName, key []byte) error {
+	accessBucket, err := getBucket(transaction, BOLT_ACCESS_BUCKET)
+
+	if err != nil {
+		return err
+	}
+
+	update := boltAccess{lastAccess: time.Now().UnixNano(), hits: 1}
+	return mergeAccess(accessBucket, makeAccessKey(bucketName, key), update)
+}
+
+func (cache boltCache) sweepForever() {
+	timer := time.NewTicker(cache.tracker.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			err := cache.sweep()
+
+			if err != nil {
+				log.Error("boltCache sweep failed: %s", err.Error())
+			}
+		case <-cache.tracker.stopper:
+			return
+		}
+	}
+}
+
+// sweep evicts cached indices, namespaces and verified namespace markers until they fit in the
+// byte budget.  The index at HEAD is never evicted, and the HEAD and memory image buckets are not
+// counted.  Bolt reuses the freed pages, so the database file stops growing, though it does not
+// shrink.
+func (cache boltCache) sweep() error {
+	const failMsg = "boltCache.sweep failed"
+
+	evicted := 0
+	err := cache.db.Update(func(transaction *bolt.Tx) error {
+		accessBucket, err := getBucket(transaction, BOLT_ACCESS_BUCKET)
+
+		if err != nil {
+			return err
+		}
+
+		err = cache.flushAccess(transaction, accessBucket)
+
+		if err != nil {
+			return err
+		}
+
+		headBucket, err := getBucket(transaction, BOLT_HEAD_CACHE_BUCKET)
+
+		if err != nil {
+			return err
+		}
+
+		head := headBucket.Get(BOLT_HEAD_CACHE_KEY)
+
+		var total int64
+		candidates := []evictionCandidate{}
+
+		for _, bucketName := range __EVICTABLE_BUCKETS {
+			bucket, err := getBucket(transaction, bucketName)
+
+			if err != nil {
+				return err
+			}
+
+			isIndex := bytes.Equal(bucketName, BOLT_INDEX_CACHE_BUCKET)
+			cursor := bucket.Cursor()
+			for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+				size := int64(len(key) + len(value))
+				total += size
+
+				if isIndex && bytes.Equal(key, head) {
+					continue
+				}
+
+				accessKey := makeAccessKey(bucketName, key)
+				candidates = append(candidates, evictionCandidate{
+					bucketName: bucketName,
+					key:        append([]byte{}, key...),
+					accessKey:  accessKey,
+					size:       size,
+					access:     decodeAccess(accessBucket.Get(accessKey)),
+				})
+			}
+		}
+
+		if total <= cache.tracker.maxBytes {
+			return nil
+		}
+
+		sortEvictionCandidates(candidates, cache.tracker.policy)
+
+		for _, candidate := range candidates {
+			if total <= cache.tracker.maxBytes {
+				break
+			}
+
+			bucket, err := getBucket(transaction, candidate.bucketName)
+
+			if err != nil {
+				return err
+			}
+
+			err = bucket.Delete(candidate.key)
+
+			if err != nil {
+				return err
+			}
+
+			err = accessBucket.Delete(candidate.accessKey)
+
+			if err != nil {
+				return err
+			}
+
+			total -= candidate.size
+			evicted++
+		}
+
+		if total > cache.tracker.maxBytes {
+			log.Warn("boltCache is over budget after evicting all it can: %d bytes", total)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if evicted > 0 {
+		log.Info("Evicted %d items from Bolt cache", evicted)
+	}
+
+	return nil
+}
+
+func (cache boltCache) flushAccess(transaction *bolt.Tx, accessBucket *bolt.Bucket) error {
+	for accessKey, update := range cache.tracker.takePending() {
+		key := []byte(accessKey)
+		bucketName, cacheKey := splitAccessKey(key)
+		bucket := transaction.Bucket(bucketName)
+
+		// The item may have been evicted since it was read.
+		if bucket == nil || bucket.Get(cacheKey) == nil {
+			continue
+		}
+
+		err := mergeAccess(accessBucket, key, update)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type evictionCandidate struct {
+	bucketName []byte
+	key        []byte
+	accessKey  []byte
+	size       int64
+	access     boltAccess
+}
+
+func sortEvictionCandidates(candidates []evictionCandidate, policy EvictionPolicy) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].access, candidates[j].access
+
+		if policy == EVICT_LFU && a.hits != b.hits {
+			return a.hits < b.hits
+		}
+
+		return a.lastAccess < b.lastAccess
+	})
+}
+
+func mergeAccess(accessBucket *bolt.Bucket, accessKey []byte, update boltAccess) error {
+	access := decodeAccess(accessBucket.Get(accessKey))
+
+	if update.lastAccess > access.lastAccess {
+		access.lastAccess = update.lastAccess
+	}
+
+	access.hits += update.hits
+
+	return accessBucket.Put(accessKey, encodeAccess(access))
+}
+
+func makeAccessKey(bucketName, key []byte) []byte {
+	accessKey := make([]byte, 0, len(bucketName)+1+len(key))
+	accessKey = append(accessKey, bucketName...)
+	accessKey = append(accessKey, __ACCESS_KEY_SEPARATOR)
+	return append(accessKey, key...)
+}
+
+func splitAccessKey(accessKey []byte) ([]byte, []byte) {
+	parts := bytes.SplitN(accessKey, []byte{__ACCESS_KEY_SEPARATOR}, 2)
+
+	if len(parts) != 2 {
+		return accessKey, nil
+	}
+
+	return parts[0], parts[1]
+}
+
+func encodeAccess(access boltAccess) []byte {
+	bs := make([]byte, __ACCESS_RECORD_SIZE)
+	binary.BigEndian.PutUint64(bs, uint64(access.lastAccess))
+	binary.BigEndian.PutUint64(bs[8:], access.hits)
+	return bs
+}
+
+// decodeAccess treats a missing or damaged record as never accessed.
+func decodeAccess(bs []byte) boltAccess {
+	if len(bs) != __ACCESS_RECORD_SIZE {
+		return boltAccess{}
+	}
+
+	return boltAccess{
+		lastAccess: int64(binary.BigEndian.Uint64(bs)),
+		hits:       binary.BigEndian.Uint64(bs[8:]),
+	}
+}
+
+var __EVICTABLE_BUCKETS = [][]byte{BOLT_INDEX_CACHE_BUCKET, BOLT_NAMESPACE_CACHE_BUCKET, BOLT_VERIFIED_NAMESPACE_BUCKET}
+
+const __ACCESS_KEY_SEPARATOR = byte(0)
+const __ACCESS_RECORD_SIZE = 16
+const __DEFAULT_SWEEP_INTERVAL = time.Minute
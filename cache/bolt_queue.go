@@ -0,0 +1,456 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	"github.com/boltdb/bolt"
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/log"
+	"github.com/johnny-morrice/godless/query"
+	"github.com/pkg/errors"
+)
+
+type BoltQueueOptions struct {
+	// BufferSize is optional.  It is the number of queued items kept in memory.
+	BufferSize int
+	// Spill is optional.  If true, api.Command items beyond the buffer are kept only on disk,
+	// instead of being rejected.
+	Spill bool
+}
+
+// MakePriorityQueue makes an api.RequestPriorityQueue that writes each request that changes data
+// to the bolt database before accepting it, and deletes it once the request has been answered.
+// Joins and replications left over from a previous run are drained before anything else.
+func (factory BoltFactory) MakePriorityQueue(options BoltQueueOptions) (api.RequestPriorityQueue, error) {
+	const failMsg = "BoltFactory.MakePriorityQueue failed"
+
+	bufferSize := options.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = __DEFAULT_BUFFER_SIZE
+	}
+
+	queue := &boltPriorityQueue{
+		db:         factory.Db,
+		bufferSize: bufferSize,
+		spill:      options.Spill,
+		buffered:   map[string]interface{}{},
+		spilled:    map[string]chan api.Response{},
+		stored:     map[string]struct{}{},
+		ready:      make(chan struct{}, 1),
+		datach:     make(chan interface{}),
+		stopper:    make(chan struct{}),
+	}
+
+	err := createAllBucketsIfNotExists(queue.db, BOLT_REQUEST_QUEUE_BUCKET)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	err = queue.loadReplay()
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	return queue, nil
+}
+
+// boltPriorityQueue keys each request by priority then sequence, so sorted keys are in the order
+// they should be run.  The in-memory data for a request is kept alongside, up to the buffer size.
+type boltPriorityQueue struct {
+	sync.Mutex
+	db         *bolt.DB
+	bufferSize int
+	spill      bool
+	length     int
+	sequence   uint64
+	buffered   map[string]interface{}
+	spilled    map[string]chan api.Response
+	stored     map[string]struct{}
+	pending    [][]byte
+	replay     [][]byte
+	ready      chan struct{}
+	datach     chan interface{}
+	stopper    chan struct{}
+}
+
+// loadReplay keeps the requests that changed state when the last run stopped, and drops the rest,
+// since nobody is waiting for their response.
+func (queue *boltPriorityQueue) loadReplay() error {
+	err := queue.db.Update(func(transaction *bolt.Tx) error {
+		bucket, err := getBucket(transaction, BOLT_REQUEST_QUEUE_BUCKET)
+
+		if err != nil {
+			return err
+		}
+
+		queue.sequence = bucket.Sequence()
+
+		dropped := [][]byte{}
+		cursor := bucket.Cursor()
+		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+			request, err := api.DecodeRequest(bytes.NewReader(value))
+
+			if err == nil && isDurableRequest(request) {
+				queue.replay = append(queue.replay, append([]byte{}, key...))
+			} else {
+				dropped = append(dropped, append([]byte{}, key...))
+			}
+		}
+
+		for _, key := range dropped {
+			err := bucket.Delete(key)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Replay in the order that requests were made.
+	sortQueueKeysBySequence(queue.replay)
+	queue.length = len(queue.replay)
+
+	for _, key := range queue.replay {
+		queue.stored[string(key)] = struct{}{}
+	}
+
+	if queue.length > 0 {
+		log.Info("Replaying %d queued requests from Bolt", queue.length)
+		queue.signal()
+	}
+
+	return nil
+}
+
+func (queue *boltPriorityQueue) Len() int {
+	queue.Lock()
+	defer queue.Unlock()
+	return queue.length
+}
+
+func (queue *boltPriorityQueue) Enqueue(request api.Request, data interface{}) error {
+	const failMsg = "boltPriorityQueue.Enqueue failed"
+
+	priority, err := findRequestPriority(request)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	queue.Lock()
+	defer queue.Unlock()
+
+	isBuffered := len(queue.buffered) < queue.bufferSize
+	command, isCommand := data.(api.Command)
+
+	if !isBuffered && !(queue.spill && isCommand) {
+		return fullQueue
+	}
+
+	queue.sequence++
+	key := makeQueueKey(priority, queue.sequence)
+
+	// Queries are lost on restart anyway, so only spilled requests need to be written for them.
+	if !isBuffered || isDurableRequest(request) {
+		err = queue.store(key, request)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+	}
+
+	if isBuffered {
+		queue.buffered[string(key)] = data
+	} else {
+		log.Debug("Spilling request to Bolt")
+		queue.spilled[string(key)] = command.Response
+	}
+
+	queue.addPending(key)
+	queue.length++
+	queue.signal()
+
+	return nil
+}
+
+func (queue *boltPriorityQueue) store(key []byte, request api.Request) error {
+	encoded := &bytes.Buffer{}
+	err := api.EncodeRequest(request, encoded)
+
+	if err != nil {
+		return err
+	}
+
+	err = queue.db.Update(func(transaction *bolt.Tx) error {
+		bucket, err := getBucket(transaction, BOLT_REQUEST_QUEUE_BUCKET)
+
+		if err != nil {
+			return err
+		}
+
+		err = bucket.SetSequence(queue.sequence)
+
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, encoded.Bytes())
+	})
+
+	if err != nil {
+		return err
+	}
+
+	queue.stored[string(key)] = struct{}{}
+
+	return nil
+}
+
+func (queue *boltPriorityQueue) addPending(key []byte) {
+	i := sort.Search(len(queue.pending), func(i int) bool {
+		return bytes.Compare(queue.pending[i], key) >= 0
+	})
+
+	queue.pending = append(queue.pending, nil)
+	copy(queue.pending[i+1:], queue.pending[i:])
+	queue.pending[i] = key
+}
+
+func (queue *boltPriorityQueue) Drain() <-chan interface{} {
+	go func() {
+		defer close(queue.datach)
+
+		for {
+			data, found, err := queue.popFront()
+
+			if err != nil {
+				log.Error("Error draining boltPriorityQueue: %s", err.Error())
+				return
+			}
+
+			if !found {
+				select {
+				case <-queue.ready:
+					continue
+				case <-queue.stopper:
+					return
+				}
+			}
+
+			select {
+			case queue.datach <- data:
+			case <-queue.stopper:
+				return
+			}
+		}
+	}()
+
+	return queue.datach
+}
+
+func (queue *boltPriorityQueue) Close() error {
+	close(queue.stopper)
+	log.Info("Closed boltPriorityQueue")
+	return nil
+}
+
+func (queue *boltPriorityQueue) signal() {
+	select {
+	case queue.ready <- struct{}{}:
+	default:
+	}
+}
+
+// popFront takes the next request, and finds or rebuilds its data.  A request written to bolt stays
+// there until it is answered.  Requests that cannot be decoded are logged and dropped.
+func (queue *boltPriorityQueue) popFront() (interface{}, bool, error) {
+	queue.Lock()
+	defer queue.Unlock()
+
+	for {
+		key := queue.nextKey()
+
+		if key == nil {
+			return nil, false, nil
+		}
+
+		queue.length--
+
+		if data, present := queue.buffered[string(key)]; present {
+			delete(queue.buffered, string(key))
+			return queue.acknowledgeLater(key, data), true, nil
+		}
+
+		response, isSpilled := queue.spilled[string(key)]
+		delete(queue.spilled, string(key))
+
+		command, err := queue.readCommand(key)
+
+		if err != nil {
+			log.Error("Dropping corrupt request from boltPriorityQueue: %s", err.Error())
+			queue.acknowledge(key)
+
+			if isSpilled {
+				fail := api.RESPONSE_FAIL
+				fail.Err = errors.Wrap(err, "Corrupt queued request")
+				response <- fail
+				close(response)
+			}
+
+			continue
+		}
+
+		if isSpilled {
+			command.Response = response
+		}
+
+		return queue.acknowledgeLater(key, command), true, nil
+	}
+}
+
+// nextKey takes the key of the next request, replaying requests from the last run first.
+func (queue *boltPriorityQueue) nextKey() []byte {
+	if len(queue.replay) > 0 {
+		key := queue.replay[0]
+		queue.replay = queue.replay[1:]
+		return key
+	}
+
+	if len(queue.pending) > 0 {
+		key := queue.pending[0]
+		queue.pending = queue.pending[1:]
+		return key
+	}
+
+	return nil
+}
+
+func (queue *boltPriorityQueue) readCommand(key []byte) (api.Command, error) {
+	var value []byte
+	err := queue.db.View(func(transaction *bolt.Tx) error {
+		bucket, err := getBucket(transaction, BOLT_REQUEST_QUEUE_BUCKET)
+
+		if err != nil {
+			return err
+		}
+
+		value = append([]byte{}, bucket.Get(key)...)
+		return nil
+	})
+
+	if err != nil {
+		return api.Command{}, err
+	}
+
+	return readQueuedCommand(value)
+}
+
+// acknowledgeLater deletes a stored request from bolt once the command has written its response,
+// which is then passed on to the caller.  Requests that were never stored are returned as they are.
+func (queue *boltPriorityQueue) acknowledgeLater(key []byte, data interface{}) interface{} {
+	if _, isStored := queue.stored[string(key)]; !isStored {
+		return data
+	}
+
+	command, isCommand := data.(api.Command)
+
+	if !isCommand {
+		queue.acknowledge(key)
+		return data
+	}
+
+	caller := command.Response
+	answer := make(chan api.Response, 1)
+	command.Response = answer
+
+	go func() {
+		response, ok := <-answer
+
+		queue.Lock()
+		queue.acknowledge(key)
+		queue.Unlock()
+
+		if ok {
+			caller <- response
+		}
+
+		close(caller)
+	}()
+
+	return command
+}
+
+func (queue *boltPriorityQueue) acknowledge(key []byte) {
+	delete(queue.stored, string(key))
+
+	err := queue.db.Update(func(transaction *bolt.Tx) error {
+		bucket, err := getBucket(transaction, BOLT_REQUEST_QUEUE_BUCKET)
+
+		if err != nil {
+			return err
+		}
+
+		return bucket.Delete(key)
+	})
+
+	if err != nil {
+		log.Error("Failed to delete request from boltPriorityQueue: %s", err.Error())
+	}
+}
+
+func readQueuedCommand(value []byte) (api.Command, error) {
+	request, err := api.DecodeRequest(bytes.NewReader(value))
+
+	if err != nil {
+		return api.Command{}, err
+	}
+
+	return request.MakeCommand()
+}
+
+func isDurableRequest(request api.Request) bool {
+	switch request.Type {
+	case api.API_QUERY:
+		return request.Query != nil && request.Query.OpCode == query.JOIN
+	case api.API_REPLICATE:
+		return true
+	default:
+		return false
+	}
+}
+
+func makeQueueKey(priority residentPriority, sequence uint64) []byte {
+	key := make([]byte, __QUEUE_KEY_SIZE)
+	key[0] = byte(priority)
+	binary.BigEndian.PutUint64(key[1:], sequence)
+	return key
+}
+
+func sortQueueKeysBySequence(keys [][]byte) {
+	sequence := func(key []byte) uint64 {
+		if len(key) != __QUEUE_KEY_SIZE {
+			return 0
+		}
+
+		return binary.BigEndian.Uint64(key[1:])
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return sequence(keys[i]) < sequence(keys[j])
+	})
+}
+
+const __QUEUE_KEY_SIZE = 9
+
+var BOLT_REQUEST_QUEUE_BUCKET = []byte("request_queue")
@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/internal/testutil"
+	"github.com/johnny-morrice/godless/query"
+)
+
+func TestBoltPriorityQueueReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godless-bolt-queue")
+	testutil.AssertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	options := BoltOptions{
+		FilePath: path.Join(dir, "test.bolt"),
+		Mode:     0600,
+	}
+
+	joinA := makeQueueTestCommand(t, "join cars rows (@key=car1, driver=\"A\")")
+	joinB := makeQueueTestCommand(t, "join cars rows (@key=car2, driver=\"B\")")
+	selectCars := makeQueueTestCommand(t, "select cars")
+
+	factory, err := MakeBoltCacheFactory(options)
+	testutil.AssertNil(t, err)
+	queue, err := factory.MakePriorityQueue(BoltQueueOptions{})
+	testutil.AssertNil(t, err)
+
+	for _, command := range []api.Command{selectCars, joinA, joinB} {
+		testutil.AssertNil(t, queue.Enqueue(command.Request, command))
+	}
+
+	testutil.AssertEquals(t, "Unexpected length", 3, queue.Len())
+	queue.Close()
+	testutil.AssertNil(t, factory.Db.Close())
+
+	factory, err = MakeBoltCacheFactory(options)
+	testutil.AssertNil(t, err)
+	defer factory.Db.Close()
+	queue, err = factory.MakePriorityQueue(BoltQueueOptions{})
+	testutil.AssertNil(t, err)
+	defer queue.Close()
+
+	// The select is dropped since nobody is waiting for it.
+	testutil.AssertEquals(t, "Unexpected length", 2, queue.Len())
+
+	reflect := makeQueueTestReflect(t)
+	testutil.AssertNil(t, queue.Enqueue(reflect.Request, reflect))
+
+	drain := queue.Drain()
+	expected := []api.Request{joinA.Request, joinB.Request, reflect.Request}
+
+	for _, request := range expected {
+		command := nextQueueCommand(t, drain)
+		testutil.Assert(t, "Unexpected request", request.Equals(command.Request))
+	}
+
+	testutil.AssertEquals(t, "Unexpected length", 0, queue.Len())
+}
+
+func TestBoltPriorityQueueSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godless-bolt-queue")
+	testutil.AssertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	options := BoltOptions{
+		FilePath: path.Join(dir, "test.bolt"),
+		Mode:     0600,
+	}
+
+	factory, err := MakeBoltCacheFactory(options)
+	testutil.AssertNil(t, err)
+	defer factory.Db.Close()
+
+	full, err := factory.MakePriorityQueue(BoltQueueOptions{BufferSize: 1})
+	testutil.AssertNil(t, err)
+	testutil.AssertNil(t, full.Enqueue(__REFLECT_REQUEST, "data"))
+	testutil.AssertNonNil(t, full.Enqueue(__REFLECT_REQUEST, "data"))
+	full.Close()
+
+	queue, err := factory.MakePriorityQueue(BoltQueueOptions{BufferSize: 1, Spill: true})
+	testutil.AssertNil(t, err)
+	defer queue.Close()
+
+	join := makeQueueTestCommand(t, "join cars rows (@key=car1, driver=\"A\")")
+	reflects := []api.Command{makeQueueTestReflect(t), makeQueueTestReflect(t)}
+
+	testutil.AssertNil(t, queue.Enqueue(reflects[0].Request, reflects[0]))
+	testutil.AssertNil(t, queue.Enqueue(reflects[1].Request, reflects[1]))
+	testutil.AssertNil(t, queue.Enqueue(join.Request, join))
+	testutil.AssertNonNil(t, queue.Enqueue(__REFLECT_REQUEST, "not a command"))
+	testutil.AssertEquals(t, "Unexpected length", 3, queue.Len())
+
+	drain := queue.Drain()
+
+	// The spilled join is rebuilt from disk, but still answers the original caller.
+	command := nextQueueCommand(t, drain)
+	testutil.Assert(t, "Unexpected request", join.Request.Equals(command.Request))
+	command.WriteResponse(api.RESPONSE_QUERY)
+	assertQueueResponse(t, join.Response)
+
+	for _, reflect := range reflects {
+		command = nextQueueCommand(t, drain)
+		command.WriteResponse(api.RESPONSE_REFLECT)
+		assertQueueResponse(t, reflect.Response)
+	}
+}
+
+func TestBoltPriorityQueueAcknowledge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godless-bolt-queue")
+	testutil.AssertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	options := BoltOptions{
+		FilePath: path.Join(dir, "test.bolt"),
+		Mode:     0600,
+	}
+
+	factory, err := MakeBoltCacheFactory(options)
+	testutil.AssertNil(t, err)
+	defer factory.Db.Close()
+
+	queue, err := factory.MakePriorityQueue(BoltQueueOptions{})
+	testutil.AssertNil(t, err)
+	defer queue.Close()
+
+	join := makeQueueTestCommand(t, "join cars rows (@key=car1, driver=\"A\")")
+	selectCars := makeQueueTestCommand(t, "select cars")
+
+	testutil.AssertNil(t, queue.Enqueue(join.Request, join))
+	testutil.AssertNil(t, queue.Enqueue(selectCars.Request, selectCars))
+
+	// Only the join changes data, so only the join is written.
+	assertQueueStored(t, factory, 1)
+
+	drain := queue.Drain()
+	command := nextQueueCommand(t, drain)
+	testutil.Assert(t, "Unexpected request", join.Request.Equals(command.Request))
+
+	// The join is kept until it has been answered.
+	assertQueueStored(t, factory, 1)
+
+	command.WriteResponse(api.RESPONSE_QUERY)
+	assertQueueResponse(t, join.Response)
+	assertQueueStored(t, factory, 0)
+
+	command = nextQueueCommand(t, drain)
+	testutil.Assert(t, "Unexpected response channel", selectCars.Response == command.Response)
+}
+
+func assertQueueStored(t *testing.T, factory BoltFactory, expected int) {
+	count := 0
+	err := factory.Db.View(func(transaction *bolt.Tx) error {
+		count = transaction.Bucket(BOLT_REQUEST_QUEUE_BUCKET).Stats().KeyN
+		return nil
+	})
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected stored requests", expected, count)
+}
+
+func assertQueueResponse(t *testing.T, response <-chan api.Response) {
+	select {
+	case _, ok := <-response:
+		testutil.Assert(t, "Expected response", ok)
+	case <-time.After(time.Second * 2):
+		t.Fatal("Timed out waiting for response")
+	}
+}
+
+func makeQueueTestCommand(t *testing.T, source string) api.Command {
+	q, err := query.Compile(source)
+	testutil.AssertNil(t, err)
+
+	command, err := api.MakeQueryRequest(q).MakeCommand()
+	testutil.AssertNil(t, err)
+	return command
+}
+
+func makeQueueTestReflect(t *testing.T) api.Command {
+	command, err := api.MakeReflectRequest(api.REFLECT_HEAD_PATH).MakeCommand()
+	testutil.AssertNil(t, err)
+	return command
+}
+
+func nextQueueCommand(t *testing.T, drain <-chan interface{}) api.Command {
+	select {
+	case data := <-drain:
+		command, ok := data.(api.Command)
+		testutil.Assert(t, "Expected api.Command", ok)
+		return command
+	case <-time.After(time.Second * 2):
+		t.Fatal("Timed out waiting for queue")
+		return api.Command{}
+	}
+}
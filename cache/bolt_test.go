@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestBoltCacheEvictLRU(t *testing.T) {
+	cache, cleanup := makeTestBoltCache(t, EVICT_LRU)
+	defer cleanup()
+
+	addrs := setTestNamespaces(t, cache, "A", "B", "C")
+
+	for _, addr := range addrs[:2] {
+		_, err := cache.GetNamespace(addr)
+		testutil.AssertNil(t, err)
+	}
+
+	cache.tracker.maxBytes = boltCacheBytes(t, cache) - 1
+	testutil.AssertNil(t, cache.sweep())
+
+	assertCachedNamespaces(t, cache, addrs[:2], addrs[2:])
+}
+
+func TestBoltCacheEvictLFU(t *testing.T) {
+	cache, cleanup := makeTestBoltCache(t, EVICT_LFU)
+	defer cleanup()
+
+	addrs := setTestNamespaces(t, cache, "A", "B", "C")
+
+	for _, addr := range []crdt.IPFSPath{addrs[0], addrs[0], addrs[2], addrs[2], addrs[1]} {
+		_, err := cache.GetNamespace(addr)
+		testutil.AssertNil(t, err)
+	}
+
+	cache.tracker.maxBytes = boltCacheBytes(t, cache) - 1
+	testutil.AssertNil(t, cache.sweep())
+
+	assertCachedNamespaces(t, cache, []crdt.IPFSPath{addrs[0], addrs[2]}, addrs[1:2])
+}
+
+func TestBoltCacheNeverEvictsHead(t *testing.T) {
+	cache, cleanup := makeTestBoltCache(t, EVICT_LRU)
+	defer cleanup()
+
+	const headAddr = crdt.IPFSPath("Head")
+	const otherAddr = crdt.IPFSPath("Other")
+	index := crdt.EmptyIndex().JoinTable("Table", crdt.UnsignedLink("Link"))
+
+	testutil.AssertNil(t, cache.SetIndex(headAddr, index))
+	testutil.AssertNil(t, cache.SetIndex(otherAddr, index))
+	testutil.AssertNil(t, cache.SetHead(headAddr))
+	addrs := setTestNamespaces(t, cache, "A")
+
+	cache.tracker.maxBytes = 0
+	testutil.AssertNil(t, cache.sweep())
+
+	_, err := cache.GetIndex(headAddr)
+	testutil.AssertNil(t, err)
+	_, err = cache.GetIndex(otherAddr)
+	testutil.AssertNonNil(t, err)
+	assertCachedNamespaces(t, cache, nil, addrs)
+
+	head, err := cache.GetHead()
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected head", headAddr, head)
+}
+
+func TestBoltCacheVerifiedNamespace(t *testing.T) {
+	cache, cleanup := makeTestBoltCache(t, EVICT_LRU)
+	defer cleanup()
+
+	const addr = crdt.IPFSPath("Namespace")
+
+	isVerified, err := cache.IsVerifiedNamespace(addr, "digest")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected marker", !isVerified)
+
+	testutil.AssertNil(t, cache.SetVerifiedNamespace(addr, "digest"))
+
+	isVerified, err = cache.IsVerifiedNamespace(addr, "digest")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected marker", isVerified)
+
+	isVerified, err = cache.IsVerifiedNamespace(addr, "other")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected marker for other digest", !isVerified)
+}
+
+func TestBoltCacheEvictsVerifiedNamespace(t *testing.T) {
+	cache, cleanup := makeTestBoltCache(t, EVICT_LRU)
+	defer cleanup()
+
+	testutil.AssertNil(t, cache.SetVerifiedNamespace("A", "digest"))
+	testutil.AssertNil(t, cache.SetVerifiedNamespace("B", "digest"))
+
+	isVerified, err := cache.IsVerifiedNamespace("A", "digest")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected marker", isVerified)
+
+	cache.tracker.maxBytes = boltCacheBytes(t, cache) - 1
+	testutil.AssertNil(t, cache.sweep())
+
+	isVerified, err = cache.IsVerifiedNamespace("A", "digest")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected marker", isVerified)
+
+	isVerified, err = cache.IsVerifiedNamespace("B", "digest")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected marker", !isVerified)
+}
+
+func makeTestBoltCache(t *testing.T, policy EvictionPolicy) (boltCache, func()) {
+	dir, err := ioutil.TempDir("", "godless-bolt")
+	testutil.AssertNil(t, err)
+
+	options := BoltOptions{
+		FilePath:       path.Join(dir, "test.bolt"),
+		Mode:           0600,
+		EvictionPolicy: policy,
+	}
+
+	factory, err := MakeBoltCacheFactory(options)
+	testutil.AssertNil(t, err)
+
+	cache, err := factory.MakeCache()
+	testutil.AssertNil(t, err)
+
+	cleanup := func() {
+		cache.CloseCache()
+		os.RemoveAll(dir)
+	}
+
+	return cache.(boltCache), cleanup
+}
+
+func setTestNamespaces(t *testing.T, cache boltCache, names ...string) []crdt.IPFSPath {
+	addrs := make([]crdt.IPFSPath, len(names))
+
+	for i, name := range names {
+		addr := crdt.IPFSPath(name)
+		namespace := crdt.EmptyNamespace().JoinTable("Table", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			crdt.RowName(name): crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point")}),
+			}),
+		}))
+
+		testutil.AssertNil(t, cache.SetNamespace(addr, namespace))
+		addrs[i] = addr
+	}
+
+	return addrs
+}
+
+func assertCachedNamespaces(t *testing.T, cache boltCache, present, absent []crdt.IPFSPath) {
+	for _, addr := range present {
+		_, err := cache.GetNamespace(addr)
+		testutil.AssertNil(t, err)
+	}
+
+	for _, addr := range absent {
+		_, err := cache.GetNamespace(addr)
+		testutil.AssertNonNil(t, err)
+	}
+}
+
+func boltCacheBytes(t *testing.T, cache boltCache) int64 {
+	var total int64
+	err := cache.db.View(func(transaction *bolt.Tx) error {
+		for _, bucketName := range __EVICTABLE_BUCKETS {
+			cursor := transaction.Bucket(bucketName).Cursor()
+			for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+				total += int64(len(key) + len(value))
+			}
+		}
+
+		return nil
+	})
+
+	testutil.AssertNil(t, err)
+	return total
+}
@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/boltdb/bolt"
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/log"
+	"github.com/johnny-morrice/godless/proto"
+	"github.com/pkg/errors"
+
+	pb "github.com/gogo/protobuf/proto"
+)
+
+// residentJoinJournal is lost when the process exits.  For use only in tests.
+type residentJoinJournal struct {
+	sync.Mutex
+	entries []api.JournalEntry
+	nextID  uint64
+}
+
+func MakeResidentJoinJournal() api.JoinJournal {
+	return &residentJoinJournal{nextID: 1}
+}
+
+func (journal *residentJoinJournal) AppendJoin(namespace crdt.Namespace) (api.JournalEntry, error) {
+	journal.Lock()
+	defer journal.Unlock()
+
+	entry := api.JournalEntry{ID: journal.nextID, Namespace: namespace}
+	journal.nextID++
+	journal.entries = append(journal.entries, entry)
+	return entry, nil
+}
+
+func (journal *residentJoinJournal) GetJoins() ([]api.JournalEntry, error) {
+	journal.Lock()
+	defer journal.Unlock()
+
+	entries := make([]api.JournalEntry, len(journal.entries))
+	copy(entries, journal.entries)
+	return entries, nil
+}
+
+func (journal *residentJoinJournal) RemoveJoin(id uint64) error {
+	journal.Lock()
+	defer journal.Unlock()
+
+	for i, entry := range journal.entries {
+		if entry.ID == id {
+			journal.entries = append(journal.entries[:i], journal.entries[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("No journal entry: %d", id)
+}
+
+func (journal *residentJoinJournal) JournalDepth() (int, error) {
+	journal.Lock()
+	defer journal.Unlock()
+	return len(journal.entries), nil
+}
+
+// MakeJoinJournal makes an api.JoinJournal that survives restarts.
+func (factory BoltFactory) MakeJoinJournal() (api.JoinJournal, error) {
+	const failMsg = "BoltFactory.MakeJoinJournal failed"
+
+	journal := boltJoinJournal{db: factory.Db}
+
+	err := createAllBucketsIfNotExists(journal.db, BOLT_JOIN_JOURNAL_BUCKET)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	return journal, nil
+}
+
+// boltJoinJournal keys entries by bolt sequence, so a cursor visits them in the order they were made.
+type boltJoinJournal struct {
+	db *bolt.DB
+}
+
+func (journal boltJoinJournal) AppendJoin(namespace crdt.Namespace) (api.JournalEntry, error) {
+	const failMsg = "boltJoinJournal.AppendJoin failed"
+
+	message, invalid := crdt.MakeNamespaceMessage(namespace)
+
+	if len(invalid) > 0 {
+		log.Error("Journal ignoring %d invalid entries", len(invalid))
+	}
+
+	entry := api.JournalEntry{Namespace: namespace}
+	err := journal.update(func(bucket *bolt.Bucket) error {
+		id, err := bucket.NextSequence()
+
+		if err != nil {
+			return err
+		}
+
+		entry.ID = id
+		return putMessage(bucket, makeJournalKey(id), message)
+	})
+
+	if err != nil {
+		return api.JournalEntry{}, errors.Wrap(err, failMsg)
+	}
+
+	log.Info("Journaled join %d in Bolt", entry.ID)
+
+	return entry, nil
+}
+
+func (journal boltJoinJournal) GetJoins() ([]api.JournalEntry, error) {
+	const failMsg = "boltJoinJournal.GetJoins failed"
+
+	entries := []api.JournalEntry{}
+	err := journal.view(func(bucket *bolt.Bucket) error {
+		cursor := bucket.Cursor()
+		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+			message := &proto.NamespaceMessage{}
+			err := pb.Unmarshal(value, message)
+
+			if err != nil {
+				return err
+			}
+
+			namespace, invalid := crdt.ReadNamespaceMessage(message)
+
+			if len(invalid) > 0 {
+				log.Error("Journal ignoring %d invalid entries", len(invalid))
+			}
+
+			entry := api.JournalEntry{
+				ID:        binary.BigEndian.Uint64(key),
+				Namespace: namespace,
+			}
+			entries = append(entries, entry)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	return entries, nil
+}
+
+func (journal boltJoinJournal) RemoveJoin(id uint64) error {
+	const failMsg = "boltJoinJournal.RemoveJoin failed"
+
+	err := journal.update(func(bucket *bolt.Bucket) error {
+		return bucket.Delete(makeJournalKey(id))
+	})
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return nil
+}
+
+func (journal boltJoinJournal) JournalDepth() (int, error) {
+	const failMsg = "boltJoinJournal.JournalDepth failed"
+
+	depth := 0
+	err := journal.view(func(bucket *bolt.Bucket) error {
+		depth = bucket.Stats().KeyN
+		return nil
+	})
+
+	if err != nil {
+		return 0, errors.Wrap(err, failMsg)
+	}
+
+	return depth, nil
+}
+
+func (journal boltJoinJournal) view(viewer func(bucket *bolt.Bucket) error) error {
+	return journal.db.View(func(transaction *bolt.Tx) error {
+		bucket, err := getBucket(transaction, BOLT_JOIN_JOURNAL_BUCKET)
+
+		if err != nil {
+			return err
+		}
+
+		return viewer(bucket)
+	})
+}
+
+func (journal boltJoinJournal) update(updater func(bucket *bolt.Bucket) error) error {
+	return journal.db.Update(func(transaction *bolt.Tx) error {
+		bucket, err := getBucket(transaction, BOLT_JOIN_JOURNAL_BUCKET)
+
+		if err != nil {
+			return err
+		}
+
+		return updater(bucket)
+	})
+}
+
+func makeJournalKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+var BOLT_JOIN_JOURNAL_BUCKET = []byte("join_journal")
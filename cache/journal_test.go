@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestBoltJoinJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godless-bolt-journal")
+	testutil.AssertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	options := BoltOptions{
+		FilePath: path.Join(dir, "test.bolt"),
+		Mode:     0600,
+	}
+
+	factory, err := MakeBoltCacheFactory(options)
+	testutil.AssertNil(t, err)
+	journal, err := factory.MakeJoinJournal()
+	testutil.AssertNil(t, err)
+
+	namespaces := []crdt.Namespace{
+		makeJournalTestNamespace("A"),
+		makeJournalTestNamespace("B"),
+		makeJournalTestNamespace("C"),
+	}
+
+	for _, namespace := range namespaces {
+		_, err := journal.AppendJoin(namespace)
+		testutil.AssertNil(t, err)
+	}
+
+	entries, err := journal.GetJoins()
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 3, entries)
+	testutil.AssertNil(t, journal.RemoveJoin(entries[0].ID))
+	testutil.AssertNil(t, factory.Db.Close())
+
+	factory, err = MakeBoltCacheFactory(options)
+	testutil.AssertNil(t, err)
+	defer factory.Db.Close()
+	journal, err = factory.MakeJoinJournal()
+	testutil.AssertNil(t, err)
+
+	depth, err := journal.JournalDepth()
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected depth", 2, depth)
+
+	entries, err = journal.GetJoins()
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 2, entries)
+
+	for i, entry := range entries {
+		testutil.Assert(t, "Unexpected namespace", namespaces[i+1].Equals(entry.Namespace))
+	}
+}
+
+func makeJournalTestNamespace(driver string) crdt.Namespace {
+	return crdt.EmptyNamespace().JoinTable("cars", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+		crdt.RowName(driver): crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"driver": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint(crdt.PointText(driver))}),
+		}),
+	}))
+}
@@ -1,9 +1,9 @@
 package cache
 
 import (
+	"container/list"
 	"fmt"
 	"sync"
-	"time"
 	"unsafe"
 
 	"github.com/johnny-morrice/godless/api"
@@ -11,6 +11,8 @@ import (
 	"github.com/johnny-morrice/godless/log"
 	"github.com/johnny-morrice/godless/query"
 	"github.com/pkg/errors"
+
+	pb "github.com/gogo/protobuf/proto"
 )
 
 // Non-ACID api.MemoryImage implementation.  For use only in tests.
@@ -39,10 +41,17 @@ func (memimg *residentMemoryImage) CloseMemoryImage() error {
 }
 
 func MakeResidentMemoryCache(indexBufferSize, namespaceBufferSize int) api.Cache {
+	indexOptions := ResidentCacheOptions{MaxItems: indexBufferSize}
+	namespaceOptions := ResidentCacheOptions{MaxItems: namespaceBufferSize}
+	return MakeBudgetedResidentMemoryCache(indexOptions, namespaceOptions)
+}
+
+// MakeBudgetedResidentMemoryCache makes an api.Cache with separate limits for indices and namespaces.
+func MakeBudgetedResidentMemoryCache(indexOptions, namespaceOptions ResidentCacheOptions) api.Cache {
 	return cacheUnion{
 		headCache:      MakeResidentHeadCache(),
-		indexCache:     MakeResidentIndexCache(indexBufferSize),
-		namespaceCache: MakeResidentNamespaceCache(namespaceBufferSize),
+		indexCache:     MakeBudgetedResidentIndexCache(indexOptions),
+		namespaceCache: MakeBudgetedResidentNamespaceCache(namespaceOptions),
 	}
 }
 
@@ -51,17 +60,34 @@ func MakeResidentMemoryImage() api.MemoryImage {
 	return &residentMemoryImage{joined: crdt.EmptyIndex()}
 }
 
+type ResidentCacheOptions struct {
+	// MaxItems is optional.  Defaults to 1024 if MaxBytes is also unset.
+	MaxItems int
+	// MaxBytes is optional.  If positive, items are evicted to keep their encoded size within this many bytes.
+	MaxBytes int64
+}
+
 type residentNamespaceCache struct {
 	*kvCache
 }
 
 func MakeResidentNamespaceCache(buffSize int) api.NamespaceCache {
-	return residentNamespaceCache{kvCache: makeKvCache(buffSize)}
+	return MakeBudgetedResidentNamespaceCache(ResidentCacheOptions{MaxItems: buffSize})
+}
+
+func MakeBudgetedResidentNamespaceCache(options ResidentCacheOptions) api.NamespaceCache {
+	return residentNamespaceCache{kvCache: makeKvCache(__NAMESPACE_CACHE_NAME, options)}
 }
 
 func (cache residentNamespaceCache) SetNamespace(addr crdt.IPFSPath, namespace crdt.Namespace) error {
+	var size int64
+	if cache.isBudgeted() {
+		message, _ := crdt.MakeNamespaceMessage(namespace)
+		size = int64(pb.Size(message))
+	}
+
 	ptr := unsafe.Pointer(&namespace)
-	return cache.set(addr, ptr)
+	return cache.set(addr, ptr, size)
 }
 
 func (cache residentNamespaceCache) GetNamespace(addr crdt.IPFSPath) (crdt.Namespace, error) {
@@ -80,12 +106,22 @@ type residentIndexCache struct {
 }
 
 func MakeResidentIndexCache(buffSize int) api.IndexCache {
-	return residentIndexCache{kvCache: makeKvCache(buffSize)}
+	return MakeBudgetedResidentIndexCache(ResidentCacheOptions{MaxItems: buffSize})
+}
+
+func MakeBudgetedResidentIndexCache(options ResidentCacheOptions) api.IndexCache {
+	return residentIndexCache{kvCache: makeKvCache(__INDEX_CACHE_NAME, options)}
 }
 
 func (cache residentIndexCache) SetIndex(addr crdt.IPFSPath, index crdt.Index) error {
+	var size int64
+	if cache.isBudgeted() {
+		message, _ := crdt.MakeIndexMessage(index)
+		size = int64(pb.Size(message))
+	}
+
 	ptr := unsafe.Pointer(&index)
-	return cache.set(addr, ptr)
+	return cache.set(addr, ptr, size)
 }
 
 func (cache residentIndexCache) GetIndex(addr crdt.IPFSPath) (crdt.Index, error) {
@@ -100,117 +136,135 @@ func (cache residentIndexCache) GetIndex(addr crdt.IPFSPath) (crdt.Index, error)
 }
 
 // Memcache style key value store.
-// Will drop oldest.
+// Will drop least recently used.
 type kvCache struct {
-	sync.RWMutex
-	buff  []cacheItem
-	assoc map[crdt.IPFSPath]*cacheItem
+	sync.Mutex
+	name     string
+	maxItems int
+	maxBytes int64
+	bytes    int64
+	hits     uint64
+	misses   uint64
+	evicted  uint64
+	// Front is most recently used.
+	order *list.List
+	assoc map[crdt.IPFSPath]*list.Element
 }
 
 type cacheItem struct {
-	key           crdt.IPFSPath
-	obj           unsafe.Pointer
-	timestamp     int64
-	nanoTimestamp int
+	key  crdt.IPFSPath
+	obj  unsafe.Pointer
+	size int64
 }
 
-func makeKvCache(buffSize int) *kvCache {
-	if buffSize <= 0 {
-		buffSize = __DEFAULT_BUFFER_SIZE
+func makeKvCache(name string, options ResidentCacheOptions) *kvCache {
+	maxItems := options.MaxItems
+	if maxItems <= 0 && options.MaxBytes <= 0 {
+		maxItems = __DEFAULT_BUFFER_SIZE
 	}
 
 	cache := &kvCache{
-		buff:  make([]cacheItem, buffSize),
-		assoc: map[crdt.IPFSPath]*cacheItem{},
+		name:     name,
+		maxItems: maxItems,
+		maxBytes: options.MaxBytes,
+		order:    list.New(),
+		assoc:    map[crdt.IPFSPath]*list.Element{},
 	}
 
-	cache.initBuff()
-
 	return cache
 }
 
-func (cache *kvCache) initBuff() {
-	for i := 0; i < len(cache.buff); i++ {
-		item := &cache.buff[i]
-		item.timestamp, item.nanoTimestamp = makeTimestamp()
-	}
+func (cache *kvCache) isBudgeted() bool {
+	return cache.maxBytes > 0
 }
 
 func (cache *kvCache) get(addr crdt.IPFSPath) (unsafe.Pointer, error) {
-	cache.RLock()
-	defer cache.RUnlock()
+	cache.Lock()
+	defer cache.Unlock()
 
-	item, present := cache.assoc[addr]
+	element, present := cache.assoc[addr]
 
 	if !present {
+		cache.misses++
 		return nil, fmt.Errorf("No cached item for: %s", addr)
 	}
 
+	cache.hits++
+	cache.order.MoveToFront(element)
+	item := element.Value.(*cacheItem)
 	return item.obj, nil
 }
 
-func (cache *kvCache) set(addr crdt.IPFSPath, pointer unsafe.Pointer) error {
+func (cache *kvCache) set(addr crdt.IPFSPath, pointer unsafe.Pointer, size int64) error {
 	cache.Lock()
 	defer cache.Unlock()
 
-	item, present := cache.assoc[addr]
+	element, present := cache.assoc[addr]
 
 	if present {
-		item.timestamp, item.nanoTimestamp = makeTimestamp()
+		cache.order.MoveToFront(element)
+		return nil
+	}
+
+	if cache.isBudgeted() && size > cache.maxBytes {
+		log.Warn("Not caching %s of %d bytes in %s cache with budget %d bytes", addr, size, cache.name, cache.maxBytes)
 		return nil
 	}
 
-	return cache.addNewItem(addr, pointer)
+	return cache.addNewItem(addr, pointer, size)
 }
 
-func (cache *kvCache) addNewItem(addr crdt.IPFSPath, pointer unsafe.Pointer) error {
-	newItem := cacheItem{
-		key: addr,
-		obj: pointer,
+func (cache *kvCache) addNewItem(addr crdt.IPFSPath, pointer unsafe.Pointer, size int64) error {
+	newItem := &cacheItem{
+		key:  addr,
+		obj:  pointer,
+		size: size,
 	}
 
-	newItem.timestamp, newItem.nanoTimestamp = makeTimestamp()
+	cache.assoc[addr] = cache.order.PushFront(newItem)
+	cache.bytes += size
 
-	bufferedItem := cache.popOldest()
-	*bufferedItem = newItem
+	for cache.isOverBudget() {
+		cache.popOldest()
+	}
 
-	cache.assoc[addr] = bufferedItem
 	return nil
 }
 
-func (cache *kvCache) popOldest() *cacheItem {
-	var oldest *cacheItem
-
-	for i := 0; i < len(cache.buff); i++ {
-		item := &cache.buff[i]
-
-		if oldest == nil {
-			oldest = item
-			continue
-		}
-
-		older := item.timestamp < oldest.timestamp
-		if !older && item.timestamp == oldest.timestamp {
-			older = item.nanoTimestamp < oldest.nanoTimestamp
-		}
+func (cache *kvCache) isOverBudget() bool {
+	overItems := cache.maxItems > 0 && cache.order.Len() > cache.maxItems
+	overBytes := cache.isBudgeted() && cache.bytes > cache.maxBytes
+	return overItems || overBytes
+}
 
-		if older {
-			oldest = item
-		}
-	}
+func (cache *kvCache) popOldest() {
+	oldest := cache.order.Back()
 
 	if oldest == nil {
 		panic("Corrupt buffer")
 	}
 
-	delete(cache.assoc, oldest.key)
-
-	return oldest
+	item := cache.order.Remove(oldest).(*cacheItem)
+	delete(cache.assoc, item.key)
+	cache.bytes -= item.size
+	cache.evicted++
 }
 
-func makeTimestamp() (int64, int) {
-	t := time.Now()
-	return t.Unix(), t.Nanosecond()
+func (cache *kvCache) GetCacheStats() []api.CacheStats {
+	cache.Lock()
+	defer cache.Unlock()
+
+	stats := api.CacheStats{
+		Name:      cache.name,
+		Hits:      cache.hits,
+		Misses:    cache.misses,
+		Evictions: cache.evicted,
+		Items:     int64(cache.order.Len()),
+		Bytes:     cache.bytes,
+		MaxBytes:  cache.maxBytes,
+	}
+
+	return []api.CacheStats{stats}
 }
 
 type residentHeadCache struct {
@@ -436,3 +490,6 @@ const (
 )
 
 const __DEFAULT_BUFFER_SIZE = 1024
+
+const __INDEX_CACHE_NAME = "index"
+const __NAMESPACE_CACHE_NAME = "namespace"
@@ -11,6 +11,8 @@ import (
 	"github.com/johnny-morrice/godless/crdt"
 	"github.com/johnny-morrice/godless/internal/testutil"
 	"github.com/pkg/errors"
+
+	pb "github.com/gogo/protobuf/proto"
 )
 
 func TestResidentMemoryImageConcurrency(t *testing.T) {
@@ -178,6 +180,66 @@ func TestResidentIndexCacheExpire(t *testing.T) {
 	}
 }
 
+func TestResidentNamespaceCacheBudget(t *testing.T) {
+	addrs := genHeads(4)
+	namespaces := make([]crdt.Namespace, len(addrs))
+
+	for i := range namespaces {
+		namespaces[i] = crdt.EmptyNamespace().JoinTable("Table", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Row": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint(crdt.PointText(addrs[i]))}),
+			}),
+		}))
+	}
+
+	message, _ := crdt.MakeNamespaceMessage(namespaces[0])
+	size := int64(pb.Size(message))
+
+	cache := MakeBudgetedResidentNamespaceCache(ResidentCacheOptions{MaxBytes: size * 2})
+
+	testutil.AssertNil(t, cache.SetNamespace(addrs[0], namespaces[0]))
+	testutil.AssertNil(t, cache.SetNamespace(addrs[1], namespaces[1]))
+
+	// Use the oldest so that the second is evicted first.
+	_, err := cache.GetNamespace(addrs[0])
+	testutil.AssertNil(t, err)
+
+	testutil.AssertNil(t, cache.SetNamespace(addrs[2], namespaces[2]))
+
+	_, err = cache.GetNamespace(addrs[1])
+	testutil.AssertNonNil(t, err)
+
+	for _, i := range []int{0, 2} {
+		actual, err := cache.GetNamespace(addrs[i])
+		testutil.AssertNil(t, err)
+		testutil.Assert(t, "Unexpected namespace", namespaces[i].Equals(actual))
+	}
+
+	huge := crdt.EmptyNamespace().JoinTable("Table", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+		"Row": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint(crdt.PointText(make([]byte, size*3)))}),
+		}),
+	}))
+
+	testutil.AssertNil(t, cache.SetNamespace(addrs[3], huge))
+	_, err = cache.GetNamespace(addrs[3])
+	testutil.AssertNonNil(t, err)
+
+	stats := cache.(api.StatisticsCache).GetCacheStats()
+	expected := api.CacheStats{
+		Name:      __NAMESPACE_CACHE_NAME,
+		Hits:      3,
+		Misses:    2,
+		Evictions: 1,
+		Items:     2,
+		Bytes:     size * 2,
+		MaxBytes:  size * 2,
+	}
+
+	testutil.AssertLenEquals(t, 1, stats)
+	testutil.AssertEquals(t, "Unexpected stats", expected, stats[0])
+}
+
 func TestResidentPriorityQueueDrain(t *testing.T) {
 	const buffSize = 10
 	const dataLength = 20
@@ -35,6 +35,38 @@ func (cache cacheUnion) SetNamespace(namespaceAddr crdt.IPFSPath, namespace crdt
 	return cache.namespaceCache.SetNamespace(namespaceAddr, namespace)
 }
 
+func (cache cacheUnion) IsVerifiedNamespace(namespaceAddr crdt.IPFSPath, digest string) (bool, error) {
+	markers, ok := cache.namespaceCache.(api.VerifiedNamespaceCache)
+
+	if !ok {
+		return false, nil
+	}
+
+	return markers.IsVerifiedNamespace(namespaceAddr, digest)
+}
+
+func (cache cacheUnion) SetVerifiedNamespace(namespaceAddr crdt.IPFSPath, digest string) error {
+	markers, ok := cache.namespaceCache.(api.VerifiedNamespaceCache)
+
+	if !ok {
+		return nil
+	}
+
+	return markers.SetVerifiedNamespace(namespaceAddr, digest)
+}
+
 func (cache cacheUnion) CloseCache() error {
 	return nil
 }
+
+func (cache cacheUnion) GetCacheStats() []api.CacheStats {
+	stats := []api.CacheStats{}
+
+	for _, sub := range []interface{}{cache.indexCache, cache.namespaceCache} {
+		if statsCache, ok := sub.(api.StatisticsCache); ok {
+			stats = append(stats, statsCache.GetCacheStats()...)
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,102 @@
+package crdt
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BlobReference is the point text of a large value stored out of line.  The blob is content
+// addressed, so a signature over the reference also covers the value.
+type BlobReference struct {
+	Path IPFSPath
+	Size int
+}
+
+func (ref BlobReference) PointText() PointText {
+	parts := []string{
+		__BLOB_REFERENCE_PREFIX,
+		strconv.Itoa(ref.Size),
+		string(ref.Path),
+	}
+
+	return PointText(strings.Join(parts, __BLOB_REFERENCE_SEPERATOR))
+}
+
+func IsBlobReference(text PointText) bool {
+	return strings.HasPrefix(string(text), __BLOB_REFERENCE_PREFIX+__BLOB_REFERENCE_SEPERATOR)
+}
+
+func ParseBlobReference(text PointText) (BlobReference, error) {
+	if !IsBlobReference(text) {
+		return BlobReference{}, fmt.Errorf("Not a blob reference: '%s'", text)
+	}
+
+	parts := strings.SplitN(string(text), __BLOB_REFERENCE_SEPERATOR, 3)
+
+	if len(parts) != 3 || parts[2] == "" {
+		return BlobReference{}, fmt.Errorf("Invalid blob reference: '%s'", text)
+	}
+
+	size, err := strconv.Atoi(parts[1])
+
+	if err != nil || size < 0 {
+		return BlobReference{}, fmt.Errorf("Invalid blob reference size: '%s'", text)
+	}
+
+	ref := BlobReference{
+		Path: IPFSPath(parts[2]),
+		Size: size,
+	}
+
+	return ref, nil
+}
+
+// BlobReferences lists the distinct blobs referred to by points in the namespace, in path order.
+// Invalid references are skipped.
+func (ns Namespace) BlobReferences() []BlobReference {
+	found := map[IPFSPath]BlobReference{}
+
+	ns.ForeachEntry(func(t TableName, r RowName, e EntryName, entry Entry) {
+		for _, point := range entry.GetValues() {
+			if !IsBlobReference(point.Text()) {
+				continue
+			}
+
+			ref, err := ParseBlobReference(point.Text())
+
+			if err == nil {
+				found[ref.Path] = ref
+			}
+		}
+	})
+
+	refs := make([]BlobReference, 0, len(found))
+	for _, ref := range found {
+		refs = append(refs, ref)
+	}
+
+	sort.Sort(byBlobPath(refs))
+
+	return refs
+}
+
+type byBlobPath []BlobReference
+
+func (refs byBlobPath) Len() int {
+	return len(refs)
+}
+
+func (refs byBlobPath) Swap(i, j int) {
+	refs[i], refs[j] = refs[j], refs[i]
+}
+
+func (refs byBlobPath) Less(i, j int) bool {
+	return refs[i].Path < refs[j].Path
+}
+
+const (
+	__BLOB_REFERENCE_PREFIX    = "!blob"
+	__BLOB_REFERENCE_SEPERATOR = ":"
+)
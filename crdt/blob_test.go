@@ -0,0 +1,64 @@
+package crdt
+
+import (
+	"testing"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestBlobReference(t *testing.T) {
+	expected := BlobReference{Path: "QmBlob", Size: 1024}
+
+	text := expected.PointText()
+	testutil.Assert(t, "Expected blob reference", IsBlobReference(text))
+
+	actual, err := ParseBlobReference(text)
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected blob reference", expected, actual)
+
+	invalid := []PointText{
+		"Hello world",
+		"!blob",
+		"!blob:1024",
+		"!blob:1024:",
+		"!blob:big:QmBlob",
+		"!blob:-1:QmBlob",
+	}
+
+	for _, text := range invalid {
+		_, err := ParseBlobReference(text)
+		testutil.AssertNonNil(t, err)
+	}
+
+	testutil.Assert(t, "Expected reserved text", IsReservedText(text))
+	testutil.Assert(t, "Unexpected reserved text", !IsReservedText("!blobby"))
+}
+
+func TestNamespaceBlobReferences(t *testing.T) {
+	first := BlobReference{Path: "QmFirst", Size: 1}
+	second := BlobReference{Path: "QmSecond", Size: 2}
+
+	namespace := MakeNamespace(map[TableName]Table{
+		"Table A": MakeTable(map[RowName]Row{
+			"Row A": MakeRow(map[EntryName]Entry{
+				"Entry A": MakeEntry([]Point{
+					UnsignedPoint(second.PointText()),
+					UnsignedPoint("Hello world"),
+				}),
+				"Entry B": MakeEntry([]Point{UnsignedPoint("!blob:bad")}),
+			}),
+		}),
+		"Table B": MakeTable(map[RowName]Row{
+			"Row B": MakeRow(map[EntryName]Entry{
+				"Entry C": MakeEntry([]Point{
+					UnsignedPoint(first.PointText()),
+					UnsignedPoint(second.PointText()),
+				}),
+			}),
+		}),
+	})
+
+	actual := namespace.BlobReferences()
+	testutil.AssertEquals(t, "Unexpected blob references", []BlobReference{first, second}, actual)
+	testutil.AssertLenEquals(t, 0, EmptyNamespace().BlobReferences())
+}
@@ -0,0 +1,104 @@
+package crdt
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Codec identifies the compression used for the records in a stream.
+type Codec uint8
+
+const (
+	CODEC_NONE = Codec(iota)
+	CODEC_SNAPPY
+	CODEC_ZSTD
+)
+
+const (
+	__CODEC_NONE_NAME   = "none"
+	__CODEC_SNAPPY_NAME = "snappy"
+	__CODEC_ZSTD_NAME   = "zstd"
+)
+
+func ParseCodec(name string) (Codec, error) {
+	switch name {
+	case __CODEC_NONE_NAME:
+		return CODEC_NONE, nil
+	case __CODEC_SNAPPY_NAME:
+		return CODEC_SNAPPY, nil
+	case __CODEC_ZSTD_NAME:
+		return CODEC_ZSTD, nil
+	default:
+		return CODEC_NONE, fmt.Errorf("Unknown codec: '%s'", name)
+	}
+}
+
+func (codec Codec) String() string {
+	switch codec {
+	case CODEC_NONE:
+		return __CODEC_NONE_NAME
+	case CODEC_SNAPPY:
+		return __CODEC_SNAPPY_NAME
+	case CODEC_ZSTD:
+		return __CODEC_ZSTD_NAME
+	default:
+		return fmt.Sprintf("unknown(%d)", codec)
+	}
+}
+
+// compress wraps w.  The returned writer must be closed to flush the compressed stream.
+func (codec Codec) compress(w io.Writer) (io.WriteCloser, error) {
+	const failMsg = "Codec.compress failed"
+
+	switch codec {
+	case CODEC_NONE:
+		return nopWriteCloser{Writer: w}, nil
+	case CODEC_SNAPPY:
+		return snappy.NewBufferedWriter(w), nil
+	case CODEC_ZSTD:
+		encoder, err := zstd.NewWriter(w)
+
+		if err != nil {
+			return nil, errors.Wrap(err, failMsg)
+		}
+
+		return encoder, nil
+	default:
+		return nil, fmt.Errorf("Unknown codec: %d", codec)
+	}
+}
+
+func (codec Codec) decompress(r io.Reader) (io.Reader, error) {
+	const failMsg = "Codec.decompress failed"
+
+	switch codec {
+	case CODEC_NONE:
+		return r, nil
+	case CODEC_SNAPPY:
+		return snappy.NewReader(r), nil
+	case CODEC_ZSTD:
+		// With a concurrency of one the stream is decoded in the calling goroutine,
+		// so there are no background resources to release.
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+
+		if err != nil {
+			return nil, errors.Wrap(err, failMsg)
+		}
+
+		return decoder, nil
+	default:
+		return nil, fmt.Errorf("Unknown codec: %d", codec)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
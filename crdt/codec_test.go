@@ -0,0 +1,117 @@
+package crdt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestParseCodec(t *testing.T) {
+	codecs := []Codec{CODEC_NONE, CODEC_SNAPPY, CODEC_ZSTD}
+
+	for _, expected := range codecs {
+		actual, err := ParseCodec(expected.String())
+		testutil.AssertNil(t, err)
+		testutil.AssertEquals(t, "Unexpected codec", expected, actual)
+	}
+
+	_, err := ParseCodec("gzip")
+	testutil.AssertNonNil(t, err)
+}
+
+func TestEncodeNamespaceCodec(t *testing.T) {
+	const size = 50
+	expected := GenNamespace(testutil.Rand(), size)
+
+	codecs := []Codec{CODEC_NONE, CODEC_SNAPPY, CODEC_ZSTD}
+
+	for _, codec := range codecs {
+		buff := &bytes.Buffer{}
+		invalid, err := EncodeNamespaceCodec(expected, codec, buff)
+		panicInvalidNamespace(invalid)
+		testutil.AssertNil(t, err)
+
+		actual, err := decodeNamespace(buff)
+		testutil.AssertNil(t, err)
+		testutil.Assert(t, "Unexpected namespace for codec "+codec.String(), expected.Equals(actual))
+	}
+}
+
+func TestEncodeIndexCodec(t *testing.T) {
+	const size = 50
+	expected := GenIndex(testutil.Rand(), size)
+
+	codecs := []Codec{CODEC_NONE, CODEC_SNAPPY, CODEC_ZSTD}
+
+	for _, codec := range codecs {
+		buff := &bytes.Buffer{}
+		invalid, err := EncodeIndexCodec(expected, codec, buff)
+		testutil.AssertLenEquals(t, 0, invalid)
+		testutil.AssertNil(t, err)
+
+		actual, invalid, err := DecodeIndex(buff)
+		testutil.AssertNil(t, err)
+		testutil.AssertLenEquals(t, 0, invalid)
+		testutil.Assert(t, "Unexpected index for codec "+codec.String(), expected.Equals(actual))
+	}
+}
+
+func TestEncodeNamespaceCodecCompresses(t *testing.T) {
+	text := PointText(strings.Repeat("godless ", 1000))
+	namespace := MakeNamespace(map[TableName]Table{
+		"Table": MakeTable(map[RowName]Row{
+			"Row": MakeRow(map[EntryName]Entry{
+				"Entry": MakeEntry([]Point{UnsignedPoint(text)}),
+			}),
+		}),
+	})
+
+	raw := &bytes.Buffer{}
+	_, err := EncodeNamespaceCodec(namespace, CODEC_NONE, raw)
+	testutil.AssertNil(t, err)
+
+	for _, codec := range []Codec{CODEC_SNAPPY, CODEC_ZSTD} {
+		compressed := &bytes.Buffer{}
+		_, err := EncodeNamespaceCodec(namespace, codec, compressed)
+		testutil.AssertNil(t, err)
+		testutil.Assert(t, "Expected compression by "+codec.String(), compressed.Len() < raw.Len())
+	}
+}
+
+func TestDecodeUncompressedStreamVersion(t *testing.T) {
+	const size = 50
+	expected := GenNamespace(testutil.Rand(), size)
+
+	buff := &bytes.Buffer{}
+	err := encodeNamespace(expected, buff)
+	testutil.AssertNil(t, err)
+
+	// Rewrite as a version 1 header, which has no codec field.
+	encoded := buff.Bytes()
+	versionOffset := len(__STREAM_MAGIC)
+	codecOffset := versionOffset + 2
+	testutil.AssertEquals(t, "Expected no codec", byte(CODEC_NONE), encoded[codecOffset])
+
+	legacy := make([]byte, 0, len(encoded)-1)
+	legacy = append(legacy, encoded[:codecOffset]...)
+	legacy = append(legacy, encoded[codecOffset+1:]...)
+	legacy[versionOffset] = __UNCOMPRESSED_STREAM_VERSION
+
+	actual, err := decodeNamespace(bytes.NewReader(legacy))
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected namespace", expected.Equals(actual))
+}
+
+func TestDecodeUnknownCodec(t *testing.T) {
+	buff := &bytes.Buffer{}
+	err := EncodeIndexStream([]IndexStreamEntry{}, CODEC_NONE, buff)
+	testutil.AssertNil(t, err)
+
+	encoded := buff.Bytes()
+	encoded[len(__STREAM_MAGIC)+2] = byte(CODEC_ZSTD) + 1
+
+	_, _, err = DecodeIndex(bytes.NewReader(encoded))
+	testutil.AssertNonNil(t, err)
+}
@@ -0,0 +1,106 @@
+package crdt
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// DELEGATION_TABLE holds delegation certificates, so they are stored and replicated like any other
+// data.  Rows are named by the hash of the delegated key.
+const DELEGATION_TABLE TableName = "godlessdelegations"
+
+const DELEGATION_ENTRY EntryName = "delegation"
+
+// MakeDelegationNamespace makes a namespace that publishes the delegations when joined.
+func MakeDelegationNamespace(delegations ...crypto.Delegation) (Namespace, error) {
+	const failMsg = "MakeDelegationNamespace failed"
+
+	table := EmptyTable()
+
+	for _, delegation := range delegations {
+		hash, err := delegation.Subject.Hash()
+
+		if err != nil {
+			return EmptyNamespace(), errors.Wrap(err, failMsg)
+		}
+
+		text, err := crypto.SerializeDelegation(delegation)
+
+		if err != nil {
+			return EmptyNamespace(), errors.Wrap(err, failMsg)
+		}
+
+		row := MakeRow(map[EntryName]Entry{
+			DELEGATION_ENTRY: MakeEntry([]Point{UnsignedPoint(PointText(text))}),
+		})
+
+		table = table.JoinRow(RowName(hash), row)
+	}
+
+	return EmptyNamespace().JoinTable(DELEGATION_TABLE, table), nil
+}
+
+// ReadDelegations finds the delegations in the namespace.  Points that are not delegations are
+// logged and skipped.  The delegations are not verified.
+func ReadDelegations(namespace Namespace) []crypto.Delegation {
+	delegations := []crypto.Delegation{}
+
+	table, err := namespace.GetTable(DELEGATION_TABLE)
+
+	if err != nil {
+		return delegations
+	}
+
+	table.ForeachEntry(func(rowName RowName, entryName EntryName, entry Entry) {
+		if entryName != DELEGATION_ENTRY {
+			return
+		}
+
+		for _, point := range entry.GetValues() {
+			delegation, err := crypto.ParseDelegation(crypto.DelegationText(point.Text()))
+
+			if err != nil {
+				log.Warn("Skipping bad delegation in row %s: %s", rowName, err.Error())
+				continue
+			}
+
+			delegations = append(delegations, delegation)
+		}
+	})
+
+	return delegations
+}
+
+// FilterDelegated is like FilterTrusted, but also accepts signatures by keys that the roots
+// delegate to for each table.  A table is kept whole if one of its keys signed the namespace.
+func (ns Namespace) FilterDelegated(roots []crypto.PublicKey, delegations []crypto.Delegation, revocations []crypto.Revocation) Namespace {
+	return ns.FilterDelegatedThreshold(roots, delegations, revocations, 1)
+}
+
+// FilterDelegatedThreshold is like FilterDelegated, but keeps only points signed by at least
+// threshold distinct roots.  A signature by a delegate counts for the root it was delegated from, so
+// a root and its delegates count once.
+func (ns Namespace) FilterDelegatedThreshold(roots []crypto.PublicKey, delegations []crypto.Delegation, revocations []crypto.Revocation, threshold int) Namespace {
+	verified := EmptyNamespace()
+	now := time.Now()
+	signature := ns.readNamespaceSignature()
+
+	for tableName, table := range ns.WithoutNamespaceSignature().Tables {
+		groups := crypto.DelegatedKeyGroups(roots, delegations, revocations, string(tableName), now)
+
+		if signature.isVerifiedByGroups(groups, threshold) {
+			verified.addTable(tableName, table)
+			continue
+		}
+
+		table.ForeachEntry(func(r RowName, e EntryName, entry Entry) {
+			verified.addEntry(tableName, r, e, entry.FilterVerifiedByGroups(groups, threshold))
+		})
+	}
+
+	return verified
+}
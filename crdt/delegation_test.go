@@ -0,0 +1,92 @@
+package crdt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestDelegationNamespace(t *testing.T) {
+	issuer, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	_, subject, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	delegation, err := crypto.MakeDelegation(issuer, subject, []string{"cars"}, time.Time{})
+	testutil.AssertNil(t, err)
+
+	namespace, err := MakeDelegationNamespace(delegation)
+	testutil.AssertNil(t, err)
+
+	delegations := ReadDelegations(namespace)
+	testutil.AssertLenEquals(t, 1, delegations)
+	testutil.Assert(t, "Unexpected delegation", delegation.Equals(delegations[0]))
+}
+
+func TestNamespaceFilterDelegated(t *testing.T) {
+	root, rootPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	member, memberPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	delegation, err := crypto.MakeDelegation(root, memberPub, []string{"cars"}, time.Time{})
+	testutil.AssertNil(t, err)
+
+	memberPoint, err := SignedPoint("Member", []crypto.PrivateKey{member})
+	testutil.AssertNil(t, err)
+
+	makeTable := func(points ...Point) Table {
+		return MakeTable(map[RowName]Row{
+			"Row": MakeRow(map[EntryName]Entry{
+				"Entry": MakeEntry(points),
+			}),
+		})
+	}
+
+	unfiltered := MakeNamespace(map[TableName]Table{
+		"cars":    makeTable(memberPoint),
+		"drivers": makeTable(memberPoint),
+	})
+
+	expected := MakeNamespace(map[TableName]Table{
+		"cars":    makeTable(memberPoint),
+		"drivers": makeTable(),
+	})
+
+	actual := unfiltered.FilterDelegated([]crypto.PublicKey{rootPub}, []crypto.Delegation{delegation}, nil)
+	testutil.Assert(t, "Unexpected filtered namespace", expected.Equals(actual))
+}
+
+func TestNamespaceFilterDelegatedThresholdCountsRoots(t *testing.T) {
+	privs := make([]crypto.PrivateKey, 3)
+	pubs := make([]crypto.PublicKey, 3)
+
+	for i := range privs {
+		priv, pub, err := crypto.GenerateKey()
+		testutil.AssertNil(t, err)
+		privs[i] = priv
+		pubs[i] = pub
+	}
+
+	member, memberPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	delegation, err := crypto.MakeDelegation(privs[0], memberPub, nil, time.Time{})
+	testutil.AssertNil(t, err)
+
+	sameRoot, err := SignedPoint("Same root", []crypto.PrivateKey{privs[0], member})
+	testutil.AssertNil(t, err)
+	twoRoots, err := SignedPoint("Two roots", []crypto.PrivateKey{privs[1], member})
+	testutil.AssertNil(t, err)
+
+	unfiltered := makeSignatureTestNamespace(sameRoot, twoRoots)
+	delegations := []crypto.Delegation{delegation}
+
+	actual := unfiltered.FilterDelegatedThreshold(pubs, delegations, nil, 2)
+	testutil.Assert(t, "Unexpected namespace", makeSignatureTestNamespace(twoRoots).Equals(actual))
+
+	actual = unfiltered.FilterDelegatedThreshold(pubs, delegations, nil, 1)
+	testutil.Assert(t, "Unexpected namespace", unfiltered.Equals(actual))
+}
@@ -0,0 +1,299 @@
+package crdt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/util"
+	"github.com/johnny-morrice/godless/log"
+	"github.com/pkg/errors"
+)
+
+// ENCRYPTION_KEY_ROW holds the wrapped symmetric keys of an encrypted table.  Each entry is named
+// by key id and holds one point per recipient.  The name sorts before alphanumeric row names, so a
+// table streamed row by row yields its keys before its encrypted rows.
+const ENCRYPTION_KEY_ROW = RowName("!encryption")
+
+type EncryptionKeyId string
+
+// TableEncryption encrypts the point text of a single join.
+type TableEncryption struct {
+	Id  EncryptionKeyId
+	key crypto.SymmetricKey
+}
+
+func MakeTableEncryption() (TableEncryption, error) {
+	const failMsg = "MakeTableEncryption failed"
+
+	key, err := crypto.GenerateSymmetricKey()
+
+	if err != nil {
+		return TableEncryption{}, errors.Wrap(err, failMsg)
+	}
+
+	idBytes := make([]byte, __ENCRYPTION_KEY_ID_SIZE)
+	_, err = io.ReadFull(rand.Reader, idBytes)
+
+	if err != nil {
+		return TableEncryption{}, errors.Wrap(err, failMsg)
+	}
+
+	enc := TableEncryption{
+		Id:  EncryptionKeyId(util.EncodeBase58(idBytes)),
+		key: key,
+	}
+
+	return enc, nil
+}
+
+func (enc TableEncryption) EncryptText(text PointText) (PointText, error) {
+	const failMsg = "TableEncryption.EncryptText failed"
+
+	sealed, err := enc.key.Seal([]byte(text))
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	parts := []string{
+		__ENCRYPTED_POINT_PREFIX,
+		string(enc.Id),
+		base64.RawURLEncoding.EncodeToString(sealed),
+	}
+
+	return PointText(strings.Join(parts, __ENCRYPTION_SEPERATOR)), nil
+}
+
+// WrapKeys creates the entry of ENCRYPTION_KEY_ROW that lets each recipient recover the key.
+func (enc TableEncryption) WrapKeys(recipients []crypto.PublicKey, signers []crypto.PrivateKey) (Row, error) {
+	const failMsg = "TableEncryption.WrapKeys failed"
+
+	points := make([]Point, 0, len(recipients))
+
+	for _, pub := range recipients {
+		hash, err := pub.Hash()
+
+		if err != nil {
+			return Row{}, errors.Wrap(err, failMsg)
+		}
+
+		wrapped, err := crypto.WrapKey(enc.key, pub)
+
+		if err != nil {
+			return Row{}, errors.Wrap(err, failMsg)
+		}
+
+		text := string(hash) + __ENCRYPTION_SEPERATOR + base64.RawURLEncoding.EncodeToString(wrapped)
+		point, err := SignedPoint(PointText(text), signers)
+
+		if err != nil {
+			return Row{}, errors.Wrap(err, failMsg)
+		}
+
+		points = append(points, point)
+	}
+
+	row := MakeRow(map[EntryName]Entry{
+		EntryName(enc.Id): MakeEntry(points),
+	})
+
+	return row, nil
+}
+
+// WithoutEncryptionKeys removes ENCRYPTION_KEY_ROW from every table, as the wrapped keys are not
+// data.  Tables left empty are removed.
+func (ns Namespace) WithoutEncryptionKeys() Namespace {
+	content := EmptyNamespace()
+
+	for tableName, table := range ns.Tables {
+		if _, present := table.Rows[ENCRYPTION_KEY_ROW]; present {
+			table = MakeTable(table.Rows)
+			delete(table.Rows, ENCRYPTION_KEY_ROW)
+		}
+
+		if len(table.Rows) > 0 {
+			content.Tables[tableName] = table
+		}
+	}
+
+	return content
+}
+
+func IsEncryptedText(text PointText) bool {
+	return strings.HasPrefix(string(text), __ENCRYPTED_POINT_PREFIX+__ENCRYPTION_SEPERATOR)
+}
+
+// Decrypter decrypts tables using any private key held by the caller.  Keys learned from one table
+// are remembered, so rows may be decrypted one at a time.
+type Decrypter struct {
+	privateKeys []crypto.PrivateKey
+	hashes      []crypto.PublicKeyHash
+	keys        map[EncryptionKeyId][]crypto.SymmetricKey
+}
+
+func MakeDecrypter(privateKeys []crypto.PrivateKey) *Decrypter {
+	decrypter := &Decrypter{
+		keys: map[EncryptionKeyId][]crypto.SymmetricKey{},
+	}
+
+	for _, priv := range privateKeys {
+		hash, err := priv.GetPublicKey().Hash()
+
+		if err != nil {
+			log.Warn("Could not hash public key: %s", err.Error())
+			continue
+		}
+
+		decrypter.privateKeys = append(decrypter.privateKeys, priv)
+		decrypter.hashes = append(decrypter.hashes, hash)
+	}
+
+	return decrypter
+}
+
+func (decrypter *Decrypter) DecryptNamespace(namespace Namespace) Namespace {
+	decrypted := EmptyNamespace()
+
+	for tableName, table := range namespace.Tables {
+		decrypted.Tables[tableName] = decrypter.DecryptTable(table)
+	}
+
+	return decrypted
+}
+
+// DecryptTable replaces encrypted points with their plain text and removes ENCRYPTION_KEY_ROW.
+// Decrypted points are unsigned, as their signatures covered the ciphertext.  Points that cannot
+// be decrypted are left as they are.
+func (decrypter *Decrypter) DecryptTable(table Table) Table {
+	keyRow, hasKeys := table.Rows[ENCRYPTION_KEY_ROW]
+
+	if !hasKeys && len(decrypter.keys) == 0 {
+		return table
+	}
+
+	if hasKeys {
+		decrypter.learnKeys(keyRow)
+	}
+
+	decrypted := EmptyTable()
+
+	for rowName, row := range table.Rows {
+		if rowName == ENCRYPTION_KEY_ROW {
+			continue
+		}
+
+		decryptedRow := EmptyRow()
+
+		for entryName, entry := range row.Entries {
+			points := make([]Point, len(entry.Set))
+
+			for i, point := range entry.Set {
+				points[i] = decrypter.DecryptPoint(point)
+			}
+
+			decryptedRow.Entries[entryName] = MakeEntry(points)
+		}
+
+		decrypted.Rows[rowName] = decryptedRow
+	}
+
+	return decrypted
+}
+
+// DecryptPoint returns the plain text of point if it is encrypted under a known key.
+func (decrypter *Decrypter) DecryptPoint(point Point) Point {
+	text := point.Text()
+
+	if !IsEncryptedText(text) {
+		return point
+	}
+
+	parts := strings.Split(string(text), __ENCRYPTION_SEPERATOR)
+
+	if len(parts) != 3 {
+		return point
+	}
+
+	keys := decrypter.keys[EncryptionKeyId(parts[1])]
+
+	if len(keys) == 0 {
+		return point
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return point
+	}
+
+	// Anyone may add a wrapped key under a known id, so try every candidate.
+	for _, key := range keys {
+		plain, err := key.Open(sealed)
+
+		if err == nil {
+			return UnsignedPoint(PointText(plain))
+		}
+	}
+
+	log.Warn("Failed to decrypt point with key %s", parts[1])
+	return point
+}
+
+func (decrypter *Decrypter) learnKeys(keyRow Row) {
+	for entryName, entry := range keyRow.Entries {
+		id := EncryptionKeyId(entryName)
+
+		for _, point := range entry.Set {
+			key, err := decrypter.unwrap(point.Text())
+
+			if err == nil {
+				decrypter.addKey(id, key)
+			}
+		}
+	}
+}
+
+func (decrypter *Decrypter) addKey(id EncryptionKeyId, key crypto.SymmetricKey) {
+	for _, known := range decrypter.keys[id] {
+		if known == key {
+			return
+		}
+	}
+
+	decrypter.keys[id] = append(decrypter.keys[id], key)
+}
+
+func (decrypter *Decrypter) unwrap(text PointText) (crypto.SymmetricKey, error) {
+	parts := strings.Split(string(text), __ENCRYPTION_SEPERATOR)
+
+	if len(parts) != 2 {
+		return crypto.SymmetricKey{}, errors.New("Invalid wrapped key")
+	}
+
+	hash := crypto.PublicKeyHash(parts[0])
+
+	for i, myHash := range decrypter.hashes {
+		if !hash.Equals(myHash) {
+			continue
+		}
+
+		wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+
+		if err != nil {
+			return crypto.SymmetricKey{}, err
+		}
+
+		return crypto.UnwrapKey(wrapped, decrypter.privateKeys[i])
+	}
+
+	return crypto.SymmetricKey{}, errors.New("No private key for wrapped key")
+}
+
+const (
+	__ENCRYPTED_POINT_PREFIX = "!encrypted"
+	__ENCRYPTION_SEPERATOR   = ":"
+	__ENCRYPTION_KEY_ID_SIZE = 16
+)
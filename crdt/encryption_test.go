@@ -0,0 +1,148 @@
+package crdt
+
+import (
+	"testing"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestDecryptTable(t *testing.T) {
+	signer, signerPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	recipient, recipientPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	stranger, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	signers := []crypto.PrivateKey{signer}
+	table := encryptTestTable(t, []crypto.PublicKey{recipientPub}, signers)
+
+	expected := MakeTable(map[RowName]Row{
+		"Row A": MakeRow(map[EntryName]Entry{
+			"Entry A": MakeEntry([]Point{UnsignedPoint("Point A")}),
+		}),
+	})
+
+	// Signatures cover the ciphertext.
+	verified := MakeNamespace(map[TableName]Table{"Table": table}).FilterVerified([]crypto.PublicKey{signerPub})
+	verifiedTable, err := verified.GetTable("Table")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected verified table", table.Equals(verifiedTable))
+
+	decrypted := MakeDecrypter([]crypto.PrivateKey{recipient}).DecryptTable(table)
+	testutil.Assert(t, "Unexpected decrypted table", expected.Equals(decrypted))
+
+	unreadable := MakeDecrypter([]crypto.PrivateKey{stranger}).DecryptTable(table)
+	_, err = unreadable.GetRow(ENCRYPTION_KEY_ROW)
+	testutil.AssertNonNil(t, err)
+
+	row, err := unreadable.GetRow("Row A")
+	testutil.AssertNil(t, err)
+	entry, err := row.GetEntry("Entry A")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, entry.Set)
+	testutil.Assert(t, "Expected encrypted text", IsEncryptedText(entry.Set[0].Text()))
+}
+
+func TestNamespaceWithoutEncryptionKeys(t *testing.T) {
+	signer, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	_, recipientPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	table := encryptTestTable(t, []crypto.PublicKey{recipientPub}, []crypto.PrivateKey{signer})
+	keyRow, err := table.GetRow(ENCRYPTION_KEY_ROW)
+	testutil.AssertNil(t, err)
+
+	namespace := MakeNamespace(map[TableName]Table{
+		"Table": table,
+		"Keys":  MakeTable(map[RowName]Row{ENCRYPTION_KEY_ROW: keyRow}),
+	})
+
+	stripped := namespace.WithoutEncryptionKeys()
+	testutil.AssertEquals(t, "Unexpected tables", []TableName{"Table"}, stripped.GetTableNames())
+
+	strippedTable, err := stripped.GetTable("Table")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, strippedTable.Rows)
+	_, err = strippedTable.GetRow(ENCRYPTION_KEY_ROW)
+	testutil.AssertNonNil(t, err)
+
+	_, err = table.GetRow(ENCRYPTION_KEY_ROW)
+	testutil.AssertNil(t, err)
+}
+
+func TestDecryptTableRowByRow(t *testing.T) {
+	recipient, recipientPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	table := encryptTestTable(t, []crypto.PublicKey{recipientPub}, nil)
+	keyRow, err := table.GetRow(ENCRYPTION_KEY_ROW)
+	testutil.AssertNil(t, err)
+	dataRow, err := table.GetRow("Row A")
+	testutil.AssertNil(t, err)
+
+	decrypter := MakeDecrypter([]crypto.PrivateKey{recipient})
+
+	keysOnly := decrypter.DecryptTable(MakeTable(map[RowName]Row{ENCRYPTION_KEY_ROW: keyRow}))
+	testutil.AssertLenEquals(t, 0, keysOnly.Rows)
+
+	decrypted := decrypter.DecryptTable(MakeTable(map[RowName]Row{"Row A": dataRow}))
+	row, err := decrypted.GetRow("Row A")
+	testutil.AssertNil(t, err)
+	entry, err := row.GetEntry("Entry A")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected entry", entry.Equals(MakeEntry([]Point{UnsignedPoint("Point A")})))
+}
+
+func TestDecryptTableForgedKey(t *testing.T) {
+	recipient, recipientPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	table := encryptTestTable(t, []crypto.PublicKey{recipientPub}, nil)
+	keyRow, err := table.GetRow(ENCRYPTION_KEY_ROW)
+	testutil.AssertNil(t, err)
+
+	var id EncryptionKeyId
+	for entryName := range keyRow.Entries {
+		id = EncryptionKeyId(entryName)
+	}
+
+	forgery, err := MakeTableEncryption()
+	testutil.AssertNil(t, err)
+	forgery.Id = id
+	forgedRow, err := forgery.WrapKeys([]crypto.PublicKey{recipientPub}, nil)
+	testutil.AssertNil(t, err)
+
+	forged := table.JoinRow(ENCRYPTION_KEY_ROW, forgedRow)
+
+	decrypted := MakeDecrypter([]crypto.PrivateKey{recipient}).DecryptTable(forged)
+	row, err := decrypted.GetRow("Row A")
+	testutil.AssertNil(t, err)
+	entry, err := row.GetEntry("Entry A")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected entry", entry.Equals(MakeEntry([]Point{UnsignedPoint("Point A")})))
+}
+
+func encryptTestTable(t *testing.T, recipients []crypto.PublicKey, signers []crypto.PrivateKey) Table {
+	encryption, err := MakeTableEncryption()
+	testutil.AssertNil(t, err)
+
+	text, err := encryption.EncryptText("Point A")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected encrypted text", IsEncryptedText(text))
+
+	point, err := SignedPoint(text, signers)
+	testutil.AssertNil(t, err)
+
+	keyRow, err := encryption.WrapKeys(recipients, signers)
+	testutil.AssertNil(t, err)
+
+	return MakeTable(map[RowName]Row{
+		"Row A": MakeRow(map[EntryName]Entry{
+			"Entry A": MakeEntry([]Point{point}),
+		}),
+		ENCRYPTION_KEY_ROW: keyRow,
+	})
+}
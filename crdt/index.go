@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sort"
 
 	"github.com/johnny-morrice/godless/crypto"
 	"github.com/johnny-morrice/godless/log"
@@ -34,36 +35,48 @@ func MakeIndex(indices map[TableName]Link) Index {
 }
 
 func EncodeIndex(index Index, w io.Writer) ([]InvalidIndexEntry, error) {
-	const failMsg = "EncodeIndex failed"
+	return EncodeIndexCodec(index, CODEC_NONE, w)
+}
+
+// EncodeIndexCodec encodes the index as a stream compressed by codec.
+func EncodeIndexCodec(index Index, codec Codec, w io.Writer) ([]InvalidIndexEntry, error) {
+	const failMsg = "EncodeIndexCodec failed"
 
-	message, invalid := MakeIndexMessage(index)
+	stream, invalid := MakeIndexStream(index)
 
 	invalidCount := len(invalid)
 	if invalidCount > 0 {
 		log.Error("EncodeIndex: %d invalid entries", invalidCount)
 	}
 
-	bs, err := pb.Marshal(message)
+	err := EncodeIndexStream(stream, codec, w)
 
 	if err != nil {
 		return invalid, errors.Wrap(err, failMsg)
 	}
 
-	var written int
-	written, err = w.Write(bs)
-
-	if err != nil {
-		return invalid, errors.Wrap(err, fmt.Sprintf("%s after %d bytes", failMsg, written))
-	}
-
 	return invalid, nil
 }
 
+// DecodeIndex reads streams with any codec, and the legacy single message encoding.
 func DecodeIndex(r io.Reader) (Index, []InvalidIndexEntry, error) {
 	const failMsg = "DecodeIndex failed"
 
+	buffered := bufferReader(r)
+
+	if IsStreamEncoding(buffered) {
+		stream, err := DecodeIndexStream(buffered)
+
+		if err != nil {
+			return __EMPTY_INDEX, nil, errors.Wrap(err, failMsg)
+		}
+
+		index, invalid := ReadIndexStream(stream)
+		return index, invalid, nil
+	}
+
 	message := &proto.IndexMessage{}
-	bs, err := ioutil.ReadAll(r)
+	bs, err := ioutil.ReadAll(buffered)
 
 	if err != nil {
 		return __EMPTY_INDEX, nil, errors.Wrap(err, failMsg)
@@ -182,6 +195,23 @@ func (index Index) AllTables() []TableName {
 	return tables
 }
 
+// TablesSignedBy lists the tables with a link signed by the key.
+func (index Index) TablesSignedBy(pub crypto.PublicKey) []TableName {
+	tables := []TableName{}
+
+	for name, links := range index.Index {
+		for _, link := range links {
+			if link.IsVerifiedBy(pub) {
+				tables = append(tables, name)
+				break
+			}
+		}
+	}
+
+	sort.Sort(byTableName(tables))
+	return tables
+}
+
 func (index Index) GetTableAddrs(tableName TableName) ([]Link, error) {
 	indices, ok := index.Index[tableName]
 
@@ -192,11 +222,17 @@ func (index Index) GetTableAddrs(tableName TableName) ([]Link, error) {
 	return indices, nil
 }
 
+// JoinNamespace links each table in the namespace to the address.  The namespace signatures are
+// not a table that can be searched, so are not linked.
 func (index Index) JoinNamespace(addr Link, namespace Namespace) Index {
 	tables := namespace.GetTableNames()
 
 	joined := index.Copy()
 	for _, t := range tables {
+		if t == NAMESPACE_SIGNATURE_TABLE {
+			continue
+		}
+
 		joined.addTable(t, addr)
 	}
 
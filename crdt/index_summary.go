@@ -0,0 +1,261 @@
+package crdt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+
+	pb "github.com/gogo/protobuf/proto"
+	"github.com/johnny-morrice/godless/proto"
+	"github.com/pkg/errors"
+)
+
+// TableHash is a digest of all the index entries for a table.
+type TableHash string
+
+// TableSummary describes the content of a table within an Index.  Link points to an
+// Index containing only that table.
+type TableSummary struct {
+	TableName TableName
+	Hash      TableHash
+	Link      Link
+}
+
+// IndexSummary is a Merkle summary of an Index.  Peers can compare summaries to
+// find the tables that they need to fetch from each other.  A summary with no
+// tables is a bare HEAD link, as published by older peers.
+type IndexSummary struct {
+	Head   Link
+	Root   TableHash
+	Tables []TableSummary
+}
+
+func LinkSummary(head Link) IndexSummary {
+	return IndexSummary{Head: head}
+}
+
+func (summary IndexSummary) IsLinkOnly() bool {
+	return len(summary.Tables) == 0
+}
+
+// DifferentTables finds the tables in the summary whose hashes are not present in hashes.
+func (summary IndexSummary) DifferentTables(hashes map[TableName]TableHash) []TableSummary {
+	different := []TableSummary{}
+
+	for _, table := range summary.Tables {
+		if hash, present := hashes[table.TableName]; present && hash == table.Hash {
+			continue
+		}
+
+		different = append(different, table)
+	}
+
+	return different
+}
+
+func (summary IndexSummary) Equals(other IndexSummary) bool {
+	ok := summary.Head.Equals(other.Head)
+	ok = ok && summary.Root == other.Root
+	ok = ok && len(summary.Tables) == len(other.Tables)
+
+	if !ok {
+		return false
+	}
+
+	for i, table := range summary.Tables {
+		theirs := other.Tables[i]
+		ok = table.TableName == theirs.TableName
+		ok = ok && table.Hash == theirs.Hash
+		ok = ok && table.Link.Equals(theirs.Link)
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HashIndexTables hashes the sorted index stream entries for each table.
+func HashIndexTables(index Index) (map[TableName]TableHash, []InvalidIndexEntry) {
+	stream, invalid := MakeIndexStream(index)
+
+	hashes := map[TableName]TableHash{}
+
+	start := 0
+	for i := 1; i <= len(stream); i++ {
+		if i < len(stream) && stream[i].TableName == stream[start].TableName {
+			continue
+		}
+
+		table := stream[start].TableName
+		hashes[table] = hashIndexStream(stream[start:i])
+		start = i
+	}
+
+	return hashes, invalid
+}
+
+// HashRoot combines table hashes into a single hash for the whole index.
+func HashRoot(hashes map[TableName]TableHash) TableHash {
+	tables := make([]TableName, 0, len(hashes))
+
+	for table := range hashes {
+		tables = append(tables, table)
+	}
+
+	sort.Sort(byTableName(tables))
+
+	digest := sha256.New()
+	for _, table := range tables {
+		writeHashField(digest, string(table))
+		writeHashField(digest, string(hashes[table]))
+	}
+
+	return TableHash(hex.EncodeToString(digest.Sum(nil)))
+}
+
+func hashIndexStream(stream []IndexStreamEntry) TableHash {
+	digest := sha256.New()
+
+	for _, entry := range stream {
+		writeHashField(digest, string(entry.TableName))
+		writeHashField(digest, string(entry.Link))
+		writeHashField(digest, string(entry.Signature))
+	}
+
+	return TableHash(hex.EncodeToString(digest.Sum(nil)))
+}
+
+func writeHashField(digest io.Writer, field string) {
+	digest.Write([]byte(field))
+	digest.Write([]byte{0})
+}
+
+// TableIndex returns an Index containing only the named table.
+func (index Index) TableIndex(table TableName) Index {
+	tableIndex := EmptyIndex()
+
+	if links, present := index.Index[table]; present {
+		tableIndex.addTable(table, links...)
+	}
+
+	return tableIndex
+}
+
+func MakeIndexSummaryMessage(summary IndexSummary) (*proto.IndexSummaryMessage, error) {
+	const failMsg = "MakeIndexSummaryMessage failed"
+
+	head, err := MakeLinkMessage(summary.Head)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	message := &proto.IndexSummaryMessage{
+		Head:   head,
+		Root:   string(summary.Root),
+		Tables: make([]*proto.TableSummaryMessage, len(summary.Tables)),
+	}
+
+	for i, table := range summary.Tables {
+		link, err := MakeLinkMessage(table.Link)
+
+		if err != nil {
+			return nil, errors.Wrap(err, failMsg)
+		}
+
+		message.Tables[i] = &proto.TableSummaryMessage{
+			Table: string(table.TableName),
+			Hash:  string(table.Hash),
+			Link:  link,
+		}
+	}
+
+	return message, nil
+}
+
+func ReadIndexSummaryMessage(message *proto.IndexSummaryMessage) (IndexSummary, error) {
+	const failMsg = "ReadIndexSummaryMessage failed"
+
+	if message.Head == nil {
+		return IndexSummary{}, errors.New("IndexSummaryMessage has no head")
+	}
+
+	head, err := ReadLinkMessage(message.Head)
+
+	if err != nil {
+		return IndexSummary{}, errors.Wrap(err, failMsg)
+	}
+
+	summary := IndexSummary{
+		Head:   head,
+		Root:   TableHash(message.Root),
+		Tables: make([]TableSummary, 0, len(message.Tables)),
+	}
+
+	for _, tableMessage := range message.Tables {
+		if tableMessage.Link == nil {
+			return IndexSummary{}, errors.New("TableSummaryMessage has no link")
+		}
+
+		link, err := ReadLinkMessage(tableMessage.Link)
+
+		if err != nil {
+			return IndexSummary{}, errors.Wrap(err, failMsg)
+		}
+
+		table := TableSummary{
+			TableName: TableName(tableMessage.Table),
+			Hash:      TableHash(tableMessage.Hash),
+			Link:      link,
+		}
+
+		summary.Tables = append(summary.Tables, table)
+	}
+
+	return summary, nil
+}
+
+type IndexSummaryText string
+
+func SerializeIndexSummary(summary IndexSummary) (IndexSummaryText, error) {
+	const failMsg = "SerializeIndexSummary failed"
+
+	message, err := MakeIndexSummaryMessage(summary)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	buff := bytes.Buffer{}
+
+	pbErr := pb.MarshalText(&buff, message)
+
+	if pbErr != nil {
+		return "", errors.Wrap(pbErr, failMsg)
+	}
+
+	return IndexSummaryText(buff.String()), nil
+}
+
+func ParseIndexSummary(text IndexSummaryText) (IndexSummary, error) {
+	const failMsg = "ParseIndexSummary failed"
+
+	message := proto.IndexSummaryMessage{}
+	pbErr := pb.UnmarshalText(string(text), &message)
+
+	if pbErr != nil {
+		return IndexSummary{}, errors.Wrap(pbErr, failMsg)
+	}
+
+	summary, err := ReadIndexSummaryMessage(&message)
+
+	if err != nil {
+		return IndexSummary{}, errors.Wrap(err, failMsg)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,116 @@
+package crdt
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestHashIndexTables(t *testing.T) {
+	config := &quick.Config{
+		MaxCount: testutil.ENCODE_REPEAT_COUNT,
+	}
+
+	err := quick.Check(hashIndexTablesOk, config)
+
+	testutil.AssertVerboseErrorIsNil(t, err)
+}
+
+func hashIndexTablesOk(index Index) bool {
+	hashes, invalid := HashIndexTables(index)
+	copyHashes, copyInvalid := HashIndexTables(index.Copy())
+
+	ok := len(invalid) == 0 && len(copyInvalid) == 0
+	ok = ok && len(hashes) == len(index.AllTables())
+	ok = ok && HashRoot(hashes) == HashRoot(copyHashes)
+
+	for _, table := range index.AllTables() {
+		ok = ok && hashes[table] == copyHashes[table]
+	}
+
+	return ok
+}
+
+func TestHashIndexTablesChange(t *testing.T) {
+	index := MakeIndex(map[TableName]Link{
+		"Cars":    UnsignedLink("Addr1"),
+		"Animals": UnsignedLink("Addr2"),
+	})
+
+	changed := index.JoinTable("Cars", UnsignedLink("Addr3"))
+
+	hashes, invalid := HashIndexTables(index)
+	testutil.AssertLenEquals(t, 0, invalid)
+	changedHashes, changedInvalid := HashIndexTables(changed)
+	testutil.AssertLenEquals(t, 0, changedInvalid)
+
+	testutil.Assert(t, "Expected different Cars hash", hashes["Cars"] != changedHashes["Cars"])
+	testutil.AssertEquals(t, "Expected same Animals hash", hashes["Animals"], changedHashes["Animals"])
+	testutil.Assert(t, "Expected different root hash", HashRoot(hashes) != HashRoot(changedHashes))
+
+	summary := IndexSummary{
+		Head: UnsignedLink("Head"),
+		Root: HashRoot(changedHashes),
+		Tables: []TableSummary{
+			TableSummary{TableName: "Animals", Hash: changedHashes["Animals"], Link: UnsignedLink("Animals")},
+			TableSummary{TableName: "Cars", Hash: changedHashes["Cars"], Link: UnsignedLink("Cars")},
+		},
+	}
+
+	different := summary.DifferentTables(hashes)
+	testutil.AssertLenEquals(t, 1, different)
+	testutil.AssertEquals(t, "Unexpected table", TableName("Cars"), different[0].TableName)
+
+	testutil.AssertLenEquals(t, 0, summary.DifferentTables(changedHashes))
+}
+
+func TestTableIndex(t *testing.T) {
+	index := MakeIndex(map[TableName]Link{
+		"Cars":    UnsignedLink("Addr1"),
+		"Animals": UnsignedLink("Addr2"),
+	})
+
+	expected := MakeIndex(map[TableName]Link{
+		"Cars": UnsignedLink("Addr1"),
+	})
+
+	actual := index.TableIndex("Cars")
+
+	testutil.Assert(t, "Unexpected table index", expected.Equals(actual))
+	testutil.Assert(t, "Expected empty index", index.TableIndex("Missing").IsEmpty())
+}
+
+func TestSerializeIndexSummary(t *testing.T) {
+	priv, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	head, err := SignedLink("Head", []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+
+	tableLink, err := SignedLink("Cars", []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+
+	expected := IndexSummary{
+		Head: head,
+		Root: "Root",
+		Tables: []TableSummary{
+			TableSummary{TableName: "Cars", Hash: "Hash", Link: tableLink},
+		},
+	}
+
+	text, err := SerializeIndexSummary(expected)
+	testutil.AssertNil(t, err)
+
+	actual, err := ParseIndexSummary(text)
+	testutil.AssertNil(t, err)
+
+	testutil.Assert(t, "Unexpected IndexSummary", expected.Equals(actual))
+
+	linkText, err := SerializeLink(head)
+	testutil.AssertNil(t, err)
+
+	_, err = ParseIndexSummary(IndexSummaryText(linkText))
+	testutil.AssertNonNil(t, err)
+}
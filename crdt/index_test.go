@@ -11,6 +11,7 @@ import (
 	"testing/quick"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/johnny-morrice/godless/crypto"
 	"github.com/johnny-morrice/godless/internal/testutil"
 	"github.com/johnny-morrice/godless/log"
 )
@@ -217,6 +218,28 @@ func assertIndexSubset(t *testing.T, subset, superset Index) {
 	testutil.Assert(t, "Expected index subset", isIndexSubset(subset, superset))
 }
 
+func TestIndexTablesSignedBy(t *testing.T) {
+	signer, signerPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	other, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	signerLink, err := SignedLink("signer", []crypto.PrivateKey{signer})
+	testutil.AssertNil(t, err)
+	otherLink, err := SignedLink("other", []crypto.PrivateKey{other})
+	testutil.AssertNil(t, err)
+
+	index := EmptyIndex()
+	index = index.JoinTable("drivers", signerLink)
+	index = index.JoinTable("cars", signerLink)
+	index = index.JoinTable("cars", otherLink)
+	index = index.JoinTable("bikes", otherLink)
+
+	expected := []TableName{"cars", "drivers"}
+	actual := index.TablesSignedBy(signerPub)
+	testutil.AssertEquals(t, "Unexpected tables", expected, actual)
+}
+
 func TestIndexIsEmpty(t *testing.T) {
 	empty := EmptyIndex()
 
@@ -69,11 +69,28 @@ func MakeNamespace(tables map[TableName]Table) Namespace {
 	return out
 }
 
+// FilterVerified keeps the points signed by any of the keys.  If any of the keys signed the
+// namespace as a whole, every point is kept.  The namespace signatures are removed.
 func (ns Namespace) FilterVerified(keys []crypto.PublicKey) Namespace {
+	return ns.FilterVerifiedThreshold(keys, 1)
+}
+
+// FilterVerifiedThreshold keeps the points signed by at least threshold distinct keys.  If enough
+// of the keys signed the namespace as a whole, every point is kept.  The namespace signatures are
+// removed.
+func (ns Namespace) FilterVerifiedThreshold(keys []crypto.PublicKey, threshold int) Namespace {
+	content := ns.WithoutNamespaceSignature()
+
+	signature := ns.readNamespaceSignature()
+
+	if signature.isVerifiedByThreshold(keys, threshold) {
+		return content
+	}
+
 	verified := EmptyNamespace()
 
-	ns.ForeachEntry(func(t TableName, r RowName, e EntryName, entry Entry) {
-		signed := entry.FilterVerified(keys)
+	content.ForeachEntry(func(t TableName, r RowName, e EntryName, entry Entry) {
+		signed := entry.FilterVerifiedThreshold(keys, threshold)
 		verified.addEntry(t, r, e, signed)
 	})
 
@@ -197,6 +214,19 @@ func (ns Namespace) JoinNamespace(other Namespace) Namespace {
 	return joined
 }
 
+// JoinAllNamespaces joins many namespaces without copying the result for each one.
+func JoinAllNamespaces(namespaces []Namespace) Namespace {
+	joined := EmptyNamespace()
+
+	for _, ns := range namespaces {
+		for k, table := range ns.Copy().Tables {
+			joined.addTable(k, table)
+		}
+	}
+
+	return joined
+}
+
 func (ns Namespace) addTable(key TableName, table Table) {
 	current, present := ns.Tables[key]
 
@@ -470,10 +500,28 @@ func MakeEntry(set []Point) Entry {
 }
 
 func (e Entry) FilterVerified(keys []crypto.PublicKey) Entry {
+	return e.FilterVerifiedThreshold(keys, 1)
+}
+
+// FilterVerifiedByGroups keeps the points signed by keys from at least threshold of the groups.
+func (e Entry) FilterVerifiedByGroups(groups [][]crypto.PublicKey, threshold int) Entry {
+	verified := make([]Point, 0, len(e.Set))
+
+	for _, p := range e.Set {
+		if p.IsVerifiedByGroups(groups, threshold) {
+			verified = append(verified, p)
+		}
+	}
+
+	return Entry{Set: verified}
+}
+
+// FilterVerifiedThreshold keeps the points signed by at least threshold distinct keys.
+func (e Entry) FilterVerifiedThreshold(keys []crypto.PublicKey, threshold int) Entry {
 	verified := make([]Point, 0, len(e.Set))
 
 	for _, p := range e.Set {
-		if p.IsVerifiedByAny(keys) {
+		if p.IsVerifiedByThreshold(keys, threshold) {
 			verified = append(verified, p)
 		}
 	}
@@ -0,0 +1,163 @@
+package crdt
+
+import (
+	"sort"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/proto"
+)
+
+// NamespaceDiff describes the points, and signatures on points, that differ between two namespaces.
+type NamespaceDiff struct {
+	Added   Namespace
+	Removed Namespace
+}
+
+func EmptyNamespaceDiff() NamespaceDiff {
+	return NamespaceDiff{
+		Added:   EmptyNamespace(),
+		Removed: EmptyNamespace(),
+	}
+}
+
+// DiffNamespace finds the points added and removed between older and newer.
+// A point with a new signature is reported as added, with only the new signature.
+func DiffNamespace(older, newer Namespace) (NamespaceDiff, []InvalidNamespaceEntry) {
+	olderStream, olderInvalid := MakeNamespaceStream(older)
+	newerStream, newerInvalid := MakeNamespaceStream(newer)
+
+	addedStream := subtractNamespaceStream(newerStream, olderStream)
+	removedStream := subtractNamespaceStream(olderStream, newerStream)
+
+	added, addedInvalid := ReadNamespaceStream(addedStream)
+	removed, removedInvalid := ReadNamespaceStream(removedStream)
+
+	invalid := append(olderInvalid, newerInvalid...)
+	invalid = append(invalid, addedInvalid...)
+	invalid = append(invalid, removedInvalid...)
+
+	diff := NamespaceDiff{
+		Added:   added,
+		Removed: removed,
+	}
+
+	return diff, invalid
+}
+
+// Both streams must be in sorted, unique order.
+func subtractNamespaceStream(stream, remove []NamespaceStreamEntry) []NamespaceStreamEntry {
+	out := []NamespaceStreamEntry{}
+
+	i := 0
+	for _, entry := range stream {
+		for i < len(remove) && remove[i].Less(entry) {
+			i++
+		}
+
+		if i < len(remove) && remove[i] == entry {
+			continue
+		}
+
+		// An unsigned point is absorbed when the same point is signed.
+		isUnsigned := crypto.IsNilSignature(entry.Point.Signature)
+		if isUnsigned && i < len(remove) && remove[i].samePoint(entry) {
+			continue
+		}
+
+		out = append(out, entry)
+	}
+
+	return out
+}
+
+func (diff NamespaceDiff) IsEmpty() bool {
+	return diff.Added.IsEmpty() && diff.Removed.IsEmpty()
+}
+
+func (diff NamespaceDiff) Equals(other NamespaceDiff) bool {
+	return diff.Added.Equals(other.Added) && diff.Removed.Equals(other.Removed)
+}
+
+func (diff NamespaceDiff) JoinNamespaceDiff(other NamespaceDiff) NamespaceDiff {
+	return NamespaceDiff{
+		Added:   diff.Added.JoinNamespace(other.Added),
+		Removed: diff.Removed.JoinNamespace(other.Removed),
+	}
+}
+
+func MakeNamespaceDiffMessage(diff NamespaceDiff) (*proto.NamespaceDiffMessage, []InvalidNamespaceEntry) {
+	added, addedInvalid := MakeNamespaceMessage(diff.Added)
+	removed, removedInvalid := MakeNamespaceMessage(diff.Removed)
+
+	message := &proto.NamespaceDiffMessage{
+		Added:   added,
+		Removed: removed,
+	}
+
+	return message, append(addedInvalid, removedInvalid...)
+}
+
+func ReadNamespaceDiffMessage(message *proto.NamespaceDiffMessage) (NamespaceDiff, []InvalidNamespaceEntry) {
+	diff := EmptyNamespaceDiff()
+	var invalid []InvalidNamespaceEntry
+
+	if message.Added != nil {
+		added, addedInvalid := ReadNamespaceMessage(message.Added)
+		diff.Added = added
+		invalid = append(invalid, addedInvalid...)
+	}
+
+	if message.Removed != nil {
+		removed, removedInvalid := ReadNamespaceMessage(message.Removed)
+		diff.Removed = removed
+		invalid = append(invalid, removedInvalid...)
+	}
+
+	return diff, invalid
+}
+
+// ChangedTables finds the tables whose links differ between two indices.
+func ChangedTables(older, newer Index) []TableName {
+	tables := map[TableName]struct{}{}
+
+	for _, table := range older.AllTables() {
+		tables[table] = struct{}{}
+	}
+
+	for _, table := range newer.AllTables() {
+		tables[table] = struct{}{}
+	}
+
+	changed := []TableName{}
+	for table := range tables {
+		olderLinks := older.Index[table]
+		newerLinks := newer.Index[table]
+
+		if !sameLinkPaths(olderLinks, newerLinks) {
+			changed = append(changed, table)
+		}
+	}
+
+	sort.Sort(byTableName(changed))
+
+	return changed
+}
+
+func sameLinkPaths(links, other []Link) bool {
+	if len(links) != len(other) {
+		return false
+	}
+
+	paths := map[IPFSPath]struct{}{}
+	for _, link := range links {
+		paths[link.Path()] = struct{}{}
+	}
+
+	for _, link := range other {
+		if _, present := paths[link.Path()]; !present {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,104 @@
+package crdt
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestDiffNamespace(t *testing.T) {
+	const table = TableName("Table")
+
+	older := EmptyNamespace().JoinTable(table, MakeTable(map[RowName]Row{
+		"Row A": MakeRow(map[EntryName]Entry{
+			"Entry": MakeEntry([]Point{UnsignedPoint("Same"), UnsignedPoint("Old")}),
+		}),
+	}))
+	newer := EmptyNamespace().JoinTable(table, MakeTable(map[RowName]Row{
+		"Row A": MakeRow(map[EntryName]Entry{
+			"Entry": MakeEntry([]Point{UnsignedPoint("Same")}),
+		}),
+		"Row B": MakeRow(map[EntryName]Entry{
+			"Entry": MakeEntry([]Point{UnsignedPoint("New")}),
+		}),
+	}))
+
+	expectedRemoved := EmptyNamespace().JoinTable(table, MakeTable(map[RowName]Row{
+		"Row A": MakeRow(map[EntryName]Entry{
+			"Entry": MakeEntry([]Point{UnsignedPoint("Old")}),
+		}),
+	}))
+	expectedAdded := EmptyNamespace().JoinTable(table, MakeTable(map[RowName]Row{
+		"Row B": MakeRow(map[EntryName]Entry{
+			"Entry": MakeEntry([]Point{UnsignedPoint("New")}),
+		}),
+	}))
+
+	diff, invalid := DiffNamespace(older, newer)
+	testutil.AssertLenEquals(t, 0, invalid)
+
+	testutil.Assert(t, "Unexpected added", expectedAdded.Equals(diff.Added))
+	testutil.Assert(t, "Unexpected removed", expectedRemoved.Equals(diff.Removed))
+}
+
+func TestDiffNamespaceSignature(t *testing.T) {
+	const table = TableName("Table")
+
+	priv, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	signed, err := SignedPoint("Point", []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+
+	older := EmptyNamespace().JoinTable(table, MakeTable(map[RowName]Row{
+		"Row": MakeRow(map[EntryName]Entry{
+			"Entry": MakeEntry([]Point{UnsignedPoint("Point")}),
+		}),
+	}))
+	newer := EmptyNamespace().JoinTable(table, MakeTable(map[RowName]Row{
+		"Row": MakeRow(map[EntryName]Entry{
+			"Entry": MakeEntry([]Point{signed}),
+		}),
+	}))
+
+	diff, invalid := DiffNamespace(older, older.JoinNamespace(newer))
+	testutil.AssertLenEquals(t, 0, invalid)
+
+	testutil.Assert(t, "Expected no removed", diff.Removed.IsEmpty())
+	testutil.Assert(t, "Unexpected added", newer.Equals(diff.Added))
+}
+
+func TestDiffNamespaceSelf(t *testing.T) {
+	config := &quick.Config{
+		MaxCount: testutil.ENCODE_REPEAT_COUNT,
+	}
+
+	err := quick.Check(diffSelfEmpty, config)
+
+	testutil.AssertVerboseErrorIsNil(t, err)
+}
+
+func diffSelfEmpty(namespace Namespace) bool {
+	diff, _ := DiffNamespace(namespace, namespace)
+	return diff.IsEmpty()
+}
+
+func TestChangedTables(t *testing.T) {
+	linkA := UnsignedLink("Addr A")
+	linkB := UnsignedLink("Addr B")
+	linkC := UnsignedLink("Addr C")
+
+	older := EmptyIndex().JoinTable("Same", linkA).JoinTable("Changed", linkB).JoinTable("Removed", linkC)
+	newer := EmptyIndex().JoinTable("Same", linkA).JoinTable("Changed", linkB, linkC).JoinTable("Added", linkA)
+
+	changed := ChangedTables(older, newer)
+
+	expected := []TableName{"Added", "Changed", "Removed"}
+	testutil.AssertLenEquals(t, len(expected), changed)
+
+	for i, table := range expected {
+		testutil.AssertEquals(t, "Unexpected table", table, changed[i])
+	}
+}
@@ -92,16 +92,21 @@ func reportEmptyTable(stream []NamespaceStreamEntry, from string) {
 
 // TODO should return the invalid entries
 func EncodeNamespace(ns Namespace, w io.Writer) ([]InvalidNamespaceEntry, error) {
-	const failMsg = "EncodeNamespace failed"
+	return EncodeNamespaceCodec(ns, CODEC_NONE, w)
+}
+
+// EncodeNamespaceCodec encodes the namespace as a stream compressed by codec.
+func EncodeNamespaceCodec(ns Namespace, codec Codec, w io.Writer) ([]InvalidNamespaceEntry, error) {
+	const failMsg = "EncodeNamespaceCodec failed"
 
-	message, invalid := MakeNamespaceMessage(ns)
+	stream, invalid := MakeNamespaceStream(ns)
 
 	invalidCount := len(invalid)
 	if invalidCount > 0 {
 		log.Error("EncodeNamespace: %d invalid points", invalidCount)
 	}
 
-	err := util.Encode(message, w)
+	err := EncodeNamespaceStream(stream, codec, w)
 
 	if err != nil {
 		return invalid, errors.Wrap(err, failMsg)
@@ -110,10 +115,25 @@ func EncodeNamespace(ns Namespace, w io.Writer) ([]InvalidNamespaceEntry, error)
 	return invalid, nil
 }
 
+// DecodeNamespace reads streams with any codec, and the legacy single message encoding.
 func DecodeNamespace(r io.Reader) (Namespace, []InvalidNamespaceEntry, error) {
 	const failMsg = "DecodeNamespace failed"
+
+	buffered := bufferReader(r)
+
+	if IsStreamEncoding(buffered) {
+		stream, err := DecodeNamespaceStream(buffered)
+
+		if err != nil {
+			return EmptyNamespace(), nil, errors.Wrap(err, failMsg)
+		}
+
+		namespace, invalid := ReadNamespaceStream(stream)
+		return namespace, invalid, nil
+	}
+
 	message := &proto.NamespaceMessage{}
-	err := util.Decode(message, r)
+	err := util.Decode(message, buffered)
 
 	if err != nil {
 		return EmptyNamespace(), nil, errors.Wrap(err, failMsg)
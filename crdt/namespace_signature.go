@@ -0,0 +1,203 @@
+package crdt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/crypto"
+)
+
+// NAMESPACE_SIGNATURE_TABLE holds signatures over the rest of the namespace, so that its points
+// need not be signed one by one.  The name sorts before any other table, so the signatures are the
+// first rows in a namespace stream.
+const NAMESPACE_SIGNATURE_TABLE TableName = "!signatures"
+
+const NAMESPACE_SIGNATURE_ROW RowName = "namespace"
+
+// NAMESPACE_SIGNATURE_ENTRY holds a point whose text is the digest of the namespace stream, signed
+// by each key that signed the namespace.
+const NAMESPACE_SIGNATURE_ENTRY EntryName = "digest"
+
+// SignNamespace signs the canonical stream of the namespace once with each key.  Points in the
+// namespace need not be signed themselves.  Any earlier namespace signature is replaced.
+func SignNamespace(ns Namespace, keys []crypto.PrivateKey) (Namespace, error) {
+	const failMsg = "SignNamespace failed"
+
+	content := ns.WithoutNamespaceSignature()
+
+	if len(keys) == 0 {
+		return content, nil
+	}
+
+	digest, err := namespaceDigest(content)
+
+	if err != nil {
+		return EmptyNamespace(), errors.Wrap(err, failMsg)
+	}
+
+	point, err := SignedPoint(digest, keys)
+
+	if err != nil {
+		return EmptyNamespace(), errors.Wrap(err, failMsg)
+	}
+
+	row := MakeRow(map[EntryName]Entry{
+		NAMESPACE_SIGNATURE_ENTRY: MakeEntry([]Point{point}),
+	})
+
+	table := EmptyTable().JoinRow(NAMESPACE_SIGNATURE_ROW, row)
+
+	return content.JoinTable(NAMESPACE_SIGNATURE_TABLE, table), nil
+}
+
+// IsNamespaceSigned is true if the namespace carries namespace signatures.  The signatures are not
+// verified.
+func (ns Namespace) IsNamespaceSigned() bool {
+	_, present := ns.Tables[NAMESPACE_SIGNATURE_TABLE]
+	return present
+}
+
+// WithoutNamespaceSignature removes the namespace signatures.
+func (ns Namespace) WithoutNamespaceSignature() Namespace {
+	if !ns.IsNamespaceSigned() {
+		return ns
+	}
+
+	content := MakeNamespace(ns.Tables)
+	delete(content.Tables, NAMESPACE_SIGNATURE_TABLE)
+	return content
+}
+
+// IsNamespaceVerifiedByAny is true if any of the keys signed the namespace as a whole.
+func (ns Namespace) IsNamespaceVerifiedByAny(keys []crypto.PublicKey) bool {
+	signature := ns.readNamespaceSignature()
+	return signature.isVerifiedByAny(keys)
+}
+
+type namespaceSignature struct {
+	digest PointText
+	points []Point
+}
+
+func (ns Namespace) readNamespaceSignature() namespaceSignature {
+	signature := namespaceSignature{}
+
+	table, present := ns.Tables[NAMESPACE_SIGNATURE_TABLE]
+
+	if !present {
+		return signature
+	}
+
+	digest, err := namespaceDigest(ns.WithoutNamespaceSignature())
+
+	if err != nil {
+		return signature
+	}
+
+	signature.digest = digest
+
+	table.ForeachEntry(func(r RowName, e EntryName, entry Entry) {
+		if r != NAMESPACE_SIGNATURE_ROW || e != NAMESPACE_SIGNATURE_ENTRY {
+			return
+		}
+
+		signature.points = append(signature.points, entry.GetValues()...)
+	})
+
+	return signature
+}
+
+func (signature namespaceSignature) isVerifiedByAny(keys []crypto.PublicKey) bool {
+	return signature.isVerifiedByThreshold(keys, 1)
+}
+
+// isVerifiedByThreshold is true if at least threshold distinct keys signed the digest.
+func (signature namespaceSignature) isVerifiedByThreshold(keys []crypto.PublicKey, threshold int) bool {
+	if len(keys) == 0 {
+		return false
+	}
+
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	count := 0
+
+	for i, pub := range keys {
+		if isDuplicateKey(keys[:i], pub) {
+			continue
+		}
+
+		if signature.isVerifiedBy(pub) {
+			count++
+		}
+
+		if count >= threshold {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isVerifiedByGroups is true if keys from at least threshold of the groups signed the digest.
+func (signature namespaceSignature) isVerifiedByGroups(groups [][]crypto.PublicKey, threshold int) bool {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	count := 0
+
+	for _, group := range groups {
+		if signature.isVerifiedByAny(group) {
+			count++
+		}
+
+		if count >= threshold {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (signature namespaceSignature) isVerifiedBy(pub crypto.PublicKey) bool {
+	for _, point := range signature.points {
+		if point.Text() == signature.digest && point.IsVerifiedBy(pub) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// namespaceDigest hashes the canonical stream of the namespace.  Each field is length prefixed so
+// that no two streams hash the same text.
+func namespaceDigest(ns Namespace) (PointText, error) {
+	stream, invalid := MakeNamespaceStream(ns)
+
+	if len(invalid) > 0 {
+		return "", errors.Errorf("Namespace has %d invalid entries", len(invalid))
+	}
+
+	hash := sha256.New()
+
+	for _, entry := range stream {
+		fields := []string{
+			string(entry.Table),
+			string(entry.Row),
+			string(entry.Entry),
+			string(entry.Point.Text),
+			string(entry.Point.Signature),
+		}
+
+		for _, field := range fields {
+			fmt.Fprintf(hash, "%d:%s", len(field), field)
+		}
+	}
+
+	return PointText(hex.EncodeToString(hash.Sum(nil))), nil
+}
@@ -0,0 +1,149 @@
+package crdt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestSignNamespace(t *testing.T) {
+	priv, pub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	_, otherPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	unsigned := makeSignatureTestNamespace(UnsignedPoint("Hello"), UnsignedPoint("World"))
+
+	signed, err := SignNamespace(unsigned, []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+
+	testutil.Assert(t, "Expected namespace signature", signed.IsNamespaceSigned())
+	testutil.Assert(t, "Expected verified namespace", signed.IsNamespaceVerifiedByAny([]crypto.PublicKey{pub}))
+	testutil.Assert(t, "Unexpected verified namespace", !signed.IsNamespaceVerifiedByAny([]crypto.PublicKey{otherPub}))
+	testutil.Assert(t, "Unexpected content", unsigned.Equals(signed.WithoutNamespaceSignature()))
+
+	index := EmptyIndex().JoinNamespace(UnsignedLink("Addr"), signed)
+	testutil.AssertLenEquals(t, 1, index.AllTables())
+
+	resigned, err := SignNamespace(signed, []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected same signed namespace", signed.Equals(resigned))
+
+	tampered := signed.JoinNamespace(makeSignatureTestNamespace(UnsignedPoint("Injected")))
+	testutil.Assert(t, "Unexpected verified tampered namespace", !tampered.IsNamespaceVerifiedByAny([]crypto.PublicKey{pub}))
+
+	same, err := SignNamespace(unsigned, nil)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected namespace signature", !same.IsNamespaceSigned())
+}
+
+func TestSignNamespaceStreamRoundTrip(t *testing.T) {
+	priv, pub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	signed, err := SignNamespace(makeSignatureTestNamespace(UnsignedPoint("Hello")), []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+
+	stream, invalid := MakeNamespaceStream(signed)
+	testutil.AssertLenEquals(t, 0, invalid)
+	testutil.AssertEquals(t, "Expected signatures first", NAMESPACE_SIGNATURE_TABLE, stream[0].Table)
+
+	read, invalid := ReadNamespaceStream(stream)
+	testutil.AssertLenEquals(t, 0, invalid)
+	testutil.Assert(t, "Expected verified namespace", read.IsNamespaceVerifiedByAny([]crypto.PublicKey{pub}))
+}
+
+func TestNamespaceFilterVerifiedNamespaceSignature(t *testing.T) {
+	namespaceKey, namespacePub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	pointKey, pointPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	signedPoint, err := SignedPoint("Signed", []crypto.PrivateKey{pointKey})
+	testutil.AssertNil(t, err)
+
+	unsigned := makeSignatureTestNamespace(UnsignedPoint("Unsigned"), signedPoint)
+	signed, err := SignNamespace(unsigned, []crypto.PrivateKey{namespaceKey})
+	testutil.AssertNil(t, err)
+
+	actual := signed.FilterVerified([]crypto.PublicKey{namespacePub})
+	testutil.Assert(t, "Expected all points", unsigned.Equals(actual))
+
+	expected := makeSignatureTestNamespace(signedPoint)
+	actual = signed.FilterVerified([]crypto.PublicKey{pointPub})
+	testutil.Assert(t, "Expected point signatures", expected.Equals(actual))
+}
+
+func TestNamespaceFilterVerifiedThresholdNamespaceSignature(t *testing.T) {
+	privs := make([]crypto.PrivateKey, 3)
+	pubs := make([]crypto.PublicKey, 3)
+
+	for i := range privs {
+		priv, pub, err := crypto.GenerateKey()
+		testutil.AssertNil(t, err)
+		privs[i] = priv
+		pubs[i] = pub
+	}
+
+	unsigned := makeSignatureTestNamespace(UnsignedPoint("Hello"))
+
+	signedTwice, err := SignNamespace(unsigned, privs[:2])
+	testutil.AssertNil(t, err)
+	actual := signedTwice.FilterVerifiedThreshold(pubs, 2)
+	testutil.Assert(t, "Expected all points", unsigned.Equals(actual))
+
+	signedOnce, err := SignNamespace(unsigned, privs[:1])
+	testutil.AssertNil(t, err)
+	actual = signedOnce.FilterVerifiedThreshold(pubs, 2)
+	stripped, _ := actual.Strip()
+	testutil.Assert(t, "Unexpected points", stripped.IsEmpty())
+
+	actual = signedOnce.FilterDelegatedThreshold(pubs, nil, nil, 2)
+	stripped, _ = actual.Strip()
+	testutil.Assert(t, "Unexpected points", stripped.IsEmpty())
+}
+
+func TestNamespaceFilterDelegatedNamespaceSignature(t *testing.T) {
+	root, rootPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	member, memberPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	delegation, err := crypto.MakeDelegation(root, memberPub, []string{"cars"}, time.Time{})
+	testutil.AssertNil(t, err)
+
+	table := MakeTable(map[RowName]Row{
+		"Row": MakeRow(map[EntryName]Entry{
+			"Entry": MakeEntry([]Point{UnsignedPoint("Member")}),
+		}),
+	})
+
+	unsigned := MakeNamespace(map[TableName]Table{
+		"cars":    table,
+		"drivers": table,
+	})
+
+	signed, err := SignNamespace(unsigned, []crypto.PrivateKey{member})
+	testutil.AssertNil(t, err)
+
+	actual := signed.FilterDelegated([]crypto.PublicKey{rootPub}, []crypto.Delegation{delegation}, nil)
+
+	cars, err := actual.GetTable("cars")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected delegated table", table.Equals(cars))
+
+	stripped, _ := actual.Strip()
+	_, err = stripped.GetTable("drivers")
+	testutil.AssertNonNil(t, err)
+	testutil.Assert(t, "Unexpected namespace signature", !actual.IsNamespaceSigned())
+}
+
+func makeSignatureTestNamespace(points ...Point) Namespace {
+	return EmptyNamespace().JoinTable("Table", MakeTable(map[RowName]Row{
+		"Row": MakeRow(map[EntryName]Entry{
+			"Entry": MakeEntry(points),
+		}),
+	}))
+}
@@ -28,7 +28,6 @@ func (point StreamPoint) Less(other StreamPoint) bool {
 
 type InvalidNamespaceEntry NamespaceStreamEntry
 
-// FIXME not really a stream, whole is kept in memory.
 type NamespaceStreamEntry struct {
 	Table TableName
 	Row   RowName
@@ -59,27 +58,29 @@ func (stream byNamespaceStreamOrder) Swap(i, j int) {
 }
 
 func (stream byNamespaceStreamOrder) Less(i, j int) bool {
-	a, b := stream[i], stream[j]
+	return stream[i].Less(stream[j])
+}
 
-	if a.Table < b.Table {
+func (entry NamespaceStreamEntry) Less(other NamespaceStreamEntry) bool {
+	if entry.Table < other.Table {
 		return true
-	} else if a.Table > b.Table {
+	} else if entry.Table > other.Table {
 		return false
 	}
 
-	if a.Row < b.Row {
+	if entry.Row < other.Row {
 		return true
-	} else if a.Row > b.Row {
+	} else if entry.Row > other.Row {
 		return false
 	}
 
-	if a.Entry < b.Entry {
+	if entry.Entry < other.Entry {
 		return true
-	} else if a.Entry > b.Entry {
+	} else if entry.Entry > other.Entry {
 		return false
 	}
 
-	return a.Point.Less(b.Point)
+	return entry.Point.Less(other.Point)
 }
 
 type streamBuilder struct {
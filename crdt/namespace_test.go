@@ -95,6 +95,35 @@ func TestNamespaceFilterVerified(t *testing.T) {
 	testutil.Assert(t, "Unexpected namespace", expected.Equals(actual))
 }
 
+func TestNamespaceFilterVerifiedThreshold(t *testing.T) {
+	privs := make([]crypto.PrivateKey, 3)
+	pubs := make([]crypto.PublicKey, 3)
+
+	for i := range privs {
+		priv, pub, err := crypto.GenerateKey()
+		testutil.AssertNil(t, err)
+		privs[i] = priv
+		pubs[i] = pub
+	}
+
+	once, err := SignedPoint("Once", privs[:1])
+	testutil.AssertNil(t, err)
+	twice, err := SignedPoint("Twice", privs[:2])
+	testutil.AssertNil(t, err)
+
+	unfiltered := makeSignatureTestNamespace(once, twice)
+
+	actual := unfiltered.FilterVerifiedThreshold(pubs, 2)
+	testutil.Assert(t, "Unexpected namespace", makeSignatureTestNamespace(twice).Equals(actual))
+
+	actual = unfiltered.FilterVerifiedThreshold(pubs, 1)
+	testutil.Assert(t, "Unexpected namespace", unfiltered.Equals(actual))
+
+	duplicates := []crypto.PublicKey{pubs[0], pubs[0]}
+	actual, _ = unfiltered.FilterVerifiedThreshold(duplicates, 2).Strip()
+	testutil.Assert(t, "Unexpected namespace", actual.IsEmpty())
+}
+
 func TestFilterSignedEntries(t *testing.T) {
 	const count = 10
 	const maxSignage = 3
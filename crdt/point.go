@@ -7,6 +7,12 @@ import (
 
 type PointText string
 
+// IsReservedText is true if the text would be read as a blob reference or as encrypted text, so
+// cannot be written as a plain value.
+func IsReservedText(text PointText) bool {
+	return IsBlobReference(text) || IsEncryptedText(text)
+}
+
 type Point struct {
 	signedText
 }
@@ -0,0 +1,82 @@
+package crdt
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// REVOCATION_TABLE holds key revocations, so they are stored and replicated like any other data.
+// Rows are named by the hash of the revoked key.
+const REVOCATION_TABLE TableName = "godlessrevocations"
+
+const REVOCATION_ENTRY EntryName = "revocation"
+
+// MakeRevocationNamespace makes a namespace that publishes the revocations when joined.
+func MakeRevocationNamespace(revocations ...crypto.Revocation) (Namespace, error) {
+	const failMsg = "MakeRevocationNamespace failed"
+
+	table := EmptyTable()
+
+	for _, revocation := range revocations {
+		hash, err := revocation.Revoked.Hash()
+
+		if err != nil {
+			return EmptyNamespace(), errors.Wrap(err, failMsg)
+		}
+
+		text, err := crypto.SerializeRevocation(revocation)
+
+		if err != nil {
+			return EmptyNamespace(), errors.Wrap(err, failMsg)
+		}
+
+		row := MakeRow(map[EntryName]Entry{
+			REVOCATION_ENTRY: MakeEntry([]Point{UnsignedPoint(PointText(text))}),
+		})
+
+		table = table.JoinRow(RowName(hash), row)
+	}
+
+	return EmptyNamespace().JoinTable(REVOCATION_TABLE, table), nil
+}
+
+// ReadRevocations finds the revocations in the namespace.  Points that are not revocations are
+// logged and skipped.  The revocations are not verified.
+func ReadRevocations(namespace Namespace) []crypto.Revocation {
+	revocations := []crypto.Revocation{}
+
+	table, err := namespace.GetTable(REVOCATION_TABLE)
+
+	if err != nil {
+		return revocations
+	}
+
+	table.ForeachEntry(func(rowName RowName, entryName EntryName, entry Entry) {
+		if entryName != REVOCATION_ENTRY {
+			return
+		}
+
+		for _, point := range entry.GetValues() {
+			revocation, err := crypto.ParseRevocation(crypto.RevocationText(point.Text()))
+
+			if err != nil {
+				log.Warn("Skipping bad revocation in row %s: %s", rowName, err.Error())
+				continue
+			}
+
+			revocations = append(revocations, revocation)
+		}
+	})
+
+	return revocations
+}
+
+// FilterTrusted is like FilterVerified, but ignores signatures by keys that are revoked.
+func (ns Namespace) FilterTrusted(keys []crypto.PublicKey, revocations []crypto.Revocation) Namespace {
+	trusted := crypto.FilterRevoked(keys, revocations, time.Now())
+	return ns.FilterVerified(trusted)
+}
@@ -0,0 +1,64 @@
+package crdt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestRevocationNamespace(t *testing.T) {
+	priv, pub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	revocation, err := crypto.MakeRevocation(pub, priv, time.Now(), "Leaked")
+	testutil.AssertNil(t, err)
+
+	namespace, err := MakeRevocationNamespace(revocation)
+	testutil.AssertNil(t, err)
+	namespace = namespace.JoinTable(REVOCATION_TABLE, MakeTable(map[RowName]Row{
+		"Junk": MakeRow(map[EntryName]Entry{
+			REVOCATION_ENTRY: MakeEntry([]Point{UnsignedPoint("Not a revocation")}),
+		}),
+	}))
+
+	revocations := ReadRevocations(namespace)
+	testutil.AssertLenEquals(t, 1, revocations)
+	testutil.Assert(t, "Unexpected revocation", revocation.Equals(revocations[0]))
+}
+
+func TestNamespaceFilterTrusted(t *testing.T) {
+	leaked, leakedPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	trusted, trustedPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	leakedPoint, err := SignedPoint("Leaked", []crypto.PrivateKey{leaked})
+	testutil.AssertNil(t, err)
+	trustedPoint, err := SignedPoint("Trusted", []crypto.PrivateKey{trusted})
+	testutil.AssertNil(t, err)
+
+	makeNamespace := func(points ...Point) Namespace {
+		return EmptyNamespace().JoinTable("Table", MakeTable(map[RowName]Row{
+			"Row": MakeRow(map[EntryName]Entry{
+				"Entry": MakeEntry(points),
+			}),
+		}))
+	}
+
+	unfiltered := makeNamespace(leakedPoint, trustedPoint)
+	keys := []crypto.PublicKey{leakedPub, trustedPub}
+
+	revocation, err := crypto.MakeRevocation(leakedPub, leaked, time.Now(), "")
+	testutil.AssertNil(t, err)
+	future, err := crypto.MakeRevocation(leakedPub, leaked, time.Now().Add(time.Hour), "")
+	testutil.AssertNil(t, err)
+
+	expected := makeNamespace(trustedPoint)
+	actual := unfiltered.FilterTrusted(keys, []crypto.Revocation{revocation})
+	testutil.Assert(t, "Unexpected filtered namespace", expected.Equals(actual))
+
+	actual = unfiltered.FilterTrusted(keys, []crypto.Revocation{future})
+	testutil.Assert(t, "Unexpected filtered namespace", unfiltered.Equals(actual))
+}
@@ -76,9 +76,69 @@ func (signed signedText) IsVerifiedByAny(keys []crypto.PublicKey) bool {
 	return false
 }
 
+// IsVerifiedByThreshold is true if at least threshold distinct keys signed the text.  A threshold
+// below one is treated as one.
+func (signed signedText) IsVerifiedByThreshold(keys []crypto.PublicKey, threshold int) bool {
+	if threshold <= 1 {
+		return signed.IsVerifiedByAny(keys)
+	}
+
+	return signed.CountVerifiedBy(keys) >= threshold
+}
+
+// IsVerifiedByGroups is true if keys from at least threshold of the groups signed the text.  Each
+// group is a root key and the keys it delegates to, so a root and its delegates count once.  A
+// threshold below one is treated as one.
+func (signed signedText) IsVerifiedByGroups(groups [][]crypto.PublicKey, threshold int) bool {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	count := 0
+
+	for _, group := range groups {
+		if signed.IsVerifiedByAny(group) {
+			count++
+		}
+
+		if count >= threshold {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CountVerifiedBy counts the distinct keys that signed the text.
+func (signed signedText) CountVerifiedBy(keys []crypto.PublicKey) int {
+	count := 0
+
+	for i, pub := range keys {
+		if isDuplicateKey(keys[:i], pub) {
+			continue
+		}
+
+		if signed.IsVerifiedBy(pub) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func isDuplicateKey(keys []crypto.PublicKey, pub crypto.PublicKey) bool {
+	for _, other := range keys {
+		if pub.Equals(other) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (signed signedText) IsVerifiedBy(publicKey crypto.PublicKey) bool {
 	for _, sig := range signed.signatures {
-		ok, err := crypto.Verify(publicKey, signed.text, sig)
+		ok, err := crypto.CachedVerify(publicKey, signed.text, sig)
 
 		if err != nil {
 			log.Warn("Bad key while verifying signedText signature")
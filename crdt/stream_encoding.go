@@ -0,0 +1,494 @@
+package crdt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	pb "github.com/gogo/protobuf/proto"
+	"github.com/johnny-morrice/godless/proto"
+	"github.com/pkg/errors"
+)
+
+// The streaming encoding is a header followed by length delimited protobuf records:
+//
+//	magic | uvarint version | uvarint kind | uvarint codec | codec((uvarint length | record)*)
+//
+// Version 1 streams have no codec field and are never compressed.
+//
+// The magic begins with a zero byte, which can never begin a protobuf message,
+// so streams can be told apart from the legacy encoding of a single message.
+const __STREAM_MAGIC = "\x00gdl"
+const STREAM_VERSION = 2
+const __UNCOMPRESSED_STREAM_VERSION = 1
+
+type streamKind uint64
+
+const (
+	__NAMESPACE_STREAM_KIND = streamKind(iota + 1)
+	__INDEX_STREAM_KIND
+)
+
+// Guard against huge allocations from corrupt input.
+const __MAX_STREAM_RECORD_SIZE = 1 << 26
+
+// IsStreamEncoding peeks at the reader to detect the streaming encoding.
+func IsStreamEncoding(r *bufio.Reader) bool {
+	magic, err := r.Peek(len(__STREAM_MAGIC))
+
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(magic, []byte(__STREAM_MAGIC))
+}
+
+type streamRecordWriter struct {
+	w      io.Writer
+	out    io.WriteCloser
+	kind   streamKind
+	codec  Codec
+	header bool
+	buff   []byte
+}
+
+func (writer *streamRecordWriter) writeHeader() error {
+	const failMsg = "streamRecordWriter.writeHeader failed"
+
+	if writer.header {
+		return nil
+	}
+
+	header := make([]byte, len(__STREAM_MAGIC)+(3*binary.MaxVarintLen64))
+	offset := copy(header, __STREAM_MAGIC)
+	offset += binary.PutUvarint(header[offset:], STREAM_VERSION)
+	offset += binary.PutUvarint(header[offset:], uint64(writer.kind))
+	offset += binary.PutUvarint(header[offset:], uint64(writer.codec))
+
+	err := writeStreamBytes(writer.w, header[:offset])
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	writer.out, err = writer.codec.compress(writer.w)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	writer.header = true
+
+	return nil
+}
+
+func (writer *streamRecordWriter) writeRecord(message pb.Message) error {
+	const failMsg = "streamRecordWriter.writeRecord failed"
+
+	err := writer.writeHeader()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	bs, err := pb.Marshal(message)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if len(writer.buff) < binary.MaxVarintLen64 {
+		writer.buff = make([]byte, binary.MaxVarintLen64)
+	}
+
+	size := binary.PutUvarint(writer.buff, uint64(len(bs)))
+
+	err = writeStreamBytes(writer.out, writer.buff[:size])
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return writeStreamBytes(writer.out, bs)
+}
+
+func (writer *streamRecordWriter) close() error {
+	const failMsg = "streamRecordWriter.close failed"
+
+	err := writer.writeHeader()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return writer.out.Close()
+}
+
+func writeStreamBytes(w io.Writer, bs []byte) error {
+	written, err := w.Write(bs)
+
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("write failed after %d bytes", written))
+	}
+
+	return nil
+}
+
+type streamRecordReader struct {
+	r      *bufio.Reader
+	kind   streamKind
+	header bool
+	buff   []byte
+}
+
+func (reader *streamRecordReader) readHeader() error {
+	const failMsg = "streamRecordReader.readHeader failed"
+
+	if reader.header {
+		return nil
+	}
+
+	reader.header = true
+
+	magic := make([]byte, len(__STREAM_MAGIC))
+	_, err := io.ReadFull(reader.r, magic)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if !bytes.Equal(magic, []byte(__STREAM_MAGIC)) {
+		return errors.New("Bad stream magic")
+	}
+
+	version, err := binary.ReadUvarint(reader.r)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if version != STREAM_VERSION && version != __UNCOMPRESSED_STREAM_VERSION {
+		return fmt.Errorf("Unsupported stream version: %d", version)
+	}
+
+	kind, err := binary.ReadUvarint(reader.r)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if streamKind(kind) != reader.kind {
+		return fmt.Errorf("Unexpected stream kind: %d", kind)
+	}
+
+	if version == __UNCOMPRESSED_STREAM_VERSION {
+		return nil
+	}
+
+	codecId, err := binary.ReadUvarint(reader.r)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if codecId > uint64(CODEC_ZSTD) {
+		return fmt.Errorf("Unknown stream codec: %d", codecId)
+	}
+
+	codec := Codec(codecId)
+
+	if codec == CODEC_NONE {
+		return nil
+	}
+
+	decompressed, err := codec.decompress(reader.r)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	reader.r = bufio.NewReader(decompressed)
+
+	return nil
+}
+
+// readRecord returns io.EOF at the end of the stream.
+func (reader *streamRecordReader) readRecord(message pb.Message) error {
+	const failMsg = "streamRecordReader.readRecord failed"
+
+	err := reader.readHeader()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	size, err := binary.ReadUvarint(reader.r)
+
+	if err == io.EOF {
+		return err
+	}
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if size > __MAX_STREAM_RECORD_SIZE {
+		return fmt.Errorf("Stream record too large: %d bytes", size)
+	}
+
+	if uint64(cap(reader.buff)) < size {
+		reader.buff = make([]byte, size)
+	}
+
+	bs := reader.buff[:size]
+	_, err = io.ReadFull(reader.r, bs)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	message.Reset()
+	err = pb.Unmarshal(bs, message)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return nil
+}
+
+// NamespaceStreamEncoder writes NamespaceStreamEntry records one at a time.
+type NamespaceStreamEncoder struct {
+	writer streamRecordWriter
+}
+
+func MakeNamespaceStreamEncoder(w io.Writer, codec Codec) *NamespaceStreamEncoder {
+	return &NamespaceStreamEncoder{
+		writer: streamRecordWriter{w: w, kind: __NAMESPACE_STREAM_KIND, codec: codec},
+	}
+}
+
+// Entries should be encoded in stream order, so decoders can find rows incrementally.
+func (encoder *NamespaceStreamEncoder) Encode(entry NamespaceStreamEntry) error {
+	return encoder.writer.writeRecord(MakeNamespaceEntryMessage(entry))
+}
+
+// Close flushes the stream, and writes the header if no entries were encoded.
+// It does not close the underlying writer.
+func (encoder *NamespaceStreamEncoder) Close() error {
+	return encoder.writer.close()
+}
+
+func EncodeNamespaceStream(stream []NamespaceStreamEntry, codec Codec, w io.Writer) error {
+	const failMsg = "EncodeNamespaceStream failed"
+
+	encoder := MakeNamespaceStreamEncoder(w, codec)
+
+	for _, entry := range stream {
+		err := encoder.Encode(entry)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+	}
+
+	err := encoder.Close()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return nil
+}
+
+// NamespaceStreamDecoder reads NamespaceStreamEntry records one at a time.  Entries
+// must arrive in stream order.
+type NamespaceStreamDecoder struct {
+	reader  streamRecordReader
+	message proto.NamespaceEntryMessage
+	last    *NamespaceStreamEntry
+	peeked  *NamespaceStreamEntry
+}
+
+func MakeNamespaceStreamDecoder(r io.Reader) *NamespaceStreamDecoder {
+	return &NamespaceStreamDecoder{
+		reader: streamRecordReader{r: bufferReader(r), kind: __NAMESPACE_STREAM_KIND},
+	}
+}
+
+// Next returns io.EOF at the end of the stream.
+func (decoder *NamespaceStreamDecoder) Next() (NamespaceStreamEntry, error) {
+	if decoder.peeked != nil {
+		entry := *decoder.peeked
+		decoder.peeked = nil
+		return entry, nil
+	}
+
+	err := decoder.reader.readRecord(&decoder.message)
+
+	if err != nil {
+		return NamespaceStreamEntry{}, err
+	}
+
+	if decoder.message.Point == nil {
+		return NamespaceStreamEntry{}, errors.New("NamespaceStreamDecoder found entry without point")
+	}
+
+	entry := ReadNamespaceEntryMessage(&decoder.message)
+
+	if decoder.last != nil && entry.Less(*decoder.last) {
+		return NamespaceStreamEntry{}, errors.New("NamespaceStreamDecoder found entry out of order")
+	}
+
+	decoder.last = &entry
+
+	return entry, nil
+}
+
+// NextRow returns all entries for the next row.  It returns io.EOF at the end of the stream.
+func (decoder *NamespaceStreamDecoder) NextRow() ([]NamespaceStreamEntry, error) {
+	first, err := decoder.Next()
+
+	if err != nil {
+		return nil, err
+	}
+
+	row := []NamespaceStreamEntry{first}
+
+	for {
+		entry, err := decoder.Next()
+
+		if err == io.EOF {
+			return row, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.Table != first.Table || entry.Row != first.Row {
+			decoder.peeked = &entry
+			return row, nil
+		}
+
+		row = append(row, entry)
+	}
+}
+
+func DecodeNamespaceStream(r io.Reader) ([]NamespaceStreamEntry, error) {
+	const failMsg = "DecodeNamespaceStream failed"
+
+	decoder := MakeNamespaceStreamDecoder(r)
+	stream := []NamespaceStreamEntry{}
+
+	for {
+		entry, err := decoder.Next()
+
+		if err == io.EOF {
+			return stream, nil
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, failMsg)
+		}
+
+		stream = append(stream, entry)
+	}
+}
+
+// IndexStreamEncoder writes IndexStreamEntry records one at a time.
+type IndexStreamEncoder struct {
+	writer streamRecordWriter
+}
+
+func MakeIndexStreamEncoder(w io.Writer, codec Codec) *IndexStreamEncoder {
+	return &IndexStreamEncoder{
+		writer: streamRecordWriter{w: w, kind: __INDEX_STREAM_KIND, codec: codec},
+	}
+}
+
+func (encoder *IndexStreamEncoder) Encode(entry IndexStreamEntry) error {
+	return encoder.writer.writeRecord(MakeIndexEntryMessage(entry))
+}
+
+// Close flushes the stream, and writes the header if no entries were encoded.
+// It does not close the underlying writer.
+func (encoder *IndexStreamEncoder) Close() error {
+	return encoder.writer.close()
+}
+
+func EncodeIndexStream(stream []IndexStreamEntry, codec Codec, w io.Writer) error {
+	const failMsg = "EncodeIndexStream failed"
+
+	encoder := MakeIndexStreamEncoder(w, codec)
+
+	for _, entry := range stream {
+		err := encoder.Encode(entry)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+	}
+
+	err := encoder.Close()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return nil
+}
+
+// IndexStreamDecoder reads IndexStreamEntry records one at a time.
+type IndexStreamDecoder struct {
+	reader  streamRecordReader
+	message proto.IndexEntryMessage
+}
+
+func MakeIndexStreamDecoder(r io.Reader) *IndexStreamDecoder {
+	return &IndexStreamDecoder{
+		reader: streamRecordReader{r: bufferReader(r), kind: __INDEX_STREAM_KIND},
+	}
+}
+
+// Next returns io.EOF at the end of the stream.
+func (decoder *IndexStreamDecoder) Next() (IndexStreamEntry, error) {
+	err := decoder.reader.readRecord(&decoder.message)
+
+	if err != nil {
+		return IndexStreamEntry{}, err
+	}
+
+	return ReadIndexEntryMessage(&decoder.message), nil
+}
+
+func DecodeIndexStream(r io.Reader) ([]IndexStreamEntry, error) {
+	const failMsg = "DecodeIndexStream failed"
+
+	decoder := MakeIndexStreamDecoder(r)
+	stream := []IndexStreamEntry{}
+
+	for {
+		entry, err := decoder.Next()
+
+		if err == io.EOF {
+			return stream, nil
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, failMsg)
+		}
+
+		stream = append(stream, entry)
+	}
+}
+
+func bufferReader(r io.Reader) *bufio.Reader {
+	if buffered, ok := r.(*bufio.Reader); ok {
+		return buffered
+	}
+
+	return bufio.NewReader(r)
+}
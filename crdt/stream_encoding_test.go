@@ -0,0 +1,175 @@
+package crdt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/quick"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+	"github.com/johnny-morrice/godless/internal/util"
+)
+
+func TestNamespaceStreamEncoding(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+		return
+	}
+
+	config := &quick.Config{
+		MaxCount: testutil.ENCODE_REPEAT_COUNT,
+	}
+
+	err := quick.Check(namespaceStreamEncodingOk, config)
+
+	testutil.AssertVerboseErrorIsNil(t, err)
+}
+
+func namespaceStreamEncodingOk(namespace Namespace) bool {
+	expected, invalid := MakeNamespaceStream(namespace)
+
+	if len(invalid) > 0 {
+		return false
+	}
+
+	buff := &bytes.Buffer{}
+	err := EncodeNamespaceStream(expected, CODEC_NONE, buff)
+
+	if err != nil {
+		panic(err)
+	}
+
+	actual, err := DecodeNamespaceStream(buff)
+
+	if err != nil {
+		panic(err)
+	}
+
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	for i, entry := range expected {
+		if entry != actual[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestNamespaceStreamDecoderNextRow(t *testing.T) {
+	namespace := MakeNamespace(map[TableName]Table{
+		"Cars": MakeTable(map[RowName]Row{
+			"Row A": MakeRow(map[EntryName]Entry{
+				"Entry A": MakeEntry([]Point{UnsignedPoint("Point A")}),
+				"Entry B": MakeEntry([]Point{UnsignedPoint("Point B")}),
+			}),
+			"Row B": MakeRow(map[EntryName]Entry{
+				"Entry C": MakeEntry([]Point{UnsignedPoint("Point C")}),
+			}),
+		}),
+		"Animals": MakeTable(map[RowName]Row{
+			"Row A": MakeRow(map[EntryName]Entry{
+				"Entry D": MakeEntry([]Point{UnsignedPoint("Point D")}),
+			}),
+		}),
+	})
+
+	buff := &bytes.Buffer{}
+	err := encodeNamespace(namespace, buff)
+	testutil.AssertNil(t, err)
+
+	decoder := MakeNamespaceStreamDecoder(buff)
+	rowLengths := []int{1, 2, 1}
+	actual := EmptyNamespace()
+
+	for _, rowLength := range rowLengths {
+		row, err := decoder.NextRow()
+		testutil.AssertNil(t, err)
+		testutil.AssertLenEquals(t, rowLength, row)
+
+		for _, entry := range row {
+			testutil.AssertEquals(t, "Unexpected row", row[0].Row, entry.Row)
+			testutil.AssertEquals(t, "Unexpected table", row[0].Table, entry.Table)
+		}
+
+		actual = actual.JoinNamespace(readNamespaceStream(row))
+	}
+
+	_, err = decoder.NextRow()
+	testutil.AssertEquals(t, "Expected EOF", io.EOF, err)
+
+	testutil.Assert(t, "Unexpected namespace", namespace.Equals(actual))
+}
+
+func TestNamespaceStreamDecoderOutOfOrder(t *testing.T) {
+	stream := []NamespaceStreamEntry{
+		NamespaceStreamEntry{Table: "Table B", Row: "Row", Entry: "Entry", Point: StreamPoint{Text: "Point"}},
+		NamespaceStreamEntry{Table: "Table A", Row: "Row", Entry: "Entry", Point: StreamPoint{Text: "Point"}},
+	}
+
+	buff := &bytes.Buffer{}
+	err := EncodeNamespaceStream(stream, CODEC_NONE, buff)
+	testutil.AssertNil(t, err)
+
+	_, err = DecodeNamespaceStream(buff)
+	testutil.AssertNonNil(t, err)
+}
+
+func TestDecodeNamespaceLegacy(t *testing.T) {
+	const size = 50
+	expected := GenNamespace(testutil.Rand(), size)
+
+	message, invalid := MakeNamespaceMessage(expected)
+	panicInvalidNamespace(invalid)
+
+	buff := &bytes.Buffer{}
+	err := util.Encode(message, buff)
+	testutil.AssertNil(t, err)
+
+	actual, err := decodeNamespace(buff)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected namespace", expected.Equals(actual))
+}
+
+func TestDecodeIndexLegacy(t *testing.T) {
+	const size = 50
+	expected := GenIndex(testutil.Rand(), size)
+
+	message, invalid := MakeIndexMessage(expected)
+	testutil.AssertLenEquals(t, 0, invalid)
+
+	buff := &bytes.Buffer{}
+	err := util.Encode(message, buff)
+	testutil.AssertNil(t, err)
+
+	actual, invalid, err := DecodeIndex(buff)
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 0, invalid)
+	testutil.Assert(t, "Unexpected index", expected.Equals(actual))
+}
+
+func TestDecodeStreamBadVersion(t *testing.T) {
+	buff := &bytes.Buffer{}
+	err := EncodeIndexStream([]IndexStreamEntry{}, CODEC_NONE, buff)
+	testutil.AssertNil(t, err)
+
+	encoded := buff.Bytes()
+	encoded[len(__STREAM_MAGIC)] = STREAM_VERSION + 1
+
+	_, _, err = DecodeIndex(bytes.NewReader(encoded))
+	testutil.AssertNonNil(t, err)
+}
+
+func TestEncodeEmptyStream(t *testing.T) {
+	buff := &bytes.Buffer{}
+	err := encodeNamespace(EmptyNamespace(), buff)
+	testutil.AssertNil(t, err)
+
+	testutil.Assert(t, "Expected header", buff.Len() > 0)
+
+	actual, err := decodeNamespace(buff)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected empty namespace", actual.IsEmpty())
+}
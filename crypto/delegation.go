@@ -0,0 +1,378 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/internal/util"
+)
+
+type DelegationText string
+
+// Delegation is a certificate in which the Issuer trusts the Subject to sign data.  A key that is
+// trusted directly, or through a chain of delegations, may delegate in turn.
+type Delegation struct {
+	Issuer  PublicKey
+	Subject PublicKey
+	// Tables limits the delegation to the named tables.  Empty means all tables.
+	Tables []string
+	// Expires is the time the delegation stops being valid.  Zero means never.
+	Expires   time.Time
+	Signature Signature
+}
+
+func MakeDelegation(issuer PrivateKey, subject PublicKey, tables []string, expires time.Time) (Delegation, error) {
+	const failMsg = "MakeDelegation failed"
+
+	delegation := Delegation{
+		Issuer:  issuer.GetPublicKey(),
+		Subject: subject,
+		Tables:  tables,
+	}
+
+	if !expires.IsZero() {
+		delegation.Expires = expires.UTC()
+	}
+
+	message, err := delegation.signedMessage()
+
+	if err != nil {
+		return Delegation{}, errors.Wrap(err, failMsg)
+	}
+
+	sig, err := Sign(issuer, message)
+
+	if err != nil {
+		return Delegation{}, errors.Wrap(err, failMsg)
+	}
+
+	delegation.Signature = sig
+	return delegation, nil
+}
+
+// Verify checks that the Issuer signed the delegation.  It does not say whether the Issuer is
+// trusted.
+func (delegation Delegation) Verify() bool {
+	message, err := delegation.signedMessage()
+
+	if err != nil {
+		return false
+	}
+
+	ok, err := Verify(delegation.Issuer, message, delegation.Signature)
+
+	return err == nil && ok
+}
+
+func (delegation Delegation) IsExpired(at time.Time) bool {
+	return !delegation.Expires.IsZero() && !at.Before(delegation.Expires)
+}
+
+// Allows is true if the delegation covers the table.  Only delegations for all tables cover the
+// empty table name, which is used for whole indices.
+func (delegation Delegation) Allows(table string) bool {
+	if len(delegation.Tables) == 0 {
+		return true
+	}
+
+	for _, allowed := range delegation.Tables {
+		if table != "" && allowed == table {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (delegation Delegation) Equals(other Delegation) bool {
+	ok := delegation.Issuer.Equals(other.Issuer)
+	ok = ok && delegation.Subject.Equals(other.Subject)
+	ok = ok && delegation.Expires.Equal(other.Expires)
+	ok = ok && strings.Join(delegation.Tables, __DELEGATION_TABLE_SEPERATOR) == strings.Join(other.Tables, __DELEGATION_TABLE_SEPERATOR)
+	return ok && delegation.Signature.Equals(other.Signature)
+}
+
+func (delegation Delegation) signedMessage() ([]byte, error) {
+	issuerText, err := SerializePublicKey(delegation.Issuer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	subjectText, err := SerializePublicKey(delegation.Subject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	message := &bytes.Buffer{}
+	fmt.Fprintf(message, "%s\x00", __DELEGATION_MESSAGE_PREFIX)
+	fmt.Fprintf(message, "%s\x00%s\x00", issuerText, subjectText)
+	fmt.Fprintf(message, "%d\x00", delegation.expiresNanos())
+	fmt.Fprint(message, strings.Join(delegation.Tables, __DELEGATION_TABLE_SEPERATOR))
+
+	return message.Bytes(), nil
+}
+
+func (delegation Delegation) expiresNanos() int64 {
+	if delegation.Expires.IsZero() {
+		return 0
+	}
+
+	return delegation.Expires.UnixNano()
+}
+
+// SerializeDelegation writes a delegation as text that is safe to store in a point.
+func SerializeDelegation(delegation Delegation) (DelegationText, error) {
+	const failMsg = "SerializeDelegation failed"
+
+	issuerText, err := SerializePublicKey(delegation.Issuer)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	subjectText, err := SerializePublicKey(delegation.Subject)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	sigText, err := PrintSignature(delegation.Signature)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	tables := strings.Join(delegation.Tables, __DELEGATION_TABLE_SEPERATOR)
+
+	parts := []string{
+		string(issuerText),
+		string(subjectText),
+		strconv.FormatInt(delegation.expiresNanos(), 10),
+		util.EncodeBase58([]byte(tables)),
+		string(sigText),
+	}
+
+	return DelegationText(strings.Join(parts, __KEY_SEPERATOR)), nil
+}
+
+func ParseDelegation(text DelegationText) (Delegation, error) {
+	const failMsg = "ParseDelegation failed"
+
+	parts := strings.Split(string(text), __KEY_SEPERATOR)
+
+	if len(parts) != __DELEGATION_PART_COUNT {
+		return Delegation{}, fmt.Errorf("%s: expected %d parts but found %d", failMsg, __DELEGATION_PART_COUNT, len(parts))
+	}
+
+	issuer, err := parsePublicKeyStrict(PublicKeyText(parts[0]))
+
+	if err != nil {
+		return Delegation{}, errors.Wrap(err, failMsg)
+	}
+
+	subject, err := parsePublicKeyStrict(PublicKeyText(parts[1]))
+
+	if err != nil {
+		return Delegation{}, errors.Wrap(err, failMsg)
+	}
+
+	nanos, err := strconv.ParseInt(parts[2], 10, 64)
+
+	if err != nil {
+		return Delegation{}, errors.Wrap(err, failMsg)
+	}
+
+	if parts[3] != "" && !util.IsBase58(parts[3]) {
+		return Delegation{}, errors.New("Delegation tables were not base58 encoded")
+	}
+
+	sig, err := ParseSignature(SignatureText(parts[4]))
+
+	if err != nil {
+		return Delegation{}, errors.Wrap(err, failMsg)
+	}
+
+	delegation := Delegation{
+		Issuer:    issuer,
+		Subject:   subject,
+		Signature: sig,
+	}
+
+	if nanos != 0 {
+		delegation.Expires = time.Unix(0, nanos).UTC()
+	}
+
+	tables := string(util.DecodeBase58(parts[3]))
+	if tables != "" {
+		delegation.Tables = strings.Split(tables, __DELEGATION_TABLE_SEPERATOR)
+	}
+
+	return delegation, nil
+}
+
+// DelegatedKeys finds the keys trusted for the table at the given time: the roots, and every key
+// reachable from them through a chain of valid delegations.  Revoked keys are not trusted, and
+// cannot delegate.  Use the empty table name for whole indices.
+func DelegatedKeys(roots []PublicKey, delegations []Delegation, revocations []Revocation, table string, at time.Time) []PublicKey {
+	trusted, _ := delegateKeys(roots, delegations, revocations, table, at)
+	return trusted
+}
+
+// DelegatedKeyGroups is like DelegatedKeys, but groups the trusted keys by the root they were
+// delegated from, so that a root and its delegates can be counted as one signer.  Each group starts
+// with its root.  A key reachable from several roots is in the group of the first.
+func DelegatedKeyGroups(roots []PublicKey, delegations []Delegation, revocations []Revocation, table string, at time.Time) [][]PublicKey {
+	trusted, owners := delegateKeys(roots, delegations, revocations, table, at)
+	groups := [][]PublicKey{}
+
+	for i, pub := range trusted {
+		owner := owners[i]
+
+		if owner == len(groups) {
+			groups = append(groups, []PublicKey{})
+		}
+
+		groups[owner] = append(groups[owner], pub)
+	}
+
+	return groups
+}
+
+// delegateKeys finds the trusted keys, and the index of the root group that each belongs to.
+func delegateKeys(roots []PublicKey, delegations []Delegation, revocations []Revocation, table string, at time.Time) ([]PublicKey, []int) {
+	trusted := []PublicKey{}
+	owners := []int{}
+
+	for _, root := range FilterRevoked(roots, revocations, at) {
+		if containsKey(trusted, root) {
+			continue
+		}
+
+		owners = append(owners, len(trusted))
+		trusted = append(trusted, root)
+	}
+
+	frontier := make([]int, len(trusted))
+	for i := range frontier {
+		frontier[i] = i
+	}
+
+	used := make([]bool, len(delegations))
+
+	for len(frontier) > 0 {
+		next := []int{}
+
+		for i, delegation := range delegations {
+			if used[i] || delegation.IsExpired(at) || !delegation.Allows(table) {
+				continue
+			}
+
+			issuer := findKey(trusted, frontier, delegation.Issuer)
+
+			if issuer < 0 {
+				continue
+			}
+
+			used[i] = true
+
+			if containsKey(trusted, delegation.Subject) || isRevoked(delegation.Subject, revocations, at) {
+				continue
+			}
+
+			if !delegation.Verify() {
+				continue
+			}
+
+			next = append(next, len(trusted))
+			owners = append(owners, owners[issuer])
+			trusted = append(trusted, delegation.Subject)
+		}
+
+		frontier = next
+	}
+
+	return trusted, owners
+}
+
+// findKey finds the index of pub among the keys at the positions given, or -1.
+func findKey(keys []PublicKey, positions []int, pub PublicKey) int {
+	for _, i := range positions {
+		if keys[i].Equals(pub) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// TrustDigest summarises the roots, delegations and revocations that decide which signatures are
+// trusted, so that results verified with them can be remembered.  It is false if the trust may
+// change with time, because a delegation expires or a revocation is not yet effective.
+func TrustDigest(roots []PublicKey, delegations []Delegation, revocations []Revocation, at time.Time) (string, bool) {
+	parts := make([]string, 0, len(roots)+len(delegations)+len(revocations))
+
+	for _, pub := range roots {
+		text, err := SerializePublicKey(pub)
+
+		if err != nil {
+			return "", false
+		}
+
+		parts = append(parts, "k"+string(text))
+	}
+
+	for _, delegation := range delegations {
+		if !delegation.Expires.IsZero() {
+			return "", false
+		}
+
+		text, err := SerializeDelegation(delegation)
+
+		if err != nil {
+			return "", false
+		}
+
+		parts = append(parts, "d"+string(text))
+	}
+
+	for _, revocation := range revocations {
+		if !revocation.IsEffective(at) {
+			return "", false
+		}
+
+		text, err := SerializeRevocation(revocation)
+
+		if err != nil {
+			return "", false
+		}
+
+		parts = append(parts, "r"+string(text))
+	}
+
+	sort.Strings(parts)
+	digest := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return util.EncodeBase58(digest[:]), true
+}
+
+func containsKey(keys []PublicKey, pub PublicKey) bool {
+	for _, other := range keys {
+		if pub.Equals(other) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const __DELEGATION_MESSAGE_PREFIX = "godless-delegation"
+const __DELEGATION_PART_COUNT = 5
+const __DELEGATION_TABLE_SEPERATOR = ","
@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestDelegationSerialize(t *testing.T) {
+	keys := genTestPrivateKeys(2)
+	issuer, subject := keys[0], keys[1]
+
+	for _, tables := range [][]string{nil, {"cars", "drivers"}} {
+		expected, err := MakeDelegation(issuer, subject.GetPublicKey(), tables, time.Now().Add(time.Hour))
+		testutil.AssertNil(t, err)
+		testutil.Assert(t, "Expected verified delegation", expected.Verify())
+
+		text, err := SerializeDelegation(expected)
+		testutil.AssertNil(t, err)
+		actual, err := ParseDelegation(text)
+		testutil.AssertNil(t, err)
+
+		testutil.Assert(t, "Unexpected delegation", expected.Equals(actual))
+		testutil.Assert(t, "Expected verified delegation", actual.Verify())
+
+		tampered := actual
+		tampered.Expires = time.Time{}
+		testutil.Assert(t, "Unexpected verified delegation", !tampered.Verify())
+	}
+}
+
+func TestDelegatedKeys(t *testing.T) {
+	keys := genTestPrivateKeys(5)
+	root, manager, member, contractor, stranger := keys[0], keys[1], keys[2], keys[3], keys[4]
+
+	now := time.Now()
+	delegate := func(issuer PrivateKey, subject PrivateKey, tables []string, expires time.Time) Delegation {
+		delegation, err := MakeDelegation(issuer, subject.GetPublicKey(), tables, expires)
+		testutil.AssertNil(t, err)
+		return delegation
+	}
+
+	delegations := []Delegation{
+		delegate(manager, member, nil, time.Time{}),
+		delegate(root, manager, nil, now.Add(time.Hour)),
+		delegate(manager, contractor, []string{"cars"}, time.Time{}),
+		delegate(stranger, stranger, nil, time.Time{}),
+	}
+
+	roots := []PublicKey{root.GetPublicKey()}
+
+	assertTrusted := func(table string, at time.Time, revocations []Revocation, expected ...PrivateKey) {
+		trusted := DelegatedKeys(roots, delegations, revocations, table, at)
+		testutil.AssertLenEquals(t, len(expected), trusted)
+
+		for _, priv := range expected {
+			testutil.Assert(t, "Expected trusted key", containsKey(trusted, priv.GetPublicKey()))
+		}
+	}
+
+	assertTrusted("", now, nil, root, manager, member)
+	assertTrusted("cars", now, nil, root, manager, member, contractor)
+	assertTrusted("drivers", now, nil, root, manager, member)
+	assertTrusted("cars", now.Add(time.Hour*2), nil, root)
+
+	revocation, err := MakeRevocation(manager.GetPublicKey(), manager, now, "")
+	testutil.AssertNil(t, err)
+	assertTrusted("cars", now, []Revocation{revocation}, root)
+}
+
+func TestDelegatedKeyGroups(t *testing.T) {
+	keys := genTestPrivateKeys(4)
+	rootA, rootB, delegateA, delegateB := keys[0], keys[1], keys[2], keys[3]
+
+	delegate := func(issuer PrivateKey, subject PrivateKey) Delegation {
+		delegation, err := MakeDelegation(issuer, subject.GetPublicKey(), nil, time.Time{})
+		testutil.AssertNil(t, err)
+		return delegation
+	}
+
+	delegations := []Delegation{
+		delegate(delegateA, delegateB),
+		delegate(rootA, delegateA),
+		delegate(rootB, delegateA),
+	}
+
+	roots := []PublicKey{rootA.GetPublicKey(), rootB.GetPublicKey(), rootA.GetPublicKey()}
+	groups := DelegatedKeyGroups(roots, delegations, nil, "", time.Now())
+
+	testutil.AssertLenEquals(t, 2, groups)
+	testutil.AssertLenEquals(t, 3, groups[0])
+	testutil.AssertLenEquals(t, 1, groups[1])
+	testutil.Assert(t, "Expected root first", groups[0][0].Equals(rootA.GetPublicKey()))
+	testutil.Assert(t, "Expected delegate in root group", containsKey(groups[0], delegateB.GetPublicKey()))
+	testutil.Assert(t, "Expected root first", groups[1][0].Equals(rootB.GetPublicKey()))
+}
+
+func TestTrustDigest(t *testing.T) {
+	keys := genTestPrivateKeys(3)
+	roots := []PublicKey{keys[0].GetPublicKey(), keys[1].GetPublicKey()}
+	reversed := []PublicKey{roots[1], roots[0]}
+	now := time.Now()
+
+	digest, ok := TrustDigest(roots, nil, nil, now)
+	testutil.Assert(t, "Expected digest", ok)
+	again, ok := TrustDigest(reversed, nil, nil, now)
+	testutil.Assert(t, "Expected digest", ok)
+	testutil.AssertEquals(t, "Unexpected digest", digest, again)
+
+	other, ok := TrustDigest(roots[:1], nil, nil, now)
+	testutil.Assert(t, "Expected digest", ok)
+	testutil.Assert(t, "Expected different digest", digest != other)
+
+	forever, err := MakeDelegation(keys[0], keys[2].GetPublicKey(), nil, time.Time{})
+	testutil.AssertNil(t, err)
+	delegated, ok := TrustDigest(roots, []Delegation{forever}, nil, now)
+	testutil.Assert(t, "Expected digest", ok)
+	testutil.Assert(t, "Expected different digest", digest != delegated)
+
+	expiring, err := MakeDelegation(keys[0], keys[2].GetPublicKey(), nil, now.Add(time.Hour))
+	testutil.AssertNil(t, err)
+	_, ok = TrustDigest(roots, []Delegation{expiring}, nil, now)
+	testutil.Assert(t, "Unexpected digest for expiring delegation", !ok)
+
+	pending, err := MakeRevocation(roots[1], keys[1], now.Add(time.Hour), "")
+	testutil.AssertNil(t, err)
+	_, ok = TrustDigest(roots, nil, []Revocation{pending}, now)
+	testutil.Assert(t, "Unexpected digest for pending revocation", !ok)
+}
@@ -0,0 +1,188 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"io"
+
+	"filippo.io/edwards25519"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+const SYMMETRIC_KEY_SIZE = 32
+
+// SymmetricKey encrypts point text shared by a set of recipients.
+type SymmetricKey [SYMMETRIC_KEY_SIZE]byte
+
+func GenerateSymmetricKey() (SymmetricKey, error) {
+	key := SymmetricKey{}
+	_, err := io.ReadFull(rand.Reader, key[:])
+	return key, err
+}
+
+// Seal encrypts and authenticates plain.  The random nonce is prepended to the output.
+func (key SymmetricKey) Seal(plain []byte) ([]byte, error) {
+	nonce, err := makeNonce()
+
+	if err != nil {
+		return nil, err
+	}
+
+	secret := [SYMMETRIC_KEY_SIZE]byte(key)
+	return secretbox.Seal(nonce[:], plain, &nonce, &secret), nil
+}
+
+func (key SymmetricKey) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < __NONCE_SIZE {
+		return nil, errors.New("Sealed text too short")
+	}
+
+	nonce := [__NONCE_SIZE]byte{}
+	copy(nonce[:], sealed)
+
+	secret := [SYMMETRIC_KEY_SIZE]byte(key)
+	plain, ok := secretbox.Open(nil, sealed[__NONCE_SIZE:], &nonce, &secret)
+
+	if !ok {
+		return nil, errors.New("Decryption failed")
+	}
+
+	return plain, nil
+}
+
+// WrapKey encrypts the symmetric key so that only the holder of the private key matching pub can
+// read it.  A fresh ephemeral key is used for each wrapping.
+func WrapKey(key SymmetricKey, pub PublicKey) ([]byte, error) {
+	recipient, err := pub.exchangeKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralPub, ephemeralPriv, err := box.GenerateKey(rand.Reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := makeNonce()
+
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := make([]byte, 0, __EXCHANGE_KEY_SIZE+__NONCE_SIZE+SYMMETRIC_KEY_SIZE+box.Overhead)
+	wrapped = append(wrapped, ephemeralPub[:]...)
+	wrapped = append(wrapped, nonce[:]...)
+
+	return box.Seal(wrapped, key[:], &nonce, &recipient, ephemeralPriv), nil
+}
+
+func UnwrapKey(wrapped []byte, priv PrivateKey) (SymmetricKey, error) {
+	const headerSize = __EXCHANGE_KEY_SIZE + __NONCE_SIZE
+
+	if len(wrapped) < headerSize {
+		return SymmetricKey{}, errors.New("Wrapped key too short")
+	}
+
+	exchangePriv, err := priv.exchangeKey()
+
+	if err != nil {
+		return SymmetricKey{}, err
+	}
+
+	ephemeralPub := [__EXCHANGE_KEY_SIZE]byte{}
+	copy(ephemeralPub[:], wrapped)
+	nonce := [__NONCE_SIZE]byte{}
+	copy(nonce[:], wrapped[__EXCHANGE_KEY_SIZE:])
+
+	plain, ok := box.Open(nil, wrapped[headerSize:], &nonce, &ephemeralPub, &exchangePriv)
+
+	if !ok || len(plain) != SYMMETRIC_KEY_SIZE {
+		return SymmetricKey{}, errors.New("Key unwrap failed")
+	}
+
+	key := SymmetricKey{}
+	copy(key[:], plain)
+	return key, nil
+}
+
+type rawKey interface {
+	Raw() ([]byte, error)
+}
+
+// exchangeKey converts an ed25519 public key to its curve25519 equivalent.
+func (pub PublicKey) exchangeKey() ([__EXCHANGE_KEY_SIZE]byte, error) {
+	exchange := [__EXCHANGE_KEY_SIZE]byte{}
+
+	edBytes, err := rawKeyBytes(pub.p2pKey, __ED25519_PUBLIC_KEY_SIZE)
+
+	if err != nil {
+		return exchange, err
+	}
+
+	point, err := new(edwards25519.Point).SetBytes(edBytes)
+
+	if err != nil {
+		return exchange, errors.New("Invalid ed25519 public key")
+	}
+
+	copy(exchange[:], point.BytesMontgomery())
+
+	return exchange, nil
+}
+
+// exchangeKey converts an ed25519 private key to its curve25519 equivalent.
+func (priv PrivateKey) exchangeKey() ([__EXCHANGE_KEY_SIZE]byte, error) {
+	exchange := [__EXCHANGE_KEY_SIZE]byte{}
+
+	edBytes, err := rawKeyBytes(priv.p2pKey, __ED25519_PRIVATE_KEY_SIZE)
+
+	if err != nil {
+		return exchange, err
+	}
+
+	digest := sha512.Sum512(edBytes[:__ED25519_SEED_SIZE])
+	copy(exchange[:], digest[:])
+	exchange[0] &= 248
+	exchange[31] &= 127
+	exchange[31] |= 64
+
+	return exchange, nil
+}
+
+func rawKeyBytes(key interface{}, size int) ([]byte, error) {
+	raw, ok := key.(rawKey)
+
+	if !ok {
+		return nil, errors.New("Encryption requires ed25519 keys")
+	}
+
+	bs, err := raw.Raw()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bs) != size {
+		return nil, errors.New("Encryption requires ed25519 keys")
+	}
+
+	return bs, nil
+}
+
+func makeNonce() ([__NONCE_SIZE]byte, error) {
+	nonce := [__NONCE_SIZE]byte{}
+	_, err := io.ReadFull(rand.Reader, nonce[:])
+	return nonce, err
+}
+
+const (
+	__NONCE_SIZE               = 24
+	__EXCHANGE_KEY_SIZE        = 32
+	__ED25519_SEED_SIZE        = 32
+	__ED25519_PUBLIC_KEY_SIZE  = 32
+	__ED25519_PRIVATE_KEY_SIZE = 64
+)
@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestSymmetricKeySeal(t *testing.T) {
+	key, err := GenerateSymmetricKey()
+	testutil.AssertNil(t, err)
+
+	expected := []byte("Hello world")
+	sealed, err := key.Seal(expected)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected ciphertext", !bytes.Contains(sealed, expected))
+
+	actual, err := key.Open(sealed)
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected plaintext", expected, actual)
+
+	sealed[len(sealed)-1]++
+	_, err = key.Open(sealed)
+	testutil.AssertNonNil(t, err)
+
+	other, err := GenerateSymmetricKey()
+	testutil.AssertNil(t, err)
+	sealed, err = key.Seal(expected)
+	testutil.AssertNil(t, err)
+	_, err = other.Open(sealed)
+	testutil.AssertNonNil(t, err)
+}
+
+func TestWrapKey(t *testing.T) {
+	priv, pub, err := GenerateKey()
+	testutil.AssertNil(t, err)
+
+	otherPriv, _, err := GenerateKey()
+	testutil.AssertNil(t, err)
+
+	expected, err := GenerateSymmetricKey()
+	testutil.AssertNil(t, err)
+
+	wrapped, err := WrapKey(expected, pub)
+	testutil.AssertNil(t, err)
+
+	actual, err := UnwrapKey(wrapped, priv)
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected key", expected, actual)
+
+	_, err = UnwrapKey(wrapped, otherPriv)
+	testutil.AssertNonNil(t, err)
+}
+
+func TestExchangeKey(t *testing.T) {
+	priv, pub, err := GenerateKey()
+	testutil.AssertNil(t, err)
+
+	exchangePriv, err := priv.exchangeKey()
+	testutil.AssertNil(t, err)
+
+	exchangePub, err := pub.exchangeKey()
+	testutil.AssertNil(t, err)
+
+	expected, err := curve25519.X25519(exchangePriv[:], curve25519.Basepoint)
+	testutil.AssertNil(t, err)
+
+	testutil.AssertEquals(t, "Unexpected exchange key", expected, exchangePub[:])
+}
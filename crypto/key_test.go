@@ -3,6 +3,7 @@ package crypto
 import (
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 	"testing/quick"
 
@@ -199,3 +200,37 @@ func generateKeys(size int) []PrivateKey {
 
 	return keys
 }
+
+func TestParseKeysText(t *testing.T) {
+	keys := genTestPrivateKeys(2)
+
+	privText, err := PrivateKeysAsText(keys[:1])
+	testutil.AssertNil(t, err)
+	pubText, err := PublicKeysAsText([]PublicKey{keys[1].GetPublicKey()})
+	testutil.AssertNil(t, err)
+
+	privKeys, pubKeys, err := ParseKeysText(privText + __KEY_SEPERATOR + pubText + "\n")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, privKeys)
+	testutil.AssertLenEquals(t, 1, pubKeys)
+	assertPrivEquals(t, keys[0], privKeys[0])
+	testutil.Assert(t, "Unexpected public key", keys[1].GetPublicKey().Equals(pubKeys[0]))
+
+	_, _, err = ParseKeysText("notakey")
+	testutil.AssertNonNil(t, err)
+}
+
+func TestPublicKeyFingerprint(t *testing.T) {
+	keys := genTestPrivateKeys(2)
+
+	fingerprint, err := keys[0].GetPublicKey().Fingerprint()
+	testutil.AssertNil(t, err)
+	again, err := keys[0].GetPublicKey().Fingerprint()
+	testutil.AssertNil(t, err)
+	other, err := keys[1].GetPublicKey().Fingerprint()
+	testutil.AssertNil(t, err)
+
+	testutil.AssertEquals(t, "Unexpected fingerprint", fingerprint, again)
+	testutil.Assert(t, "Expected different fingerprints", fingerprint != other)
+	testutil.AssertLenEquals(t, 16, strings.Fields(fingerprint))
+}
@@ -3,6 +3,8 @@ package crypto
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"strings"
 
 	crypto "github.com/libp2p/go-libp2p-crypto"
@@ -57,6 +59,10 @@ func PrivateKeysFromText(text string) ([]PrivateKey, error) {
 	keys := make([]PrivateKey, 0, len(parts))
 
 	for _, text := range parts {
+		if text == "" {
+			continue
+		}
+
 		priv, err := ParsePrivateKey(PrivateKeyText(text))
 
 		if err != nil {
@@ -75,6 +81,10 @@ func PublicKeysFromText(text string) ([]PublicKey, error) {
 	keys := make([]PublicKey, 0, len(parts))
 
 	for _, text := range parts {
+		if text == "" {
+			continue
+		}
+
 		pub, err := ParsePublicKey(PublicKeyText(text))
 
 		if err != nil {
@@ -87,6 +97,50 @@ func PublicKeysFromText(text string) ([]PublicKey, error) {
 	return keys, nil
 }
 
+// ParseKeysText reads text holding any mix of public and private keys, as written by
+// PublicKeysAsText and PrivateKeysAsText.  Unlike those functions, it fails on bad keys.
+func ParseKeysText(text string) ([]PrivateKey, []PublicKey, error) {
+	privKeys := []PrivateKey{}
+	pubKeys := []PublicKey{}
+
+	for _, part := range strings.Split(strings.TrimSpace(text), __KEY_SEPERATOR) {
+		if part == "" {
+			continue
+		}
+
+		priv, err := parsePrivateKeyStrict(PrivateKeyText(part))
+
+		if err == nil {
+			privKeys = append(privKeys, priv)
+			continue
+		}
+
+		pub, err := parsePublicKeyStrict(PublicKeyText(part))
+
+		if err != nil {
+			return nil, nil, errors.New("ParseKeysText failed: not a public or private key")
+		}
+
+		pubKeys = append(pubKeys, pub)
+	}
+
+	return privKeys, pubKeys, nil
+}
+
+func parsePrivateKeyStrict(text PrivateKeyText) (PrivateKey, error) {
+	priv, err := ParsePrivateKey(text)
+
+	if err != nil {
+		return PrivateKey{}, err
+	}
+
+	if priv.p2pKey == nil {
+		return PrivateKey{}, errors.New("Invalid private key text")
+	}
+
+	return priv, nil
+}
+
 func (hash PublicKeyHash) Equals(other PublicKeyHash) bool {
 	cmp := bytes.Compare(hash, other)
 	return cmp == 0
@@ -223,7 +277,29 @@ func (pub PublicKey) Hash() (PublicKeyHash, error) {
 	return PublicKeyHash(util.EncodeBase58(bs)), nil
 }
 
+// Fingerprint is a digest of the key that is easy to compare by eye.
+func (pub PublicKey) Fingerprint() (string, error) {
+	const failMsg = "PublicKey.Fingerprint failed"
+
+	bs, err := crypto.MarshalPublicKey(pub.p2pKey)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	digest := sha256.Sum256(bs)
+	hexDigest := strings.ToUpper(hex.EncodeToString(digest[:]))
+
+	groups := make([]string, 0, len(hexDigest)/__FINGERPRINT_GROUP_SIZE)
+	for i := 0; i < len(hexDigest); i += __FINGERPRINT_GROUP_SIZE {
+		groups = append(groups, hexDigest[i:i+__FINGERPRINT_GROUP_SIZE])
+	}
+
+	return strings.Join(groups, " "), nil
+}
+
 var NIL_PUBLIC_KEY_TEXT = PublicKeyText([]byte(""))
 var NIL_PRIVATE_KEY_TEXT = PrivateKeyText([]byte(""))
 
 const __KEY_SEPERATOR = ":"
+const __FINGERPRINT_GROUP_SIZE = 4
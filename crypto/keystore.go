@@ -3,6 +3,7 @@ package crypto
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	crypto "github.com/libp2p/go-libp2p-crypto"
 	mh "github.com/multiformats/go-multihash"
@@ -15,6 +16,10 @@ type KeyStore struct {
 	privKeys     []PrivateKey
 	pubKeys      []PublicKey
 	pubKeyHashes []PublicKeyHash
+	authorities  []PublicKey
+	revocations  []Revocation
+	delegations  []Delegation
+	policies     map[string]TablePolicy
 }
 
 func (keys *KeyStore) PutPrivateKey(priv PrivateKey) error {
@@ -174,6 +179,221 @@ func (keys *KeyStore) GetAllPublicKeys() []PublicKey {
 	return pubKeys
 }
 
+// DeleteKey forgets the public key and its private key, if the KeyStore holds it.
+func (keys *KeyStore) DeleteKey(hash PublicKeyHash) error {
+	const failMsg = "KeyStore.DeleteKey failed"
+
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	pub, err := keys.lookupPublicKey(hash)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	pubKeys := make([]PublicKey, 0, len(keys.pubKeys))
+	pubKeyHashes := make([]PublicKeyHash, 0, len(keys.pubKeyHashes))
+
+	for i, other := range keys.pubKeys {
+		if other.Equals(pub) {
+			continue
+		}
+
+		pubKeys = append(pubKeys, other)
+		pubKeyHashes = append(pubKeyHashes, keys.pubKeyHashes[i])
+	}
+
+	privKeys := make([]PrivateKey, 0, len(keys.privKeys))
+
+	for _, priv := range keys.privKeys {
+		if priv.GetPublicKey().Equals(pub) {
+			continue
+		}
+
+		privKeys = append(privKeys, priv)
+	}
+
+	keys.pubKeys = pubKeys
+	keys.pubKeyHashes = pubKeyHashes
+	keys.privKeys = privKeys
+	return nil
+}
+
+// PutAuthorityKey trusts a key to revoke other keys.
+func (keys *KeyStore) PutAuthorityKey(pub PublicKey) error {
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	for _, other := range keys.authorities {
+		if pub.Equals(other) {
+			return errors.New("duplicate authority key")
+		}
+	}
+
+	keys.authorities = append(keys.authorities, pub)
+	return nil
+}
+
+func (keys *KeyStore) GetAllAuthorityKeys() []PublicKey {
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	authorities := make([]PublicKey, len(keys.authorities))
+	copy(authorities, keys.authorities)
+	return authorities
+}
+
+// PutRevocation accepts a revocation that was signed by the revoked key or by an authority key.
+// Duplicate revocations are ignored.
+func (keys *KeyStore) PutRevocation(revocation Revocation) error {
+	const failMsg = "KeyStore.PutRevocation failed"
+
+	if !revocation.Verify() {
+		return errors.New(failMsg + ": bad signature")
+	}
+
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	if !revocation.IsSelfSigned() && !keys.isAuthority(revocation.Authority) {
+		return errors.New(failMsg + ": not signed by an authority key")
+	}
+
+	for _, other := range keys.revocations {
+		if revocation.Equals(other) {
+			return nil
+		}
+	}
+
+	keys.revocations = append(keys.revocations, revocation)
+	return nil
+}
+
+func (keys *KeyStore) GetAllRevocations() []Revocation {
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	revocations := make([]Revocation, len(keys.revocations))
+	copy(revocations, keys.revocations)
+	return revocations
+}
+
+func (keys *KeyStore) IsRevoked(pub PublicKey) bool {
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	return isRevoked(pub, keys.revocations, time.Now())
+}
+
+// GetTrustedPublicKeys lists the public keys that have not been revoked.
+func (keys *KeyStore) GetTrustedPublicKeys() []PublicKey {
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	return FilterRevoked(keys.pubKeys, keys.revocations, time.Now())
+}
+
+// PutDelegation accepts a delegation signed by its Issuer.  Whether the Issuer is trusted is
+// decided when the delegation is used.  Duplicate delegations are ignored.
+func (keys *KeyStore) PutDelegation(delegation Delegation) error {
+	if !delegation.Verify() {
+		return errors.New("KeyStore.PutDelegation failed: bad signature")
+	}
+
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	for _, other := range keys.delegations {
+		if delegation.Equals(other) {
+			return nil
+		}
+	}
+
+	keys.delegations = append(keys.delegations, delegation)
+	return nil
+}
+
+func (keys *KeyStore) GetAllDelegations() []Delegation {
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	delegations := make([]Delegation, len(keys.delegations))
+	copy(delegations, keys.delegations)
+	return delegations
+}
+
+// GetDelegatedPublicKeys lists the trusted public keys, and the keys they delegate to for the table.
+// Use the empty table name for whole indices.
+func (keys *KeyStore) GetDelegatedPublicKeys(table string) []PublicKey {
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	return DelegatedKeys(keys.pubKeys, keys.delegations, keys.revocations, table, time.Now())
+}
+
+// PutTablePolicy limits the keys that may write the table.  It replaces any previous policy for
+// the table.
+func (keys *KeyStore) PutTablePolicy(table string, policy TablePolicy) error {
+	if table == "" {
+		return errors.New("KeyStore.PutTablePolicy failed: empty table name")
+	}
+
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	keys.policies[table] = policy
+	return nil
+}
+
+// GetAllTablePolicies finds the policy for each table that has one.
+func (keys *KeyStore) GetAllTablePolicies() map[string]TablePolicy {
+	keys.Lock()
+	defer keys.Unlock()
+
+	keys.init()
+
+	policies := make(map[string]TablePolicy, len(keys.policies))
+
+	for table, policy := range keys.policies {
+		policies[table] = policy
+	}
+
+	return policies
+}
+
+func (keys *KeyStore) isAuthority(pub PublicKey) bool {
+	for _, authority := range keys.authorities {
+		if pub.Equals(authority) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (keys *KeyStore) init() {
 	if keys.privKeys == nil {
 		keys.privKeys = []PrivateKey{}
@@ -186,6 +406,22 @@ func (keys *KeyStore) init() {
 	if keys.pubKeyHashes == nil {
 		keys.pubKeyHashes = []PublicKeyHash{}
 	}
+
+	if keys.authorities == nil {
+		keys.authorities = []PublicKey{}
+	}
+
+	if keys.revocations == nil {
+		keys.revocations = []Revocation{}
+	}
+
+	if keys.delegations == nil {
+		keys.delegations = []Delegation{}
+	}
+
+	if keys.policies == nil {
+		keys.policies = map[string]TablePolicy{}
+	}
 }
 
 // keyHash hashes a key.
@@ -144,6 +144,25 @@ func genTestPublicKeys(count int) []PublicKey {
 	return pubKeys
 }
 
+func TestKeyStoreDeleteKey(t *testing.T) {
+	keyStore := &KeyStore{}
+	keys := genTestPrivateKeys(2)
+
+	testutil.AssertNil(t, keyStore.PutPrivateKey(keys[0]))
+	testutil.AssertNil(t, keyStore.PutPublicKey(keys[1].GetPublicKey()))
+
+	hash, err := keys[0].GetPublicKey().Hash()
+	testutil.AssertNil(t, err)
+
+	testutil.AssertNil(t, keyStore.DeleteKey(hash))
+	testutil.AssertNonNil(t, keyStore.DeleteKey(hash))
+
+	_, err = keyStore.GetPublicKey(hash)
+	testutil.AssertNonNil(t, err)
+	testutil.AssertLenEquals(t, 0, keyStore.GetAllPrivateKeys())
+	testutil.AssertLenEquals(t, 1, keyStore.GetAllPublicKeys())
+}
+
 func genTestPrivateKeys(count int) []PrivateKey {
 	privKeys := make([]PrivateKey, count)
 
@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/johnny-morrice/godless/internal/util"
+)
+
+// EncryptPrivateKeys seals the private keys with a key derived from the passphrase by scrypt.  The
+// salt and nonce are kept in the output, so only the passphrase is needed to decrypt.
+func EncryptPrivateKeys(keys []PrivateKey, passphrase []byte) (string, error) {
+	const failMsg = "EncryptPrivateKeys failed"
+
+	if len(passphrase) == 0 {
+		return "", errors.New(failMsg + ": empty passphrase")
+	}
+
+	plain, err := PrivateKeysAsText(keys)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	salt := make([]byte, __PASSPHRASE_SALT_SIZE)
+	_, err = io.ReadFull(rand.Reader, salt)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	key, err := derivePassphraseKey(passphrase, salt)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	sealed, err := key.Seal([]byte(plain))
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	parts := []string{
+		__PASSPHRASE_TEXT_PREFIX,
+		util.EncodeBase58(salt),
+		util.EncodeBase58(sealed),
+	}
+
+	return strings.Join(parts, __PASSPHRASE_TEXT_SEPERATOR), nil
+}
+
+// DecryptPrivateKeys opens text made by EncryptPrivateKeys.
+func DecryptPrivateKeys(text string, passphrase []byte) ([]PrivateKey, error) {
+	const failMsg = "DecryptPrivateKeys failed"
+
+	parts := strings.Split(text, __PASSPHRASE_TEXT_SEPERATOR)
+
+	if len(parts) != 3 || parts[0] != __PASSPHRASE_TEXT_PREFIX {
+		return nil, errors.New(failMsg + ": not encrypted private keys")
+	}
+
+	if !util.IsBase58(parts[1]) || !util.IsBase58(parts[2]) {
+		return nil, errors.New(failMsg + ": encrypted private keys were not base58 encoded")
+	}
+
+	key, err := derivePassphraseKey(passphrase, util.DecodeBase58(parts[1]))
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	plain, err := key.Open(util.DecodeBase58(parts[2]))
+
+	if err != nil {
+		return nil, errors.New(failMsg + ": wrong passphrase")
+	}
+
+	keys, err := PrivateKeysFromText(string(plain))
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	return keys, nil
+}
+
+// IsEncryptedPrivateKeys is true if the text was made by EncryptPrivateKeys.  Otherwise it is
+// expected to be plain text from PrivateKeysAsText.
+func IsEncryptedPrivateKeys(text string) bool {
+	return strings.HasPrefix(text, __PASSPHRASE_TEXT_PREFIX+__PASSPHRASE_TEXT_SEPERATOR)
+}
+
+func derivePassphraseKey(passphrase, salt []byte) (SymmetricKey, error) {
+	derived, err := scrypt.Key(passphrase, salt, __SCRYPT_N, __SCRYPT_R, __SCRYPT_P, SYMMETRIC_KEY_SIZE)
+
+	if err != nil {
+		return SymmetricKey{}, err
+	}
+
+	key := SymmetricKey{}
+	copy(key[:], derived)
+	return key, nil
+}
+
+const (
+	__PASSPHRASE_TEXT_PREFIX    = "scrypt1"
+	__PASSPHRASE_TEXT_SEPERATOR = "$"
+	__PASSPHRASE_SALT_SIZE      = 16
+	__SCRYPT_N                  = 1 << 15
+	__SCRYPT_R                  = 8
+	__SCRYPT_P                  = 1
+)
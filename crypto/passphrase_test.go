@@ -0,0 +1,35 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestEncryptPrivateKeys(t *testing.T) {
+	keys := genTestPrivateKeys(2)
+	passphrase := []byte("correct horse battery staple")
+
+	text, err := EncryptPrivateKeys(keys, passphrase)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected encrypted keys", IsEncryptedPrivateKeys(text))
+
+	plain, err := PrivateKeysAsText(keys)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected encrypted keys", !IsEncryptedPrivateKeys(plain))
+
+	decrypted, err := DecryptPrivateKeys(text, passphrase)
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, len(keys), decrypted)
+
+	for i, priv := range keys {
+		assertPrivEquals(t, priv, decrypted[i])
+		testutil.Assert(t, "Unexpected private key", priv.SamePublicKey(decrypted[i]))
+	}
+
+	_, err = DecryptPrivateKeys(text, []byte("wrong"))
+	testutil.AssertNonNil(t, err)
+
+	_, err = EncryptPrivateKeys(keys, []byte{})
+	testutil.AssertNonNil(t, err)
+}
@@ -0,0 +1,47 @@
+package crypto
+
+// TablePolicy limits the keys that may write a table.
+type TablePolicy struct {
+	// Signers are the hashes of the keys that may sign links to the table.
+	Signers []PublicKeyHash
+	// MinSignatures is optional.  Links need at least one signature by the Signers.
+	MinSignatures int
+}
+
+// Threshold is the number of distinct Signers that must sign a link.
+func (policy TablePolicy) Threshold() int {
+	if policy.MinSignatures < 1 {
+		return 1
+	}
+
+	return policy.MinSignatures
+}
+
+// FilterSigners keeps the keys that are named in the policy.
+func (policy TablePolicy) FilterSigners(keys []PublicKey) []PublicKey {
+	signers := make([]PublicKey, 0, len(keys))
+
+	for _, pub := range keys {
+		hash, err := pub.Hash()
+
+		if err != nil {
+			continue
+		}
+
+		if policy.isSigner(hash) {
+			signers = append(signers, pub)
+		}
+	}
+
+	return signers
+}
+
+func (policy TablePolicy) isSigner(hash PublicKeyHash) bool {
+	for _, signer := range policy.Signers {
+		if signer.Equals(hash) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestTablePolicyFilterSigners(t *testing.T) {
+	keys := genTestPrivateKeys(3)
+	pubs := []PublicKey{keys[0].GetPublicKey(), keys[1].GetPublicKey(), keys[2].GetPublicKey()}
+
+	hash, err := pubs[1].Hash()
+	testutil.AssertNil(t, err)
+
+	policy := TablePolicy{Signers: []PublicKeyHash{hash}}
+	signers := policy.FilterSigners(pubs)
+
+	testutil.AssertLenEquals(t, 1, signers)
+	testutil.Assert(t, "Unexpected signer", signers[0].Equals(pubs[1]))
+	testutil.AssertEquals(t, "Unexpected threshold", 1, policy.Threshold())
+
+	policy.MinSignatures = 2
+	testutil.AssertEquals(t, "Unexpected threshold", 2, policy.Threshold())
+}
+
+func TestKeyStoreTablePolicy(t *testing.T) {
+	keyStore := &KeyStore{}
+
+	testutil.AssertNonNil(t, keyStore.PutTablePolicy("", TablePolicy{}))
+	testutil.AssertNil(t, keyStore.PutTablePolicy("prices", TablePolicy{MinSignatures: 1}))
+	testutil.AssertNil(t, keyStore.PutTablePolicy("prices", TablePolicy{MinSignatures: 2}))
+
+	policies := keyStore.GetAllTablePolicies()
+	testutil.AssertLenEquals(t, 1, policies)
+	testutil.AssertEquals(t, "Unexpected policy", 2, policies["prices"].MinSignatures)
+}
@@ -0,0 +1,229 @@
+package crypto
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/internal/util"
+)
+
+type RevocationText string
+
+// Revocation withdraws trust from a leaked key.  It is signed either by the revoked key itself, or
+// by an authority key trusted by the KeyStore.  Once the revocation is effective, no signature by
+// the revoked key is trusted, including those made before the Effective time.  Signatures carry no
+// time, so whoever holds a leaked key could backdate anything.
+type Revocation struct {
+	Revoked   PublicKey
+	Authority PublicKey
+	Effective time.Time
+	Reason    string
+	Signature Signature
+}
+
+func MakeRevocation(revoked PublicKey, authority PrivateKey, effective time.Time, reason string) (Revocation, error) {
+	const failMsg = "MakeRevocation failed"
+
+	revocation := Revocation{
+		Revoked:   revoked,
+		Authority: authority.GetPublicKey(),
+		Effective: effective.UTC(),
+		Reason:    reason,
+	}
+
+	message, err := revocation.signedMessage()
+
+	if err != nil {
+		return Revocation{}, errors.Wrap(err, failMsg)
+	}
+
+	sig, err := Sign(authority, message)
+
+	if err != nil {
+		return Revocation{}, errors.Wrap(err, failMsg)
+	}
+
+	revocation.Signature = sig
+	return revocation, nil
+}
+
+// IsSelfSigned is true if the revoked key signed its own revocation.
+func (revocation Revocation) IsSelfSigned() bool {
+	return revocation.Revoked.Equals(revocation.Authority)
+}
+
+// IsEffective is true if the revocation applies at the given time.
+func (revocation Revocation) IsEffective(at time.Time) bool {
+	return !at.Before(revocation.Effective)
+}
+
+// Verify checks that the Authority signed the revocation.  It does not say whether the Authority
+// is trusted.
+func (revocation Revocation) Verify() bool {
+	message, err := revocation.signedMessage()
+
+	if err != nil {
+		return false
+	}
+
+	ok, err := Verify(revocation.Authority, message, revocation.Signature)
+
+	return err == nil && ok
+}
+
+func (revocation Revocation) Equals(other Revocation) bool {
+	ok := revocation.Revoked.Equals(other.Revoked)
+	ok = ok && revocation.Authority.Equals(other.Authority)
+	ok = ok && revocation.Effective.Equal(other.Effective)
+	ok = ok && revocation.Reason == other.Reason
+	return ok && revocation.Signature.Equals(other.Signature)
+}
+
+func (revocation Revocation) signedMessage() ([]byte, error) {
+	revokedText, err := SerializePublicKey(revocation.Revoked)
+
+	if err != nil {
+		return nil, err
+	}
+
+	authorityText, err := SerializePublicKey(revocation.Authority)
+
+	if err != nil {
+		return nil, err
+	}
+
+	message := &bytes.Buffer{}
+	fmt.Fprintf(message, "%s\x00", __REVOCATION_MESSAGE_PREFIX)
+	fmt.Fprintf(message, "%s\x00%s\x00", revokedText, authorityText)
+	fmt.Fprintf(message, "%d\x00%s", revocation.Effective.UnixNano(), revocation.Reason)
+
+	return message.Bytes(), nil
+}
+
+// SerializeRevocation writes a revocation as text that is safe to store in a point.
+func SerializeRevocation(revocation Revocation) (RevocationText, error) {
+	const failMsg = "SerializeRevocation failed"
+
+	revokedText, err := SerializePublicKey(revocation.Revoked)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	authorityText, err := SerializePublicKey(revocation.Authority)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	sigText, err := PrintSignature(revocation.Signature)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	parts := []string{
+		string(revokedText),
+		string(authorityText),
+		strconv.FormatInt(revocation.Effective.UnixNano(), 10),
+		util.EncodeBase58([]byte(revocation.Reason)),
+		string(sigText),
+	}
+
+	return RevocationText(strings.Join(parts, __KEY_SEPERATOR)), nil
+}
+
+func ParseRevocation(text RevocationText) (Revocation, error) {
+	const failMsg = "ParseRevocation failed"
+
+	parts := strings.Split(string(text), __KEY_SEPERATOR)
+
+	if len(parts) != __REVOCATION_PART_COUNT {
+		return Revocation{}, fmt.Errorf("%s: expected %d parts but found %d", failMsg, __REVOCATION_PART_COUNT, len(parts))
+	}
+
+	revoked, err := parsePublicKeyStrict(PublicKeyText(parts[0]))
+
+	if err != nil {
+		return Revocation{}, errors.Wrap(err, failMsg)
+	}
+
+	authority, err := parsePublicKeyStrict(PublicKeyText(parts[1]))
+
+	if err != nil {
+		return Revocation{}, errors.Wrap(err, failMsg)
+	}
+
+	nanos, err := strconv.ParseInt(parts[2], 10, 64)
+
+	if err != nil {
+		return Revocation{}, errors.Wrap(err, failMsg)
+	}
+
+	if parts[3] != "" && !util.IsBase58(parts[3]) {
+		return Revocation{}, errors.New("Revocation reason was not base58 encoded")
+	}
+
+	sig, err := ParseSignature(SignatureText(parts[4]))
+
+	if err != nil {
+		return Revocation{}, errors.Wrap(err, failMsg)
+	}
+
+	revocation := Revocation{
+		Revoked:   revoked,
+		Authority: authority,
+		Effective: time.Unix(0, nanos).UTC(),
+		Reason:    string(util.DecodeBase58(parts[3])),
+		Signature: sig,
+	}
+
+	return revocation, nil
+}
+
+// parsePublicKeyStrict fails on bad text, unlike ParsePublicKey.
+func parsePublicKeyStrict(text PublicKeyText) (PublicKey, error) {
+	pub, err := ParsePublicKey(text)
+
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	if pub.p2pKey == nil {
+		return PublicKey{}, errors.New("Invalid public key text")
+	}
+
+	return pub, nil
+}
+
+// FilterRevoked removes the keys that are revoked at the given time.  Revocation is retroactive:
+// every signature by a removed key is distrusted, however old.
+func FilterRevoked(keys []PublicKey, revocations []Revocation, at time.Time) []PublicKey {
+	trusted := make([]PublicKey, 0, len(keys))
+
+	for _, pub := range keys {
+		if !isRevoked(pub, revocations, at) {
+			trusted = append(trusted, pub)
+		}
+	}
+
+	return trusted
+}
+
+func isRevoked(pub PublicKey, revocations []Revocation, at time.Time) bool {
+	for _, revocation := range revocations {
+		if revocation.IsEffective(at) && revocation.Revoked.Equals(pub) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const __REVOCATION_MESSAGE_PREFIX = "godless-revocation"
+const __REVOCATION_PART_COUNT = 5
@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestRevocationSerialize(t *testing.T) {
+	priv, pub, err := GenerateKey()
+	testutil.AssertNil(t, err)
+
+	expected, err := MakeRevocation(pub, priv, time.Now(), "Leaked on a bus")
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected verified revocation", expected.Verify())
+	testutil.Assert(t, "Expected self signed revocation", expected.IsSelfSigned())
+
+	text, err := SerializeRevocation(expected)
+	testutil.AssertNil(t, err)
+	actual, err := ParseRevocation(text)
+	testutil.AssertNil(t, err)
+
+	testutil.Assert(t, "Unexpected revocation", expected.Equals(actual))
+	testutil.Assert(t, "Expected verified revocation", actual.Verify())
+
+	tampered := actual
+	tampered.Reason = "Changed my mind"
+	testutil.Assert(t, "Unexpected verified revocation", !tampered.Verify())
+
+	_, err = ParseRevocation(RevocationText("not a revocation"))
+	testutil.AssertNonNil(t, err)
+}
+
+func TestKeyStoreRevocation(t *testing.T) {
+	keyStore := &KeyStore{}
+
+	keys := genTestPrivateKeys(3)
+	for _, priv := range keys {
+		testutil.AssertNil(t, keyStore.PutPrivateKey(priv))
+	}
+
+	leaked, authority, other := keys[0], keys[1], keys[2]
+
+	now := time.Now()
+	byOther, err := MakeRevocation(leaked.GetPublicKey(), other, now, "")
+	testutil.AssertNil(t, err)
+	testutil.AssertNonNil(t, keyStore.PutRevocation(byOther))
+
+	testutil.AssertNil(t, keyStore.PutAuthorityKey(authority.GetPublicKey()))
+	byAuthority, err := MakeRevocation(leaked.GetPublicKey(), authority, now, "")
+	testutil.AssertNil(t, err)
+	testutil.AssertNil(t, keyStore.PutRevocation(byAuthority))
+	testutil.AssertNil(t, keyStore.PutRevocation(byAuthority))
+	testutil.AssertLenEquals(t, 1, keyStore.GetAllRevocations())
+
+	future, err := MakeRevocation(other.GetPublicKey(), other, now.Add(time.Hour), "")
+	testutil.AssertNil(t, err)
+	testutil.AssertNil(t, keyStore.PutRevocation(future))
+
+	testutil.Assert(t, "Expected revoked key", keyStore.IsRevoked(leaked.GetPublicKey()))
+	testutil.Assert(t, "Unexpected revoked key", !keyStore.IsRevoked(other.GetPublicKey()))
+
+	trusted := keyStore.GetTrustedPublicKeys()
+	testutil.AssertLenEquals(t, 2, trusted)
+
+	for _, pub := range trusted {
+		testutil.Assert(t, "Unexpected trusted key", !pub.Equals(leaked.GetPublicKey()))
+	}
+}
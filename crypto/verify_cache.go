@@ -0,0 +1,166 @@
+package crypto
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// VerifyCache remembers the results of Verify.  Signed data is immutable, so the same signatures
+// are checked over and over as namespaces are loaded for each query.  The least recently used
+// results are evicted to keep within MaxItems.  A nil VerifyCache calls Verify directly.
+type VerifyCache struct {
+	sync.Mutex
+	maxItems  int
+	assoc     map[verifyKey]*list.Element
+	order     *list.List
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// VerifyCacheStats counts the work done by a VerifyCache.
+type VerifyCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Items     int
+	MaxItems  int
+}
+
+type verifyKey struct {
+	pub  string
+	text [sha256.Size]byte
+	sig  string
+}
+
+type verifyItem struct {
+	key verifyKey
+	ok  bool
+}
+
+func MakeVerifyCache(maxItems int) *VerifyCache {
+	if maxItems < 1 {
+		maxItems = DEFAULT_VERIFY_CACHE_SIZE
+	}
+
+	return &VerifyCache{
+		maxItems: maxItems,
+		assoc:    map[verifyKey]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// Verify is like the Verify function, but looks for the result in the cache first.  Errors are not
+// cached.
+func (cache *VerifyCache) Verify(pub PublicKey, message []byte, sig Signature) (bool, error) {
+	if cache == nil {
+		return Verify(pub, message, sig)
+	}
+
+	if pub.p2pKey == nil || sig.sig == nil {
+		return Verify(pub, message, sig)
+	}
+
+	hash, err := pub.Hash()
+
+	if err != nil {
+		return false, err
+	}
+
+	key := verifyKey{
+		pub:  string(hash),
+		text: sha256.Sum256(message),
+		sig:  string(sig.sig),
+	}
+
+	if ok, present := cache.get(key); present {
+		return ok, nil
+	}
+
+	ok, err := Verify(pub, message, sig)
+
+	if err != nil {
+		return false, err
+	}
+
+	cache.put(key, ok)
+	return ok, nil
+}
+
+func (cache *VerifyCache) get(key verifyKey) (bool, bool) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	element, present := cache.assoc[key]
+
+	if !present {
+		cache.misses++
+		return false, false
+	}
+
+	cache.hits++
+	cache.order.MoveToFront(element)
+	return element.Value.(*verifyItem).ok, true
+}
+
+func (cache *VerifyCache) put(key verifyKey, ok bool) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	if _, present := cache.assoc[key]; present {
+		return
+	}
+
+	cache.assoc[key] = cache.order.PushFront(&verifyItem{key: key, ok: ok})
+
+	for cache.order.Len() > cache.maxItems {
+		oldest := cache.order.Back()
+		item := cache.order.Remove(oldest).(*verifyItem)
+		delete(cache.assoc, item.key)
+		cache.evictions++
+	}
+}
+
+func (cache *VerifyCache) GetStats() VerifyCacheStats {
+	if cache == nil {
+		return VerifyCacheStats{}
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+
+	return VerifyCacheStats{
+		Hits:      cache.hits,
+		Misses:    cache.misses,
+		Evictions: cache.evictions,
+		Items:     cache.order.Len(),
+		MaxItems:  cache.maxItems,
+	}
+}
+
+var sharedVerifyCache = MakeVerifyCache(DEFAULT_VERIFY_CACHE_SIZE)
+var sharedVerifyCacheLock sync.RWMutex
+
+// SetVerifyCache replaces the VerifyCache used by CachedVerify.  Nil disables the cache.
+func SetVerifyCache(cache *VerifyCache) {
+	sharedVerifyCacheLock.Lock()
+	defer sharedVerifyCacheLock.Unlock()
+
+	sharedVerifyCache = cache
+}
+
+// GetVerifyCache finds the VerifyCache used by CachedVerify.  It is nil if the cache is disabled.
+func GetVerifyCache() *VerifyCache {
+	sharedVerifyCacheLock.RLock()
+	defer sharedVerifyCacheLock.RUnlock()
+
+	return sharedVerifyCache
+}
+
+// CachedVerify is Verify through the VerifyCache shared by the whole process.
+func CachedVerify(pub PublicKey, message []byte, sig Signature) (bool, error) {
+	return GetVerifyCache().Verify(pub, message, sig)
+}
+
+const DEFAULT_VERIFY_CACHE_SIZE = 1 << 15
@@ -0,0 +1,57 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestVerifyCache(t *testing.T) {
+	keys := genTestPrivateKeys(2)
+	signer, stranger := keys[0], keys[1]
+	cache := MakeVerifyCache(2)
+
+	message := []byte("Hello")
+	sig, err := Sign(signer, message)
+	testutil.AssertNil(t, err)
+
+	for i := 0; i < 2; i++ {
+		ok, err := cache.Verify(signer.GetPublicKey(), message, sig)
+		testutil.AssertNil(t, err)
+		testutil.Assert(t, "Expected verified", ok)
+
+		ok, err = cache.Verify(stranger.GetPublicKey(), message, sig)
+		testutil.AssertNil(t, err)
+		testutil.Assert(t, "Unexpected verified", !ok)
+	}
+
+	stats := cache.GetStats()
+	testutil.AssertEquals(t, "Unexpected hits", uint64(2), stats.Hits)
+	testutil.AssertEquals(t, "Unexpected misses", uint64(2), stats.Misses)
+	testutil.AssertEquals(t, "Unexpected items", 2, stats.Items)
+
+	ok, err := cache.Verify(signer.GetPublicKey(), []byte("Goodbye"), sig)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected verified", !ok)
+
+	stats = cache.GetStats()
+	testutil.AssertEquals(t, "Unexpected evictions", uint64(1), stats.Evictions)
+	testutil.AssertEquals(t, "Unexpected items", 2, stats.Items)
+
+	_, err = cache.Verify(PublicKey{}, message, sig)
+	testutil.AssertNonNil(t, err)
+}
+
+func TestVerifyCacheNil(t *testing.T) {
+	keys := genTestPrivateKeys(1)
+
+	message := []byte("Hello")
+	sig, err := Sign(keys[0], message)
+	testutil.AssertNil(t, err)
+
+	var cache *VerifyCache
+	ok, err := cache.Verify(keys[0].GetPublicKey(), message, sig)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected verified", ok)
+	testutil.AssertEquals(t, "Unexpected stats", VerifyCacheStats{}, cache.GetStats())
+}
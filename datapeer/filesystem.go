@@ -0,0 +1,253 @@
+package datapeer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/internal/util"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// MakeFilesystemDataPeer stores data in a local directory, so it survives restarts without IPFS.
+// PubSub is only shared within the process.
+func MakeFilesystemDataPeer(options FilesystemStorageOptions) api.PinningDataPeer {
+	storage := makeFilesystemStorage(options)
+	pubsubber := MakeResidentMemoryPubSubBus()
+
+	return Union{
+		Storage:    storage,
+		Publisher:  pubsubber,
+		Subscriber: pubsubber,
+		Connecter:  storage,
+		Pinger:     storage,
+		Pinner:     storage,
+		Hasher:     storage,
+	}
+}
+
+type FilesystemStorageOptions struct {
+	Dir string
+}
+
+// filesystemStorage keeps each block in a file named by its sha256 multihash, so paths look like
+// those of IPFS.  Pins are empty files named by the pinned hash.
+type filesystemStorage struct {
+	FilesystemStorageOptions
+}
+
+func MakeFilesystemStorage(options FilesystemStorageOptions) api.ContentAddressableStorage {
+	return makeFilesystemStorage(options)
+}
+
+func makeFilesystemStorage(options FilesystemStorageOptions) *filesystemStorage {
+	return &filesystemStorage{FilesystemStorageOptions: options}
+}
+
+func (storage *filesystemStorage) Connect() error {
+	const failMsg = "filesystemStorage.Connect failed"
+
+	log.Info("Using filesystem storage at: %s", storage.Dir)
+
+	for _, dir := range []string{storage.blockDir(), storage.pinDir()} {
+		err := os.MkdirAll(dir, __FILESYSTEM_DIR_MODE)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+	}
+
+	return nil
+}
+
+func (storage *filesystemStorage) IsUp() bool {
+	info, err := os.Stat(storage.blockDir())
+	return err == nil && info.IsDir()
+}
+
+func (storage *filesystemStorage) Cat(hash string) (io.ReadCloser, error) {
+	log.Info("Catting '%s' from filesystemStorage", hash)
+
+	if !isValidFilesystemHash(hash) {
+		return nil, fmt.Errorf("Invalid hash: '%s'", hash)
+	}
+
+	file, err := os.Open(storage.blockPath(hash))
+
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("Data not found for '%s'", hash)
+	}
+
+	return file, err
+}
+
+func (storage *filesystemStorage) Add(r io.Reader) (string, error) {
+	const failMsg = "filesystemStorage.Add failed"
+
+	log.Info("Adding to filesystemStorage...")
+
+	data, err := ioutil.ReadAll(r)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	address := filesystemHash(data)
+	path := storage.blockPath(address)
+
+	if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+		err = writeFileAtomic(path, data)
+
+		if err != nil {
+			return "", errors.Wrap(err, failMsg)
+		}
+	}
+
+	// Like IPFS, we pin everything we add.
+	err = storage.touchPin(address)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	log.Info("Added '%s' to filesystemStorage", address)
+
+	return address, nil
+}
+
+func (storage *filesystemStorage) Hash(r io.Reader) (string, error) {
+	const failMsg = "filesystemStorage.Hash failed"
+
+	data, err := ioutil.ReadAll(r)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	return filesystemHash(data), nil
+}
+
+func (storage *filesystemStorage) Pin(hash string) error {
+	if !isValidFilesystemHash(hash) {
+		return fmt.Errorf("Invalid hash: '%s'", hash)
+	}
+
+	if _, err := os.Stat(storage.blockPath(hash)); err != nil {
+		return fmt.Errorf("Data not found for '%s'", hash)
+	}
+
+	return storage.touchPin(hash)
+}
+
+func (storage *filesystemStorage) Unpin(hash string) error {
+	if !isValidFilesystemHash(hash) {
+		return fmt.Errorf("Invalid hash: '%s'", hash)
+	}
+
+	err := os.Remove(storage.pinPath(hash))
+
+	if os.IsNotExist(err) {
+		return fmt.Errorf("Not pinned: '%s'", hash)
+	}
+
+	return err
+}
+
+func (storage *filesystemStorage) Pins() ([]string, error) {
+	infos, err := ioutil.ReadDir(storage.pinDir())
+
+	if err != nil {
+		return nil, errors.Wrap(err, "filesystemStorage.Pins failed")
+	}
+
+	pins := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if isValidFilesystemHash(info.Name()) {
+			pins = append(pins, info.Name())
+		}
+	}
+
+	return pins, nil
+}
+
+func (storage *filesystemStorage) touchPin(hash string) error {
+	file, err := os.OpenFile(storage.pinPath(hash), os.O_CREATE|os.O_WRONLY, __FILESYSTEM_FILE_MODE)
+
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func (storage *filesystemStorage) blockDir() string {
+	return filepath.Join(storage.Dir, __FILESYSTEM_BLOCK_DIR)
+}
+
+func (storage *filesystemStorage) pinDir() string {
+	return filepath.Join(storage.Dir, __FILESYSTEM_PIN_DIR)
+}
+
+func (storage *filesystemStorage) blockPath(hash string) string {
+	return filepath.Join(storage.blockDir(), hash)
+}
+
+func (storage *filesystemStorage) pinPath(hash string) string {
+	return filepath.Join(storage.pinDir(), hash)
+}
+
+// writeFileAtomic writes to a temporary file first, so readers never see a partial block.
+func writeFileAtomic(path string, data []byte) error {
+	temp, err := ioutil.TempFile(filepath.Dir(path), __FILESYSTEM_TEMP_PREFIX)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(temp, bytes.NewReader(data))
+
+	if err == nil {
+		err = temp.Sync()
+	}
+
+	closeErr := temp.Close()
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
+
+// filesystemHash encodes the sha256 multihash of data.
+func filesystemHash(data []byte) string {
+	digest := sha256.Sum256(data)
+	multihash := append([]byte{__MULTIHASH_SHA256, sha256.Size}, digest[:]...)
+	return util.EncodeBase58(multihash)
+}
+
+// isValidFilesystemHash prevents hashes from naming files outside the storage directory.
+func isValidFilesystemHash(hash string) bool {
+	return hash != "" && util.IsBase58(hash)
+}
+
+const (
+	__FILESYSTEM_BLOCK_DIR   = "blocks"
+	__FILESYSTEM_PIN_DIR     = "pins"
+	__FILESYSTEM_TEMP_PREFIX = ".add-"
+	__FILESYSTEM_DIR_MODE    = 0700
+	__FILESYSTEM_FILE_MODE   = 0600
+	__MULTIHASH_SHA256       = 0x12
+)
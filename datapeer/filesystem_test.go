@@ -0,0 +1,65 @@
+package datapeer
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestFilesystemStorage(t *testing.T) {
+	const dataText = "Much data!"
+
+	dir, err := ioutil.TempDir("", "godless-datapeer")
+	testutil.AssertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	options := FilesystemStorageOptions{Dir: dir}
+	storage := makeFilesystemStorage(options)
+	testutil.Assert(t, "Expected storage down before connect", !storage.IsUp())
+	testutil.AssertNil(t, storage.Connect())
+	testutil.Assert(t, "Expected storage up", storage.IsUp())
+
+	data, err := storage.Cat("notpresent")
+	testutil.AssertNil(t, data)
+	testutil.AssertNonNil(t, err)
+
+	_, err = storage.Cat("../pins")
+	testutil.AssertNonNil(t, err)
+
+	key, err := storage.Add(strings.NewReader(dataText))
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected multihash", strings.HasPrefix(key, "Qm"))
+
+	again, err := storage.Add(strings.NewReader(dataText))
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected hash", key, again)
+
+	// Data survives a restart.
+	restarted := makeFilesystemStorage(options)
+	testutil.AssertNil(t, restarted.Connect())
+
+	data, err = restarted.Cat(key)
+	testutil.AssertNil(t, err)
+	dataBytes, err := ioutil.ReadAll(data)
+	testutil.AssertNil(t, err)
+	testutil.AssertNil(t, data.Close())
+	testutil.AssertBytesEqual(t, []byte(dataText), dataBytes)
+
+	pins, err := restarted.Pins()
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, pins)
+	testutil.AssertEquals(t, "Unexpected pin", key, pins[0])
+
+	testutil.AssertNil(t, restarted.Unpin(key))
+	testutil.AssertNonNil(t, restarted.Unpin(key))
+
+	pins, err = restarted.Pins()
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 0, pins)
+
+	testutil.AssertNil(t, restarted.Pin(key))
+	testutil.AssertNonNil(t, restarted.Pin("notpresent"))
+}
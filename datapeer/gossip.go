@@ -0,0 +1,645 @@
+package datapeer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/util"
+	"github.com/johnny-morrice/godless/log"
+	"github.com/johnny-morrice/godless/proto"
+)
+
+type GossipOptions struct {
+	// ListenAddr is optional.  If empty, the peer will only dial out.
+	ListenAddr string
+	// Peers is optional.  Addresses of static peers, which are redialled when disconnected.
+	Peers []string
+	// PrivateKey is required.  It identifies this peer to others.
+	PrivateKey crypto.PrivateKey
+	// TrustedKeys is required.  Only peers holding one of these keys may connect.
+	TrustedKeys []crypto.PublicKey
+	// Storage is required.  Blocks are served from here, and blocks fetched from peers are added.
+	Storage api.ContentAddressableStorage
+	// Timeout is optional.  Limits handshakes and block requests.
+	Timeout time.Duration
+	// RedialInterval is optional.  The duration between attempts to reach a static peer.
+	RedialInterval time.Duration
+	// WantConcurrency is optional.  The number of block requests from peers served at once.
+	WantConcurrency int
+}
+
+// GossipPeer replicates over direct TCP connections to other godless servers, without IPFS.
+// Published messages are flooded through the network of peers.  Blocks missing from Storage are
+// requested from directly connected peers, and only accepted if they have the requested hash under
+// Storage, so peers must use the same kind of Storage.  Blocks are checked before they are added if
+// Storage is an api.HashingStorage.
+type GossipPeer struct {
+	GossipOptions
+	sync.RWMutex
+	bus         *residentMemoryPubSubBus
+	listener    net.Listener
+	sessions    map[*gossipSession]struct{}
+	requests    map[uint64]chan *proto.GossipMessage
+	nextRequest uint64
+	seen        map[string]struct{}
+	seenOrder   []string
+	origin      string
+	seqNo       uint64
+	name        string
+	semaphore   chan struct{}
+	stopch      chan struct{}
+	stopOnce    sync.Once
+	wg          sync.WaitGroup
+}
+
+func MakeGossipPeer(options GossipOptions) *GossipPeer {
+	if options.Timeout == 0 {
+		options.Timeout = __DEFAULT_GOSSIP_TIMEOUT
+	}
+
+	if options.RedialInterval == 0 {
+		options.RedialInterval = __DEFAULT_GOSSIP_REDIAL_INTERVAL
+	}
+
+	if options.WantConcurrency == 0 {
+		options.WantConcurrency = __DEFAULT_GOSSIP_WANT_CONCURRENCY
+	}
+
+	return &GossipPeer{
+		GossipOptions: options,
+		bus:           &residentMemoryPubSubBus{},
+		sessions:      map[*gossipSession]struct{}{},
+		requests:      map[uint64]chan *proto.GossipMessage{},
+		seen:          map[string]struct{}{},
+		semaphore:     make(chan struct{}, options.WantConcurrency),
+		stopch:        make(chan struct{}),
+	}
+}
+
+// MakeGossipDataPeer returns peer as a DagStorage too, if its Storage is one.  DAG nodes are read
+// from and written to Storage directly, and are not requested from other gossip peers.
+func MakeGossipDataPeer(peer *GossipPeer) api.PinningDataPeer {
+	if dag, ok := peer.Storage.(api.DagStorage); ok {
+		return gossipDagPeer{GossipPeer: peer, dag: dag}
+	}
+
+	return peer
+}
+
+type gossipDagPeer struct {
+	*GossipPeer
+	dag api.DagStorage
+}
+
+func (peer gossipDagPeer) DagPut(data interface{}, inputEncoding, kind string) (string, error) {
+	return peer.dag.DagPut(data, inputEncoding, kind)
+}
+
+func (peer gossipDagPeer) DagGet(ref string, out interface{}) error {
+	return peer.dag.DagGet(ref, out)
+}
+
+func (peer *GossipPeer) Connect() error {
+	const failMsg = "GossipPeer.Connect failed"
+
+	if peer.Storage == nil {
+		return errors.New("GossipPeer requires Storage")
+	}
+
+	if connecter, ok := peer.Storage.(api.ConnectablePeer); ok {
+		err := connecter.Connect()
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+	}
+
+	hash, err := peer.PrivateKey.GetPublicKey().Hash()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	originBytes := make([]byte, __GOSSIP_ORIGIN_SIZE)
+	_, err = io.ReadFull(rand.Reader, originBytes)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	peer.name = string(hash)
+	peer.origin = util.EncodeBase58(originBytes)
+
+	if peer.ListenAddr != "" {
+		listener, err := net.Listen("tcp", peer.ListenAddr)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		log.Info("Gossip listening on: %s", listener.Addr())
+
+		peer.Lock()
+		peer.listener = listener
+		peer.Unlock()
+
+		peer.wg.Add(1)
+		go peer.acceptLoop(listener)
+	}
+
+	for _, addr := range peer.Peers {
+		peer.wg.Add(1)
+		go peer.dialLoop(addr)
+	}
+
+	return nil
+}
+
+// Addr is the listening address, or nil if the peer is not listening.
+func (peer *GossipPeer) Addr() net.Addr {
+	peer.RLock()
+	defer peer.RUnlock()
+
+	if peer.listener == nil {
+		return nil
+	}
+
+	return peer.listener.Addr()
+}
+
+// IsUp follows Storage, if it can be pinged, since blocks cannot be stored while it is down.
+func (peer *GossipPeer) IsUp() bool {
+	if pingable, ok := peer.Storage.(api.PingablePeer); ok {
+		return pingable.IsUp()
+	}
+
+	peer.RLock()
+	defer peer.RUnlock()
+
+	return peer.listener != nil || len(peer.sessions) > 0
+}
+
+func (peer *GossipPeer) Disconnect() error {
+	peer.stopOnce.Do(func() {
+		close(peer.stopch)
+
+		peer.Lock()
+		if peer.listener != nil {
+			peer.listener.Close()
+		}
+
+		for session := range peer.sessions {
+			session.close()
+		}
+		peer.Unlock()
+	})
+
+	peer.wg.Wait()
+
+	if disconnecter, ok := peer.Storage.(api.DisconnectablePeer); ok {
+		return disconnecter.Disconnect()
+	}
+
+	return nil
+}
+
+func (peer *GossipPeer) Add(r io.Reader) (string, error) {
+	return peer.Storage.Add(r)
+}
+
+// Cat reads from Storage, falling back to connected peers.
+func (peer *GossipPeer) Cat(hash string) (io.ReadCloser, error) {
+	reader, err := peer.Storage.Cat(hash)
+
+	if err == nil {
+		return reader, nil
+	}
+
+	data, fetchErr := peer.fetchBlock(hash)
+
+	if fetchErr != nil {
+		log.Warn("Failed to fetch '%s' from gossip peers: %s", hash, fetchErr.Error())
+		return nil, err
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (peer *GossipPeer) Pin(hash string) error {
+	pinner, ok := peer.Storage.(api.PinningPeer)
+
+	if !ok {
+		return errors.New("GossipPeer Storage does not support pinning")
+	}
+
+	return pinner.Pin(hash)
+}
+
+func (peer *GossipPeer) Unpin(hash string) error {
+	pinner, ok := peer.Storage.(api.PinningPeer)
+
+	if !ok {
+		return errors.New("GossipPeer Storage does not support pinning")
+	}
+
+	return pinner.Unpin(hash)
+}
+
+func (peer *GossipPeer) Pins() ([]string, error) {
+	pinner, ok := peer.Storage.(api.PinningPeer)
+
+	if !ok {
+		return nil, errors.New("GossipPeer Storage does not support pinning")
+	}
+
+	return pinner.Pins()
+}
+
+// PubSubPublish delivers to local subscribers as well as to peers, like IPFS pubsub.
+func (peer *GossipPeer) PubSubPublish(topic, data string) error {
+	peer.Lock()
+	peer.seqNo++
+	id := fmt.Sprintf("%s:%d", peer.origin, peer.seqNo)
+	peer.markSeen(id)
+	peer.Unlock()
+
+	message := &proto.GossipMessage{
+		Type:  __GOSSIP_PUBLISH,
+		Id:    id,
+		Topic: topic,
+		Data:  []byte(data),
+	}
+
+	peer.bus.publish(peer.name, topic, data)
+	peer.broadcast(message, nil)
+
+	return nil
+}
+
+func (peer *GossipPeer) PubSubSubscribe(topic string) (api.PubSubSubscription, error) {
+	return peer.bus.PubSubSubscribe(topic)
+}
+
+func (peer *GossipPeer) acceptLoop(listener net.Listener) {
+	defer peer.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			if peer.isStopped() {
+				return
+			}
+
+			log.Warn("Gossip accept failed: %s", err.Error())
+			continue
+		}
+
+		peer.wg.Add(1)
+		go func() {
+			defer peer.wg.Done()
+			peer.runSession(conn, false)
+		}()
+	}
+}
+
+func (peer *GossipPeer) dialLoop(addr string) {
+	defer peer.wg.Done()
+
+	for {
+		conn, err := net.DialTimeout("tcp", addr, peer.Timeout)
+
+		if err == nil {
+			peer.runSession(conn, true)
+		} else {
+			log.Debug("Failed to dial gossip peer at %s: %s", addr, err.Error())
+		}
+
+		select {
+		case <-peer.stopch:
+			return
+		case <-time.After(peer.RedialInterval):
+		}
+	}
+}
+
+func (peer *GossipPeer) runSession(conn net.Conn, initiator bool) {
+	options := gossipHandshakeOptions{
+		conn:        conn,
+		privateKey:  peer.PrivateKey,
+		trustedKeys: peer.TrustedKeys,
+		initiator:   initiator,
+		timeout:     peer.Timeout,
+	}
+
+	session, err := handshakeGossip(options)
+
+	if err != nil {
+		log.Warn("Gossip handshake with %s failed: %s", conn.RemoteAddr(), err.Error())
+		conn.Close()
+		return
+	}
+
+	if !peer.addSession(session) {
+		session.close()
+		return
+	}
+
+	defer peer.removeSession(session)
+
+	log.Info("Gossip connected to %s at %s", session.peerName, conn.RemoteAddr())
+
+	for {
+		message, err := session.receive()
+
+		if err != nil {
+			if !peer.isStopped() {
+				log.Info("Gossip disconnected from %s: %s", session.peerName, err.Error())
+			}
+
+			return
+		}
+
+		peer.handle(session, message)
+	}
+}
+
+func (peer *GossipPeer) handle(session *gossipSession, message *proto.GossipMessage) {
+	switch message.Type {
+	case __GOSSIP_PUBLISH:
+		peer.handlePublish(session, message)
+	case __GOSSIP_WANT:
+		peer.serveWant(session, message)
+	case __GOSSIP_BLOCK, __GOSSIP_MISSING:
+		peer.handleBlock(message)
+	default:
+		log.Warn("Unknown gossip message type from %s: %d", session.peerName, message.Type)
+	}
+}
+
+// handlePublish attributes the record to the authenticated peer that forwarded it.
+func (peer *GossipPeer) handlePublish(source *gossipSession, message *proto.GossipMessage) {
+	peer.Lock()
+	_, seen := peer.seen[message.Id]
+	if !seen {
+		peer.markSeen(message.Id)
+	}
+	peer.Unlock()
+
+	if seen {
+		return
+	}
+
+	peer.bus.publish(source.peerName, message.Topic, string(message.Data))
+	peer.broadcast(message, source)
+}
+
+// serveWant waits for a free worker, so a peer flooding us with requests stops being read rather
+// than spawning unbounded goroutines.
+func (peer *GossipPeer) serveWant(session *gossipSession, message *proto.GossipMessage) {
+	select {
+	case peer.semaphore <- struct{}{}:
+	case <-peer.stopch:
+		return
+	}
+
+	go func() {
+		defer func() { <-peer.semaphore }()
+		peer.handleWant(session, message)
+	}()
+}
+
+func (peer *GossipPeer) handleWant(session *gossipSession, message *proto.GossipMessage) {
+	response := &proto.GossipMessage{
+		Type:    __GOSSIP_MISSING,
+		Hash:    message.Hash,
+		Request: message.Request,
+	}
+
+	reader, err := peer.Storage.Cat(message.Hash)
+
+	if err == nil {
+		data, readErr := ioutil.ReadAll(reader)
+		reader.Close()
+
+		if readErr == nil {
+			response.Type = __GOSSIP_BLOCK
+			response.Data = data
+		}
+	}
+
+	err = session.send(response)
+
+	if err != nil {
+		log.Warn("Failed to send block to %s: %s", session.peerName, err.Error())
+	}
+}
+
+func (peer *GossipPeer) handleBlock(message *proto.GossipMessage) {
+	peer.RLock()
+	responses, present := peer.requests[message.Request]
+	peer.RUnlock()
+
+	if !present {
+		return
+	}
+
+	select {
+	case responses <- message:
+	default:
+	}
+}
+
+func (peer *GossipPeer) fetchBlock(hash string) ([]byte, error) {
+	sessions := peer.connectedSessions()
+
+	if len(sessions) == 0 {
+		return nil, errors.New("No gossip peers")
+	}
+
+	responses := make(chan *proto.GossipMessage, len(sessions))
+
+	peer.Lock()
+	peer.nextRequest++
+	request := peer.nextRequest
+	peer.requests[request] = responses
+	peer.Unlock()
+
+	defer func() {
+		peer.Lock()
+		delete(peer.requests, request)
+		peer.Unlock()
+	}()
+
+	want := &proto.GossipMessage{
+		Type:    __GOSSIP_WANT,
+		Hash:    hash,
+		Request: request,
+	}
+
+	asked := 0
+	for _, session := range sessions {
+		err := session.send(want)
+
+		if err == nil {
+			asked++
+		}
+	}
+
+	timeout := time.After(peer.Timeout)
+
+	for answered := 0; answered < asked; answered++ {
+		select {
+		case response := <-responses:
+			if response.Type != __GOSSIP_BLOCK || response.Hash != hash {
+				continue
+			}
+
+			isStored, err := peer.storeBlock(hash, response.Data)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !isStored {
+				continue
+			}
+
+			return response.Data, nil
+		case <-timeout:
+			return nil, errors.New("Timed out waiting for gossip peers")
+		case <-peer.stopch:
+			return nil, errors.New("GossipPeer stopped")
+		}
+	}
+
+	return nil, fmt.Errorf("No gossip peer had '%s'", hash)
+}
+
+// storeBlock adds data to Storage if it has the wanted hash.  The hash is checked before data is
+// stored if Storage is an api.HashingStorage, so bad blocks are never added or pinned.
+func (peer *GossipPeer) storeBlock(hash string, data []byte) (bool, error) {
+	if hasher, ok := peer.Storage.(api.HashingStorage); ok {
+		address, err := hasher.Hash(bytes.NewReader(data))
+
+		if err != nil {
+			return false, err
+		}
+
+		if address != hash {
+			log.Warn("Gossip block hash mismatch: expected '%s' but was '%s'", hash, address)
+			return false, nil
+		}
+	}
+
+	address, err := peer.Storage.Add(bytes.NewReader(data))
+
+	if err != nil {
+		return false, err
+	}
+
+	if address != hash {
+		log.Warn("Gossip block hash mismatch: expected '%s' but was '%s'", hash, address)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (peer *GossipPeer) broadcast(message *proto.GossipMessage, except *gossipSession) {
+	for _, session := range peer.connectedSessions() {
+		if session == except {
+			continue
+		}
+
+		err := session.send(message)
+
+		if err != nil {
+			log.Warn("Failed to gossip to %s: %s", session.peerName, err.Error())
+		}
+	}
+}
+
+func (peer *GossipPeer) addSession(session *gossipSession) bool {
+	peer.Lock()
+	defer peer.Unlock()
+
+	if peer.isStopped() {
+		return false
+	}
+
+	peer.sessions[session] = struct{}{}
+	return true
+}
+
+func (peer *GossipPeer) removeSession(session *gossipSession) {
+	peer.Lock()
+	delete(peer.sessions, session)
+	peer.Unlock()
+
+	session.close()
+}
+
+func (peer *GossipPeer) connectedSessions() []*gossipSession {
+	peer.RLock()
+	defer peer.RUnlock()
+
+	sessions := make([]*gossipSession, 0, len(peer.sessions))
+	for session := range peer.sessions {
+		sessions = append(sessions, session)
+	}
+
+	return sessions
+}
+
+func (peer *GossipPeer) sessionCount() int {
+	peer.RLock()
+	defer peer.RUnlock()
+
+	return len(peer.sessions)
+}
+
+// markSeen must be called with the write lock held.
+func (peer *GossipPeer) markSeen(id string) {
+	peer.seen[id] = struct{}{}
+	peer.seenOrder = append(peer.seenOrder, id)
+
+	if len(peer.seenOrder) > __GOSSIP_SEEN_SIZE {
+		delete(peer.seen, peer.seenOrder[0])
+		peer.seenOrder = peer.seenOrder[1:]
+	}
+}
+
+func (peer *GossipPeer) isStopped() bool {
+	select {
+	case <-peer.stopch:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	__GOSSIP_PUBLISH = uint32(iota + 1)
+	__GOSSIP_WANT
+	__GOSSIP_BLOCK
+	__GOSSIP_MISSING
+)
+
+const (
+	__GOSSIP_ORIGIN_SIZE              = 8
+	__GOSSIP_SEEN_SIZE                = 4096
+	__DEFAULT_GOSSIP_TIMEOUT          = time.Second * 30
+	__DEFAULT_GOSSIP_REDIAL_INTERVAL  = time.Second * 5
+	__DEFAULT_GOSSIP_WANT_CONCURRENCY = 16
+)
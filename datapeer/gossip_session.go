@@ -0,0 +1,361 @@
+package datapeer
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	pb "github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/proto"
+)
+
+// gossipSession is an authenticated connection to another gossip peer.  Each side proves it holds
+// a trusted godless key by signing the handshake, which includes fresh exchange keys.  Frames after
+// the handshake are encrypted under keys derived from the exchange, so they cannot be forged or
+// replayed by anyone who did not take part in the handshake.
+type gossipSession struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	peerKey   crypto.PublicKey
+	peerName  string
+	sendKey   [__GOSSIP_KEY_SIZE]byte
+	recvKey   [__GOSSIP_KEY_SIZE]byte
+	sendLock  sync.Mutex
+	sendCount uint64
+	recvCount uint64
+	// timeout bounds each write, so a peer that stops reading cannot block senders forever.
+	timeout time.Duration
+}
+
+type gossipHandshakeOptions struct {
+	conn        net.Conn
+	privateKey  crypto.PrivateKey
+	trustedKeys []crypto.PublicKey
+	initiator   bool
+	timeout     time.Duration
+}
+
+func handshakeGossip(options gossipHandshakeOptions) (*gossipSession, error) {
+	const failMsg = "handshakeGossip failed"
+
+	conn := options.conn
+	conn.SetDeadline(time.Now().Add(options.timeout))
+	defer conn.SetDeadline(time.Time{})
+
+	session := &gossipSession{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: options.timeout,
+	}
+
+	exchangePub, exchangePriv, err := box.GenerateKey(rand.Reader)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	publicKeyText, err := crypto.SerializePublicKey(options.privateKey.GetPublicKey())
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	myHello, err := pb.Marshal(&proto.GossipHelloMessage{
+		PublicKey:   string(publicKeyText),
+		ExchangeKey: exchangePub[:],
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	err = writeGossipFrame(conn, myHello)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	peerHello, err := readGossipFrame(session.reader, __GOSSIP_MAX_HANDSHAKE_FRAME_SIZE)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	hello := &proto.GossipHelloMessage{}
+	err = pb.Unmarshal(peerHello, hello)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	if len(hello.ExchangeKey) != __GOSSIP_KEY_SIZE {
+		return nil, errors.New("Invalid gossip exchange key")
+	}
+
+	peerKey, err := crypto.ParsePublicKey(crypto.PublicKeyText(hello.PublicKey))
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	var initiatorHello, responderHello []byte
+	if options.initiator {
+		initiatorHello, responderHello = myHello, peerHello
+	} else {
+		initiatorHello, responderHello = peerHello, myHello
+	}
+
+	myTranscript := gossipTranscript(options.initiator, initiatorHello, responderHello)
+	signature, err := crypto.Sign(options.privateKey, myTranscript)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	signatureText, err := crypto.PrintSignature(signature)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	myAuth, err := pb.Marshal(&proto.GossipAuthMessage{Signature: string(signatureText)})
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	err = writeGossipFrame(conn, myAuth)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	peerAuth, err := readGossipFrame(session.reader, __GOSSIP_MAX_HANDSHAKE_FRAME_SIZE)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	auth := &proto.GossipAuthMessage{}
+	err = pb.Unmarshal(peerAuth, auth)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	peerSignature, err := crypto.ParseSignature(crypto.SignatureText(auth.Signature))
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	peerTranscript := gossipTranscript(!options.initiator, initiatorHello, responderHello)
+	verified, err := crypto.Verify(peerKey, peerTranscript, peerSignature)
+
+	if err != nil || !verified {
+		return nil, errors.New("Gossip peer failed authentication")
+	}
+
+	if !isTrustedGossipKey(peerKey, options.trustedKeys) {
+		return nil, errors.New("Gossip peer key is not trusted")
+	}
+
+	peerHash, err := peerKey.Hash()
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	peerExchange := [__GOSSIP_KEY_SIZE]byte{}
+	copy(peerExchange[:], hello.ExchangeKey)
+	shared := [__GOSSIP_KEY_SIZE]byte{}
+	box.Precompute(&shared, &peerExchange, exchangePriv)
+
+	initiatorKey := gossipSessionKey(shared, true)
+	responderKey := gossipSessionKey(shared, false)
+
+	if options.initiator {
+		session.sendKey, session.recvKey = initiatorKey, responderKey
+	} else {
+		session.sendKey, session.recvKey = responderKey, initiatorKey
+	}
+
+	session.peerKey = peerKey
+	session.peerName = string(peerHash)
+
+	return session, nil
+}
+
+func (session *gossipSession) send(message *proto.GossipMessage) error {
+	const failMsg = "gossipSession.send failed"
+
+	plain, err := pb.Marshal(message)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	session.sendLock.Lock()
+	defer session.sendLock.Unlock()
+
+	nonce := gossipNonce(session.sendCount)
+	session.sendCount++
+	sealed := secretbox.Seal(nil, plain, &nonce, &session.sendKey)
+
+	err = session.conn.SetWriteDeadline(time.Now().Add(session.timeout))
+
+	if err == nil {
+		err = writeGossipFrame(session.conn, sealed)
+	}
+
+	// A failed write may leave part of a frame on the wire, so the session is closed, which
+	// ends its receive loop and drops the peer.
+	if err != nil {
+		session.close()
+		return errors.Wrap(err, failMsg)
+	}
+
+	return nil
+}
+
+// receive must only be called from a single goroutine.
+func (session *gossipSession) receive() (*proto.GossipMessage, error) {
+	const failMsg = "gossipSession.receive failed"
+
+	sealed, err := readGossipFrame(session.reader, __GOSSIP_MAX_FRAME_SIZE)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	nonce := gossipNonce(session.recvCount)
+	session.recvCount++
+	plain, ok := secretbox.Open(nil, sealed, &nonce, &session.recvKey)
+
+	if !ok {
+		return nil, errors.New("Gossip frame failed authentication")
+	}
+
+	message := &proto.GossipMessage{}
+	err = pb.Unmarshal(plain, message)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	return message, nil
+}
+
+func (session *gossipSession) close() error {
+	return session.conn.Close()
+}
+
+func isTrustedGossipKey(key crypto.PublicKey, trustedKeys []crypto.PublicKey) bool {
+	for _, trusted := range trustedKeys {
+		if key.Equals(trusted) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gossipTranscript is the text signed by one side of the handshake.  The role is included so that
+// a signature cannot be reflected back to its author.
+func gossipTranscript(initiator bool, initiatorHello, responderHello []byte) []byte {
+	role := __GOSSIP_RESPONDER_ROLE
+	if initiator {
+		role = __GOSSIP_INITIATOR_ROLE
+	}
+
+	transcript := make([]byte, 0, len(__GOSSIP_PROTOCOL)+len(role)+len(initiatorHello)+len(responderHello)+2*binary.MaxVarintLen64)
+	transcript = append(transcript, __GOSSIP_PROTOCOL...)
+	transcript = append(transcript, role...)
+	transcript = appendGossipBytes(transcript, initiatorHello)
+	transcript = appendGossipBytes(transcript, responderHello)
+
+	return transcript
+}
+
+func appendGossipBytes(buff, bs []byte) []byte {
+	size := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(size, uint64(len(bs)))
+	buff = append(buff, size[:n]...)
+	return append(buff, bs...)
+}
+
+func gossipSessionKey(shared [__GOSSIP_KEY_SIZE]byte, initiator bool) [__GOSSIP_KEY_SIZE]byte {
+	role := __GOSSIP_RESPONDER_ROLE
+	if initiator {
+		role = __GOSSIP_INITIATOR_ROLE
+	}
+
+	hash := sha256.New()
+	hash.Write(shared[:])
+	hash.Write([]byte(role))
+
+	key := [__GOSSIP_KEY_SIZE]byte{}
+	copy(key[:], hash.Sum(nil))
+	return key
+}
+
+// gossipNonce is the frame count, which never repeats for a key within a session.
+func gossipNonce(count uint64) [__GOSSIP_NONCE_SIZE]byte {
+	nonce := [__GOSSIP_NONCE_SIZE]byte{}
+	binary.LittleEndian.PutUint64(nonce[:], count)
+	return nonce
+}
+
+func writeGossipFrame(w io.Writer, bs []byte) error {
+	frame := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(bs))
+	n := binary.PutUvarint(frame, uint64(len(bs)))
+	frame = append(frame[:n], bs...)
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// readGossipFrame rejects frames larger than maxSize before allocating them.  Handshake frames
+// come from unauthenticated peers, so they have a much smaller limit.
+func readGossipFrame(r *bufio.Reader, maxSize uint64) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if size > maxSize {
+		return nil, fmt.Errorf("Gossip frame too large: %d", size)
+	}
+
+	bs := make([]byte, size)
+	_, err = io.ReadFull(r, bs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bs, nil
+}
+
+const (
+	__GOSSIP_PROTOCOL       = "godless-gossip-1"
+	__GOSSIP_INITIATOR_ROLE = "initiator"
+	__GOSSIP_RESPONDER_ROLE = "responder"
+	__GOSSIP_KEY_SIZE       = 32
+	__GOSSIP_NONCE_SIZE     = 24
+	__GOSSIP_MAX_FRAME_SIZE = 64 * 1024 * 1024
+	// The hello and auth frames hold a public key, an exchange key and a signature.
+	__GOSSIP_MAX_HANDSHAKE_FRAME_SIZE = 16 * 1024
+)
@@ -0,0 +1,308 @@
+package datapeer
+
+import (
+	"bufio"
+	"bytes"
+	stdcrypto "crypto"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/internal/testutil"
+	"github.com/johnny-morrice/godless/proto"
+)
+
+func TestGossipPeerPubSub(t *testing.T) {
+	keys := generateGossipKeys(t, 3)
+	trusted := publicGossipKeys(keys)
+
+	peerA := startGossipPeer(t, keys[0], trusted)
+	defer peerA.Disconnect()
+	peerB := startGossipPeer(t, keys[1], trusted, peerA.Addr().String())
+	defer peerB.Disconnect()
+	// C is only connected to A through B.
+	peerC := startGossipPeer(t, keys[2], trusted, peerB.Addr().String())
+	defer peerC.Disconnect()
+
+	waitForGossipSessions(t, peerA, 1)
+	waitForGossipSessions(t, peerB, 2)
+	waitForGossipSessions(t, peerC, 1)
+
+	subA, err := peerA.PubSubSubscribe("topic")
+	testutil.AssertNil(t, err)
+	subC, err := peerC.PubSubSubscribe("topic")
+	testutil.AssertNil(t, err)
+	otherC, err := peerC.PubSubSubscribe("other")
+	testutil.AssertNil(t, err)
+
+	testutil.AssertNil(t, peerA.PubSubPublish("topic", "hello"))
+
+	expectedFrom, err := keys[1].GetPublicKey().Hash()
+	testutil.AssertNil(t, err)
+
+	record := nextGossipRecord(t, subC)
+	testutil.AssertEquals(t, "Unexpected data", "hello", string(record.Data()))
+	testutil.AssertEquals(t, "Unexpected sender", string(expectedFrom), record.From())
+
+	record = nextGossipRecord(t, subA)
+	testutil.AssertEquals(t, "Unexpected local data", "hello", string(record.Data()))
+
+	assertNoGossipRecord(t, otherC)
+	assertNoGossipRecord(t, subC)
+}
+
+func TestGossipPeerBlockExchange(t *testing.T) {
+	const dataText = "Much data!"
+
+	keys := generateGossipKeys(t, 2)
+	trusted := publicGossipKeys(keys)
+
+	peerA := startGossipPeer(t, keys[0], trusted)
+	defer peerA.Disconnect()
+	peerB := startGossipPeer(t, keys[1], trusted, peerA.Addr().String())
+	defer peerB.Disconnect()
+
+	waitForGossipSessions(t, peerA, 1)
+	waitForGossipSessions(t, peerB, 1)
+
+	hash, err := peerA.Add(strings.NewReader(dataText))
+	testutil.AssertNil(t, err)
+
+	_, err = peerB.Storage.Cat(hash)
+	testutil.AssertNonNil(t, err)
+
+	reader, err := peerB.Cat(hash)
+	testutil.AssertNil(t, err)
+	data, err := ioutil.ReadAll(reader)
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected block", dataText, string(data))
+
+	// The block is now held by B.
+	_, err = peerB.Storage.Cat(hash)
+	testutil.AssertNil(t, err)
+
+	_, err = peerB.Cat("notpresent")
+	testutil.AssertNonNil(t, err)
+}
+
+func TestGossipPeerRejectsBlockBeforeStoring(t *testing.T) {
+	keys := generateGossipKeys(t, 2)
+	trusted := publicGossipKeys(keys)
+
+	peerA := startGossipPeer(t, keys[0], trusted)
+	defer peerA.Disconnect()
+
+	// B hashes differently, so every block A serves fails its check.
+	storageB := MakeResidentMemoryStorage(ResidentMemoryStorageOptions{Hash: stdcrypto.SHA256})
+	peerB := MakeGossipPeer(GossipOptions{
+		Peers:          []string{peerA.Addr().String()},
+		PrivateKey:     keys[1],
+		TrustedKeys:    trusted,
+		Storage:        storageB,
+		Timeout:        time.Second,
+		RedialInterval: time.Millisecond * 50,
+	})
+	testutil.AssertNil(t, peerB.Connect())
+	defer peerB.Disconnect()
+
+	waitForGossipSessions(t, peerA, 1)
+	waitForGossipSessions(t, peerB, 1)
+
+	hash, err := peerA.Add(strings.NewReader("Much data!"))
+	testutil.AssertNil(t, err)
+
+	_, err = peerB.Cat(hash)
+	testutil.AssertNonNil(t, err)
+
+	pins, err := storageB.(api.PinningPeer).Pins()
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 0, pins)
+}
+
+func TestGossipPeerIsUpFollowsStorage(t *testing.T) {
+	keys := generateGossipKeys(t, 1)
+
+	peer := MakeGossipPeer(GossipOptions{
+		ListenAddr:  "127.0.0.1:0",
+		PrivateKey:  keys[0],
+		TrustedKeys: publicGossipKeys(keys),
+		Storage: Union{
+			Storage: MakeResidentMemoryStorage(ResidentMemoryStorageOptions{Hash: stdcrypto.SHA1}),
+			Pinger:  downPinger{},
+		},
+		Timeout: time.Second,
+	})
+	testutil.AssertNil(t, peer.Connect())
+	defer peer.Disconnect()
+
+	testutil.Assert(t, "Expected peer to be down with its storage", !peer.IsUp())
+}
+
+func TestGossipPeerRejectsUntrusted(t *testing.T) {
+	keys := generateGossipKeys(t, 2)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	testutil.AssertNil(t, err)
+	defer listener.Close()
+
+	results := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			results <- err
+			return
+		}
+
+		defer conn.Close()
+
+		_, err = handshakeGossip(gossipHandshakeOptions{
+			conn:        conn,
+			privateKey:  keys[0],
+			trustedKeys: publicGossipKeys(keys[:1]),
+			timeout:     __TEST_GOSSIP_TIMEOUT,
+		})
+		results <- err
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	testutil.AssertNil(t, err)
+	defer conn.Close()
+
+	// The stranger trusts the peer, so its side of the handshake completes.
+	stranger, err := handshakeGossip(gossipHandshakeOptions{
+		conn:        conn,
+		privateKey:  keys[1],
+		trustedKeys: publicGossipKeys(keys),
+		initiator:   true,
+		timeout:     __TEST_GOSSIP_TIMEOUT,
+	})
+	testutil.AssertNil(t, err)
+
+	select {
+	case err := <-results:
+		testutil.AssertNonNil(t, err)
+	case <-time.After(__TEST_GOSSIP_TIMEOUT):
+		t.Fatal("Timed out waiting for handshake")
+	}
+
+	_, err = stranger.receive()
+	testutil.AssertNonNil(t, err)
+}
+
+func TestGossipSessionSendTimesOut(t *testing.T) {
+	conn, stalled := net.Pipe()
+	defer stalled.Close()
+
+	session := &gossipSession{conn: conn, timeout: time.Millisecond * 50}
+
+	// Nothing reads from the other end of the pipe.
+	err := session.send(&proto.GossipMessage{Type: __GOSSIP_PUBLISH})
+	testutil.AssertNonNil(t, err)
+
+	_, err = stalled.Read(make([]byte, 1))
+	testutil.AssertNonNil(t, err)
+}
+
+func TestReadGossipFrameTooLarge(t *testing.T) {
+	buff := &bytes.Buffer{}
+	testutil.AssertNil(t, writeGossipFrame(buff, make([]byte, __GOSSIP_MAX_HANDSHAKE_FRAME_SIZE+1)))
+
+	_, err := readGossipFrame(bufio.NewReader(buff), __GOSSIP_MAX_HANDSHAKE_FRAME_SIZE)
+	testutil.AssertNonNil(t, err)
+}
+
+func startGossipPeer(t *testing.T, priv crypto.PrivateKey, trusted []crypto.PublicKey, peers ...string) *GossipPeer {
+	options := GossipOptions{
+		ListenAddr:     "127.0.0.1:0",
+		Peers:          peers,
+		PrivateKey:     priv,
+		TrustedKeys:    trusted,
+		Storage:        MakeResidentMemoryStorage(ResidentMemoryStorageOptions{Hash: stdcrypto.SHA1}),
+		Timeout:        time.Second,
+		RedialInterval: time.Millisecond * 50,
+	}
+
+	peer := MakeGossipPeer(options)
+	testutil.AssertNil(t, peer.Connect())
+	return peer
+}
+
+func generateGossipKeys(t *testing.T, count int) []crypto.PrivateKey {
+	keys := make([]crypto.PrivateKey, count)
+
+	for i := range keys {
+		priv, _, err := crypto.GenerateKey()
+		testutil.AssertNil(t, err)
+		keys[i] = priv
+	}
+
+	return keys
+}
+
+func publicGossipKeys(keys []crypto.PrivateKey) []crypto.PublicKey {
+	pubs := make([]crypto.PublicKey, len(keys))
+
+	for i, priv := range keys {
+		pubs[i] = priv.GetPublicKey()
+	}
+
+	return pubs
+}
+
+func waitForGossipSessions(t *testing.T, peer *GossipPeer, count int) {
+	deadline := time.Now().Add(__TEST_GOSSIP_TIMEOUT)
+
+	for peer.sessionCount() < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d gossip sessions but had %d", count, peer.sessionCount())
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func nextGossipRecord(t *testing.T, sub api.PubSubSubscription) api.PubSubRecord {
+	records := make(chan api.PubSubRecord, 1)
+
+	go func() {
+		record, _ := sub.Next()
+		records <- record
+	}()
+
+	select {
+	case record := <-records:
+		return record
+	case <-time.After(__TEST_GOSSIP_TIMEOUT):
+		t.Fatal("Timed out waiting for gossip")
+		return nil
+	}
+}
+
+func assertNoGossipRecord(t *testing.T, sub api.PubSubSubscription) {
+	records := make(chan api.PubSubRecord, 1)
+
+	go func() {
+		record, _ := sub.Next()
+		records <- record
+	}()
+
+	select {
+	case record := <-records:
+		t.Errorf("Unexpected gossip: %s", record.Data())
+	case <-time.After(__TEST_GOSSIP_SETTLE):
+	}
+}
+
+const __TEST_GOSSIP_TIMEOUT = time.Second * 5
+const __TEST_GOSSIP_SETTLE = time.Millisecond * 300
+
+type downPinger struct{}
+
+func (downPinger) IsUp() bool {
+	return false
+}
@@ -1,11 +1,18 @@
 package datapeer
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"mime/multipart"
 	gohttp "net/http"
+	"strings"
 	"time"
 
 	ipfs "github.com/ipfs/go-ipfs-api"
+	"github.com/pkg/errors"
 
 	"github.com/johnny-morrice/godless/api"
 	"github.com/johnny-morrice/godless/http"
@@ -24,7 +31,7 @@ type ipfsWebService struct {
 	pinger *ipfs.Shell
 }
 
-func MakeIpfsWebService(options IpfsWebServiceOptions) api.DataPeer {
+func MakeIpfsWebService(options IpfsWebServiceOptions) api.PinningDataPeer {
 	return &ipfsWebService{IpfsWebServiceOptions: options}
 }
 
@@ -66,6 +73,94 @@ func (client ipfsWebService) Add(r io.Reader) (string, error) {
 	return client.Shell.Add(r)
 }
 
+// Hash asks IPFS to chunk r as Add would, but only to report the hash.
+func (client ipfsWebService) Hash(r io.Reader) (string, error) {
+	const failMsg = "ipfsWebService.Hash failed"
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("file", "")
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	_, err = io.Copy(part, r)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	err = form.Close()
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	url := client.Url
+	if !strings.HasPrefix(url, "http") {
+		url = "http://" + url
+	}
+
+	resp, err := client.Http.Post(url+"/api/v0/add?only-hash=true", form.FormDataContentType(), body)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != gohttp.StatusOK {
+		text, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("IPFS hash failed with %d: %s", resp.StatusCode, string(text))
+	}
+
+	added := struct{ Hash string }{}
+	err = json.NewDecoder(resp.Body).Decode(&added)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	return added.Hash, nil
+}
+
+func (client ipfsWebService) DagPut(data interface{}, inputEncoding, kind string) (string, error) {
+	return client.Shell.DagPut(data, inputEncoding, kind)
+}
+
+func (client ipfsWebService) DagGet(ref string, out interface{}) error {
+	return client.Shell.DagGet(ref, out)
+}
+
+func (client ipfsWebService) Pin(hash string) error {
+	return client.Shell.Pin(hash)
+}
+
+func (client ipfsWebService) Unpin(hash string) error {
+	return client.Shell.Unpin(hash)
+}
+
+// Pins ignores indirect pins, since they are protected by their parents.
+func (client ipfsWebService) Pins() ([]string, error) {
+	pinInfo, err := client.Shell.Pins()
+
+	if err != nil {
+		return nil, err
+	}
+
+	pins := make([]string, 0, len(pinInfo))
+	for hash, info := range pinInfo {
+		if info.Type == ipfs.IndirectPin {
+			continue
+		}
+
+		pins = append(pins, hash)
+	}
+
+	return pins, nil
+}
+
 func (client ipfsWebService) PubSubPublish(topic, data string) error {
 	return client.Shell.PubSubPublish(topic, data)
 }
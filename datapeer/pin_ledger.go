@@ -0,0 +1,268 @@
+package datapeer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/api"
+)
+
+// MakeRecordingDataPeer records every block added or pinned through peer in the ledger.
+// The result is also a DagStorage if peer is.
+func MakeRecordingDataPeer(peer api.PinningDataPeer, ledger api.PinLedger) api.PinningDataPeer {
+	recorder := recordingDataPeer{PinningDataPeer: peer, ledger: ledger}
+
+	if dag, ok := peer.(api.DagStorage); ok {
+		return recordingDagPeer{recordingDataPeer: recorder, dag: dag}
+	}
+
+	return recorder
+}
+
+type recordingDataPeer struct {
+	api.PinningDataPeer
+	ledger api.PinLedger
+}
+
+func (peer recordingDataPeer) Add(r io.Reader) (string, error) {
+	const failMsg = "recordingDataPeer.Add failed"
+
+	hash, err := peer.PinningDataPeer.Add(r)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	err = peer.ledger.RecordPin(hash)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	return hash, nil
+}
+
+// Hash does not record anything, since nothing is stored.
+func (peer recordingDataPeer) Hash(r io.Reader) (string, error) {
+	hasher, ok := peer.PinningDataPeer.(api.HashingStorage)
+
+	if !ok {
+		return "", errors.New("recordingDataPeer wraps a peer that cannot hash")
+	}
+
+	return hasher.Hash(r)
+}
+
+func (peer recordingDataPeer) Pin(hash string) error {
+	const failMsg = "recordingDataPeer.Pin failed"
+
+	err := peer.PinningDataPeer.Pin(hash)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return errors.Wrap(peer.ledger.RecordPin(hash), failMsg)
+}
+
+type recordingDagPeer struct {
+	recordingDataPeer
+	dag api.DagStorage
+}
+
+func (peer recordingDagPeer) DagPut(data interface{}, inputEncoding, kind string) (string, error) {
+	const failMsg = "recordingDagPeer.DagPut failed"
+
+	hash, err := peer.dag.DagPut(data, inputEncoding, kind)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	err = peer.ledger.RecordPin(hash)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	return hash, nil
+}
+
+func (peer recordingDagPeer) DagGet(ref string, out interface{}) error {
+	return peer.dag.DagGet(ref, out)
+}
+
+func MakeResidentPinLedger() api.PinLedger {
+	return &residentPinLedger{records: map[string]time.Time{}}
+}
+
+type residentPinLedger struct {
+	sync.Mutex
+	records map[string]time.Time
+}
+
+func (ledger *residentPinLedger) RecordPin(hash string) error {
+	ledger.Lock()
+	defer ledger.Unlock()
+
+	ledger.records[hash] = time.Now()
+	return nil
+}
+
+func (ledger *residentPinLedger) ForgetPin(hash string, before time.Time) error {
+	ledger.Lock()
+	defer ledger.Unlock()
+
+	forgetPinRecord(ledger.records, hash, before)
+	return nil
+}
+
+func (ledger *residentPinLedger) PinRecords() ([]api.PinRecord, error) {
+	ledger.Lock()
+	defer ledger.Unlock()
+
+	return pinRecordList(ledger.records), nil
+}
+
+// MakeFilePinLedger keeps the ledger in an append-only file, so that a running server and
+// 'godless store gc' can share it.
+func MakeFilePinLedger(path string) api.PinLedger {
+	return &filePinLedger{path: path}
+}
+
+// filePinLedger appends one line per change.  Each line is written with a single call, so
+// lines from different processes do not interleave.
+type filePinLedger struct {
+	sync.Mutex
+	path string
+}
+
+func (ledger *filePinLedger) RecordPin(hash string) error {
+	return ledger.appendLine(__PIN_LEDGER_RECORD, hash, time.Now())
+}
+
+func (ledger *filePinLedger) ForgetPin(hash string, before time.Time) error {
+	return ledger.appendLine(__PIN_LEDGER_FORGET, hash, before)
+}
+
+func (ledger *filePinLedger) PinRecords() ([]api.PinRecord, error) {
+	const failMsg = "filePinLedger.PinRecords failed"
+
+	ledger.Lock()
+	defer ledger.Unlock()
+
+	file, err := os.Open(ledger.path)
+
+	if os.IsNotExist(err) {
+		return []api.PinRecord{}, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	defer file.Close()
+
+	records := map[string]time.Time{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		op, hash, at, err := parsePinLedgerLine(scanner.Text())
+
+		if err != nil {
+			return nil, errors.Wrap(err, failMsg)
+		}
+
+		switch op {
+		case __PIN_LEDGER_RECORD:
+			if at.After(records[hash]) {
+				records[hash] = at
+			}
+		case __PIN_LEDGER_FORGET:
+			forgetPinRecord(records, hash, at)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	return pinRecordList(records), nil
+}
+
+func (ledger *filePinLedger) appendLine(op, hash string, at time.Time) error {
+	const failMsg = "filePinLedger.appendLine failed"
+
+	if strings.ContainsAny(hash, " \n") {
+		return fmt.Errorf("Invalid hash: '%s'", hash)
+	}
+
+	ledger.Lock()
+	defer ledger.Unlock()
+
+	file, err := os.OpenFile(ledger.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, __PIN_LEDGER_FILE_MODE)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	line := fmt.Sprintf("%s %s %d\n", op, hash, at.UnixNano())
+	_, err = file.Write([]byte(line))
+
+	if err != nil {
+		file.Close()
+		return errors.Wrap(err, failMsg)
+	}
+
+	return errors.Wrap(file.Close(), failMsg)
+}
+
+func parsePinLedgerLine(line string) (string, string, time.Time, error) {
+	parts := strings.Split(line, " ")
+
+	if len(parts) != 3 {
+		return "", "", time.Time{}, fmt.Errorf("Invalid pin ledger line: '%s'", line)
+	}
+
+	nanos, err := strconv.ParseInt(parts[2], 10, 64)
+
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("Invalid pin ledger time: '%s'", line)
+	}
+
+	op := parts[0]
+	if op != __PIN_LEDGER_RECORD && op != __PIN_LEDGER_FORGET {
+		return "", "", time.Time{}, fmt.Errorf("Invalid pin ledger operation: '%s'", line)
+	}
+
+	return op, parts[1], time.Unix(0, nanos), nil
+}
+
+func forgetPinRecord(records map[string]time.Time, hash string, before time.Time) {
+	if added, present := records[hash]; present && added.Before(before) {
+		delete(records, hash)
+	}
+}
+
+func pinRecordList(records map[string]time.Time) []api.PinRecord {
+	list := make([]api.PinRecord, 0, len(records))
+
+	for hash, added := range records {
+		list = append(list, api.PinRecord{Hash: hash, Added: added})
+	}
+
+	return list
+}
+
+const (
+	__PIN_LEDGER_RECORD    = "pin"
+	__PIN_LEDGER_FORGET    = "forget"
+	__PIN_LEDGER_FILE_MODE = 0600
+)
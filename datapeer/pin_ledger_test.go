@@ -0,0 +1,60 @@
+package datapeer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestFilePinLedger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "godless-datapeer")
+	testutil.AssertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "godless.pins")
+	ledger := MakeFilePinLedger(path)
+
+	records, err := ledger.PinRecords()
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 0, records)
+
+	testutil.AssertNil(t, ledger.RecordPin("QmA"))
+	testutil.AssertNil(t, ledger.RecordPin("QmB"))
+	beforeRecordAgain := time.Now()
+	testutil.AssertNil(t, ledger.RecordPin("QmB"))
+
+	testutil.AssertNil(t, ledger.ForgetPin("QmA", time.Now()))
+	testutil.AssertNil(t, ledger.ForgetPin("QmB", beforeRecordAgain))
+
+	// The ledger survives a restart.
+	records, err = MakeFilePinLedger(path).PinRecords()
+	testutil.AssertNil(t, err)
+	assertPinRecordHashes(t, []string{"QmB"}, records)
+
+	testutil.AssertNonNil(t, ledger.RecordPin("Qm C"))
+}
+
+func TestResidentPinLedger(t *testing.T) {
+	ledger := MakeResidentPinLedger()
+
+	testutil.AssertNil(t, ledger.RecordPin("QmA"))
+	testutil.AssertNil(t, ledger.RecordPin("QmB"))
+	testutil.AssertNil(t, ledger.ForgetPin("QmA", time.Now()))
+
+	records, err := ledger.PinRecords()
+	testutil.AssertNil(t, err)
+	assertPinRecordHashes(t, []string{"QmB"}, records)
+}
+
+func assertPinRecordHashes(t *testing.T, expected []string, records []api.PinRecord) {
+	testutil.AssertLenEquals(t, len(expected), records)
+
+	for i, hash := range expected {
+		testutil.AssertEquals(t, "Unexpected hash", hash, records[i].Hash)
+	}
+}
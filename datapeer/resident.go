@@ -13,14 +13,16 @@ import (
 	"github.com/johnny-morrice/godless/log"
 )
 
-func MakeResidentMemoryDataPeer(options ResidentMemoryStorageOptions) api.DataPeer {
-	storage := MakeResidentMemoryStorage(options)
+func MakeResidentMemoryDataPeer(options ResidentMemoryStorageOptions) api.PinningDataPeer {
+	storage := makeResidentMemoryStorage(options)
 	pubsubber := MakeResidentMemoryPubSubBus()
 
 	return Union{
 		Storage:    storage,
 		Publisher:  pubsubber,
 		Subscriber: pubsubber,
+		Pinner:     storage,
+		Hasher:     storage,
 	}
 }
 
@@ -32,12 +34,18 @@ type residentMemoryStorage struct {
 	sync.RWMutex
 	ResidentMemoryStorageOptions
 	hashes map[string][]byte
+	pins   map[string]struct{}
 }
 
 func MakeResidentMemoryStorage(options ResidentMemoryStorageOptions) api.ContentAddressableStorage {
+	return makeResidentMemoryStorage(options)
+}
+
+func makeResidentMemoryStorage(options ResidentMemoryStorageOptions) *residentMemoryStorage {
 	return &residentMemoryStorage{
 		ResidentMemoryStorageOptions: options,
-		hashes: map[string][]byte{},
+		hashes:                       map[string][]byte{},
+		pins:                         map[string]struct{}{},
 	}
 }
 
@@ -64,21 +72,79 @@ func (storage *residentMemoryStorage) Add(r io.Reader) (string, error) {
 		return "", err
 	}
 
-	hash := storage.Hash.New()
-	hash.Write(data)
-	addressBytes := hash.Sum(nil)
-	address := util.EncodeBase58(addressBytes)
+	address := storage.address(data)
 
 	_, present := storage.hashes[address]
 	if !present {
 		storage.hashes[address] = data
 	}
 
+	// Like IPFS, we pin everything we add.
+	storage.pins[address] = struct{}{}
+
 	log.Info("Added '%s' to residentMemoryStorage", address)
 
 	return address, nil
 }
 
+func (storage *residentMemoryStorage) Hash(r io.Reader) (string, error) {
+	data, err := ioutil.ReadAll(r)
+
+	if err != nil {
+		return "", err
+	}
+
+	return storage.address(data), nil
+}
+
+func (storage *residentMemoryStorage) address(data []byte) string {
+	hash := storage.ResidentMemoryStorageOptions.Hash.New()
+	hash.Write(data)
+	return util.EncodeBase58(hash.Sum(nil))
+}
+
+func (storage *residentMemoryStorage) Pin(hash string) error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	_, present := storage.hashes[hash]
+
+	if !present {
+		return fmt.Errorf("Data not found for '%s'", hash)
+	}
+
+	storage.pins[hash] = struct{}{}
+
+	return nil
+}
+
+func (storage *residentMemoryStorage) Unpin(hash string) error {
+	storage.Lock()
+	defer storage.Unlock()
+
+	_, present := storage.pins[hash]
+
+	if !present {
+		return fmt.Errorf("Not pinned: '%s'", hash)
+	}
+
+	delete(storage.pins, hash)
+
+	return nil
+}
+
+func (storage *residentMemoryStorage) Pins() ([]string, error) {
+	storage.RLock()
+	defer storage.RUnlock()
+
+	pins := make([]string, 0, len(storage.pins))
+	for hash := range storage.pins {
+		pins = append(pins, hash)
+	}
+
+	return pins, nil
+}
+
 type residentMemoryPubSubBus struct {
 	sync.RWMutex
 	bus []residentSubscription
@@ -89,6 +155,11 @@ func MakeResidentMemoryPubSubBus() api.PubSubber {
 }
 
 func (pubsubber *residentMemoryPubSubBus) PubSubPublish(topic, data string) error {
+	pubsubber.publish(__RESIDENT_PEER_NAME, topic, data)
+	return nil
+}
+
+func (pubsubber *residentMemoryPubSubBus) publish(from, topic, data string) {
 	log.Debug("Publishing '%s' to '%s'...", topic, data)
 
 	pubsubber.RLock()
@@ -97,11 +168,9 @@ func (pubsubber *residentMemoryPubSubBus) PubSubPublish(topic, data string) erro
 	for _, sub := range pubsubber.bus {
 		subscription := sub
 		if subscription.topic == topic {
-			go subscription.publish(topic, data)
+			go subscription.publish(from, topic, data)
 		}
 	}
-
-	return nil
 }
 
 func (pubsubber *residentMemoryPubSubBus) PubSubSubscribe(topic string) (api.PubSubSubscription, error) {
@@ -124,8 +193,9 @@ type residentSubscription struct {
 	nextch chan api.PubSubRecord
 }
 
-func (subscription residentSubscription) publish(topic, data string) {
+func (subscription residentSubscription) publish(from, topic, data string) {
 	record := residentPubSubRecord{
+		from:   from,
 		data:   []byte(data),
 		topics: []string{topic},
 	}
@@ -139,12 +209,13 @@ func (subscription residentSubscription) Next() (api.PubSubRecord, error) {
 }
 
 type residentPubSubRecord struct {
+	from   string
 	data   []byte
 	topics []string
 }
 
 func (record residentPubSubRecord) From() string {
-	return "Local Memory Peer"
+	return record.from
 }
 
 func (record residentPubSubRecord) Data() []byte {
@@ -159,3 +230,5 @@ func (record residentPubSubRecord) SeqNo() int64 {
 func (record residentPubSubRecord) TopicIDs() []string {
 	return record.topics
 }
+
+const __RESIDENT_PEER_NAME = "Local Memory Peer"
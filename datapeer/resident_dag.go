@@ -0,0 +1,171 @@
+package datapeer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/internal/util"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// residentMemoryDagStorage stands in for the IPFS dag API.  Nodes are kept as JSON, and links are
+// objects of the form {"/": "<hash>"}, which are followed when resolving paths.
+type residentMemoryDagStorage struct {
+	sync.RWMutex
+	ResidentMemoryStorageOptions
+	nodes map[string][]byte
+}
+
+func MakeResidentMemoryDagStorage(options ResidentMemoryStorageOptions) api.DagStorage {
+	return &residentMemoryDagStorage{
+		ResidentMemoryStorageOptions: options,
+		nodes:                        map[string][]byte{},
+	}
+}
+
+func (storage *residentMemoryDagStorage) DagPut(data interface{}, inputEncoding, kind string) (string, error) {
+	if inputEncoding != __DAG_JSON_ENCODING {
+		return "", fmt.Errorf("Unsupported dag input encoding: '%s'", inputEncoding)
+	}
+
+	input, err := readDagInput(data)
+
+	if err != nil {
+		return "", err
+	}
+
+	var node interface{}
+	err = json.Unmarshal(input, &node)
+
+	if err != nil {
+		return "", err
+	}
+
+	// Go sorts map keys, so equal nodes have equal hashes.
+	canonical, err := json.Marshal(node)
+
+	if err != nil {
+		return "", err
+	}
+
+	hash := storage.Hash.New()
+	hash.Write([]byte(kind))
+	hash.Write(canonical)
+	address := util.EncodeBase58(hash.Sum(nil))
+
+	storage.Lock()
+	storage.nodes[address] = canonical
+	storage.Unlock()
+
+	log.Debug("Put '%s' to residentMemoryDagStorage", address)
+
+	return address, nil
+}
+
+// DagGet resolves ref, which is a hash optionally followed by a path of map keys and array indices.
+func (storage *residentMemoryDagStorage) DagGet(ref string, out interface{}) error {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(ref, __DAG_IPFS_PREFIX), "/"), "/")
+
+	node, err := storage.getNode(parts[0])
+
+	if err != nil {
+		return err
+	}
+
+	for _, part := range parts[1:] {
+		node, err = storage.resolveChild(node, part)
+
+		if err != nil {
+			return fmt.Errorf("Failed to resolve '%s': %s", ref, err.Error())
+		}
+	}
+
+	text, err := json.Marshal(node)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(text, out)
+}
+
+func (storage *residentMemoryDagStorage) resolveChild(node interface{}, key string) (interface{}, error) {
+	var child interface{}
+
+	switch parent := node.(type) {
+	case map[string]interface{}:
+		value, present := parent[key]
+
+		if !present {
+			return nil, fmt.Errorf("No key '%s'", key)
+		}
+
+		child = value
+	case []interface{}:
+		index, err := strconv.Atoi(key)
+
+		if err != nil || index < 0 || index >= len(parent) {
+			return nil, fmt.Errorf("No index '%s'", key)
+		}
+
+		child = parent[index]
+	default:
+		return nil, fmt.Errorf("Cannot resolve '%s' in a leaf", key)
+	}
+
+	if target, isLink := dagLinkTarget(child); isLink {
+		return storage.getNode(target)
+	}
+
+	return child, nil
+}
+
+func (storage *residentMemoryDagStorage) getNode(address string) (interface{}, error) {
+	storage.RLock()
+	text, present := storage.nodes[address]
+	storage.RUnlock()
+
+	if !present {
+		return nil, fmt.Errorf("Node not found for '%s'", address)
+	}
+
+	var node interface{}
+	err := json.Unmarshal(text, &node)
+	return node, err
+}
+
+func dagLinkTarget(node interface{}) (string, bool) {
+	object, ok := node.(map[string]interface{})
+
+	if !ok || len(object) != 1 {
+		return "", false
+	}
+
+	target, ok := object[__DAG_LINK_KEY].(string)
+	return target, ok
+}
+
+func readDagInput(data interface{}) ([]byte, error) {
+	switch input := data.(type) {
+	case string:
+		return []byte(input), nil
+	case []byte:
+		return input, nil
+	case io.Reader:
+		return ioutil.ReadAll(input)
+	default:
+		return nil, fmt.Errorf("Unsupported dag input type: %T", data)
+	}
+}
+
+const (
+	__DAG_JSON_ENCODING = "json"
+	__DAG_LINK_KEY      = "/"
+	__DAG_IPFS_PREFIX   = "/ipfs/"
+)
@@ -0,0 +1,41 @@
+package datapeer
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestResidentMemoryDagStorage(t *testing.T) {
+	storage := MakeResidentMemoryDagStorage(ResidentMemoryStorageOptions{Hash: crypto.SHA1})
+
+	_, err := storage.DagPut(`{"a": 1}`, "protobuf", "cbor")
+	testutil.AssertNonNil(t, err)
+
+	child, err := storage.DagPut(`{"name": "child", "values": [1, 2]}`, "json", "cbor")
+	testutil.AssertNil(t, err)
+
+	again, err := storage.DagPut([]byte(`{"values": [1, 2], "name": "child"}`), "json", "cbor")
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected hash", child, again)
+
+	parent, err := storage.DagPut(`{"children": [{"/": "`+child+`"}]}`, "json", "cbor")
+	testutil.AssertNil(t, err)
+
+	var name string
+	err = storage.DagGet(parent+"/children/0/name", &name)
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected name", "child", name)
+
+	var value int
+	err = storage.DagGet("/ipfs/"+parent+"/children/0/values/1", &value)
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected value", 2, value)
+
+	err = storage.DagGet(parent+"/children/1", &value)
+	testutil.AssertNonNil(t, err)
+
+	err = storage.DagGet("notpresent", &value)
+	testutil.AssertNonNil(t, err)
+}
@@ -37,6 +37,41 @@ func TestResidentMemoryStorage(t *testing.T) {
 	testutil.AssertEquals(t, "Unexpected hash", keyOne, keyTwo)
 }
 
+func TestResidentMemoryStoragePins(t *testing.T) {
+	options := ResidentMemoryStorageOptions{
+		Hash: crypto.SHA1,
+	}
+
+	storage := makeResidentMemoryStorage(options)
+
+	err := storage.Pin("not present")
+	testutil.AssertNonNil(t, err)
+
+	key, err := storage.Add(strings.NewReader("Much data!"))
+	testutil.AssertNil(t, err)
+
+	pins, err := storage.Pins()
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, pins)
+	testutil.AssertEquals(t, "Unexpected pin", key, pins[0])
+
+	err = storage.Unpin(key)
+	testutil.AssertNil(t, err)
+	err = storage.Unpin(key)
+	testutil.AssertNonNil(t, err)
+
+	pins, err = storage.Pins()
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 0, pins)
+
+	err = storage.Pin(key)
+	testutil.AssertNil(t, err)
+
+	pins, err = storage.Pins()
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, pins)
+}
+
 func TestResidentMemoryPubSub(t *testing.T) {
 	const topicA = "Topic A"
 	const topicB = "Topic B"
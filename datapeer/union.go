@@ -15,6 +15,8 @@ type Union struct {
 	Connecter    api.ConnectablePeer
 	Disconnecter api.DisconnectablePeer
 	Pinger       api.PingablePeer
+	Pinner       api.PinningPeer
+	Hasher       api.HashingStorage
 }
 
 func (peer Union) IsUp() bool {
@@ -57,6 +59,38 @@ func (peer Union) Add(r io.Reader) (string, error) {
 	return peer.Storage.Add(r)
 }
 
+func (peer Union) Hash(r io.Reader) (string, error) {
+	if peer.Hasher == nil {
+		return "", unionError
+	}
+
+	return peer.Hasher.Hash(r)
+}
+
+func (peer Union) Pin(hash string) error {
+	if peer.Pinner == nil {
+		return unionError
+	}
+
+	return peer.Pinner.Pin(hash)
+}
+
+func (peer Union) Unpin(hash string) error {
+	if peer.Pinner == nil {
+		return unionError
+	}
+
+	return peer.Pinner.Unpin(hash)
+}
+
+func (peer Union) Pins() ([]string, error) {
+	if peer.Pinner == nil {
+		return nil, unionError
+	}
+
+	return peer.Pinner.Pins()
+}
+
 func (peer Union) PubSubPublish(topic, data string) error {
 	if peer.Publisher == nil {
 		return unionError
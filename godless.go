@@ -32,6 +32,8 @@ type Options struct {
 	DataPeer api.DataPeer
 	// RemoteStore is optional.  If specified, the DataPeer will not be used, nor any of the IPFS options.
 	RemoteStore api.RemoteStore
+	// DagStorage is optional.  If specified, namespaces and indices are stored as linked IPLD DAG nodes.
+	DagStorage api.DagStorage
 	// KeyStore is required. A private Key store.
 	KeyStore api.KeyStore
 	// MemoryImage is required.
@@ -69,6 +71,24 @@ type Options struct {
 	PublicServer bool
 	// WebService is optional.
 	WebService api.WebService
+	// Codec is optional.  Compression for namespaces and indices written to IPFS.  Defaults to none.
+	Codec crdt.Codec
+	// BlobThreshold is optional.  Point text longer than this is stored in separate chunked blobs.  Zero disables blobs.
+	BlobThreshold int
+	// Journal is optional.  If specified, joins are journaled while the DataPeer is down, and flushed when it returns.
+	Journal api.JoinJournal
+	// VerifyCacheSize is optional.  It bounds the signature verifications remembered by the process.  Defaults to crypto.DEFAULT_VERIFY_CACHE_SIZE.  Negative disables the cache.
+	VerifyCacheSize int
+	// VerifiedMarkers is optional.  If set, namespaces that were fully verified are marked in the NamespaceCache so they are not verified again.
+	VerifiedMarkers bool
+	// SignNamespaces is optional.  If set, joins sign each namespace once instead of signing every point.
+	SignNamespaces bool
+	// ResponseKey is optional.  If set, webservice responses are signed with it.
+	ResponseKey *crypto.PrivateKey
+	// AllowedRequestKeys is optional.  If set, webservice requests must be signed by one of these keys.
+	AllowedRequestKeys []crypto.PublicKey
+	// MaxRequestAge is optional.  The clock difference allowed for signed webservice requests.
+	MaxRequestAge time.Duration
 }
 
 // Godless is a peer-to-peer database.  It shares structured data between peers, using IPFS as a backing store.
@@ -220,19 +240,24 @@ func (godless *Godless) connectDataPeer() error {
 
 func (godless *Godless) connectRemoteStore() error {
 	if godless.RemoteStore == nil {
-		ipfs := &service.ContentAddressableRemoteStore{
+		var store api.RemoteStore = &service.ContentAddressableRemoteStore{
 			Shell: godless.DataPeer,
+			Codec: godless.Codec,
+		}
+
+		if godless.DagStorage != nil {
+			store = service.MakeDagRemoteStore(godless.DataPeer, godless.DagStorage, godless.Codec)
 		}
 
 		if godless.FailEarly {
-			err := ipfs.Connect()
+			err := store.Connect()
 
 			if err != nil {
 				return err
 			}
 		}
 
-		godless.RemoteStore = ipfs
+		godless.RemoteStore = store
 	}
 
 	return nil
@@ -273,14 +298,24 @@ func (godless *Godless) setupNamespace() error {
 	}
 
 	namespaceOptions := service.RemoteNamespaceCoreOptions{
-		Pulse:          godless.Pulse,
-		Store:          godless.RemoteStore,
-		HeadCache:      godless.HeadCache,
-		IndexCache:     godless.IndexCache,
-		NamespaceCache: godless.NamespaceCache,
-		KeyStore:       godless.KeyStore,
-		IsPublicIndex:  godless.PublicServer,
-		MemoryImage:    godless.MemoryImage,
+		Pulse:           godless.Pulse,
+		Store:           godless.RemoteStore,
+		HeadCache:       godless.HeadCache,
+		IndexCache:      godless.IndexCache,
+		NamespaceCache:  godless.NamespaceCache,
+		KeyStore:        godless.KeyStore,
+		IsPublicIndex:   godless.PublicServer,
+		MemoryImage:     godless.MemoryImage,
+		BlobThreshold:   godless.BlobThreshold,
+		Journal:         godless.Journal,
+		VerifiedMarkers: godless.VerifiedMarkers,
+		SignNamespaces:  godless.SignNamespaces,
+	}
+
+	if godless.VerifyCacheSize < 0 {
+		crypto.SetVerifyCache(nil)
+	} else if godless.VerifyCacheSize > 0 {
+		crypto.SetVerifyCache(crypto.MakeVerifyCache(godless.VerifyCacheSize))
 	}
 
 	godless.remote = service.MakeRemoteNamespaceCore(namespaceOptions)
@@ -320,7 +355,10 @@ func (godless *Godless) setupWebService() error {
 	}
 
 	options := http.WebServiceOptions{
-		Api: godless.api,
+		Api:           godless.api,
+		SigningKey:    godless.ResponseKey,
+		AllowedKeys:   godless.AllowedRequestKeys,
+		MaxRequestAge: godless.MaxRequestAge,
 	}
 
 	godless.WebService = http.MakeWebService(options)
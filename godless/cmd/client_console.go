@@ -36,6 +36,8 @@ var clientConsoleCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		log.SetLevel(log.LOG_WARN)
 
+		readKeysFromViper()
+
 		options := cli.TerminalOptions{
 			Client: makeClient(),
 		}
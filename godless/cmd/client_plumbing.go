@@ -26,9 +26,11 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/johnny-morrice/godless/api"
 	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
 	"github.com/johnny-morrice/godless/http"
 	"github.com/johnny-morrice/godless/query"
 
@@ -42,12 +44,12 @@ var clientPlumbingCmd = &cobra.Command{
 	// TODO tidy method.
 	Run: func(cmd *cobra.Command, args []string) {
 		var err error
-		client := makeClient()
-
 		validateClientPlumbingArgs(cmd)
 
 		readKeysFromViper()
 
+		client := makeClient()
+
 		query := parseQuery()
 
 		if dryrun {
@@ -106,6 +108,8 @@ func makeClient() api.Client {
 	options := http.ClientOptions{
 		Http:       webClient,
 		ServerAddr: serverAddr,
+		ServerKey:  readServerKey(),
+		SigningKey: findSigningKey(),
 	}
 
 	client, err := http.MakeClient(options)
@@ -117,6 +121,43 @@ func makeClient() api.Client {
 	return client
 }
 
+// findSigningKey signs requests with the first private key, if the keys were read.
+func findSigningKey() *crypto.PrivateKey {
+	privateKeys := keyStore.GetAllPrivateKeys()
+
+	if len(privateKeys) == 0 {
+		return nil
+	}
+
+	return &privateKeys[0]
+}
+
+// readServerKey finds the --server-key in the config.  Only public keys are read, so there is no
+// passphrase prompt.
+func readServerKey() *crypto.PublicKey {
+	if serverKeyHash == "" {
+		return nil
+	}
+
+	pubTexts, _ := viper.Get(__PUBLIC_KEY_CONFIG_KEY).(string)
+	pubKeys, err := crypto.PublicKeysFromText(pubTexts)
+
+	if err != nil {
+		die(err)
+	}
+
+	for _, pub := range pubKeys {
+		hash, err := pub.Hash()
+
+		if err == nil && hash.Equals(crypto.PublicKeyHash(serverKeyHash)) {
+			return &pub
+		}
+	}
+
+	die(errors.Errorf("No public key for --server-key: %s", serverKeyHash))
+	return nil
+}
+
 func outputResponse(response api.Response) {
 	var err error
 	if queryBinary {
@@ -192,6 +233,10 @@ func parseReflect() (api.ReflectionType, error) {
 		return api.REFLECT_HEAD_PATH, nil
 	case "namespace":
 		return api.REFLECT_DUMP_NAMESPACE, nil
+	case "cache":
+		return api.REFLECT_CACHE_STATS, nil
+	case "journal":
+		return api.REFLECT_JOURNAL, nil
 	default:
 		return api.REFLECT_NOOP, fmt.Errorf("Unknown reflect type: %v", reflect)
 	}
@@ -201,7 +246,7 @@ func init() {
 	queryCmd.AddCommand(clientPlumbingCmd)
 
 	clientPlumbingCmd.Flags().StringVar(&replicate, "replicate", "", "Replicate index from hash")
-	clientPlumbingCmd.Flags().StringVar(&reflect, "reflect", "", "Reflect on server state. (index|head|namespace)")
+	clientPlumbingCmd.Flags().StringVar(&reflect, "reflect", "", "Reflect on server state. (index|head|namespace|cache|journal)")
 	clientPlumbingCmd.Flags().BoolVar(&queryBinary, "binary", false, "Output protocol buffer binary")
 	clientPlumbingCmd.Flags().BoolVar(&dryrun, "dryrun", false, "Don't send query to server")
 	clientPlumbingCmd.Flags().StringVar(&source, "query", "", "Godless NoSQL query text")
@@ -0,0 +1,194 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <indexA> <indexB>",
+	Short: "Show the changes between two godless indices",
+	Long: `Ask a godless server for the points added and removed between two index hashes.
+
+Points are listed per table, row and entry, along with the hashes of the public keys
+that signed them.  Signers that are not in your key list are shown as 'unknown'.
+
+	godless diff QmOldIndex QmNewIndex --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			err := cmd.Help()
+
+			if err != nil {
+				die(err)
+			}
+
+			return
+		}
+
+		readKeysFromViper()
+
+		client := makeClient()
+		request := api.MakeDiffRequest(crdt.IPFSPath(args[0]), crdt.IPFSPath(args[1]))
+		response, err := client.Send(request)
+
+		if err != nil {
+			die(err)
+		}
+
+		err = outputDiff(response)
+
+		if err != nil {
+			die(err)
+		}
+	},
+}
+
+type diffLine struct {
+	Change  string
+	Table   crdt.TableName
+	Row     crdt.RowName
+	Entry   crdt.EntryName
+	Point   crdt.PointText
+	Signers []string
+}
+
+func outputDiff(response api.Response) error {
+	switch diffFormat {
+	case "text":
+		return api.EncodeResponseText(response, os.Stdout)
+	case "json":
+		lines := makeDiffLines(response.Diff)
+		bs, err := json.MarshalIndent(lines, "", " ")
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(bs))
+		return nil
+	case "table":
+		return printDiffTable(makeDiffLines(response.Diff))
+	default:
+		return fmt.Errorf("Unknown diff format: %s", diffFormat)
+	}
+}
+
+func printDiffTable(lines []diffLine) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+
+	fmt.Fprintln(w, "\tTable\tRow\tEntry\tPoint\tSigners")
+	for _, line := range lines {
+		signers := strings.Join(line.Signers, ",")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%q\t%s\n", line.Change, line.Table, line.Row, line.Entry, line.Point, signers)
+	}
+
+	return w.Flush()
+}
+
+func makeDiffLines(diff crdt.NamespaceDiff) []diffLine {
+	lines := []diffLine{}
+	lines = appendDiffLines(lines, "-", diff.Removed)
+	lines = appendDiffLines(lines, "+", diff.Added)
+	return lines
+}
+
+func appendDiffLines(lines []diffLine, change string, namespace crdt.Namespace) []diffLine {
+	stream, invalid := crdt.MakeNamespaceStream(namespace)
+
+	if len(invalid) > 0 {
+		log.Warn("Ignoring %d invalid entries in diff", len(invalid))
+	}
+
+	var last *diffLine
+	for _, entry := range stream {
+		isSamePoint := last != nil
+		isSamePoint = isSamePoint && last.Table == entry.Table && last.Row == entry.Row
+		isSamePoint = isSamePoint && last.Entry == entry.Entry && last.Point == entry.Point.Text
+
+		if !isSamePoint {
+			lines = append(lines, diffLine{
+				Change:  change,
+				Table:   entry.Table,
+				Row:     entry.Row,
+				Entry:   entry.Entry,
+				Point:   entry.Point.Text,
+				Signers: []string{},
+			})
+			last = &lines[len(lines)-1]
+		}
+
+		if !crypto.IsNilSignature(entry.Point.Signature) {
+			last.Signers = append(last.Signers, findSigner(entry.Point))
+		}
+	}
+
+	return lines
+}
+
+func findSigner(point crdt.StreamPoint) string {
+	const unknown = "unknown"
+
+	sig, err := crypto.ParseSignature(point.Signature)
+
+	if err != nil {
+		return unknown
+	}
+
+	for _, pub := range keyStore.GetAllPublicKeys() {
+		ok, err := crypto.Verify(pub, []byte(point.Text), sig)
+
+		if err != nil || !ok {
+			continue
+		}
+
+		hash, err := pub.Hash()
+
+		if err != nil {
+			return unknown
+		}
+
+		return string(hash)
+	}
+
+	return unknown
+}
+
+var diffFormat string
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffFormat, "format", "table", "Output format (table|json|text)")
+	diffCmd.Flags().StringVar(&serverAddr, "server", __DEFAULT_QUERY_SERVER, "Server address")
+}
@@ -63,7 +63,7 @@ func init() {
 var keyStore api.KeyStore = lib.MakeKeyStore()
 
 func flushKeysToViper() {
-	privTexts, err := crypto.PrivateKeysAsText(keyStore.GetAllPrivateKeys())
+	privTexts, err := privateKeysConfigText(keyStore.GetAllPrivateKeys())
 
 	if err != nil {
 		die(err)
@@ -80,6 +80,9 @@ func flushKeysToViper() {
 }
 
 func readKeysFromViper() {
+	readAuthorityKeysFromViper()
+	readTablePoliciesFromViper()
+
 	maybePrivTexts := viper.Get(__PRIVATE_KEY_CONFIG_KEY)
 	maybePubTexts := viper.Get(__PUBLIC_KEY_CONFIG_KEY)
 
@@ -95,7 +98,7 @@ func readKeysFromViper() {
 		die(err)
 	}
 
-	privKeys, err := crypto.PrivateKeysFromText(privTexts)
+	privKeys, err := readPrivateKeysConfigText(privTexts)
 
 	if err != nil {
 		die(err)
@@ -116,6 +119,56 @@ func readKeysFromViper() {
 	}
 }
 
+// readAuthorityKeysFromViper loads the keys trusted to revoke other keys.
+func readAuthorityKeysFromViper() {
+	authorityTexts := viper.GetString(__AUTHORITY_KEY_CONFIG_KEY)
+
+	if authorityTexts == "" {
+		return
+	}
+
+	authorities, err := crypto.PublicKeysFromText(authorityTexts)
+
+	if err != nil {
+		die(err)
+	}
+
+	for _, pub := range authorities {
+		keyStore.PutAuthorityKey(pub)
+	}
+}
+
+// tablePolicyConfig is the config file form of a crypto.TablePolicy.
+type tablePolicyConfig struct {
+	Table         string
+	Signers       []string
+	MinSignatures int
+}
+
+// readTablePoliciesFromViper loads the keys allowed to write each table.
+func readTablePoliciesFromViper() {
+	configs := []tablePolicyConfig{}
+	err := viper.UnmarshalKey(__TABLE_POLICY_CONFIG_KEY, &configs)
+
+	if err != nil {
+		die(errors.Wrap(err, "Corrupt viper config for table policies"))
+	}
+
+	for _, config := range configs {
+		policy := crypto.TablePolicy{MinSignatures: config.MinSignatures}
+
+		for _, signer := range config.Signers {
+			policy.Signers = append(policy.Signers, crypto.PublicKeyHash(signer))
+		}
+
+		err := keyStore.PutTablePolicy(config.Table, policy)
+
+		if err != nil {
+			die(err)
+		}
+	}
+}
+
 func writeViperConfig() {
 	configFilePath := viper.ConfigFileUsed()
 
@@ -179,3 +232,5 @@ func homeConfigFilePath() string {
 
 const __PRIVATE_KEY_CONFIG_KEY = "PrivateKeys"
 const __PUBLIC_KEY_CONFIG_KEY = "PublicKeys"
+const __AUTHORITY_KEY_CONFIG_KEY = "AuthorityKeys"
+const __TABLE_POLICY_CONFIG_KEY = "TablePolicies"
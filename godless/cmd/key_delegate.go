@@ -0,0 +1,96 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+)
+
+// keyDelegateCmd represents the key delegate command
+var keyDelegateCmd = &cobra.Command{
+	Use:   "delegate",
+	Short: "Delegate trust to another godless key",
+	Long: `Print a query that publishes a delegation certificate.  Servers that trust the
+	issuer key, directly or through other delegations, then trust data signed by the
+	subject key, without changing their config.
+
+	godless query plumbing --query "$(godless key delegate --issuer ... --subject ...)"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readKeysFromViper()
+
+		if delegateIssuer == "" || delegateSubject == "" {
+			cmd.Help()
+			die(errors.New("Expected --issuer and --subject"))
+		}
+
+		issuer, err := keyStore.GetPrivateKey(crypto.PublicKeyHash(delegateIssuer))
+
+		if err != nil {
+			die(err)
+		}
+
+		subject, err := keyStore.GetPublicKey(crypto.PublicKeyHash(delegateSubject))
+
+		if err != nil {
+			die(err)
+		}
+
+		var expires time.Time
+		if delegateExpiry > 0 {
+			expires = time.Now().Add(delegateExpiry)
+		}
+
+		delegation, err := crypto.MakeDelegation(issuer, subject, delegateTables, expires)
+
+		if err != nil {
+			die(err)
+		}
+
+		text, err := crypto.SerializeDelegation(delegation)
+
+		if err != nil {
+			die(err)
+		}
+
+		fmt.Printf("join %s rows (@key=@\"%s\", %s=\"%s\")\n", crdt.DELEGATION_TABLE, delegateSubject, crdt.DELEGATION_ENTRY, text)
+	},
+}
+
+var delegateIssuer string
+var delegateSubject string
+var delegateTables []string
+var delegateExpiry time.Duration
+
+func init() {
+	keyCmd.AddCommand(keyDelegateCmd)
+
+	keyDelegateCmd.Flags().StringVar(&delegateIssuer, "issuer", "", "Hash of the private key that signs the delegation")
+	keyDelegateCmd.Flags().StringVar(&delegateSubject, "subject", "", "Hash of the public key that is trusted")
+	keyDelegateCmd.Flags().StringSliceVar(&delegateTables, "tables", []string{}, "Comma separated list of tables the subject may sign. Defaults to all tables.")
+	keyDelegateCmd.Flags().DurationVar(&delegateExpiry, "expiry", 0, "Time until the delegation expires. 0 for never.")
+}
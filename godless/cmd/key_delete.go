@@ -0,0 +1,63 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+// keyDeleteCmd represents the key delete command
+var keyDeleteCmd = &cobra.Command{
+	Use:   "delete <hash>",
+	Short: "Delete a godless key",
+	Long: `Remove the key with the hash from your config.  Deleting a private key cannot be
+	undone, so it needs --force.  Export the key first to keep a copy.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readKeysFromViper()
+
+		hash := readKeyHashArg(cmd, args)
+
+		_, err := keyStore.GetPrivateKey(hash)
+
+		if err == nil && !deleteForce {
+			die(errors.New("Refusing to delete private key without --force"))
+		}
+
+		err = keyStore.DeleteKey(hash)
+
+		if err != nil {
+			die(err)
+		}
+
+		flushKeysToViper()
+		writeViperConfig()
+	},
+}
+
+var deleteForce bool
+
+func init() {
+	keyCmd.AddCommand(keyDeleteCmd)
+
+	keyDeleteCmd.Flags().BoolVar(&deleteForce, "force", false, "Delete a private key")
+}
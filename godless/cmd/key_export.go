@@ -0,0 +1,126 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// keyExportCmd represents the key export command
+var keyExportCmd = &cobra.Command{
+	Use:   "export <hash>",
+	Short: "Export a godless key",
+	Long: `Print the public key with the hash.  With --private, print the private key instead,
+	encrypted with a key passphrase read from GODLESS_KEY_PASSPHRASE or the terminal.
+
+	godless key export --private --out key.txt <hash>`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readKeysFromViper()
+
+		hash := readKeyHashArg(cmd, args)
+
+		var text string
+		var err error
+		if exportPrivate {
+			text, err = exportPrivateKeyText(hash)
+		} else {
+			text, err = exportPublicKeyText(hash)
+		}
+
+		if err != nil {
+			die(err)
+		}
+
+		if exportKeyPath == "" {
+			fmt.Println(text)
+			return
+		}
+
+		err = ioutil.WriteFile(exportKeyPath, []byte(text+"\n"), 0600)
+
+		if err != nil {
+			die(err)
+		}
+	},
+}
+
+func exportPublicKeyText(hash crypto.PublicKeyHash) (string, error) {
+	pub, err := keyStore.GetPublicKey(hash)
+
+	if err != nil {
+		return "", err
+	}
+
+	return crypto.PublicKeysAsText([]crypto.PublicKey{pub})
+}
+
+func exportPrivateKeyText(hash crypto.PublicKeyHash) (string, error) {
+	priv, err := keyStore.GetPrivateKey(hash)
+
+	if err != nil {
+		return "", err
+	}
+
+	privKeys := []crypto.PrivateKey{priv}
+
+	if exportUnencrypted {
+		log.Warn("Exporting private key without a passphrase")
+		return crypto.PrivateKeysAsText(privKeys)
+	}
+
+	passphrase := readKeyPassphrase(true)
+
+	if len(passphrase) == 0 {
+		return "", errors.New("Empty key passphrase: use --unencrypted to export the private key as plain text")
+	}
+
+	return crypto.EncryptPrivateKeys(privKeys, passphrase)
+}
+
+// readKeyHashArg reads the key hash that is the only argument to the command.
+func readKeyHashArg(cmd *cobra.Command, args []string) crypto.PublicKeyHash {
+	if len(args) != 1 {
+		cmd.Help()
+		os.Exit(1)
+	}
+
+	return crypto.PublicKeyHash(args[0])
+}
+
+var exportPrivate bool
+var exportUnencrypted bool
+var exportKeyPath string
+
+func init() {
+	keyCmd.AddCommand(keyExportCmd)
+
+	keyExportCmd.Flags().BoolVar(&exportPrivate, "private", false, "Export the private key")
+	keyExportCmd.Flags().BoolVar(&exportUnencrypted, "unencrypted", false, "Export the private key without a passphrase")
+	keyExportCmd.Flags().StringVar(&exportKeyPath, "out", "", "Key file path. Defaults to stdout.")
+}
@@ -0,0 +1,117 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// keyImportCmd represents the key import command
+var keyImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import godless keys",
+	Long: `Add the keys made by 'godless key export' to your config.  The keys are read from
+	the file, or from stdin if there is no file.  Encrypted private keys are opened with the
+	key passphrase from GODLESS_KEY_PASSPHRASE or the terminal.
+
+	godless key import key.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readKeysFromViper()
+
+		if len(args) > 1 {
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		text := readImportText(args)
+		privKeys, pubKeys, err := parseImportText(text)
+
+		if err != nil {
+			die(err)
+		}
+
+		for _, priv := range privKeys {
+			err := keyStore.PutPrivateKey(priv)
+
+			if err != nil {
+				log.Warn("Skipping private key: %s", err.Error())
+				continue
+			}
+
+			printKeyHash(priv.GetPublicKey())
+		}
+
+		for _, pub := range pubKeys {
+			err := keyStore.PutPublicKey(pub)
+
+			if err != nil {
+				log.Warn("Skipping public key: %s", err.Error())
+				continue
+			}
+
+			printKeyHash(pub)
+		}
+
+		flushKeysToViper()
+		writeViperConfig()
+	},
+}
+
+func readImportText(args []string) string {
+	var bs []byte
+	var err error
+	if len(args) == 0 || args[0] == "-" {
+		bs, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		bs, err = ioutil.ReadFile(args[0])
+	}
+
+	if err != nil {
+		die(err)
+	}
+
+	return strings.TrimSpace(string(bs))
+}
+
+func parseImportText(text string) ([]crypto.PrivateKey, []crypto.PublicKey, error) {
+	if !crypto.IsEncryptedPrivateKeys(text) {
+		return crypto.ParseKeysText(text)
+	}
+
+	privKeys, err := crypto.DecryptPrivateKeys(text, readKeyPassphrase(false))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return privKeys, nil, nil
+}
+
+func init() {
+	keyCmd.AddCommand(keyImportCmd)
+}
@@ -22,9 +22,12 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
 	"github.com/johnny-morrice/godless/crypto"
 )
 
@@ -32,20 +35,32 @@ import (
 var keyListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List godless key hashes",
+	Long: `List the hashes of the keys in your config.  With --tables, also list the tables
+	that each key has signed in the index of the godless server.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		readKeysFromViper()
 
+		pubKeys := keyStore.GetAllPublicKeys()
+
 		if listPrivateOnly {
+			pubKeys = []crypto.PublicKey{}
 			for _, priv := range keyStore.GetAllPrivateKeys() {
-				pub := priv.GetPublicKey()
+				pubKeys = append(pubKeys, priv.GetPublicKey())
+			}
+		}
+
+		if !listTables {
+			for _, pub := range pubKeys {
 				printKeyHash(pub)
 			}
 
 			return
 		}
 
-		for _, pub := range keyStore.GetAllPublicKeys() {
-			printKeyHash(pub)
+		index := readServerIndex()
+
+		for _, pub := range pubKeys {
+			printKeyTables(pub, index)
 		}
 	},
 }
@@ -61,10 +76,47 @@ func printKeyHash(pub crypto.PublicKey) {
 	fmt.Println(string(hash))
 }
 
+func printKeyTables(pub crypto.PublicKey, index crdt.Index) {
+	hash, err := pub.Hash()
+
+	if err != nil {
+		die(err)
+	}
+
+	tables := index.TablesSignedBy(pub)
+	names := make([]string, len(tables))
+
+	for i, table := range tables {
+		names[i] = string(table)
+	}
+
+	fmt.Printf("%s\t%s\n", hash, strings.Join(names, ","))
+}
+
+// readServerIndex reflects on the index of the godless server.
+func readServerIndex() crdt.Index {
+	client := makeClient()
+	response, err := client.Send(api.MakeReflectRequest(api.REFLECT_INDEX))
+
+	if err != nil {
+		die(err)
+	}
+
+	if response.Err != nil {
+		die(response.Err)
+	}
+
+	return response.Index
+}
+
 var listPrivateOnly bool
+var listTables bool
 
 func init() {
 	keyCmd.AddCommand(keyListCmd)
 
 	keyListCmd.PersistentFlags().BoolVar(&listPrivateOnly, "private", false, "List private keys only")
+	keyListCmd.Flags().BoolVar(&listTables, "tables", false, "List the tables each key has signed")
+	keyListCmd.Flags().StringVar(&serverAddr, "server", __DEFAULT_QUERY_SERVER, "Server address")
+	keyListCmd.Flags().DurationVar(&queryTimeout, "timeout", __DEFAULT_QUERY_TIMEOUT, "Query timeout")
 }
@@ -0,0 +1,108 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+)
+
+// keyRevokeCmd represents the key revoke command
+var keyRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke a godless key",
+	Long: `Print a query that publishes a revocation for a leaked key.  Once the query is
+	run, servers stop trusting every signature by the key, including signatures made
+	before the revocation, since signatures do not record when they were made.
+
+	The revocation is signed by the key itself, or by --authority, which must be one of
+	the "AuthorityKeys" in the config of each server.
+
+	godless query plumbing --query "$(godless key revoke --hash ...)"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readKeysFromViper()
+
+		if revokeHash == "" {
+			cmd.Help()
+			die(errors.New("Expected --hash"))
+		}
+
+		revoked, err := keyStore.GetPublicKey(crypto.PublicKeyHash(revokeHash))
+
+		if err != nil {
+			die(err)
+		}
+
+		authorityHash := revokeAuthority
+		if authorityHash == "" {
+			authorityHash = revokeHash
+		}
+
+		authority, err := keyStore.GetPrivateKey(crypto.PublicKeyHash(authorityHash))
+
+		if err != nil {
+			die(err)
+		}
+
+		effective := time.Now()
+		if revokeEffective != "" {
+			effective, err = time.Parse(time.RFC3339, revokeEffective)
+
+			if err != nil {
+				die(err)
+			}
+		}
+
+		revocation, err := crypto.MakeRevocation(revoked, authority, effective, revokeReason)
+
+		if err != nil {
+			die(err)
+		}
+
+		text, err := crypto.SerializeRevocation(revocation)
+
+		if err != nil {
+			die(err)
+		}
+
+		fmt.Printf("join %s rows (@key=@\"%s\", %s=\"%s\")\n", crdt.REVOCATION_TABLE, revokeHash, crdt.REVOCATION_ENTRY, text)
+	},
+}
+
+var revokeHash string
+var revokeAuthority string
+var revokeReason string
+var revokeEffective string
+
+func init() {
+	keyCmd.AddCommand(keyRevokeCmd)
+
+	keyRevokeCmd.Flags().StringVar(&revokeHash, "hash", "", "Hash of the key to revoke")
+	keyRevokeCmd.Flags().StringVar(&revokeAuthority, "authority", "", "Hash of the authority key that signs the revocation. Defaults to the revoked key.")
+	keyRevokeCmd.Flags().StringVar(&revokeReason, "reason", "", "Reason for the revocation")
+	keyRevokeCmd.Flags().StringVar(&revokeEffective, "effective", "", "Time the revocation takes effect, in RFC3339 format. Defaults to now. Earlier signatures by the key are distrusted too.")
+}
@@ -0,0 +1,96 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/crypto"
+)
+
+// keyShowCmd represents the key show command
+var keyShowCmd = &cobra.Command{
+	Use:   "show <hash>",
+	Short: "Show details of a godless key",
+	Long: `Print the fingerprint of the key with the hash, and how your config trusts it.
+	Compare fingerprints with a peer before trusting their key.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readKeysFromViper()
+
+		hash := readKeyHashArg(cmd, args)
+
+		pub, err := keyStore.GetPublicKey(hash)
+
+		if err != nil {
+			die(err)
+		}
+
+		fingerprint, err := pub.Fingerprint()
+
+		if err != nil {
+			die(err)
+		}
+
+		_, privErr := keyStore.GetPrivateKey(hash)
+
+		fmt.Printf("Hash:        %s\n", hash)
+		fmt.Printf("Fingerprint: %s\n", fingerprint)
+		fmt.Printf("Private:     %t\n", privErr == nil)
+		fmt.Printf("Authority:   %t\n", isAuthorityKey(pub))
+		fmt.Printf("Revoked:     %t\n", keyStore.IsRevoked(pub))
+		fmt.Printf("Policies:    %s\n", strings.Join(policyTables(hash), ", "))
+	},
+}
+
+func isAuthorityKey(pub crypto.PublicKey) bool {
+	for _, authority := range keyStore.GetAllAuthorityKeys() {
+		if pub.Equals(authority) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policyTables lists the tables whose write policy names the key.
+func policyTables(hash crypto.PublicKeyHash) []string {
+	tables := []string{}
+
+	for table, policy := range keyStore.GetAllTablePolicies() {
+		for _, signer := range policy.Signers {
+			if signer.Equals(hash) {
+				tables = append(tables, table)
+				break
+			}
+		}
+	}
+
+	sort.Strings(tables)
+	return tables
+}
+
+func init() {
+	keyCmd.AddCommand(keyShowCmd)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/crypto"
+)
+
+// keyTrustCmd represents the key trust command
+var keyTrustCmd = &cobra.Command{
+	Use:   "trust <public key>",
+	Short: "Trust a peer's public key",
+	Long: `Add a public key to your config, so that data signed by its private key is trusted.
+	The public key is the text printed by 'godless key export' on the peer.
+
+	godless key trust "$(cat peer.pub)"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readKeysFromViper()
+
+		if len(args) != 1 {
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		privKeys, pubKeys, err := crypto.ParseKeysText(args[0])
+
+		if err != nil {
+			die(err)
+		}
+
+		if len(privKeys) > 0 {
+			die(errors.New("Expected a public key: use 'godless key import' for private keys"))
+		}
+
+		if len(pubKeys) != 1 {
+			die(errors.New("Expected one public key"))
+		}
+
+		err = keyStore.PutPublicKey(pubKeys[0])
+
+		if err != nil {
+			die(err)
+		}
+
+		printKeyHash(pubKeys[0])
+
+		flushKeysToViper()
+		writeViperConfig()
+	},
+}
+
+func init() {
+	keyCmd.AddCommand(keyTrustCmd)
+}
@@ -0,0 +1,186 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/peterh/liner"
+
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// configPassphrase encrypts the private keys in the config.  It is set once the keys are read, or a
+// new passphrase is chosen.
+var configPassphrase []byte
+
+var passphraseFile string
+
+// readPassphrase finds the passphrase for the private keys in the config, from the environment, the
+// passphrase file, or the terminal, in that order.
+func readPassphrase() []byte {
+	if passphrase, ok := os.LookupEnv(__PASSPHRASE_ENV); ok {
+		return []byte(passphrase)
+	}
+
+	if passphraseFile != "" {
+		bs, err := ioutil.ReadFile(passphraseFile)
+
+		if err != nil {
+			die(err)
+		}
+
+		return []byte(strings.TrimRight(string(bs), "\r\n"))
+	}
+
+	passphrase, err := promptPassphrase("Passphrase: ")
+
+	if err == liner.ErrNotTerminalOutput {
+		die(errors.New("Private keys are encrypted: set " + __PASSPHRASE_ENV + " or --passphrase-file"))
+	}
+
+	if err != nil {
+		die(err)
+	}
+
+	return []byte(passphrase)
+}
+
+// readNewPassphrase chooses a passphrase for the private keys in the config.  An empty passphrase
+// leaves them unencrypted.
+func readNewPassphrase() []byte {
+	if passphrase, ok := os.LookupEnv(__NEW_PASSPHRASE_ENV); ok {
+		return []byte(passphrase)
+	}
+
+	passphrase, err := promptPassphrase("New passphrase: ")
+
+	if err == liner.ErrNotTerminalOutput {
+		log.Warn("No terminal to read a new passphrase: set %s to encrypt private keys", __NEW_PASSPHRASE_ENV)
+		return []byte{}
+	}
+
+	if err != nil {
+		die(err)
+	}
+
+	confirm, err := promptPassphrase("Repeat new passphrase: ")
+
+	if err != nil {
+		die(err)
+	}
+
+	if passphrase != confirm {
+		die(errors.New("Passphrases did not match"))
+	}
+
+	return []byte(passphrase)
+}
+
+// readKeyPassphrase finds the passphrase for exported private keys, from the environment or the
+// terminal.  A new passphrase is asked for twice.
+func readKeyPassphrase(isNew bool) []byte {
+	if passphrase, ok := os.LookupEnv(__KEY_PASSPHRASE_ENV); ok {
+		return []byte(passphrase)
+	}
+
+	passphrase, err := promptPassphrase("Key passphrase: ")
+
+	if err == liner.ErrNotTerminalOutput {
+		die(errors.New("No terminal to read the key passphrase: set " + __KEY_PASSPHRASE_ENV))
+	}
+
+	if err != nil {
+		die(err)
+	}
+
+	if !isNew {
+		return []byte(passphrase)
+	}
+
+	confirm, err := promptPassphrase("Repeat key passphrase: ")
+
+	if err != nil {
+		die(err)
+	}
+
+	if passphrase != confirm {
+		die(errors.New("Passphrases did not match"))
+	}
+
+	return []byte(passphrase)
+}
+
+func promptPassphrase(prompt string) (string, error) {
+	line := liner.NewLiner()
+	defer line.Close()
+
+	return line.PasswordPrompt(prompt)
+}
+
+// privateKeysConfigText encrypts the private keys for the config, choosing a new passphrase if
+// there is none yet.
+func privateKeysConfigText(privKeys []crypto.PrivateKey) (string, error) {
+	if configPassphrase == nil {
+		configPassphrase = readNewPassphrase()
+	}
+
+	if len(configPassphrase) == 0 {
+		log.Warn("Writing private keys without a passphrase")
+		return crypto.PrivateKeysAsText(privKeys)
+	}
+
+	return crypto.EncryptPrivateKeys(privKeys, configPassphrase)
+}
+
+// readPrivateKeysConfigText reads the private keys in the config, asking for the passphrase if
+// they are encrypted.
+func readPrivateKeysConfigText(text string) ([]crypto.PrivateKey, error) {
+	if !crypto.IsEncryptedPrivateKeys(text) {
+		if text != "" {
+			log.Warn("Private keys in config are not encrypted: run 'godless key passwd' to set a passphrase")
+		}
+
+		return crypto.PrivateKeysFromText(text)
+	}
+
+	passphrase := readPassphrase()
+	privKeys, err := crypto.DecryptPrivateKeys(text, passphrase)
+
+	if err != nil {
+		return nil, err
+	}
+
+	configPassphrase = passphrase
+	return privKeys, nil
+}
+
+func init() {
+	RootCmd.PersistentFlags().StringVar(&passphraseFile, "passphrase-file", "", "File holding the passphrase for encrypted private keys")
+}
+
+const __PASSPHRASE_ENV = "GODLESS_PASSPHRASE"
+const __NEW_PASSPHRASE_ENV = "GODLESS_NEW_PASSPHRASE"
+const __KEY_PASSPHRASE_ENV = "GODLESS_KEY_PASSPHRASE"
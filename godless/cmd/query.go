@@ -40,10 +40,12 @@ func init() {
 
 	queryCmd.PersistentFlags().StringVar(&serverAddr, "server", __DEFAULT_QUERY_SERVER, "Server address")
 	queryCmd.PersistentFlags().DurationVar(&queryTimeout, "timeout", __DEFAULT_QUERY_TIMEOUT, "Query timeout")
+	queryCmd.PersistentFlags().StringVar(&serverKeyHash, "server-key", "", "Hash of a public key that must sign server responses")
 }
 
 var serverAddr string
 var queryTimeout time.Duration
+var serverKeyHash string
 
 const __DEFAULT_QUERY_TIMEOUT = time.Minute
 const __DEFAULT_QUERY_SERVER = "http://localhost:8085"
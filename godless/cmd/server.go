@@ -30,8 +30,11 @@ import (
 	lib "github.com/johnny-morrice/godless"
 	"github.com/johnny-morrice/godless/api"
 	"github.com/johnny-morrice/godless/cache"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/datapeer"
 	"github.com/johnny-morrice/godless/http"
 	"github.com/johnny-morrice/godless/log"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -59,21 +62,38 @@ func serve(cmd *cobra.Command) {
 		die(err)
 	}
 
+	peer := makeStoreDataPeer(datapeer.IpfsWebServiceOptions{Http: client})
+
+	if gossipAddr != "" || len(gossipPeers) > 0 {
+		peer = makeGossipPeer(peer)
+	}
+
+	dag := makeDagStorage(peer)
+
 	options := lib.Options{
-		IpfsServiceUrl:    ipfsService,
-		WebServiceAddr:    addr,
-		IndexHash:         hash,
-		FailEarly:         earlyConnect,
-		ReplicateInterval: interval,
-		Topics:            topics,
-		ApiConcurrency:    apiQueryLimit,
-		KeyStore:          keyStore,
-		PublicServer:      publicServer,
-		IpfsClient:        client,
-		Pulse:             pulse,
-		PriorityQueue:     queue,
-		Cache:             cache,
-		MemoryImage:       memimg,
+		DataPeer:           peer,
+		DagStorage:         dag,
+		WebServiceAddr:     addr,
+		IndexHash:          hash,
+		FailEarly:          earlyConnect,
+		ReplicateInterval:  interval,
+		Topics:             topics,
+		ApiConcurrency:     apiQueryLimit,
+		KeyStore:           keyStore,
+		PublicServer:       publicServer,
+		Pulse:              pulse,
+		PriorityQueue:      queue,
+		Cache:              cache,
+		MemoryImage:        memimg,
+		Codec:              readStoreCodec(),
+		BlobThreshold:      blobThreshold,
+		Journal:            makeJournal(),
+		VerifyCacheSize:    verifyCacheSize,
+		VerifiedMarkers:    verifiedMarkers,
+		SignNamespaces:     signNamespaces,
+		ResponseKey:        makeResponseKey(),
+		AllowedRequestKeys: readAllowedRequestKeys(),
+		MaxRequestAge:      maxRequestAge,
 	}
 
 	godless, err := lib.New(options)
@@ -97,11 +117,29 @@ var pulse time.Duration
 var earlyConnect bool
 var apiQueryLimit int
 var apiQueueLength int
+var queueType string
+var queueSpill bool
+var blobThreshold int
+var useJournal bool
+var verifyCacheSize int
+var verifiedMarkers bool
+var signNamespaces bool
+var signResponses bool
+var allowedRequestKeys []string
+var maxRequestAge time.Duration
+var useDag bool
+var gossipAddr string
+var gossipPeers []string
 var memoryBufferLength int
+var indexCacheBytes int64
+var namespaceCacheBytes int64
 var publicServer bool
 var serverTimeout time.Duration
 var cacheType string
 var databaseFilePath string
+var cacheMaxBytes int64
+var cacheEviction string
+var cacheSweep time.Duration
 var boltFactory *cache.BoltFactory
 
 func makeCache(cmd *cobra.Command) (api.Cache, error) {
@@ -125,7 +163,15 @@ func makeBoltCache() (api.Cache, error) {
 }
 
 func makeMemoryCache() (api.Cache, error) {
-	memCache := cache.MakeResidentMemoryCache(memoryBufferLength, memoryBufferLength)
+	indexOptions := cache.ResidentCacheOptions{
+		MaxItems: memoryBufferLength,
+		MaxBytes: indexCacheBytes,
+	}
+	namespaceOptions := cache.ResidentCacheOptions{
+		MaxItems: memoryBufferLength,
+		MaxBytes: namespaceCacheBytes,
+	}
+	memCache := cache.MakeBudgetedResidentMemoryCache(indexOptions, namespaceOptions)
 	return memCache, nil
 }
 
@@ -137,8 +183,11 @@ func makeMemoryImage() (api.MemoryImage, error) {
 func getBoltFactoryInstance() *cache.BoltFactory {
 	if boltFactory == nil {
 		options := cache.BoltOptions{
-			FilePath: databaseFilePath,
-			Mode:     0600,
+			FilePath:       databaseFilePath,
+			Mode:           0600,
+			MaxBytes:       cacheMaxBytes,
+			EvictionPolicy: parseEvictionPolicy(),
+			SweepInterval:  cacheSweep,
 		}
 		factory, err := cache.MakeBoltCacheFactory(options)
 
@@ -152,6 +201,85 @@ func getBoltFactoryInstance() *cache.BoltFactory {
 	return boltFactory
 }
 
+func parseEvictionPolicy() cache.EvictionPolicy {
+	switch cacheEviction {
+	case "", __LRU_EVICTION:
+		return cache.EVICT_LRU
+	case __LFU_EVICTION:
+		return cache.EVICT_LFU
+	default:
+		die(fmt.Errorf("Unknown cache eviction policy: '%s'", cacheEviction))
+	}
+
+	return cache.EVICT_LRU
+}
+
+func makeDagStorage(peer api.PinningDataPeer) api.DagStorage {
+	if !useDag {
+		return nil
+	}
+
+	dag, ok := peer.(api.DagStorage)
+
+	if !ok {
+		die(errors.New("DAG storage requires the ipfs datapeer"))
+	}
+
+	return dag
+}
+
+// makeGossipPeer replicates directly with the --gossip-peers, and with anyone in the public key list
+// who dials in.
+func makeGossipPeer(storage api.PinningDataPeer) api.PinningDataPeer {
+	privateKeys := keyStore.GetAllPrivateKeys()
+
+	if len(privateKeys) == 0 {
+		die(errors.New("Gossip requires a private key"))
+	}
+
+	options := datapeer.GossipOptions{
+		ListenAddr:  gossipAddr,
+		Peers:       gossipPeers,
+		PrivateKey:  privateKeys[0],
+		TrustedKeys: keyStore.GetAllPublicKeys(),
+		Storage:     storage,
+	}
+
+	return datapeer.MakeGossipDataPeer(datapeer.MakeGossipPeer(options))
+}
+
+// makeResponseKey finds the key that signs webservice responses, if --sign-responses is given.
+func makeResponseKey() *crypto.PrivateKey {
+	if !signResponses {
+		return nil
+	}
+
+	privateKeys := keyStore.GetAllPrivateKeys()
+
+	if len(privateKeys) == 0 {
+		die(errors.New("Signing responses requires a private key"))
+	}
+
+	return &privateKeys[0]
+}
+
+// readAllowedRequestKeys finds the --allowed-keys in the key store.
+func readAllowedRequestKeys() []crypto.PublicKey {
+	keys := make([]crypto.PublicKey, 0, len(allowedRequestKeys))
+
+	for _, hash := range allowedRequestKeys {
+		pub, err := keyStore.GetPublicKey(crypto.PublicKeyHash(hash))
+
+		if err != nil {
+			die(errors.Wrap(err, "No public key for --allowed-keys"))
+		}
+
+		keys = append(keys, pub)
+	}
+
+	return keys
+}
+
 func shutdownOnTrap(godless *lib.Godless) {
 	onTrap(func(signal os.Signal) {
 		log.Warn("Caught signal: %s", signal.String())
@@ -167,7 +295,48 @@ func onTrap(handler func(signal os.Signal)) {
 }
 
 func makePriorityQueue(cmd *cobra.Command) api.RequestPriorityQueue {
-	return cache.MakeResidentBufferQueue(apiQueueLength)
+	switch queueType {
+	case __MEMORY_CACHE_TYPE:
+		return cache.MakeResidentBufferQueue(apiQueueLength)
+	case __BOLT_CACHE_TYPE:
+		return makeBoltQueue()
+	default:
+		err := fmt.Errorf("Unknown queue: '%s'", queueType)
+		cmd.Help()
+		die(err)
+	}
+
+	return nil
+}
+
+func makeBoltQueue() api.RequestPriorityQueue {
+	factory := getBoltFactoryInstance()
+	options := cache.BoltQueueOptions{
+		BufferSize: apiQueueLength,
+		Spill:      queueSpill,
+	}
+
+	queue, err := factory.MakePriorityQueue(options)
+
+	if err != nil {
+		die(err)
+	}
+
+	return queue
+}
+
+func makeJournal() api.JoinJournal {
+	if !useJournal {
+		return nil
+	}
+
+	journal, err := getBoltFactoryInstance().MakeJoinJournal()
+
+	if err != nil {
+		die(err)
+	}
+
+	return journal
 }
 
 func shutdown(godless *lib.Godless) {
@@ -187,14 +356,36 @@ func init() {
 	serveCmd.PersistentFlags().BoolVar(&publicServer, "public", __DEFAULT_SERVER_PUBLIC_STATUS, "Don't limit pubsub updates to the public key list")
 	serveCmd.PersistentFlags().DurationVar(&serverTimeout, "timeout", __DEFAULT_SERVER_TIMEOUT, "Timeout for serverside HTTP queries")
 	serveCmd.PersistentFlags().IntVar(&apiQueueLength, "qlength", __DEFAULT_QUEUE_LENGTH, "API Priority queue length")
+	serveCmd.PersistentFlags().StringVar(&queueType, "queue", __DEFAULT_QUEUE_TYPE, "API Priority queue type (disk|memory)")
+	serveCmd.PersistentFlags().BoolVar(&queueSpill, "qspill", __DEFAULT_QUEUE_SPILL, "Keep requests beyond the queue length on disk instead of rejecting them")
 	serveCmd.PersistentFlags().StringVar(&cacheType, "cache", __DEFAULT_CACHE_TYPE, "Cache type (disk|memory)")
 	serveCmd.PersistentFlags().IntVar(&memoryBufferLength, "buffer", __DEFAULT_MEMORY_BUFFER_LENGTH, "Buffer length if using memory cache")
+	serveCmd.PersistentFlags().Int64Var(&indexCacheBytes, "index-cache-bytes", 0, "Byte budget for indices if using memory cache. 0 for no limit.")
+	serveCmd.PersistentFlags().Int64Var(&namespaceCacheBytes, "namespace-cache-bytes", 0, "Byte budget for namespaces if using memory cache. 0 for no limit.")
 	serveCmd.PersistentFlags().StringVar(&databaseFilePath, "dbpath", __DEFAULT_BOLT_DB_PATH, "Embedded database file path")
+	serveCmd.PersistentFlags().StringVar(&pinLedgerPath, "pin-ledger", "", __PIN_LEDGER_USAGE)
+	serveCmd.PersistentFlags().Int64Var(&cacheMaxBytes, "cache-bytes", __DEFAULT_CACHE_MAX_BYTES, "Byte budget for cached indices and namespaces in the embedded database. 0 for no limit.")
+	serveCmd.PersistentFlags().StringVar(&cacheEviction, "cache-evict", __LRU_EVICTION, "Embedded database eviction policy (lru|lfu)")
+	serveCmd.PersistentFlags().DurationVar(&cacheSweep, "cache-sweep", __DEFAULT_CACHE_SWEEP, "Interval between embedded database evictions")
+	serveCmd.PersistentFlags().BoolVar(&useJournal, "journal", false, "Journal joins in the embedded database while IPFS is down")
+	serveCmd.PersistentFlags().IntVar(&verifyCacheSize, "verify-cache", crypto.DEFAULT_VERIFY_CACHE_SIZE, "Number of signature verifications to remember. < 0 to disable.")
+	serveCmd.PersistentFlags().BoolVar(&verifiedMarkers, "verified-markers", false, "Mark fully verified namespaces in the cache so they are not verified again")
+	serveCmd.PersistentFlags().StringSliceVar(&allowedRequestKeys, "allowed-keys", []string{}, "Comma separated list of public key hashes that may send requests.  If empty, requests are not authenticated")
+	serveCmd.PersistentFlags().DurationVar(&maxRequestAge, "max-request-age", http.DEFAULT_MAX_REQUEST_AGE, "Clock difference allowed for signed requests")
+	serveCmd.PersistentFlags().BoolVar(&signResponses, "sign-responses", false, "Sign webservice responses with the first private key")
+	serveCmd.PersistentFlags().BoolVar(&signNamespaces, "sign-namespaces", false, "Sign each joined namespace once instead of signing every point")
+	serveCmd.PersistentFlags().BoolVar(&useDag, "dag", false, "Store data as linked IPLD DAG nodes")
+	serveCmd.PersistentFlags().StringVar(&gossipAddr, "gossip", "", "Listen address for direct replication with other godless servers")
+	serveCmd.PersistentFlags().StringSliceVar(&gossipPeers, "gossip-peers", []string{}, "Comma separated list of godless servers to replicate with directly")
+	serveCmd.PersistentFlags().IntVar(&blobThreshold, "blob-threshold", __DEFAULT_BLOB_THRESHOLD, "Store point values longer than this in chunked blobs. 0 to disable.")
 }
 
 const __MEMORY_CACHE_TYPE = "memory"
 const __BOLT_CACHE_TYPE = "disk"
 
+const __LRU_EVICTION = "lru"
+const __LFU_EVICTION = "lfu"
+
 const __DEFAULT_BOLT_DB_PATH = "godless.bolt"
 const __DEFAULT_EARLY_CONNECTION = false
 const __DEFAULT_SERVER_PUBLIC_STATUS = false
@@ -202,6 +393,11 @@ const __DEFAULT_CACHE_TYPE = __BOLT_CACHE_TYPE
 const __DEFAULT_LISTEN_ADDR = "localhost:8085"
 const __DEFAULT_SERVER_TIMEOUT = time.Minute * 10
 const __DEFAULT_QUEUE_LENGTH = 4096
+const __DEFAULT_QUEUE_TYPE = __MEMORY_CACHE_TYPE
+const __DEFAULT_QUEUE_SPILL = false
 const __DEFAULT_PULSE = time.Second * 10
 const __DEFAULT_REPLICATION_INTERVAL = time.Minute
 const __DEFAULT_MEMORY_BUFFER_LENGTH = -1
+const __DEFAULT_CACHE_MAX_BYTES = 1024 * 1024 * 1024
+const __DEFAULT_CACHE_SWEEP = time.Minute
+const __DEFAULT_BLOB_THRESHOLD = 64 * 1024
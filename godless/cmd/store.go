@@ -13,7 +13,18 @@
 
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/datapeer"
+)
 
 // storeCmd represents the store command
 var storeCmd = &cobra.Command{
@@ -34,6 +45,65 @@ var storeCmd = &cobra.Command{
 var hash string
 var topics []string
 var ipfsService string
+var dataPeerSpec string
+var pinLedgerPath string
+
+// readStoreCodec reads the codec from the --codec flag, or the config file.
+func readStoreCodec() crdt.Codec {
+	codec, err := crdt.ParseCodec(viper.GetString(__CODEC_CONFIG_KEY))
+
+	if err != nil {
+		die(err)
+	}
+
+	return codec
+}
+
+// makeStoreDataPeer creates the DataPeer named by the --datapeer flag.  Blocks added through it
+// are recorded in the pin ledger, so that 'godless store gc' may later unpin them.
+func makeStoreDataPeer(ipfsOptions datapeer.IpfsWebServiceOptions) api.PinningDataPeer {
+	return datapeer.MakeRecordingDataPeer(makeBackingDataPeer(ipfsOptions), makePinLedger())
+}
+
+func makePinLedger() api.PinLedger {
+	return datapeer.MakeFilePinLedger(pinLedgerFilePath())
+}
+
+// pinLedgerFilePath is the --pin-ledger flag, or a file beside the config file.  The server and
+// 'godless store gc' must share a ledger, so the default does not depend on the working directory.
+func pinLedgerFilePath() string {
+	if pinLedgerPath != "" {
+		return pinLedgerPath
+	}
+
+	configFilePath := viper.ConfigFileUsed()
+
+	if configFilePath == "" {
+		configFilePath = homeConfigFilePath()
+	}
+
+	return filepath.Join(filepath.Dir(configFilePath), __PIN_LEDGER_FILE_NAME)
+}
+
+func makeBackingDataPeer(ipfsOptions datapeer.IpfsWebServiceOptions) api.PinningDataPeer {
+	if dataPeerSpec == __IPFS_DATAPEER {
+		ipfsOptions.Url = ipfsService
+		return datapeer.MakeIpfsWebService(ipfsOptions)
+	}
+
+	if strings.HasPrefix(dataPeerSpec, __FILESYSTEM_DATAPEER_PREFIX) {
+		dir := strings.TrimPrefix(dataPeerSpec, __FILESYSTEM_DATAPEER_PREFIX)
+
+		if dir == "" {
+			die(fmt.Errorf("No directory in datapeer: '%s'", dataPeerSpec))
+		}
+
+		return datapeer.MakeFilesystemDataPeer(datapeer.FilesystemStorageOptions{Dir: dir})
+	}
+
+	die(fmt.Errorf("Unknown datapeer: '%s'", dataPeerSpec))
+	return nil
+}
 
 func init() {
 	RootCmd.AddCommand(storeCmd)
@@ -41,4 +111,16 @@ func init() {
 	storeCmd.PersistentFlags().StringVar(&hash, "hash", "", "IPFS hash")
 	storeCmd.PersistentFlags().StringSliceVar(&topics, "topics", []string{}, "Comma separated list of pubsub topics")
 	storeCmd.PersistentFlags().StringVar(&ipfsService, "ipfs", "http://localhost:5001", "IPFS webservice URL")
+	storeCmd.PersistentFlags().StringVar(&dataPeerSpec, "datapeer", __IPFS_DATAPEER, "Backing store (ipfs|fs:/path/to/dir)")
+	storeCmd.PersistentFlags().StringVar(&pinLedgerPath, "pin-ledger", "", __PIN_LEDGER_USAGE)
+	storeCmd.PersistentFlags().String("codec", __DEFAULT_CODEC, "Compression for new IPFS data (none|snappy|zstd)")
+
+	viper.BindPFlag(__CODEC_CONFIG_KEY, storeCmd.PersistentFlags().Lookup("codec"))
 }
+
+const __CODEC_CONFIG_KEY = "Codec"
+const __DEFAULT_CODEC = "none"
+const __IPFS_DATAPEER = "ipfs"
+const __PIN_LEDGER_FILE_NAME = __CONFIG_FILE_NAME + ".pins"
+const __PIN_LEDGER_USAGE = "File recording the blocks godless has pinned (default is .godless.pins beside the config file)"
+const __FILESYSTEM_DATAPEER_PREFIX = "fs:"
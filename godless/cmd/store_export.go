@@ -0,0 +1,120 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/datapeer"
+	"github.com/johnny-morrice/godless/internal/service"
+)
+
+// storeExportCmd represents the export command
+var storeExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export godless data to a snapshot archive",
+	Long: `Write the index at HEAD, and all the namespaces it links to, to a tar archive.
+
+The archive contains a manifest of content digests, signed with your private keys.
+HEAD is read from --hash, or from the embedded database if --hash is not given.
+
+	godless store export --out db.tar`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readKeysFromViper()
+
+		if exportPath == "" {
+			die(errors.New("Must specify --out"))
+		}
+
+		head := crdt.IPFSPath(hash)
+
+		if crdt.IsNilPath(head) {
+			head = readBoltHead()
+		}
+
+		output, err := os.Create(exportPath)
+
+		if err != nil {
+			die(err)
+		}
+
+		defer output.Close()
+
+		options := service.SnapshotExportOptions{
+			Store:  connectSnapshotStore(),
+			Head:   head,
+			Keys:   keyStore.GetAllPrivateKeys(),
+			Output: output,
+		}
+
+		err = service.ExportSnapshot(options)
+
+		if err != nil {
+			die(err)
+		}
+	},
+}
+
+func readBoltHead() crdt.IPFSPath {
+	cache, err := makeBoltCache()
+
+	if err != nil {
+		die(err)
+	}
+
+	head, err := cache.GetHead()
+
+	if err != nil {
+		die(err)
+	}
+
+	return head
+}
+
+func connectSnapshotStore() api.RemoteStore {
+	peer := makeStoreDataPeer(datapeer.IpfsWebServiceOptions{})
+	store := &service.ContentAddressableRemoteStore{
+		Shell: peer,
+		Codec: readStoreCodec(),
+	}
+
+	err := store.Connect()
+
+	if err != nil {
+		die(err)
+	}
+
+	return store
+}
+
+var exportPath string
+
+func init() {
+	storeCmd.AddCommand(storeExportCmd)
+
+	storeExportCmd.Flags().StringVar(&exportPath, "out", "", "Snapshot archive file path")
+	storeExportCmd.Flags().StringVar(&databaseFilePath, "dbpath", __DEFAULT_BOLT_DB_PATH, "Embedded database file path")
+}
@@ -0,0 +1,140 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/datapeer"
+	"github.com/johnny-morrice/godless/internal/service"
+)
+
+// storeGcCmd represents the gc command
+var storeGcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Pin live godless data and unpin dead data",
+	Long: `Walk the godless data reachable from HEAD and any retained indices.
+
+Live blocks are pinned in IPFS.  Dead blocks are unpinned, so that they may be removed
+by 'ipfs repo gc'.  Only blocks that godless recorded in its --pin-ledger are ever
+unpinned, and blocks added while the collection runs are kept.  The server must use the
+same --pin-ledger, which by default sits beside the config file.
+
+If --hash is not given, HEAD and the unsaved index are read from a running godless server.
+
+To see what would change, without changing anything, do:
+
+	godless store gc --dryrun`,
+	Run: func(cmd *cobra.Command, args []string) {
+		options := service.GarbageCollectorOptions{
+			Head:    crdt.IPFSPath(hash),
+			History: parsePaths(gcRetain),
+			DryRun:  gcDryRun,
+		}
+
+		if crdt.IsNilPath(options.Head) {
+			options.Head, options.Indices = reflectServerHead()
+		}
+
+		peer := makeStoreDataPeer(datapeer.IpfsWebServiceOptions{})
+		err := peer.Connect()
+
+		if err != nil {
+			die(err)
+		}
+
+		options.Store = service.MakeContentAddressableRemoteStore(peer)
+		options.Pinner = peer
+		options.Ledger = makePinLedger()
+
+		report, err := service.CollectGarbage(options)
+
+		if err != nil {
+			die(err)
+		}
+
+		printGarbageReport(report)
+	},
+}
+
+func reflectServerHead() (crdt.IPFSPath, []crdt.Index) {
+	readKeysFromViper()
+	client := makeClient()
+
+	headResp, err := client.Send(api.MakeReflectRequest(api.REFLECT_HEAD_PATH))
+
+	if err != nil {
+		die(err)
+	}
+
+	indexResp, err := client.Send(api.MakeReflectRequest(api.REFLECT_INDEX))
+
+	if err != nil {
+		die(err)
+	}
+
+	return headResp.Path, []crdt.Index{indexResp.Index}
+}
+
+func parsePaths(text []string) []crdt.IPFSPath {
+	paths := make([]crdt.IPFSPath, len(text))
+
+	for i, t := range text {
+		paths[i] = crdt.IPFSPath(t)
+	}
+
+	return paths
+}
+
+func printGarbageReport(report service.GarbageReport) {
+	if report.DryRun {
+		fmt.Println("Dry run: no pins were changed")
+	}
+
+	printPathList("Live", report.Live)
+	printPathList("Pinned", report.Pinned)
+	printPathList("Unpinned", report.Unpinned)
+	printPathList("Recent", report.Recent)
+	printPathList("Ignored", report.Ignored)
+}
+
+func printPathList(title string, paths []crdt.IPFSPath) {
+	fmt.Printf("%s (%d):\n", title, len(paths))
+
+	for _, p := range paths {
+		fmt.Printf("\t%s\n", p)
+	}
+}
+
+var gcRetain []string
+var gcDryRun bool
+
+func init() {
+	storeCmd.AddCommand(storeGcCmd)
+
+	storeGcCmd.Flags().StringSliceVar(&gcRetain, "retain", []string{}, "Comma separated list of older index hashes to keep")
+	storeGcCmd.Flags().BoolVar(&gcDryRun, "dryrun", false, "Report without changing pins")
+	storeGcCmd.Flags().StringVar(&serverAddr, "server", __DEFAULT_QUERY_SERVER, "Server address, used to find HEAD if --hash is not given")
+}
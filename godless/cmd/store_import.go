@@ -0,0 +1,91 @@
+// Copyright © 2017 Johnny Morrice <john@functorama.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/johnny-morrice/godless/internal/service"
+)
+
+// storeImportCmd represents the import command
+var storeImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import godless data from a snapshot archive",
+	Long: `Load a snapshot archive, created by 'godless store export', into IPFS.
+
+The archive contents are verified against the manifest, and the index is joined into
+the embedded database, so that it will be served by the next 'godless store server'.
+
+	godless store import --in db.tar`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readKeysFromViper()
+
+		if importPath == "" {
+			die(errors.New("Must specify --in"))
+		}
+
+		input, err := os.Open(importPath)
+
+		if err != nil {
+			die(err)
+		}
+
+		defer input.Close()
+
+		memimg, err := makeMemoryImage()
+
+		if err != nil {
+			die(err)
+		}
+
+		options := service.SnapshotImportOptions{
+			Store:       connectSnapshotStore(),
+			MemoryImage: memimg,
+			KeyStore:    keyStore,
+			Input:       input,
+			IsPublic:    importPublic,
+		}
+
+		head, err := service.ImportSnapshot(options)
+
+		if err != nil {
+			die(err)
+		}
+
+		fmt.Println(head)
+	},
+}
+
+var importPath string
+var importPublic bool
+
+func init() {
+	storeCmd.AddCommand(storeImportCmd)
+
+	storeImportCmd.Flags().StringVar(&importPath, "in", "", "Snapshot archive file path")
+	storeImportCmd.Flags().BoolVar(&importPublic, "public", false, "Accept snapshots that are not signed by a known public key")
+	storeImportCmd.Flags().StringVar(&databaseFilePath, "dbpath", __DEFAULT_BOLT_DB_PATH, "Embedded database file path")
+}
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	gohttp "net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crypto"
+)
+
+const AUTH_KEY_HEADER = "X-Godless-Key"
+const AUTH_TIMESTAMP_HEADER = "X-Godless-Timestamp"
+const AUTH_NONCE_HEADER = "X-Godless-Nonce"
+const AUTH_SIGNATURE_HEADER = "X-Godless-Signature"
+
+// RequestAuth is a signature over an encoded api.Request, sent in the HTTP headers.  The timestamp
+// and nonce stop the request being replayed.
+type RequestAuth struct {
+	KeyHash   crypto.PublicKeyHash
+	Timestamp time.Time
+	Nonce     string
+	Signature crypto.Signature
+}
+
+// SignRequest signs the encoded api.Request.
+func SignRequest(body []byte, priv crypto.PrivateKey, now time.Time) (RequestAuth, error) {
+	const failMsg = "SignRequest failed"
+
+	hash, err := priv.GetPublicKey().Hash()
+
+	if err != nil {
+		return RequestAuth{}, errors.Wrap(err, failMsg)
+	}
+
+	nonce := make([]byte, __NONCE_LENGTH)
+	_, err = rand.Read(nonce)
+
+	if err != nil {
+		return RequestAuth{}, errors.Wrap(err, failMsg)
+	}
+
+	auth := RequestAuth{
+		KeyHash:   hash,
+		Timestamp: time.Unix(now.Unix(), 0),
+		Nonce:     hex.EncodeToString(nonce),
+	}
+
+	auth.Signature, err = crypto.Sign(priv, auth.signedText(body))
+
+	if err != nil {
+		return RequestAuth{}, errors.Wrap(err, failMsg)
+	}
+
+	return auth, nil
+}
+
+// Verify is true if the key signed the encoded api.Request with this timestamp and nonce.
+func (auth RequestAuth) Verify(body []byte, pub crypto.PublicKey) bool {
+	ok, err := crypto.Verify(pub, auth.signedText(body), auth.Signature)
+	return err == nil && ok
+}
+
+func (auth RequestAuth) signedText(body []byte) []byte {
+	fields := []string{
+		string(auth.KeyHash),
+		strconv.FormatInt(auth.Timestamp.Unix(), 10),
+		auth.Nonce,
+		api.RequestHash(body),
+	}
+
+	text := ""
+	for _, field := range fields {
+		text += fmt.Sprintf("%d:%s", len(field), field)
+	}
+
+	return []byte(text)
+}
+
+func (auth RequestAuth) writeHeaders(header gohttp.Header) error {
+	sigText, err := crypto.PrintSignature(auth.Signature)
+
+	if err != nil {
+		return errors.Wrap(err, "RequestAuth.writeHeaders failed")
+	}
+
+	header.Set(AUTH_KEY_HEADER, string(auth.KeyHash))
+	header.Set(AUTH_TIMESTAMP_HEADER, strconv.FormatInt(auth.Timestamp.Unix(), 10))
+	header.Set(AUTH_NONCE_HEADER, auth.Nonce)
+	header.Set(AUTH_SIGNATURE_HEADER, string(sigText))
+	return nil
+}
+
+func readRequestAuth(header gohttp.Header) (RequestAuth, error) {
+	const failMsg = "readRequestAuth failed"
+
+	keyHash := header.Get(AUTH_KEY_HEADER)
+	nonce := header.Get(AUTH_NONCE_HEADER)
+	sigText := header.Get(AUTH_SIGNATURE_HEADER)
+
+	if keyHash == "" || nonce == "" || sigText == "" {
+		return RequestAuth{}, errors.New("Request is not signed")
+	}
+
+	seconds, err := strconv.ParseInt(header.Get(AUTH_TIMESTAMP_HEADER), 10, 64)
+
+	if err != nil {
+		return RequestAuth{}, errors.Wrap(err, failMsg)
+	}
+
+	sig, err := crypto.ParseSignature(crypto.SignatureText(sigText))
+
+	if err != nil {
+		return RequestAuth{}, errors.Wrap(err, failMsg)
+	}
+
+	auth := RequestAuth{
+		KeyHash:   crypto.PublicKeyHash(keyHash),
+		Timestamp: time.Unix(seconds, 0),
+		Nonce:     nonce,
+		Signature: sig,
+	}
+
+	return auth, nil
+}
+
+// requestAuthenticator accepts requests signed by the allowed keys within maxAge of now, and
+// remembers their nonces until they are too old to be accepted anyway.
+type requestAuthenticator struct {
+	sync.Mutex
+	keys   []crypto.PublicKey
+	maxAge time.Duration
+	seen   map[string]time.Time
+}
+
+func makeRequestAuthenticator(keys []crypto.PublicKey, maxAge time.Duration) *requestAuthenticator {
+	if maxAge <= 0 {
+		maxAge = DEFAULT_MAX_REQUEST_AGE
+	}
+
+	return &requestAuthenticator{
+		keys:   keys,
+		maxAge: maxAge,
+		seen:   map[string]time.Time{},
+	}
+}
+
+func (authenticator *requestAuthenticator) authenticate(header gohttp.Header, body []byte, now time.Time) error {
+	auth, err := readRequestAuth(header)
+
+	if err != nil {
+		return err
+	}
+
+	age := now.Sub(auth.Timestamp)
+	if age > authenticator.maxAge || -age > authenticator.maxAge {
+		return fmt.Errorf("Request timestamp outside of %v: %v", authenticator.maxAge, auth.Timestamp)
+	}
+
+	pub, ok := authenticator.findKey(auth.KeyHash)
+
+	if !ok {
+		return fmt.Errorf("Key not allowed: %s", auth.KeyHash)
+	}
+
+	if !auth.Verify(body, pub) {
+		return errors.New("Request signature verification failed")
+	}
+
+	return authenticator.useNonce(auth, now)
+}
+
+func (authenticator *requestAuthenticator) findKey(hash crypto.PublicKeyHash) (crypto.PublicKey, bool) {
+	for _, pub := range authenticator.keys {
+		other, err := pub.Hash()
+
+		if err == nil && other.Equals(hash) {
+			return pub, true
+		}
+	}
+
+	return crypto.PublicKey{}, false
+}
+
+func (authenticator *requestAuthenticator) useNonce(auth RequestAuth, now time.Time) error {
+	authenticator.Lock()
+	defer authenticator.Unlock()
+
+	for nonce, timestamp := range authenticator.seen {
+		if now.Sub(timestamp) > authenticator.maxAge {
+			delete(authenticator.seen, nonce)
+		}
+	}
+
+	nonce := string(auth.KeyHash) + " " + auth.Nonce
+
+	if _, present := authenticator.seen[nonce]; present {
+		return errors.New("Request was replayed")
+	}
+
+	authenticator.seen[nonce] = auth.Timestamp
+	return nil
+}
+
+// DEFAULT_MAX_REQUEST_AGE bounds the clock difference between clients and the server.
+const DEFAULT_MAX_REQUEST_AGE = 5 * time.Minute
+
+const __NONCE_LENGTH = 16
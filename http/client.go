@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	gohttp "net/http"
 	"time"
 
 	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crypto"
 	"github.com/johnny-morrice/godless/log"
 	"github.com/pkg/errors"
 )
@@ -16,6 +18,12 @@ type ClientOptions struct {
 	Endpoints
 	ServerAddr string
 	Http       *gohttp.Client
+	// ServerKey is optional.  If set, responses are rejected unless the server signed them with
+	// this key.
+	ServerKey *crypto.PublicKey
+	// SigningKey is optional.  If set, requests are signed with it, so that servers that only
+	// allow some keys will accept them.
+	SigningKey *crypto.PrivateKey
 }
 
 type client struct {
@@ -59,7 +67,19 @@ func (client *client) Post(path, bodyType string, body io.Reader) (api.Response,
 	addr := client.ServerAddr + path
 	log.Info("HTTP POST to %s", addr)
 
-	resp, err := client.Http.Post(addr, bodyType, body)
+	bs, err := ioutil.ReadAll(body)
+
+	if err != nil {
+		return api.RESPONSE_FAIL, errors.Wrap(err, "HTTP POST failed")
+	}
+
+	post, err := client.makePost(addr, bodyType, bs)
+
+	if err != nil {
+		return api.RESPONSE_FAIL, errors.Wrap(err, "HTTP POST failed")
+	}
+
+	resp, err := client.Http.Do(post)
 
 	if err != nil {
 		return api.RESPONSE_FAIL, errors.Wrap(err, "HTTP POST failed")
@@ -67,11 +87,14 @@ func (client *client) Post(path, bodyType string, body io.Reader) (api.Response,
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode != WEB_API_SUCCESS && resp.StatusCode != WEB_API_ERROR {
+	isOk := resp.StatusCode == WEB_API_SUCCESS
+	isOk = isOk || resp.StatusCode == WEB_API_ERROR
+	isOk = isOk || resp.StatusCode == WEB_API_UNAUTHORIZED
+	if !isOk {
 		return api.RESPONSE_FAIL, fmt.Errorf("HTTP error: %d", resp.StatusCode)
 	}
 
-	apiresp, err := client.decodeHttpResponse(resp)
+	apiresp, err := client.decodeHttpResponse(resp, api.RequestHash(bs))
 
 	if err != nil {
 		return apiresp, errors.Wrap(err, "Error decoding API response")
@@ -84,11 +107,52 @@ func (client *client) Post(path, bodyType string, body io.Reader) (api.Response,
 	}
 }
 
-func (client *client) decodeHttpResponse(resp *gohttp.Response) (api.Response, error) {
+// makePost signs the request body if the client has a SigningKey.
+func (client *client) makePost(addr, bodyType string, body []byte) (*gohttp.Request, error) {
+	post, err := gohttp.NewRequest("POST", addr, bytes.NewReader(body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	post.Header.Set(CONTENT_TYPE, bodyType)
+
+	if client.SigningKey == nil {
+		return post, nil
+	}
+
+	auth, err := SignRequest(body, *client.SigningKey, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = auth.writeHeaders(post.Header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// decodeHttpResponse verifies the response if the client has a ServerKey.  Error reports about
+// invalid requests are never signed, but they can only cause the request to fail.
+func (client *client) decodeHttpResponse(resp *gohttp.Response, requestHash string) (api.Response, error) {
 	if HasContentType(resp.Header, MIME_PROTO) {
+		if client.ServerKey != nil {
+			return api.DecodeVerifiedResponse(resp.Body, requestHash, *client.ServerKey)
+		}
+
 		return api.DecodeResponse(resp.Body)
 	} else if HasContentType(resp.Header, MIME_PROTO_TEXT) {
-		return api.DecodeResponseText(resp.Body)
+		apiresp, err := api.DecodeResponseText(resp.Body)
+
+		if err == nil && apiresp.Err == nil && client.ServerKey != nil {
+			return api.RESPONSE_FAIL, errors.New("Unsigned response was not an error report")
+		}
+
+		return apiresp, err
 	} else {
 		return api.RESPONSE_FAIL, incorrectContentType(resp.Header)
 	}
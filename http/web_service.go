@@ -3,9 +3,12 @@ package http
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	gohttp "net/http"
+	"time"
 
 	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crypto"
 	"github.com/johnny-morrice/godless/log"
 	"github.com/pkg/errors"
 )
@@ -17,11 +20,23 @@ const REFLECT_API_ROOT = "/reflect"
 type WebServiceOptions struct {
 	Endpoints
 	Api api.RequestService
+	// SigningKey is optional.  If set, responses to api.Requests are signed so that clients can
+	// verify them.
+	SigningKey *crypto.PrivateKey
+	// AllowedKeys is optional.  If set, api.Requests must be signed by one of these keys.
+	AllowedKeys []crypto.PublicKey
+	// MaxRequestAge is optional.  Signed requests with timestamps further than this from the server
+	// clock are rejected.  Defaults to DEFAULT_MAX_REQUEST_AGE.
+	MaxRequestAge time.Duration
+	// MaxRequestBytes is optional.  Larger request bodies are rejected.  Defaults to
+	// DEFAULT_MAX_REQUEST_BYTES.
+	MaxRequestBytes int64
 }
 
 type WebService struct {
 	WebServiceOptions
-	stopch chan struct{}
+	stopch        chan struct{}
+	authenticator *requestAuthenticator
 }
 
 func MakeWebService(options WebServiceOptions) api.WebService {
@@ -30,6 +45,14 @@ func MakeWebService(options WebServiceOptions) api.WebService {
 		stopch:            make(chan struct{}),
 	}
 
+	if len(options.AllowedKeys) > 0 {
+		service.authenticator = makeRequestAuthenticator(options.AllowedKeys, options.MaxRequestAge)
+	}
+
+	if service.MaxRequestBytes <= 0 {
+		service.MaxRequestBytes = DEFAULT_MAX_REQUEST_BYTES
+	}
+
 	service.UseDefaultEndpoints()
 
 	return service
@@ -51,7 +74,23 @@ func (service *WebService) handleApiRequest(rw gohttp.ResponseWriter, req *gohtt
 	}
 
 	log.Info("WebService api.Request at: %v", req.RequestURI)
-	request, err := api.DecodeRequest(req.Body)
+	body, err := ioutil.ReadAll(gohttp.MaxBytesReader(rw, req.Body, service.MaxRequestBytes))
+
+	if err != nil {
+		invalidRequest(rw, err)
+		return
+	}
+
+	if service.authenticator != nil {
+		err = service.authenticator.authenticate(req.Header, body, time.Now())
+
+		if err != nil {
+			unauthorizedRequest(rw, err)
+			return
+		}
+	}
+
+	request, err := api.DecodeRequest(bytes.NewReader(body))
 
 	if err != nil {
 		invalidRequest(rw, err)
@@ -59,7 +98,7 @@ func (service *WebService) handleApiRequest(rw gohttp.ResponseWriter, req *gohtt
 	}
 
 	respch, err := service.Api.Call(request)
-	service.respond(rw, respch, err)
+	service.respond(rw, respch, err, api.RequestHash(body))
 }
 
 func invalidRequest(rw gohttp.ResponseWriter, err error) {
@@ -70,6 +109,14 @@ func invalidRequest(rw gohttp.ResponseWriter, err error) {
 	}
 }
 
+func unauthorizedRequest(rw gohttp.ResponseWriter, err error) {
+	log.Warn("Unauthorized Request: %s", err.Error())
+	reportErr := sendErrStatus(rw, WEB_API_UNAUTHORIZED, err)
+	if reportErr != nil {
+		log.Error("Error sending error report: '%s'", reportErr.Error())
+	}
+}
+
 func (service *WebService) readResponse(respch <-chan api.Response) api.Response {
 	select {
 	case resp := <-respch:
@@ -81,7 +128,7 @@ func (service *WebService) readResponse(respch <-chan api.Response) api.Response
 	}
 }
 
-func (service *WebService) respond(rw gohttp.ResponseWriter, respch <-chan api.Response, err error) {
+func (service *WebService) respond(rw gohttp.ResponseWriter, respch <-chan api.Response, err error, requestHash string) {
 	if err != nil {
 		invalidRequest(rw, err)
 		return
@@ -91,7 +138,11 @@ func (service *WebService) respond(rw gohttp.ResponseWriter, respch <-chan api.R
 	resp := service.readResponse(respch)
 	log.Info("Webservice received API response")
 
-	err = sendMessage(rw, resp)
+	if service.SigningKey == nil {
+		err = sendMessage(rw, resp)
+	} else {
+		err = service.sendSignedMessage(rw, resp, requestHash)
+	}
 
 	if err != nil {
 		log.Error("Error sending response: %v", err)
@@ -99,6 +150,10 @@ func (service *WebService) respond(rw gohttp.ResponseWriter, respch <-chan api.R
 }
 
 func sendErr(rw gohttp.ResponseWriter, err error) error {
+	return sendErrStatus(rw, WEB_API_ERROR, err)
+}
+
+func sendErrStatus(rw gohttp.ResponseWriter, status int, err error) error {
 	message := api.Response{
 		Err: err,
 	}
@@ -112,7 +167,7 @@ func sendErr(rw gohttp.ResponseWriter, err error) error {
 
 	log.Info("Sending error APIResponse (%d bytes) to HTTP client...", buff.Len())
 	rw.Header()[CONTENT_TYPE] = []string{MIME_PROTO_TEXT}
-	rw.WriteHeader(WEB_API_ERROR)
+	rw.WriteHeader(status)
 	_, senderr := rw.Write(buff.Bytes())
 
 	if senderr != nil {
@@ -124,6 +179,26 @@ func sendErr(rw gohttp.ResponseWriter, err error) error {
 	return nil
 }
 
+// sendSignedMessage signs the response along with the HEAD that the API core answered with.
+func (service *WebService) sendSignedMessage(rw gohttp.ResponseWriter, resp api.Response, requestHash string) error {
+	const failMsg = "sendSignedMessage failed"
+
+	signing := api.ResponseSigning{
+		RequestHash: requestHash,
+		Head:        resp.Head,
+		Key:         *service.SigningKey,
+	}
+
+	buff := &bytes.Buffer{}
+	err := api.EncodeSignedResponse(resp, signing, buff)
+
+	if err != nil {
+		return sendErr(rw, errors.Wrap(err, failMsg))
+	}
+
+	return sendBytes(rw, buff)
+}
+
 func sendMessage(rw gohttp.ResponseWriter, resp api.Response) error {
 	// Encode gob into buffer first to check for encoding errors.
 	// TODO is that actually a good idea?
@@ -134,6 +209,10 @@ func sendMessage(rw gohttp.ResponseWriter, resp api.Response) error {
 		panic(fmt.Sprintf("BUG encoding resp: %v", encerr))
 	}
 
+	return sendBytes(rw, buff)
+}
+
+func sendBytes(rw gohttp.ResponseWriter, buff *bytes.Buffer) error {
 	log.Info("Sending APIResponse (%d bytes) to HTTP client...", buff.Len())
 	rw.Header()[CONTENT_TYPE] = []string{MIME_PROTO}
 	_, senderr := rw.Write(buff.Bytes())
@@ -147,8 +226,13 @@ func sendMessage(rw gohttp.ResponseWriter, resp api.Response) error {
 	return nil
 }
 
+// DEFAULT_MAX_REQUEST_BYTES bounds the size of an encoded api.Request.
+const DEFAULT_MAX_REQUEST_BYTES = 16 * 1024 * 1024
+
 const (
 	NOT_FOUND       = 404
 	WEB_API_SUCCESS = 200
 	WEB_API_ERROR   = 400
+	// WEB_API_UNAUTHORIZED is sent when request authentication fails.
+	WEB_API_UNAUTHORIZED = 401
 )
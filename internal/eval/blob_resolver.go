@@ -0,0 +1,113 @@
+package eval
+
+import (
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// blobResolver fetches the values of blob references.  Blobs are fetched only for entries that are
+// tested by a predicate or returned in the result.
+type blobResolver struct {
+	namespace api.BlobNamespace
+	decrypter *crdt.Decrypter
+	cache     map[crdt.BlobReference]crdt.PointText
+}
+
+func makeBlobResolver(namespace api.RemoteNamespace, decrypter *crdt.Decrypter) *blobResolver {
+	blobNamespace, ok := namespace.(api.BlobNamespace)
+
+	if !ok {
+		return nil
+	}
+
+	resolver := &blobResolver{
+		namespace: blobNamespace,
+		decrypter: decrypter,
+		cache:     map[crdt.BlobReference]crdt.PointText{},
+	}
+
+	return resolver
+}
+
+func (resolver *blobResolver) resolveNamespace(namespace crdt.Namespace) crdt.Namespace {
+	if resolver == nil {
+		return namespace
+	}
+
+	resolved := crdt.EmptyNamespace()
+
+	for tableName, table := range namespace.Tables {
+		resolvedTable := crdt.EmptyTable()
+
+		for rowName, row := range table.Rows {
+			resolvedTable.Rows[rowName] = resolver.resolveRow(row)
+		}
+
+		resolved.Tables[tableName] = resolvedTable
+	}
+
+	return resolved
+}
+
+func (resolver *blobResolver) resolveRow(row crdt.Row) crdt.Row {
+	resolved := crdt.EmptyRow()
+
+	for entryName, entry := range row.Entries {
+		resolved.Entries[entryName] = resolver.resolveEntry(entry)
+	}
+
+	return resolved
+}
+
+func (resolver *blobResolver) resolveEntry(entry crdt.Entry) crdt.Entry {
+	if resolver == nil {
+		return entry
+	}
+
+	points := make([]crdt.Point, len(entry.Set))
+
+	for i, point := range entry.Set {
+		points[i] = resolver.resolvePoint(point)
+	}
+
+	return crdt.MakeEntry(points)
+}
+
+// resolvePoint replaces a blob reference with an unsigned point holding the blob value.  The
+// reference is kept if the blob cannot be fetched.
+func (resolver *blobResolver) resolvePoint(point crdt.Point) crdt.Point {
+	text := point.Text()
+
+	if !crdt.IsBlobReference(text) {
+		return point
+	}
+
+	ref, err := crdt.ParseBlobReference(text)
+
+	if err != nil {
+		log.Warn("Invalid blob reference: %s", err.Error())
+		return point
+	}
+
+	value, cached := resolver.cache[ref]
+
+	if !cached {
+		value, err = resolver.namespace.CatBlob(ref)
+
+		if err != nil {
+			log.Warn("Failed to fetch blob at %s: %s", ref.Path, err.Error())
+			return point
+		}
+
+		resolver.cache[ref] = value
+	}
+
+	resolved := crdt.UnsignedPoint(value)
+
+	if resolver.decrypter != nil {
+		resolved = resolver.decrypter.DecryptPoint(resolved)
+	}
+
+	return resolved
+}
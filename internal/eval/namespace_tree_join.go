@@ -18,6 +18,8 @@ type NamespaceTreeJoin struct {
 	table       crdt.Table
 	privateKeys []crypto.PrivateKey
 	keyStore    api.KeyStore
+	recipients  []crypto.PublicKey
+	encryption  *crdt.TableEncryption
 }
 
 func MakeNamespaceTreeJoin(ns api.RemoteNamespace, keyStore api.KeyStore) *NamespaceTreeJoin {
@@ -41,7 +43,18 @@ func (visitor *NamespaceTreeJoin) RunQuery() api.Response {
 		panic("Expected table key")
 	}
 
-	path, err := visitor.Namespace.JoinTable(visitor.tableKey, visitor.table)
+	if visitor.encryption != nil {
+		keyRow, err := visitor.encryption.WrapKeys(visitor.recipients, visitor.privateKeys)
+
+		if err != nil {
+			fail.Err = errors.Wrap(err, "NamespaceTreeJoin failed")
+			return fail
+		}
+
+		visitor.table = visitor.table.JoinRow(crdt.ENCRYPTION_KEY_ROW, keyRow)
+	}
+
+	path, err := visitor.joinTable()
 
 	if err != nil {
 		fail.Err = errors.Wrap(err, "NamespaceTreeJoin failed")
@@ -51,6 +64,10 @@ func (visitor *NamespaceTreeJoin) RunQuery() api.Response {
 	resp := api.RESPONSE_QUERY
 	resp.Path = path
 
+	if api.IsProvisionalPath(path) {
+		resp.Msg = api.RESPONSE_PROVISIONAL_MSG
+	}
+
 	return resp
 }
 
@@ -81,7 +98,32 @@ func (visitor *NamespaceTreeJoin) VisitTableKey(tableKey crdt.TableName) {
 	visitor.tableKey = tableKey
 }
 
-func (visitor *NamespaceTreeJoin) VisitJoin(*query.QueryJoin) {
+func (visitor *NamespaceTreeJoin) VisitJoin(join *query.QueryJoin) {
+	if visitor.Error() != nil || !join.IsEncrypted() {
+		return
+	}
+
+	for _, hash := range join.Recipients {
+		pub, err := visitor.keyStore.GetPublicKey(hash)
+
+		if err != nil {
+			log.Warn("Recipient public key lookup failed with: %s", err.Error())
+			visitor.BadPublicKey(hash)
+			return
+		}
+
+		visitor.recipients = append(visitor.recipients, pub)
+	}
+
+	encryption, err := crdt.MakeTableEncryption()
+
+	if err != nil {
+		visitor.CollectError(errors.Wrap(err, "Failed to create table encryption"))
+		return
+	}
+
+	log.Info("Encrypting join for %d recipients", len(visitor.recipients))
+	visitor.encryption = &encryption
 }
 
 func (visitor *NamespaceTreeJoin) LeaveJoin(*query.QueryJoin) {
@@ -95,7 +137,14 @@ func (visitor *NamespaceTreeJoin) VisitRowJoin(position int, rowJoin *query.Quer
 	row := crdt.Row{}
 
 	for k, entryValue := range rowJoin.Entries {
-		point, err := visitor.makePoint(entryValue)
+		text, err := visitor.prepareText(entryValue)
+
+		if err != nil {
+			visitor.CollectError(errors.Wrap(err, "Failed to prepare point text"))
+			return
+		}
+
+		point, err := visitor.makePoint(text)
 
 		if err != nil {
 			visitor.badPrivateKey()
@@ -110,10 +159,61 @@ func (visitor *NamespaceTreeJoin) VisitRowJoin(position int, rowJoin *query.Quer
 	visitor.table = joined
 }
 
+// makePoint signs the point, unless the whole namespace will be signed instead.
 func (visitor *NamespaceTreeJoin) makePoint(text crdt.PointText) (crdt.Point, error) {
+	if _, ok := visitor.namespaceSigner(); ok {
+		return crdt.UnsignedPoint(text), nil
+	}
+
 	return crdt.SignedPoint(text, visitor.privateKeys)
 }
 
+func (visitor *NamespaceTreeJoin) joinTable() (crdt.IPFSPath, error) {
+	if signer, ok := visitor.namespaceSigner(); ok {
+		return signer.JoinSignedTable(visitor.tableKey, visitor.table, visitor.privateKeys)
+	}
+
+	return visitor.Namespace.JoinTable(visitor.tableKey, visitor.table)
+}
+
+func (visitor *NamespaceTreeJoin) namespaceSigner() (api.NamespaceSigningRemote, bool) {
+	signer, ok := visitor.Namespace.(api.NamespaceSigningRemote)
+	return signer, ok && signer.IsSigningNamespaces()
+}
+
+// prepareText encrypts the text and moves large values into blobs.  Signatures cover the prepared
+// text, so they can be verified without the plain text or the blob.  Text that readers would take
+// for a blob reference or encrypted text is rejected.
+func (visitor *NamespaceTreeJoin) prepareText(text crdt.PointText) (crdt.PointText, error) {
+	if crdt.IsReservedText(text) {
+		return "", errors.New("Point text begins with a reserved prefix")
+	}
+
+	if visitor.encryption != nil {
+		encrypted, err := visitor.encryption.EncryptText(text)
+
+		if err != nil {
+			return "", err
+		}
+
+		text = encrypted
+	}
+
+	blobNamespace, ok := visitor.Namespace.(api.BlobNamespace)
+
+	if !ok || !blobNamespace.IsLargeValue(text) {
+		return text, nil
+	}
+
+	ref, err := blobNamespace.AddBlob(text)
+
+	if err != nil {
+		return "", err
+	}
+
+	return ref.PointText(), nil
+}
+
 func (visitor *NamespaceTreeJoin) badPrivateKey() {
 	err := errors.New("Failed to sign Point with bad private key")
 	visitor.CollectError(err)
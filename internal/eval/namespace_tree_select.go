@@ -2,6 +2,7 @@ package eval
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/johnny-morrice/godless/api"
 	"github.com/johnny-morrice/godless/crdt"
@@ -18,7 +19,9 @@ type NamespaceTreeSelect struct {
 	Namespace          api.RemoteNamespace
 	crit               *rowCriteria
 	keys               []crypto.PublicKey
+	threshold          int
 	keyStore           api.KeyStore
+	decrypter          *crdt.Decrypter
 	namespaceLoadError bool
 	indexLoadError     bool
 }
@@ -50,11 +53,22 @@ func (visitor *NamespaceTreeSelect) RunQuery() api.Response {
 		panic("didn't visit query")
 	}
 
+	visitor.decrypter = crdt.MakeDecrypter(visitor.keyStore.GetAllPrivateKeys())
+	visitor.crit.blobs = makeBlobResolver(visitor.Namespace, visitor.decrypter)
+
 	log.Info("Searching namespaces...")
 
 	searcher := api.SignedTableSearcher{
-		Reader: api.SearchResultLambda(visitor.ReadSearchResult),
-		Tables: []crdt.TableName{visitor.crit.tableKey},
+		Reader:    api.SearchResultLambda(visitor.ReadSearchResult),
+		Tables:    []crdt.TableName{visitor.crit.tableKey},
+		Keys:      visitor.keys,
+		Threshold: visitor.threshold,
+		Policy:    api.MakeWritePolicy(visitor.keyStore),
+	}
+
+	if visitor.needsSignature() {
+		searcher.Delegations = visitor.keyStore.GetAllDelegations()
+		searcher.Revocations = visitor.keyStore.GetAllRevocations()
 	}
 	searchErr := visitor.Namespace.LoadTraverse(searcher)
 
@@ -83,8 +97,13 @@ func (visitor *NamespaceTreeSelect) RunQuery() api.Response {
 }
 
 func (visitor *NamespaceTreeSelect) ReadSearchResult(result api.SearchResult) api.TraversalUpdate {
+	if result.Source != visitor.crit.streamSource {
+		visitor.crit.beginStream(result.Source)
+	}
+
 	if result.NamespaceLoadFailure {
 		visitor.namespaceLoadError = true
+		visitor.crit.dropStream()
 		return api.TraversalUpdate{More: true}
 	}
 
@@ -93,9 +112,10 @@ func (visitor *NamespaceTreeSelect) ReadSearchResult(result api.SearchResult) ap
 		return api.TraversalUpdate{}
 	}
 
-	verified := visitor.filterVerified(result.Namespace)
+	verified := visitor.filterVerified(result.Namespace, result.Path)
+	decrypted := visitor.decrypter.DecryptNamespace(verified)
 
-	return visitor.crit.selectMatching(verified)
+	return visitor.crit.selectMatching(decrypted)
 }
 
 func (visitor *NamespaceTreeSelect) getSelectResults() crdt.Namespace {
@@ -105,14 +125,17 @@ func (visitor *NamespaceTreeSelect) getSelectResults() crdt.Namespace {
 	namespace, invalid := crdt.ReadNamespaceStream(stream)
 	visitor.logInvalid(invalid)
 
-	return namespace
+	// Like the namespace signatures, the wrapped keys are never part of the results.
+	namespace = namespace.WithoutEncryptionKeys()
+
+	return visitor.crit.blobs.resolveNamespace(namespace)
 }
 
-func (visitor *NamespaceTreeSelect) filterVerified(namespace crdt.Namespace) crdt.Namespace {
+func (visitor *NamespaceTreeSelect) filterVerified(namespace crdt.Namespace, path crdt.IPFSPath) crdt.Namespace {
 	if visitor.needsSignature() {
-		log.Info("Filtering results by public key...")
-		namespace = namespace.FilterVerified(visitor.keys)
-		log.Info("Filtering complete")
+		namespace = visitor.filterDelegated(namespace, path)
+	} else {
+		namespace = namespace.WithoutNamespaceSignature()
 	}
 
 	namespace, invalid := namespace.Strip()
@@ -122,6 +145,55 @@ func (visitor *NamespaceTreeSelect) filterVerified(namespace crdt.Namespace) crd
 	return namespace
 }
 
+// filterDelegated skips verifying namespaces that are marked as fully verified by the same keys,
+// and marks those found to be fully verified.
+func (visitor *NamespaceTreeSelect) filterDelegated(namespace crdt.Namespace, path crdt.IPFSPath) crdt.Namespace {
+	delegations := visitor.keyStore.GetAllDelegations()
+	revocations := visitor.keyStore.GetAllRevocations()
+
+	content := namespace.WithoutNamespaceSignature()
+
+	markers, canMark := visitor.Namespace.(api.VerifiedNamespaceCache)
+	canMark = canMark && !crdt.IsNilPath(path)
+
+	var digest string
+	if canMark {
+		digest, canMark = crypto.TrustDigest(visitor.keys, delegations, revocations, time.Now())
+	}
+
+	// A namespace verified by one key is not necessarily verified by several.
+	if canMark && visitor.threshold > 1 {
+		digest = fmt.Sprintf("%s:%d", digest, visitor.threshold)
+	}
+
+	if canMark {
+		isVerified, err := markers.IsVerifiedNamespace(path, digest)
+
+		if err != nil {
+			log.Warn("Failed to read verified namespace marker: %s", err.Error())
+		}
+
+		if isVerified {
+			log.Info("Namespace already verified: %s", path)
+			return content
+		}
+	}
+
+	log.Info("Filtering results by public key...")
+	verified := namespace.FilterDelegatedThreshold(visitor.keys, delegations, revocations, visitor.threshold)
+	log.Info("Filtering complete")
+
+	if canMark && verified.Equals(content) {
+		err := markers.SetVerifiedNamespace(path, digest)
+
+		if err != nil {
+			log.Warn("Failed to write verified namespace marker: %s", err.Error())
+		}
+	}
+
+	return verified
+}
+
 func (visitor *NamespaceTreeSelect) logInvalid(invalid []crdt.InvalidNamespaceEntry) {
 	invalidCount := len(invalid)
 	if invalidCount > 0 {
@@ -169,7 +241,13 @@ func (visitor *NamespaceTreeSelect) VisitSelect(qselect *query.QuerySelect) {
 		return
 	}
 
+	if int(qselect.KeyThreshold) > len(visitor.keys) {
+		visitor.CollectError(errors.New("Key threshold is more than the keys"))
+		return
+	}
+
 	visitor.crit.limit = int(qselect.Limit)
+	visitor.threshold = int(qselect.KeyThreshold)
 
 	visitor.crit.rootWhere = &qselect.Where
 }
@@ -192,6 +270,25 @@ type rowCriteria struct {
 	limit     int
 	result    []crdt.NamespaceStreamEntry
 	rootWhere *query.QueryWhere
+	blobs     *blobResolver
+	// streamSource is the namespace whose rows are being streamed.  Its results start at
+	// streamStart, and are dropped if it fails to load.
+	streamSource crdt.IPFSPath
+	streamStart  int
+}
+
+func (crit *rowCriteria) beginStream(source crdt.IPFSPath) {
+	crit.streamSource = source
+	crit.streamStart = len(crit.result)
+}
+
+func (crit *rowCriteria) dropStream() {
+	if crdt.IsNilPath(crit.streamSource) {
+		return
+	}
+
+	crit.count -= len(crit.result) - crit.streamStart
+	crit.result = crit.result[:crit.streamStart]
 }
 
 func (crit *rowCriteria) selectMatching(namespace crdt.Namespace) api.TraversalUpdate {
@@ -253,6 +350,7 @@ func (crit *rowCriteria) findRows(namespace crdt.Namespace) []crdt.NamespaceStre
 
 	table.ForeachRow(func(rowKey crdt.RowName, r crdt.Row) {
 		eval := makeSelectEvalTree(rowKey, r)
+		eval.blobs = crit.blobs
 		where := query.MakeWhereStack(crit.rootWhere)
 
 		if eval.evaluate(where) {
@@ -284,6 +382,7 @@ type selectEvalTree struct {
 	row    crdt.Row
 	root   *expr
 	stk    []*expr
+	blobs  *blobResolver
 }
 
 type exprOpCode uint8
@@ -366,7 +465,7 @@ func (eval *selectEvalTree) evalPred(where *query.QueryWhere) *expr {
 
 		if err == nil {
 			// logdbg("entry %v: %v", key, more)
-			entries = append(entries, more)
+			entries = append(entries, eval.blobs.resolveEntry(more))
 		} else {
 			// No key = no match.
 			// logdbg("no key = no match for pred %v", pred)
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/internal/util"
+	"github.com/johnny-morrice/godless/log"
+	"github.com/johnny-morrice/godless/proto"
+	"github.com/pkg/errors"
+)
+
+type blobManifestRecord struct {
+	Manifest *proto.BlobManifestMessage
+}
+
+func (record *blobManifestRecord) encode(w io.Writer) error {
+	return util.Encode(record.Manifest, w)
+}
+
+func (record *blobManifestRecord) decode(r io.Reader) error {
+	record.Manifest = &proto.BlobManifestMessage{}
+	return util.Decode(record.Manifest, r)
+}
+
+// AddBlob splits the data into chunks and adds each to the store, followed by
+// a manifest listing the chunks.  The path of the manifest is returned.
+func (peer *ContentAddressableRemoteStore) AddBlob(r io.Reader) (crdt.IPFSPath, error) {
+	const failMsg = "ContentAddressableRemoteStore.AddBlob failed"
+
+	log.Info("Adding blob to IPFS...")
+
+	if verr := peer.validateShell(); verr != nil {
+		return crdt.NIL_PATH, verr
+	}
+
+	manifest := &proto.BlobManifestMessage{}
+	chunk := make([]byte, __BLOB_CHUNK_SIZE)
+
+	for {
+		n, readErr := io.ReadFull(r, chunk)
+
+		if n > 0 {
+			hash, err := peer.Shell.Add(bytes.NewReader(chunk[:n]))
+
+			if err != nil {
+				return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+			}
+
+			manifest.Chunks = append(manifest.Chunks, hash)
+			manifest.Size += uint64(n)
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+
+		if readErr != nil {
+			return crdt.NIL_PATH, errors.Wrap(readErr, failMsg)
+		}
+	}
+
+	path, err := peer.add(&blobManifestRecord{Manifest: manifest})
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	log.Info("Added blob of %d bytes in %d chunks", manifest.Size, len(manifest.Chunks))
+
+	return path, nil
+}
+
+// CatBlob reads the manifest at addr.  The chunks are fetched as the returned reader is read.
+func (peer *ContentAddressableRemoteStore) CatBlob(addr crdt.IPFSPath) (io.ReadCloser, error) {
+	const failMsg = "ContentAddressableRemoteStore.CatBlob failed"
+
+	log.Info("Catting blob from IPFS at: %s ...", addr)
+
+	if verr := peer.validateShell(); verr != nil {
+		return nil, verr
+	}
+
+	record := &blobManifestRecord{}
+	err := peer.cat(addr, record)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	reader := &blobReader{
+		peer:     peer.Shell,
+		manifest: record.Manifest,
+	}
+
+	return reader, nil
+}
+
+// BlobChunks reads the manifest at addr and returns the paths of its chunks.
+func (peer *ContentAddressableRemoteStore) BlobChunks(addr crdt.IPFSPath) ([]crdt.IPFSPath, error) {
+	const failMsg = "ContentAddressableRemoteStore.BlobChunks failed"
+
+	if verr := peer.validateShell(); verr != nil {
+		return nil, verr
+	}
+
+	record := &blobManifestRecord{}
+	err := peer.cat(addr, record)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	chunks := make([]crdt.IPFSPath, len(record.Manifest.Chunks))
+	for i, hash := range record.Manifest.Chunks {
+		chunks[i] = crdt.IPFSPath(hash)
+	}
+
+	return chunks, nil
+}
+
+type blobReader struct {
+	peer     api.ContentAddressableStorage
+	manifest *proto.BlobManifestMessage
+	next     int
+	current  io.ReadCloser
+	count    uint64
+}
+
+func (reader *blobReader) Read(p []byte) (int, error) {
+	for {
+		if reader.current == nil {
+			if reader.next >= len(reader.manifest.Chunks) {
+				return 0, reader.finish()
+			}
+
+			chunk, err := reader.peer.Cat(reader.manifest.Chunks[reader.next])
+
+			if err != nil {
+				return 0, errors.Wrap(err, "blobReader.Read failed")
+			}
+
+			reader.current = chunk
+			reader.next++
+		}
+
+		n, err := reader.current.Read(p)
+		reader.count += uint64(n)
+
+		if err == io.EOF {
+			reader.current.Close()
+			reader.current = nil
+
+			if n == 0 {
+				continue
+			}
+
+			return n, nil
+		}
+
+		return n, err
+	}
+}
+
+func (reader *blobReader) finish() error {
+	if reader.count != reader.manifest.Size {
+		return fmt.Errorf("Blob size was %d but expected %d", reader.count, reader.manifest.Size)
+	}
+
+	return io.EOF
+}
+
+func (reader *blobReader) Close() error {
+	if reader.current == nil {
+		return nil
+	}
+
+	err := reader.current.Close()
+	reader.current = nil
+	return err
+}
+
+const __BLOB_CHUNK_SIZE = 256 * 1024
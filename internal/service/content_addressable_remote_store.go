@@ -1,6 +1,7 @@
 package service
 
 import (
+	"bufio"
 	"bytes"
 	"io"
 	"io/ioutil"
@@ -16,6 +17,7 @@ import (
 
 type namespaceRecord struct {
 	Namespace crdt.Namespace
+	Codec     crdt.Codec
 }
 
 func makeNamespaceRecord(namespace crdt.Namespace) *namespaceRecord {
@@ -25,7 +27,7 @@ func makeNamespaceRecord(namespace crdt.Namespace) *namespaceRecord {
 }
 
 func (record *namespaceRecord) encode(w io.Writer) error {
-	invalid, err := crdt.EncodeNamespace(record.Namespace, w)
+	invalid, err := crdt.EncodeNamespaceCodec(record.Namespace, record.Codec, w)
 
 	record.logInvalid(invalid)
 
@@ -63,6 +65,7 @@ type decoder interface {
 
 type indexRecord struct {
 	Index crdt.Index
+	Codec crdt.Codec
 }
 
 func makeIndexRecord(index crdt.Index) *indexRecord {
@@ -72,7 +75,7 @@ func makeIndexRecord(index crdt.Index) *indexRecord {
 }
 
 func (index *indexRecord) encode(w io.Writer) error {
-	invalid, err := crdt.EncodeIndex(index.Index, w)
+	invalid, err := crdt.EncodeIndexCodec(index.Index, index.Codec, w)
 
 	index.logInvalid(invalid)
 
@@ -125,7 +128,9 @@ func (closer *ipfsCloser) closeIpfs() {
 }
 
 type ContentAddressableRemoteStore struct {
-	Shell  api.DataPeer
+	Shell api.DataPeer
+	// Codec compresses new namespaces and indices.  All codecs can be read.
+	Codec  crdt.Codec
 	closer ipfsCloser
 }
 
@@ -142,6 +147,11 @@ func (peer *ContentAddressableRemoteStore) Disconnect() error {
 	return peer.Shell.Disconnect()
 }
 
+// IsUp is false while the DataPeer cannot be reached.
+func (peer *ContentAddressableRemoteStore) IsUp() bool {
+	return peer.Shell != nil && peer.Shell.IsUp()
+}
+
 func (peer *ContentAddressableRemoteStore) validateShell() error {
 	if peer.Shell == nil {
 		return peer.Connect()
@@ -171,10 +181,39 @@ func (peer *ContentAddressableRemoteStore) PublishAddr(addr crdt.Link, topics []
 		return errors.Wrap(printErr, failMsg)
 	}
 
+	peer.publishAllTopics(string(publishValue), topics)
+
+	return nil
+}
+
+func (peer *ContentAddressableRemoteStore) PublishSummary(summary crdt.IndexSummary, topics []api.PubSubTopic) error {
+	const failMsg = "ContentAddressableRemoteStore.PublishSummary failed"
+
+	if verr := peer.validateShell(); verr != nil {
+		return verr
+	}
+
+	publishValue, printErr := crdt.SerializeIndexSummary(summary)
+
+	if printErr != nil {
+		return errors.Wrap(printErr, failMsg)
+	}
+
+	summaryTopics := make([]api.PubSubTopic, len(topics))
+	for i, t := range topics {
+		summaryTopics[i] = summaryTopic(t)
+	}
+
+	peer.publishAllTopics(string(publishValue), summaryTopics)
+
+	return nil
+}
+
+func (peer *ContentAddressableRemoteStore) publishAllTopics(publishValue string, topics []api.PubSubTopic) {
 	for _, t := range topics {
 		topicText := string(t)
 		log.Info("Publishing to topic: %s", t)
-		pubsubErr := peer.Shell.PubSubPublish(topicText, string(publishValue))
+		pubsubErr := peer.Shell.PubSubPublish(topicText, publishValue)
 
 		if pubsubErr != nil {
 			log.Warn("Pubsub failed (topic %s): %s", t, pubsubErr.Error())
@@ -183,12 +222,34 @@ func (peer *ContentAddressableRemoteStore) PublishAddr(addr crdt.Link, topics []
 
 		log.Info("Published to topic: %s", t)
 	}
-
-	return nil
 }
 
+// SubscribeAddrStream receives the HEAD link from both bare links and index summaries.
 func (peer *ContentAddressableRemoteStore) SubscribeAddrStream(topic api.PubSubTopic) (<-chan crdt.Link, <-chan error) {
 	stream := make(chan crdt.Link)
+	summaries, errch := peer.subscribeSummaries(topic)
+
+	go func() {
+		defer close(stream)
+
+		for summary := range summaries {
+			stream <- summary.Head
+		}
+	}()
+
+	return stream, errch
+}
+
+// SubscribeSummaryStream receives index summaries.  Summaries are published on a topic of
+// their own, because older peers cannot read them, so they are sent alongside the bare links
+// on the main topic.
+func (peer *ContentAddressableRemoteStore) SubscribeSummaryStream(topic api.PubSubTopic) (<-chan crdt.IndexSummary, <-chan error) {
+	return peer.subscribeSummaries(summaryTopic(topic))
+}
+
+// subscribeSummaries receives bare links as summaries with no tables.
+func (peer *ContentAddressableRemoteStore) subscribeSummaries(topic api.PubSubTopic) (<-chan crdt.IndexSummary, <-chan error) {
+	stream := make(chan crdt.IndexSummary)
 	errch := make(chan error)
 
 	tidy := func() {
@@ -215,7 +276,7 @@ func (peer *ContentAddressableRemoteStore) SubscribeAddrStream(topic api.PubSubT
 	return stream, errch
 }
 
-func (peer *ContentAddressableRemoteStore) restartSubscriptionUntilDisconnect(topic api.PubSubTopic, stream chan<- crdt.Link) {
+func (peer *ContentAddressableRemoteStore) restartSubscriptionUntilDisconnect(topic api.PubSubTopic, stream chan<- crdt.IndexSummary) {
 	topicText := string(topic)
 
 	var subscription api.PubSubSubscription
@@ -259,19 +320,39 @@ RESTART:
 
 			pubsubPeer := record.From()
 			bs := record.Data()
-			addr, err := crdt.ParseLink(crdt.LinkText(bs))
+			summary, err := parsePubSubSummary(bs)
 
 			if err != nil {
 				log.Warn("Bad link from peer (topic %s): %v", topic, pubsubPeer)
 				continue
 			}
 
-			stream <- addr
-			log.Info("Subscription update: '%s'", addr.Path())
+			stream <- summary
+			log.Info("Subscription update: '%s'", summary.Head.Path())
 		}
 	}
 }
 
+func summaryTopic(topic api.PubSubTopic) api.PubSubTopic {
+	return topic + __SUMMARY_TOPIC_SUFFIX
+}
+
+func parsePubSubSummary(bs []byte) (crdt.IndexSummary, error) {
+	summary, summaryErr := crdt.ParseIndexSummary(crdt.IndexSummaryText(bs))
+
+	if summaryErr == nil {
+		return summary, nil
+	}
+
+	link, linkErr := crdt.ParseLink(crdt.LinkText(bs))
+
+	if linkErr != nil {
+		return crdt.IndexSummary{}, linkErr
+	}
+
+	return crdt.LinkSummary(link), nil
+}
+
 func (peer *ContentAddressableRemoteStore) AddIndex(index crdt.Index) (crdt.IPFSPath, error) {
 	const failMsg = "ContentAddressableRemoteStore.AddIndex failed"
 
@@ -282,6 +363,7 @@ func (peer *ContentAddressableRemoteStore) AddIndex(index crdt.Index) (crdt.IPFS
 	}
 
 	chunk := makeIndexRecord(index)
+	chunk.Codec = peer.Codec
 
 	path, addErr := peer.add(chunk)
 
@@ -321,6 +403,7 @@ func (peer *ContentAddressableRemoteStore) AddNamespace(namespace crdt.Namespace
 	}
 
 	chunk := makeNamespaceRecord(namespace)
+	chunk.Codec = peer.Codec
 
 	path, err := peer.add(chunk)
 
@@ -352,6 +435,68 @@ func (peer *ContentAddressableRemoteStore) CatNamespace(addr crdt.IPFSPath) (crd
 	return chunk.Namespace, nil
 }
 
+// CatNamespaceRows decodes the namespace row by row.  Namespaces in the legacy
+// encoding are read in full and passed to f at once.
+func (peer *ContentAddressableRemoteStore) CatNamespaceRows(addr crdt.IPFSPath, f func(row crdt.Namespace) bool) error {
+	const failMsg = "ContentAddressableRemoteStore.CatNamespaceRows failed"
+
+	log.Info("Streaming namespace from IPFS at: %s ...", addr)
+
+	if verr := peer.validateShell(); verr != nil {
+		return verr
+	}
+
+	reader, err := peer.Shell.Cat(string(addr))
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	defer reader.Close()
+
+	buffered := bufio.NewReader(reader)
+
+	if !crdt.IsStreamEncoding(buffered) {
+		chunk := &namespaceRecord{}
+		err = chunk.decode(buffered)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		f(chunk.Namespace)
+		return nil
+	}
+
+	decoder := crdt.MakeNamespaceStreamDecoder(buffered)
+	rowCount := 0
+
+	for {
+		stream, err := decoder.NextRow()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		row, invalid := crdt.ReadNamespaceStream(stream)
+		makeNamespaceRecord(row).logInvalid(invalid)
+		rowCount++
+
+		if !f(row) {
+			log.Info("Stopped streaming namespace after %d rows", rowCount)
+			return nil
+		}
+	}
+
+	log.Info("Streamed %d rows from namespace", rowCount)
+
+	return nil
+}
+
 func (peer *ContentAddressableRemoteStore) add(chunk encoder) (crdt.IPFSPath, error) {
 	const failMsg = "ContentAddressableRemoteStore.add failed"
 	buff := &bytes.Buffer{}
@@ -402,3 +547,4 @@ func (peer *ContentAddressableRemoteStore) cat(path crdt.IPFSPath, out decoder)
 
 // TODO make parameter
 const __RESTART_TICK = time.Millisecond * 500
+const __SUMMARY_TOPIC_SUFFIX = "/summary"
@@ -0,0 +1,567 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/log"
+)
+
+// DagRemoteStore writes indices and namespaces as IPLD DAG nodes with native links, so IPFS tools
+// can traverse a godless database, pinning a HEAD pins everything it reaches, and rows that do not
+// change between versions are stored once.  PubSub and blobs are handled as by the embedded
+// ContentAddressableRemoteStore, which also reads indices and namespaces written before the
+// database used DAG nodes.
+type DagRemoteStore struct {
+	*ContentAddressableRemoteStore
+	Dag api.DagStorage
+}
+
+// MakeDagRemoteStore compresses blocks written by the embedded ContentAddressableRemoteStore with codec.
+func MakeDagRemoteStore(peer api.DataPeer, dag api.DagStorage, codec crdt.Codec) api.RemoteStore {
+	store := &DagRemoteStore{
+		ContentAddressableRemoteStore: &ContentAddressableRemoteStore{Shell: peer, Codec: codec},
+		Dag:                           dag,
+	}
+
+	return store
+}
+
+type dagNode interface {
+	nodeType() string
+}
+
+type dagLink struct {
+	Target string `json:"/"`
+}
+
+// Nodes hold lists of named links rather than maps keyed by name, since a map with the single
+// key "/" would be read as a link.
+type dagIndexNode struct {
+	Type   string          `json:"type"`
+	Tables []dagIndexTable `json:"tables"`
+}
+
+type dagIndexTable struct {
+	Name  string         `json:"name"`
+	Links []dagTableLink `json:"links"`
+}
+
+type dagTableLink struct {
+	Namespace  dagLink  `json:"namespace"`
+	Signatures []string `json:"signatures,omitempty"`
+}
+
+type dagNamespaceNode struct {
+	Type   string         `json:"type"`
+	Tables []dagNamedLink `json:"tables"`
+}
+
+type dagTableNode struct {
+	Type string         `json:"type"`
+	Rows []dagNamedLink `json:"rows"`
+}
+
+type dagNamedLink struct {
+	Name string  `json:"name"`
+	Link dagLink `json:"link"`
+}
+
+type dagRowNode struct {
+	Type    string     `json:"type"`
+	Entries []dagEntry `json:"entries"`
+}
+
+type dagEntry struct {
+	Name   string     `json:"name"`
+	Points []dagPoint `json:"points"`
+}
+
+// dagPoint holds text that is not valid UTF-8 in Data, since JSON strings would mangle it.
+type dagPoint struct {
+	Text       string   `json:"text,omitempty"`
+	Data       []byte   `json:"data,omitempty"`
+	Signatures []string `json:"signatures,omitempty"`
+}
+
+func (node *dagIndexNode) nodeType() string {
+	return node.Type
+}
+
+func (node *dagNamespaceNode) nodeType() string {
+	return node.Type
+}
+
+func (node *dagTableNode) nodeType() string {
+	return node.Type
+}
+
+func (node *dagRowNode) nodeType() string {
+	return node.Type
+}
+
+func (store *DagRemoteStore) AddIndex(index crdt.Index) (crdt.IPFSPath, error) {
+	const failMsg = "DagRemoteStore.AddIndex failed"
+
+	log.Info("Adding index DAG to IPFS...")
+
+	node := dagIndexNode{
+		Type:   __DAG_INDEX_TYPE,
+		Tables: []dagIndexTable{},
+	}
+
+	for _, table := range index.AllTables() {
+		if !utf8.ValidString(string(table)) {
+			return crdt.NIL_PATH, fmt.Errorf("Table name is not UTF-8: %s", table)
+		}
+
+		links := []dagTableLink{}
+		err := index.ForTable(table, func(link crdt.Link) {
+			links = append(links, dagTableLink{
+				Namespace:  dagLink{Target: string(link.Path())},
+				Signatures: printDagSignatures(link.Signatures()),
+			})
+		})
+
+		if err != nil {
+			return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+		}
+
+		node.Tables = append(node.Tables, dagIndexTable{Name: string(table), Links: links})
+	}
+
+	path, err := store.put(node)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	log.Info("Added index DAG")
+
+	return path, nil
+}
+
+func (store *DagRemoteStore) CatIndex(addr crdt.IPFSPath) (crdt.Index, error) {
+	const failMsg = "DagRemoteStore.CatIndex failed"
+
+	log.Info("Catting index DAG from IPFS at: %s ...", addr)
+
+	node := dagIndexNode{}
+	err := store.get(string(addr), __DAG_INDEX_TYPE, &node)
+
+	if err != nil {
+		return store.catLegacyIndex(addr, err)
+	}
+
+	index := crdt.EmptyIndex()
+
+	for _, table := range node.Tables {
+		links := make([]crdt.Link, 0, len(table.Links))
+
+		for _, tableLink := range table.Links {
+			signatures, err := parseDagSignatures(tableLink.Signatures)
+
+			if err != nil {
+				return crdt.EmptyIndex(), errors.Wrap(err, failMsg)
+			}
+
+			path := crdt.IPFSPath(tableLink.Namespace.Target)
+			links = append(links, crdt.PresignedLink(path, signatures))
+		}
+
+		index = index.JoinTable(crdt.TableName(table.Name), links...)
+	}
+
+	log.Info("Catted index DAG")
+
+	return index, nil
+}
+
+// catLegacyIndex reads an index written by ContentAddressableRemoteStore, before the database used
+// DAG nodes.  dagErr is returned if the block is not a legacy index either.
+func (store *DagRemoteStore) catLegacyIndex(addr crdt.IPFSPath, dagErr error) (crdt.Index, error) {
+	const failMsg = "DagRemoteStore.CatIndex failed"
+
+	index, err := store.ContentAddressableRemoteStore.CatIndex(addr)
+
+	if err != nil {
+		return crdt.EmptyIndex(), errors.Wrap(dagErr, failMsg)
+	}
+
+	log.Info("Catted legacy index at: %s", addr)
+
+	return index, nil
+}
+
+// AddNamespace puts a node for each row, then each table, then the namespace.
+func (store *DagRemoteStore) AddNamespace(namespace crdt.Namespace) (crdt.IPFSPath, error) {
+	const failMsg = "DagRemoteStore.AddNamespace failed"
+
+	log.Info("Adding namespace DAG to IPFS...")
+
+	node := dagNamespaceNode{
+		Type:   __DAG_NAMESPACE_TYPE,
+		Tables: []dagNamedLink{},
+	}
+
+	for _, tableName := range namespace.GetTableNames() {
+		table := namespace.Tables[tableName]
+
+		if !utf8.ValidString(string(tableName)) {
+			return crdt.NIL_PATH, fmt.Errorf("Table name is not UTF-8: %s", tableName)
+		}
+
+		path, err := store.addTable(table)
+
+		if err != nil {
+			return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+		}
+
+		node.Tables = append(node.Tables, makeDagNamedLink(string(tableName), path))
+	}
+
+	path, err := store.put(node)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	log.Info("Added namespace DAG")
+
+	return path, nil
+}
+
+func (store *DagRemoteStore) CatNamespace(addr crdt.IPFSPath) (crdt.Namespace, error) {
+	const failMsg = "DagRemoteStore.CatNamespace failed"
+
+	log.Info("Catting namespace DAG from IPFS at: %s ...", addr)
+
+	namespace := crdt.EmptyNamespace()
+	err := store.CatNamespaceRows(addr, func(row crdt.Namespace) bool {
+		namespace = namespace.JoinNamespace(row)
+		return true
+	})
+
+	if err != nil {
+		return crdt.EmptyNamespace(), errors.Wrap(err, failMsg)
+	}
+
+	log.Info("Catted namespace DAG")
+
+	return namespace, nil
+}
+
+// CatNamespaceRows fetches each row node only when f asks for more.  Rows of the signature table
+// come first, then the other tables in name order.
+func (store *DagRemoteStore) CatNamespaceRows(addr crdt.IPFSPath, f func(row crdt.Namespace) bool) error {
+	const failMsg = "DagRemoteStore.CatNamespaceRows failed"
+
+	node := dagNamespaceNode{}
+	err := store.get(string(addr), __DAG_NAMESPACE_TYPE, &node)
+
+	if err != nil {
+		return store.catLegacyNamespaceRows(addr, err, f)
+	}
+
+	sort.SliceStable(node.Tables, func(i, j int) bool {
+		return isBeforeInRowStream(crdt.TableName(node.Tables[i].Name), crdt.TableName(node.Tables[j].Name))
+	})
+
+	for _, tableLink := range node.Tables {
+		table := dagTableNode{}
+		err := store.get(tableLink.Link.Target, __DAG_TABLE_TYPE, &table)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		for _, rowLink := range table.Rows {
+			row, err := store.catRow(rowLink.Link.Target)
+
+			if err != nil {
+				return errors.Wrap(err, failMsg)
+			}
+
+			rowNamespace := makeRowNamespace(crdt.TableName(tableLink.Name), crdt.RowName(rowLink.Name), row)
+
+			if !f(rowNamespace) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// catLegacyNamespaceRows reads a namespace written by ContentAddressableRemoteStore, before the
+// database used DAG nodes.  dagErr is returned if the block is not a legacy namespace either.
+func (store *DagRemoteStore) catLegacyNamespaceRows(addr crdt.IPFSPath, dagErr error, f func(row crdt.Namespace) bool) error {
+	const failMsg = "DagRemoteStore.CatNamespaceRows failed"
+
+	namespace, err := store.ContentAddressableRemoteStore.CatNamespace(addr)
+
+	if err != nil {
+		return errors.Wrap(dagErr, failMsg)
+	}
+
+	log.Info("Catted legacy namespace at: %s", addr)
+
+	tableNames := namespace.GetTableNames()
+	sort.SliceStable(tableNames, func(i, j int) bool {
+		return isBeforeInRowStream(tableNames[i], tableNames[j])
+	})
+
+	for _, tableName := range tableNames {
+		table := namespace.Tables[tableName]
+
+		for _, rowName := range sortedRowNames(table) {
+			if !f(makeRowNamespace(tableName, rowName, table.Rows[rowName])) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+func (store *DagRemoteStore) addTable(table crdt.Table) (crdt.IPFSPath, error) {
+	node := dagTableNode{
+		Type: __DAG_TABLE_TYPE,
+		Rows: []dagNamedLink{},
+	}
+
+	for _, rowName := range sortedRowNames(table) {
+		row := table.Rows[rowName]
+
+		if !utf8.ValidString(string(rowName)) {
+			return crdt.NIL_PATH, fmt.Errorf("Row name is not UTF-8: %s", rowName)
+		}
+
+		path, err := store.addRow(row)
+
+		if err != nil {
+			return crdt.NIL_PATH, err
+		}
+
+		node.Rows = append(node.Rows, makeDagNamedLink(string(rowName), path))
+	}
+
+	return store.put(node)
+}
+
+func (store *DagRemoteStore) addRow(row crdt.Row) (crdt.IPFSPath, error) {
+	node := dagRowNode{
+		Type:    __DAG_ROW_TYPE,
+		Entries: []dagEntry{},
+	}
+
+	for _, entryName := range sortedEntryNames(row) {
+		entry := row.Entries[entryName]
+
+		if !utf8.ValidString(string(entryName)) {
+			return crdt.NIL_PATH, fmt.Errorf("Entry name is not UTF-8: %s", entryName)
+		}
+
+		values := entry.GetValues()
+		points := make([]dagPoint, len(values))
+
+		for i, point := range values {
+			points[i] = makeDagPoint(point)
+		}
+
+		node.Entries = append(node.Entries, dagEntry{Name: string(entryName), Points: points})
+	}
+
+	return store.put(node)
+}
+
+func (store *DagRemoteStore) catRow(addr string) (crdt.Row, error) {
+	node := dagRowNode{}
+	err := store.get(addr, __DAG_ROW_TYPE, &node)
+
+	if err != nil {
+		return crdt.EmptyRow(), err
+	}
+
+	row := crdt.EmptyRow()
+
+	for _, dagEntry := range node.Entries {
+		points := make([]crdt.Point, len(dagEntry.Points))
+
+		for i, dagPoint := range dagEntry.Points {
+			point, err := readDagPoint(dagPoint)
+
+			if err != nil {
+				return crdt.EmptyRow(), err
+			}
+
+			points[i] = point
+		}
+
+		row = row.JoinEntry(crdt.EntryName(dagEntry.Name), crdt.MakeEntry(points))
+	}
+
+	return row, nil
+}
+
+func (store *DagRemoteStore) put(node interface{}) (crdt.IPFSPath, error) {
+	text, err := json.Marshal(node)
+
+	if err != nil {
+		return crdt.NIL_PATH, err
+	}
+
+	path, err := store.Dag.DagPut(text, __DAG_INPUT_ENCODING, __DAG_FORMAT)
+
+	if err != nil {
+		return crdt.NIL_PATH, err
+	}
+
+	return crdt.IPFSPath(path), nil
+}
+
+// get decodes the node at addr into out, and checks that it has the expected type.
+func (store *DagRemoteStore) get(addr string, expectedType string, out dagNode) error {
+	err := store.Dag.DagGet(addr, out)
+
+	if err != nil {
+		return err
+	}
+
+	if out.nodeType() != expectedType {
+		return fmt.Errorf("Expected %s node at %s but was '%s'", expectedType, addr, out.nodeType())
+	}
+
+	return nil
+}
+
+func isBeforeInRowStream(a, b crdt.TableName) bool {
+	if a == crdt.NAMESPACE_SIGNATURE_TABLE || b == crdt.NAMESPACE_SIGNATURE_TABLE {
+		return a == crdt.NAMESPACE_SIGNATURE_TABLE && b != crdt.NAMESPACE_SIGNATURE_TABLE
+	}
+
+	return a < b
+}
+
+func makeDagNamedLink(name string, path crdt.IPFSPath) dagNamedLink {
+	return dagNamedLink{Name: name, Link: dagLink{Target: string(path)}}
+}
+
+func makeRowNamespace(tableName crdt.TableName, rowName crdt.RowName, row crdt.Row) crdt.Namespace {
+	table := crdt.MakeTable(map[crdt.RowName]crdt.Row{rowName: row})
+	return crdt.EmptyNamespace().JoinTable(tableName, table)
+}
+
+// Names are sorted so that equal nodes have equal hashes.
+func sortedRowNames(table crdt.Table) []crdt.RowName {
+	names := make([]string, 0, len(table.Rows))
+	for rowName := range table.Rows {
+		names = append(names, string(rowName))
+	}
+
+	sort.Strings(names)
+
+	rowNames := make([]crdt.RowName, len(names))
+	for i, name := range names {
+		rowNames[i] = crdt.RowName(name)
+	}
+
+	return rowNames
+}
+
+func sortedEntryNames(row crdt.Row) []crdt.EntryName {
+	names := make([]string, 0, len(row.Entries))
+	for entryName := range row.Entries {
+		names = append(names, string(entryName))
+	}
+
+	sort.Strings(names)
+
+	entryNames := make([]crdt.EntryName, len(names))
+	for i, name := range names {
+		entryNames[i] = crdt.EntryName(name)
+	}
+
+	return entryNames
+}
+
+func makeDagPoint(point crdt.Point) dagPoint {
+	node := dagPoint{
+		Signatures: printDagSignatures(point.Signatures()),
+	}
+
+	text := string(point.Text())
+
+	if utf8.ValidString(text) {
+		node.Text = text
+	} else {
+		node.Data = []byte(text)
+	}
+
+	return node
+}
+
+func readDagPoint(node dagPoint) (crdt.Point, error) {
+	signatures, err := parseDagSignatures(node.Signatures)
+
+	if err != nil {
+		return crdt.Point{}, err
+	}
+
+	text := crdt.PointText(node.Text)
+
+	if len(node.Data) > 0 {
+		text = crdt.PointText(node.Data)
+	}
+
+	return crdt.PresignedPoint(text, signatures), nil
+}
+
+func printDagSignatures(signatures []crypto.Signature) []string {
+	texts := make([]string, 0, len(signatures))
+
+	for _, sig := range signatures {
+		text, err := crypto.PrintSignature(sig)
+
+		if err != nil {
+			log.Warn("Failed to print signature: %s", err.Error())
+			continue
+		}
+
+		texts = append(texts, string(text))
+	}
+
+	return texts
+}
+
+func parseDagSignatures(texts []string) ([]crypto.Signature, error) {
+	signatures := make([]crypto.Signature, len(texts))
+
+	for i, text := range texts {
+		sig, err := crypto.ParseSignature(crypto.SignatureText(text))
+
+		if err != nil {
+			return nil, err
+		}
+
+		signatures[i] = sig
+	}
+
+	return signatures, nil
+}
+
+const (
+	__DAG_INDEX_TYPE     = "godless-index"
+	__DAG_NAMESPACE_TYPE = "godless-namespace"
+	__DAG_TABLE_TYPE     = "godless-table"
+	__DAG_ROW_TYPE       = "godless-row"
+	__DAG_INPUT_ENCODING = "json"
+	__DAG_FORMAT         = "cbor"
+)
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/log"
+	"github.com/pkg/errors"
+)
+
+type GarbageCollectorOptions struct {
+	Store  api.RemoteStore
+	Pinner api.PinningPeer
+	// Ledger records the blocks that godless created.  Only these are ever unpinned.
+	Ledger api.PinLedger
+	// Head is the current HEAD index.
+	Head crdt.IPFSPath
+	// History contains older index paths that should be retained.
+	History []crdt.IPFSPath
+	// Indices are indices that have not yet been written to HEAD, such as
+	// the MemoryImage of a running server.
+	Indices []crdt.Index
+	// DryRun reports what would be pinned and unpinned without doing so.
+	DryRun bool
+}
+
+// GarbageReport describes the outcome of a garbage collection.
+type GarbageReport struct {
+	DryRun bool
+	// Live blocks are reachable from HEAD or the retained history.
+	Live []crdt.IPFSPath
+	// Pinned blocks were live but not previously pinned.
+	Pinned []crdt.IPFSPath
+	// Unpinned blocks were recorded in the ledger and are no longer reachable.
+	Unpinned []crdt.IPFSPath
+	// Recent blocks were unreachable but added while the collection was running, so were kept.
+	Recent []crdt.IPFSPath
+	// Ignored blocks were pinned, unreachable, and not recorded in the ledger.
+	Ignored []crdt.IPFSPath
+}
+
+type garbageCollector struct {
+	GarbageCollectorOptions
+	start      time.Time
+	live       map[crdt.IPFSPath]struct{}
+	namespaces map[crdt.IPFSPath]struct{}
+	report     GarbageReport
+}
+
+// CollectGarbage walks the indices reachable from HEAD and the retained history, and the
+// blobs their namespaces refer to, pinning live blocks and unpinning dead blocks recorded in
+// the ledger.  Pins that godless did not create, and blocks added since the collection began,
+// are left alone.
+func CollectGarbage(options GarbageCollectorOptions) (GarbageReport, error) {
+	const failMsg = "CollectGarbage failed"
+
+	if options.Store == nil || options.Pinner == nil || options.Ledger == nil {
+		panic("GarbageCollectorOptions requires Store, Pinner and Ledger")
+	}
+
+	collector := &garbageCollector{
+		GarbageCollectorOptions: options,
+		start:                   time.Now(),
+		live:                    map[crdt.IPFSPath]struct{}{},
+		namespaces:              map[crdt.IPFSPath]struct{}{},
+	}
+	collector.report.DryRun = options.DryRun
+
+	err := collector.walk()
+
+	if err != nil {
+		return collector.report, errors.Wrap(err, failMsg)
+	}
+
+	err = collector.sweep()
+
+	if err != nil {
+		return collector.report, errors.Wrap(err, failMsg)
+	}
+
+	return collector.report, nil
+}
+
+func (collector *garbageCollector) walk() error {
+	const failMsg = "garbageCollector.walk failed"
+
+	roots := make([]crdt.IPFSPath, 0, len(collector.History)+1)
+
+	if !crdt.IsNilPath(collector.Head) {
+		roots = append(roots, collector.Head)
+	}
+
+	roots = append(roots, collector.History...)
+
+	if len(roots) == 0 && len(collector.Indices) == 0 {
+		return errors.New("No HEAD to walk from")
+	}
+
+	// We must fail on any unreadable root, or we would unpin everything it links to.
+	for _, path := range roots {
+		log.Info("Walking Index at: %s", path)
+		index, err := collector.Store.CatIndex(path)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		collector.markLive(path)
+		collector.markIndex(index)
+	}
+
+	for _, index := range collector.Indices {
+		collector.markIndex(index)
+	}
+
+	err := collector.markBlobs()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	collector.report.Live = sortedPaths(collector.live)
+
+	log.Info("Found %d live blocks", len(collector.report.Live))
+
+	return nil
+}
+
+func (collector *garbageCollector) markIndex(index crdt.Index) {
+	for _, table := range index.AllTables() {
+		index.ForTable(table, func(link crdt.Link) {
+			collector.markLive(link.Path())
+			collector.namespaces[link.Path()] = struct{}{}
+		})
+	}
+}
+
+// markBlobs marks the manifest and chunks of every blob referred to by a live namespace.  As with
+// the roots, we must fail on any unreadable namespace or manifest.
+func (collector *garbageCollector) markBlobs() error {
+	const failMsg = "garbageCollector.markBlobs failed"
+
+	blobs, ok := collector.Store.(api.BlobStore)
+
+	if !ok {
+		return nil
+	}
+
+	for _, path := range sortedPaths(collector.namespaces) {
+		namespace, err := collector.Store.CatNamespace(path)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		for _, ref := range namespace.BlobReferences() {
+			if collector.isLive(ref.Path) {
+				continue
+			}
+
+			chunks, err := blobs.BlobChunks(ref.Path)
+
+			if err != nil {
+				return errors.Wrap(err, failMsg)
+			}
+
+			collector.markLive(ref.Path)
+
+			for _, chunk := range chunks {
+				collector.markLive(chunk)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (collector *garbageCollector) markLive(path crdt.IPFSPath) {
+	collector.live[path] = struct{}{}
+}
+
+func (collector *garbageCollector) isLive(path crdt.IPFSPath) bool {
+	_, present := collector.live[path]
+	return present
+}
+
+func (collector *garbageCollector) sweep() error {
+	const failMsg = "garbageCollector.sweep failed"
+
+	pinText, err := collector.Pinner.Pins()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	pinned := map[crdt.IPFSPath]struct{}{}
+	for _, hash := range pinText {
+		pinned[crdt.IPFSPath(hash)] = struct{}{}
+	}
+
+	for _, path := range collector.report.Live {
+		if _, present := pinned[path]; present {
+			continue
+		}
+
+		err := collector.pin(path)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+	}
+
+	records, err := collector.pinRecords()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	for _, path := range sortedPaths(pinned) {
+		if collector.isLive(path) {
+			continue
+		}
+
+		added, recorded := records[path]
+
+		if !recorded {
+			collector.report.Ignored = append(collector.report.Ignored, path)
+			continue
+		}
+
+		if collector.isRecent(added) {
+			collector.report.Recent = append(collector.report.Recent, path)
+			continue
+		}
+
+		err := collector.unpin(path)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+	}
+
+	return collector.restoreRecent()
+}
+
+// restoreRecent pins again any block that was added after we read the ledger, but before we
+// unpinned it.
+func (collector *garbageCollector) restoreRecent() error {
+	const failMsg = "garbageCollector.restoreRecent failed"
+
+	if collector.DryRun || len(collector.report.Unpinned) == 0 {
+		return nil
+	}
+
+	records, err := collector.pinRecords()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	unpinned := make([]crdt.IPFSPath, 0, len(collector.report.Unpinned))
+	for _, path := range collector.report.Unpinned {
+		added, recorded := records[path]
+
+		if !recorded || !collector.isRecent(added) {
+			unpinned = append(unpinned, path)
+			continue
+		}
+
+		log.Info("Restoring recent block: %s", path)
+		err := collector.Pinner.Pin(string(path))
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		collector.report.Recent = append(collector.report.Recent, path)
+	}
+
+	collector.report.Unpinned = unpinned
+
+	return nil
+}
+
+func (collector *garbageCollector) pinRecords() (map[crdt.IPFSPath]time.Time, error) {
+	recordList, err := collector.Ledger.PinRecords()
+
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[crdt.IPFSPath]time.Time, len(recordList))
+	for _, record := range recordList {
+		records[crdt.IPFSPath(record.Hash)] = record.Added
+	}
+
+	return records, nil
+}
+
+func (collector *garbageCollector) isRecent(added time.Time) bool {
+	return !added.Before(collector.start)
+}
+
+func (collector *garbageCollector) pin(path crdt.IPFSPath) error {
+	collector.report.Pinned = append(collector.report.Pinned, path)
+
+	if collector.DryRun {
+		return nil
+	}
+
+	log.Info("Pinning live block: %s", path)
+	err := collector.Pinner.Pin(string(path))
+
+	if err != nil {
+		return err
+	}
+
+	return collector.Ledger.RecordPin(string(path))
+}
+
+func (collector *garbageCollector) unpin(path crdt.IPFSPath) error {
+	collector.report.Unpinned = append(collector.report.Unpinned, path)
+
+	if collector.DryRun {
+		return nil
+	}
+
+	log.Info("Unpinning dead block: %s", path)
+	err := collector.Pinner.Unpin(string(path))
+
+	if err != nil {
+		return err
+	}
+
+	return collector.Ledger.ForgetPin(string(path), collector.start)
+}
+
+func sortedPaths(pathSet map[crdt.IPFSPath]struct{}) []crdt.IPFSPath {
+	paths := make([]crdt.IPFSPath, 0, len(pathSet))
+
+	for path := range pathSet {
+		paths = append(paths, path)
+	}
+
+	sort.Sort(byPath(paths))
+
+	return paths
+}
+
+type byPath []crdt.IPFSPath
+
+func (paths byPath) Len() int {
+	return len(paths)
+}
+
+func (paths byPath) Swap(i, j int) {
+	paths[i], paths[j] = paths[j], paths[i]
+}
+
+func (paths byPath) Less(i, j int) bool {
+	return paths[i] < paths[j]
+}
@@ -2,12 +2,15 @@ package service
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/johnny-morrice/godless/api"
 	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
 	"github.com/johnny-morrice/godless/internal/eval"
 	"github.com/johnny-morrice/godless/log"
 	"github.com/johnny-morrice/godless/query"
@@ -24,6 +27,17 @@ type RemoteNamespaceCoreOptions struct {
 	IsPublicIndex  bool
 	Pulse          time.Duration
 	Debug          bool
+	// BlobThreshold is optional.  Longer point text is stored out of line, if the Store is an api.BlobStore.
+	BlobThreshold int
+	// Journal is optional.  If specified, joins are held in the Journal while the Store reports that
+	// it is down, and written to the Store in order once it is back up.
+	Journal api.JoinJournal
+	// VerifiedMarkers is optional.  If set, and the NamespaceCache is an api.VerifiedNamespaceCache,
+	// namespaces that were fully verified are marked so later queries skip verifying them.
+	VerifiedMarkers bool
+	// SignNamespaces is optional.  If set, joins sign the namespace once with each key, instead of
+	// signing each point.
+	SignNamespaces bool
 }
 
 func checkOptions(options RemoteNamespaceCoreOptions) {
@@ -50,6 +64,11 @@ type remoteNamespace struct {
 	stopch        chan struct{}
 	wg            *sync.WaitGroup
 	memImgTracker dirtyTracker
+	// journalLock is held while the journal is flushed, so that new joins queue behind it.
+	journalLock sync.Mutex
+	// journalLinks caches the signed links to journaled joins.
+	journalLinks     map[crdt.IPFSPath]crdt.Link
+	journalLinksLock sync.Mutex
 }
 
 func MakeRemoteNamespaceCore(options RemoteNamespaceCoreOptions) api.RemoteNamespaceCore {
@@ -68,6 +87,7 @@ func MakeRemoteNamespaceCore(options RemoteNamespaceCoreOptions) api.RemoteNames
 		stopch:                     make(chan struct{}),
 		wg:                         &sync.WaitGroup{},
 		memImgTracker:              makeDirtyTracker(),
+		journalLinks:               map[crdt.IPFSPath]crdt.Link{},
 	}
 
 	remote.wg.Add(__REMOTE_NAMESPACE_PROCESS_COUNT)
@@ -76,6 +96,11 @@ func MakeRemoteNamespaceCore(options RemoteNamespaceCoreOptions) api.RemoteNames
 	go remote.addIndices()
 	go remote.memoryImageWriteLoop()
 
+	if remote.Journal != nil {
+		remote.wg.Add(1)
+		go remote.journalFlushLoop(pulseInterval)
+	}
+
 	if initWait != nil {
 		<-initWait
 	}
@@ -107,6 +132,7 @@ func (rn *remoteNamespace) initializeMemoryImage() <-chan struct{} {
 		_, err := rn.insertIndex(index)
 
 		if err == nil {
+			rn.loadKeyRecords(index)
 			log.Info("Initialized remoteNamespace with Index at: %s", head)
 		} else {
 			log.Error("Failed to initialize remoteNamespace with Index (%s): %s", head, err.Error())
@@ -248,7 +274,7 @@ func (rn *remoteNamespace) Close() {
 func (rn *remoteNamespace) Replicate(links []crdt.Link, kvq api.Command) {
 	runner := api.ResponderLambda(func() api.Response { return rn.joinPeerIndex(links) })
 	response := runner.RunQuery()
-	kvq.WriteResponse(response)
+	rn.writeResponse(kvq, response)
 }
 
 func (rn *remoteNamespace) joinPeerIndex(links []crdt.Link) api.Response {
@@ -257,7 +283,9 @@ func (rn *remoteNamespace) joinPeerIndex(links []crdt.Link) api.Response {
 
 	log.Info("Replicating peer indices...")
 
-	keys := rn.KeyStore.GetAllPublicKeys()
+	keys := rn.KeyStore.GetDelegatedPublicKeys("")
+	hasDelegations := len(rn.KeyStore.GetAllDelegations()) > 0
+	policy := api.MakeWritePolicy(rn.KeyStore)
 
 	joined := crdt.EmptyIndex()
 
@@ -265,13 +293,13 @@ func (rn *remoteNamespace) joinPeerIndex(links []crdt.Link) api.Response {
 	for _, link := range links {
 		if !rn.IsPublicIndex {
 			log.Info("Verifying link...")
-			isVerified := link.IsVerifiedByAny(keys)
-			if !isVerified {
+
+			// Keys delegated for some tables only are checked once their index is loaded.
+			if !link.IsVerifiedByAny(keys) && !hasDelegations {
 				log.Warn("Skipping unverified Index Link")
 				someFailed = true
 				continue
 			}
-			log.Info("Verified link: %s", link.Path())
 		}
 
 		peerAddr := link.Path()
@@ -284,6 +312,19 @@ func (rn *remoteNamespace) joinPeerIndex(links []crdt.Link) api.Response {
 			continue
 		}
 
+		if !rn.IsPublicIndex {
+			theirIndex = rn.filterDelegatedTables(link, theirIndex)
+
+			if theirIndex.IsEmpty() {
+				log.Warn("Skipping unverified Index Link")
+				someFailed = true
+				continue
+			}
+		}
+
+		theirIndex = policy.FilterIndex(theirIndex)
+
+		log.Info("Verified link: %s", link.Path())
 		joined = joined.JoinIndex(theirIndex)
 	}
 
@@ -301,11 +342,67 @@ func (rn *remoteNamespace) joinPeerIndex(links []crdt.Link) api.Response {
 		resp.Msg = "Update ok with load failures"
 	}
 
+	rn.loadKeyRecords(joined)
+
 	log.Info("Index replicated to: %s", indexAddr)
 
 	return resp
 }
 
+// filterDelegatedTables keeps the tables of the index for which a link signer is trusted.  Table
+// policy thresholds apply to the table links, and are left to the WritePolicy.
+func (rn *remoteNamespace) filterDelegatedTables(link crdt.Link, index crdt.Index) crdt.Index {
+	delegated := crdt.EmptyIndex()
+
+	for _, table := range index.AllTables() {
+		keys := rn.KeyStore.GetDelegatedPublicKeys(string(table))
+
+		if !link.IsVerifiedByAny(keys) {
+			continue
+		}
+
+		index.ForTable(table, func(tableLink crdt.Link) {
+			delegated = delegated.JoinTable(table, tableLink)
+		})
+	}
+
+	return delegated
+}
+
+// loadKeyRecords reads the revocation and delegation tables in the index into the KeyStore.
+func (rn *remoteNamespace) loadKeyRecords(index crdt.Index) {
+	for _, table := range []crdt.TableName{crdt.REVOCATION_TABLE, crdt.DELEGATION_TABLE} {
+		index.ForTable(table, func(link crdt.Link) {
+			namespace, err := rn.loadNamespace(link.Path())
+
+			if err != nil {
+				log.Error("Failed to load %s at %s: %s", table, link.Path(), err.Error())
+				return
+			}
+
+			rn.putKeyRecords(namespace)
+		})
+	}
+}
+
+func (rn *remoteNamespace) putKeyRecords(namespace crdt.Namespace) {
+	for _, revocation := range crdt.ReadRevocations(namespace) {
+		err := rn.KeyStore.PutRevocation(revocation)
+
+		if err != nil {
+			log.Warn("Ignoring revocation: %s", err.Error())
+		}
+	}
+
+	for _, delegation := range crdt.ReadDelegations(namespace) {
+		err := rn.KeyStore.PutDelegation(delegation)
+
+		if err != nil {
+			log.Warn("Ignoring delegation: %s", err.Error())
+		}
+	}
+}
+
 func (rn *remoteNamespace) loadIndex(indexAddr crdt.IPFSPath) (crdt.Index, error) {
 	const failMsg = "remoteNamespace.loadIndex failed"
 	cached, cacheErr := rn.IndexCache.GetIndex(indexAddr)
@@ -337,11 +434,31 @@ func (rn *remoteNamespace) Reflect(reflect api.ReflectionType, kvq api.Command)
 		runner = api.ResponderLambda(rn.getReflectIndex)
 	case api.REFLECT_DUMP_NAMESPACE:
 		runner = api.ResponderLambda(rn.dumpReflectNamespaces)
+	case api.REFLECT_DIFF:
+		runner = api.ResponderLambda(func() api.Response { return rn.diffReflectIndices(kvq.Request.Diff) })
+	case api.REFLECT_CACHE_STATS:
+		runner = api.ResponderLambda(rn.getReflectCacheStats)
+	case api.REFLECT_JOURNAL:
+		runner = api.ResponderLambda(rn.getReflectJournal)
 	default:
 		panic("Unknown reflection command")
 	}
 
 	response := runner.RunQuery()
+	rn.writeResponse(kvq, response)
+}
+
+// writeResponse answers kvq along with the HEAD, so that the response can be signed without
+// asking for the HEAD again.
+func (rn *remoteNamespace) writeResponse(kvq api.Command, response api.Response) {
+	head, err := rn.getHead()
+
+	if err != nil {
+		log.Warn("Responding without HEAD: %s", err.Error())
+	} else {
+		response.Head = head
+	}
+
 	kvq.WriteResponse(response)
 }
 
@@ -364,6 +481,94 @@ func (rn *remoteNamespace) getReflectHead() api.Response {
 	return response
 }
 
+func (rn *remoteNamespace) getReflectJournal() api.Response {
+	response := api.RESPONSE_REFLECT
+
+	if rn.Journal == nil {
+		return response
+	}
+
+	depth, err := rn.Journal.JournalDepth()
+
+	if err != nil {
+		response = api.RESPONSE_FAIL
+		response.Err = errors.Wrap(err, "remoteNamespace.getReflectJournal failed")
+		response.Type = api.API_REFLECT
+		return response
+	}
+
+	response.JournalDepth = depth
+
+	return response
+}
+
+// getReflectCacheStats reports each named cache once, since the index and namespace caches are
+// often the same object.
+func (rn *remoteNamespace) getReflectCacheStats() api.Response {
+	response := api.RESPONSE_REFLECT
+	response.CacheStats = []api.CacheStats{}
+
+	seen := map[string]struct{}{}
+	for _, cache := range []interface{}{rn.IndexCache, rn.NamespaceCache} {
+		statsCache, ok := cache.(api.StatisticsCache)
+
+		if !ok {
+			continue
+		}
+
+		for _, stats := range statsCache.GetCacheStats() {
+			if _, present := seen[stats.Name]; present {
+				continue
+			}
+
+			seen[stats.Name] = struct{}{}
+			response.CacheStats = append(response.CacheStats, stats)
+		}
+	}
+
+	if verifyCache := crypto.GetVerifyCache(); verifyCache != nil {
+		verifyStats := verifyCache.GetStats()
+		response.CacheStats = append(response.CacheStats, api.CacheStats{
+			Name:      __VERIFY_CACHE_NAME,
+			Hits:      verifyStats.Hits,
+			Misses:    verifyStats.Misses,
+			Evictions: verifyStats.Evictions,
+			Items:     int64(verifyStats.Items),
+		})
+	}
+
+	return response
+}
+
+func (rn *remoteNamespace) IsVerifiedNamespace(namespaceAddr crdt.IPFSPath, digest string) (bool, error) {
+	markers, ok := rn.verifiedMarkers()
+
+	if !ok {
+		return false, nil
+	}
+
+	return markers.IsVerifiedNamespace(namespaceAddr, digest)
+}
+
+func (rn *remoteNamespace) SetVerifiedNamespace(namespaceAddr crdt.IPFSPath, digest string) error {
+	markers, ok := rn.verifiedMarkers()
+
+	if !ok {
+		return nil
+	}
+
+	return markers.SetVerifiedNamespace(namespaceAddr, digest)
+}
+
+func (rn *remoteNamespace) verifiedMarkers() (api.VerifiedNamespaceCache, bool) {
+	if !rn.VerifiedMarkers {
+		return nil, false
+	}
+
+	markers, ok := rn.NamespaceCache.(api.VerifiedNamespaceCache)
+	return markers, ok
+}
+
 func (rn *remoteNamespace) getReflectIndex() api.Response {
 	const failMsg = "remoteNamespace.getReflectIndex failed"
 	response := api.RESPONSE_REFLECT
@@ -396,13 +601,27 @@ func (rn *remoteNamespace) dumpReflectNamespaces() api.Response {
 
 	everything := crdt.EmptyNamespace()
 
+	// Rows streamed from a namespace are kept apart until it has loaded.
+	streamed := crdt.EmptyNamespace()
+	var streamSource crdt.IPFSPath
+	keepStreamed := func() {
+		everything = everything.JoinNamespace(streamed)
+		streamed = crdt.EmptyNamespace()
+		streamSource = crdt.NIL_PATH
+	}
+
 	namespaceError := false
 	indexError := false
 	lambda := api.SearchResultLambda(func(result api.SearchResult) api.TraversalUpdate {
 		more := api.TraversalUpdate{More: true}
 
+		if result.Source != streamSource {
+			keepStreamed()
+		}
+
 		if result.NamespaceLoadFailure {
 			namespaceError = true
+			streamed = crdt.EmptyNamespace()
 			return more
 		}
 
@@ -411,17 +630,27 @@ func (rn *remoteNamespace) dumpReflectNamespaces() api.Response {
 			return api.TraversalUpdate{More: false}
 		}
 
-		everything = everything.JoinNamespace(result.Namespace)
+		if crdt.IsNilPath(result.Source) {
+			everything = everything.JoinNamespace(result.Namespace)
+		} else {
+			streamSource = result.Source
+			streamed = streamed.JoinNamespace(result.Namespace)
+		}
+
 		return more
 	})
 
 	searcher := api.SignedTableSearcher{
-		Keys:   rn.KeyStore.GetAllPublicKeys(),
-		Reader: lambda,
-		Tables: index.AllTables(),
+		Keys:        rn.KeyStore.GetAllPublicKeys(),
+		Revocations: rn.KeyStore.GetAllRevocations(),
+		Delegations: rn.KeyStore.GetAllDelegations(),
+		Policy:      api.MakeWritePolicy(rn.KeyStore),
+		Reader:      lambda,
+		Tables:      index.AllTables(),
 	}
 
 	err = rn.LoadTraverse(searcher)
+	keepStreamed()
 
 	if err != nil {
 		response = api.RESPONSE_FAIL
@@ -446,6 +675,92 @@ func (rn *remoteNamespace) dumpReflectNamespaces() api.Response {
 	return response
 }
 
+func (rn *remoteNamespace) diffReflectIndices(paths []crdt.IPFSPath) api.Response {
+	const failMsg = "remoteNamespace.diffReflectIndices failed"
+	response := api.RESPONSE_REFLECT
+
+	fail := func(err error) api.Response {
+		response = api.RESPONSE_FAIL
+		response.Err = errors.Wrap(err, failMsg)
+		response.Type = api.API_REFLECT
+		return response
+	}
+
+	if len(paths) != 2 {
+		return fail(fmt.Errorf("Expected 2 diff paths but got %d", len(paths)))
+	}
+
+	older, err := rn.loadIndex(paths[0])
+
+	if err != nil {
+		return fail(err)
+	}
+
+	newer, err := rn.loadIndex(paths[1])
+
+	if err != nil {
+		return fail(err)
+	}
+
+	diff := crdt.EmptyNamespaceDiff()
+	for _, table := range crdt.ChangedTables(older, newer) {
+		olderTable, err := rn.loadTableNamespace(older, table)
+
+		if err != nil {
+			return fail(err)
+		}
+
+		newerTable, err := rn.loadTableNamespace(newer, table)
+
+		if err != nil {
+			return fail(err)
+		}
+
+		tableDiff, invalid := crdt.DiffNamespace(olderTable, newerTable)
+
+		if len(invalid) > 0 {
+			log.Warn("Ignored %d invalid entries in diff of table: %s", len(invalid), table)
+		}
+
+		diff = diff.JoinNamespaceDiff(tableDiff)
+	}
+
+	response.Diff = diff
+
+	return response
+}
+
+// loadTableNamespace joins every namespace linked by the table, keeping only that table.
+func (rn *remoteNamespace) loadTableNamespace(index crdt.Index, table crdt.TableName) (crdt.Namespace, error) {
+	const failMsg = "remoteNamespace.loadTableNamespace failed"
+
+	joined := crdt.EmptyNamespace()
+
+	links, err := index.GetTableAddrs(table)
+
+	if err != nil {
+		return joined, nil
+	}
+
+	for _, link := range links {
+		namespace, err := rn.loadNamespace(link.Path())
+
+		if err != nil {
+			return crdt.EmptyNamespace(), errors.Wrap(err, failMsg)
+		}
+
+		found, err := namespace.GetTable(table)
+
+		if err != nil {
+			continue
+		}
+
+		joined = joined.JoinTable(table, found)
+	}
+
+	return joined, nil
+}
+
 // RunQuery will block until the result can be written to kvq.
 func (rn *remoteNamespace) RunQuery(q *query.Query, kvq api.Command) {
 	var runner api.Responder
@@ -466,7 +781,7 @@ func (rn *remoteNamespace) RunQuery(q *query.Query, kvq api.Command) {
 	}
 
 	response := runner.RunQuery()
-	kvq.WriteResponse(response)
+	rn.writeResponse(kvq, response)
 }
 
 // TODO there should be more clarity on who locks and when.
@@ -475,27 +790,247 @@ func (rn *remoteNamespace) JoinTable(tableKey crdt.TableName, table crdt.Table)
 
 	joined := crdt.EmptyNamespace().JoinTable(tableKey, table)
 
+	path, err := rn.joinNamespace(tableKey, joined)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	return path, nil
+}
+
+func (rn *remoteNamespace) IsSigningNamespaces() bool {
+	return rn.SignNamespaces
+}
+
+func (rn *remoteNamespace) JoinSignedTable(tableKey crdt.TableName, table crdt.Table, keys []crypto.PrivateKey) (crdt.IPFSPath, error) {
+	const failMsg = "remoteNamespace.JoinSignedTable failed"
+
+	joined, err := crdt.SignNamespace(crdt.EmptyNamespace().JoinTable(tableKey, table), keys)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	path, err := rn.joinNamespace(tableKey, joined)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	return path, nil
+}
+
+func (rn *remoteNamespace) joinNamespace(tableKey crdt.TableName, joined crdt.Namespace) (crdt.IPFSPath, error) {
+	if tableKey == crdt.REVOCATION_TABLE || tableKey == crdt.DELEGATION_TABLE {
+		rn.putKeyRecords(joined)
+	}
+
+	entry, isJournaled, err := rn.journalJoin(joined)
+
+	if err != nil {
+		return crdt.NIL_PATH, err
+	}
+
+	if isJournaled {
+		return entry.Path(), nil
+	}
+
+	return rn.insertJoin(joined)
+}
+
+// insertJoin adds the namespace, then an index linking each of its tables to it.
+func (rn *remoteNamespace) insertJoin(joined crdt.Namespace) (crdt.IPFSPath, error) {
 	addr, nsErr := rn.insertNamespace(joined)
 
 	if nsErr != nil {
-		return crdt.NIL_PATH, errors.Wrap(nsErr, failMsg)
+		return crdt.NIL_PATH, nsErr
 	}
 
 	signed, signErr := crdt.SignedLink(addr, rn.KeyStore.GetAllPrivateKeys())
 
 	if signErr != nil {
-		return crdt.NIL_PATH, errors.Wrap(signErr, failMsg)
+		return crdt.NIL_PATH, signErr
 	}
 
-	index := crdt.EmptyIndex().JoinTable(tableKey, signed)
+	index := crdt.EmptyIndex()
+	for _, tableKey := range joined.GetTableNames() {
+		if tableKey == crdt.NAMESPACE_SIGNATURE_TABLE {
+			continue
+		}
+
+		index = index.JoinTable(tableKey, signed)
+	}
 
-	indexAddr, indexErr := rn.insertIndex(index)
+	return rn.insertIndex(index)
+}
 
-	if indexErr != nil {
-		return crdt.NIL_PATH, errors.Wrap(indexErr, failMsg)
+// journalJoin appends to the journal if the Store is down, or if earlier joins are still waiting.
+func (rn *remoteNamespace) journalJoin(joined crdt.Namespace) (api.JournalEntry, bool, error) {
+	if rn.Journal == nil {
+		return api.JournalEntry{}, false, nil
 	}
 
-	return indexAddr, nil
+	rn.journalLock.Lock()
+	defer rn.journalLock.Unlock()
+
+	depth, err := rn.Journal.JournalDepth()
+
+	if err != nil {
+		return api.JournalEntry{}, false, err
+	}
+
+	if depth == 0 && rn.isStoreUp() {
+		return api.JournalEntry{}, false, nil
+	}
+
+	entry, err := rn.Journal.AppendJoin(joined)
+
+	if err != nil {
+		return api.JournalEntry{}, false, err
+	}
+
+	_, err = rn.journalLink(entry.Path())
+
+	if err != nil {
+		return api.JournalEntry{}, false, err
+	}
+
+	log.Warn("Journaled join until the Store is up: %s", entry.Path())
+
+	return entry, true, nil
+}
+
+func (rn *remoteNamespace) isStoreUp() bool {
+	pinger, ok := rn.Store.(api.PingablePeer)
+	return !ok || pinger.IsUp()
+}
+
+func (rn *remoteNamespace) isJournaling() bool {
+	return rn.Journal != nil && !rn.isStoreUp()
+}
+
+func (rn *remoteNamespace) journalFlushLoop(interval time.Duration) {
+	defer rn.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := rn.flushJournal()
+
+			if err != nil {
+				log.Error("Failed to flush journal: %s", err.Error())
+			}
+		case <-rn.stopch:
+			return
+		}
+	}
+}
+
+// flushJournal writes journaled joins to the Store in the order they were made.  It stops at the
+// first failure, so that later joins are not written before earlier ones.
+func (rn *remoteNamespace) flushJournal() error {
+	const failMsg = "remoteNamespace.flushJournal failed"
+
+	rn.journalLock.Lock()
+	defer rn.journalLock.Unlock()
+
+	if !rn.isStoreUp() {
+		return nil
+	}
+
+	entries, err := rn.Journal.GetJoins()
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	for _, entry := range entries {
+		indexAddr, err := rn.insertJoin(entry.Namespace)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		err = rn.Journal.RemoveJoin(entry.ID)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		rn.forgetJournalLink(entry.Path())
+
+		log.Info("Flushed journaled join %s to: %s", entry.Path(), indexAddr)
+	}
+
+	return nil
+}
+
+func (rn *remoteNamespace) IsLargeValue(text crdt.PointText) bool {
+	if rn.BlobThreshold <= 0 || len(text) <= rn.BlobThreshold {
+		return false
+	}
+
+	// Journaled joins keep their values inline, since blobs cannot be written while offline.
+	if rn.isJournaling() {
+		return false
+	}
+
+	_, isBlobStore := rn.Store.(api.BlobStore)
+	return isBlobStore
+}
+
+func (rn *remoteNamespace) AddBlob(text crdt.PointText) (crdt.BlobReference, error) {
+	const failMsg = "remoteNamespace.AddBlob failed"
+
+	blobStore, ok := rn.Store.(api.BlobStore)
+
+	if !ok {
+		return crdt.BlobReference{}, errors.New("Store does not support blobs")
+	}
+
+	path, err := blobStore.AddBlob(strings.NewReader(string(text)))
+
+	if err != nil {
+		return crdt.BlobReference{}, errors.Wrap(err, failMsg)
+	}
+
+	ref := crdt.BlobReference{Path: path, Size: len(text)}
+	return ref, nil
+}
+
+func (rn *remoteNamespace) CatBlob(ref crdt.BlobReference) (crdt.PointText, error) {
+	const failMsg = "remoteNamespace.CatBlob failed"
+
+	blobStore, ok := rn.Store.(api.BlobStore)
+
+	if !ok {
+		return "", errors.New("Store does not support blobs")
+	}
+
+	reader, err := blobStore.CatBlob(ref.Path)
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	defer reader.Close()
+
+	// Read one byte past the expected size, so an oversized blob is caught without reading all of it.
+	data, err := ioutil.ReadAll(io.LimitReader(reader, int64(ref.Size)+1))
+
+	if err != nil {
+		return "", errors.Wrap(err, failMsg)
+	}
+
+	if len(data) != ref.Size {
+		return "", fmt.Errorf("Blob size was %d but reference expected %d", len(data), ref.Size)
+	}
+
+	return crdt.PointText(data), nil
 }
 
 func (rn *remoteNamespace) LoadTraverse(searcher api.NamespaceSearcher) error {
@@ -509,9 +1044,114 @@ func (rn *remoteNamespace) LoadTraverse(searcher api.NamespaceSearcher) error {
 		return errors.Wrap(indexerr, failMsg)
 	}
 
+	journaled, err := rn.joinJournalIndex(index)
+
+	if err != nil {
+		log.Error("Searching without journaled joins: %s", err.Error())
+	} else {
+		index = journaled.index
+	}
+
 	tableAddrs := searcher.Search(index)
+	storeAddrs := make([]crdt.Link, 0, len(tableAddrs))
+	journalAddrs := []crdt.Link{}
+
+	for _, link := range tableAddrs {
+		if api.IsProvisionalPath(link.Path()) {
+			journalAddrs = append(journalAddrs, link)
+		} else {
+			storeAddrs = append(storeAddrs, link)
+		}
+	}
+
+	err = rn.traverseTableNamespaces(storeAddrs, searcher)
+
+	if err != nil {
+		return err
+	}
+
+	for _, link := range journalAddrs {
+		update := searcher.ReadSearchResult(api.SearchResult{Namespace: journaled.namespaces[link.Path()]})
+
+		if update.Error != nil {
+			return errors.Wrap(update.Error, failMsg)
+		}
+
+		if !update.More {
+			return nil
+		}
+	}
+
+	return nil
+}
 
-	return rn.traverseTableNamespaces(tableAddrs, searcher)
+type journalIndex struct {
+	index      crdt.Index
+	namespaces map[crdt.IPFSPath]crdt.Namespace
+}
+
+// joinJournalIndex links journaled joins into the index under their provisional paths, signed as
+// they will be when flushed, so local searches treat them like any other namespace.
+func (rn *remoteNamespace) joinJournalIndex(index crdt.Index) (journalIndex, error) {
+	journaled := journalIndex{
+		index:      index,
+		namespaces: map[crdt.IPFSPath]crdt.Namespace{},
+	}
+
+	if rn.Journal == nil {
+		return journaled, nil
+	}
+
+	entries, err := rn.Journal.GetJoins()
+
+	if err != nil {
+		return journaled, err
+	}
+
+	for _, entry := range entries {
+		path := entry.Path()
+		signed, err := rn.journalLink(path)
+
+		if err != nil {
+			return journaled, err
+		}
+
+		for _, tableKey := range entry.Namespace.GetTableNames() {
+			journaled.index = journaled.index.JoinTable(tableKey, signed)
+		}
+
+		journaled.namespaces[path] = entry.Namespace
+	}
+
+	return journaled, nil
+}
+
+// journalLink signs the link to a journaled join once, when it is written.  Joins journaled by
+// an earlier run are signed the first time they are searched.
+func (rn *remoteNamespace) journalLink(path crdt.IPFSPath) (crdt.Link, error) {
+	rn.journalLinksLock.Lock()
+	defer rn.journalLinksLock.Unlock()
+
+	if link, present := rn.journalLinks[path]; present {
+		return link, nil
+	}
+
+	link, err := crdt.SignedLink(path, rn.KeyStore.GetAllPrivateKeys())
+
+	if err != nil {
+		return crdt.Link{}, err
+	}
+
+	rn.journalLinks[path] = link
+
+	return link, nil
+}
+
+func (rn *remoteNamespace) forgetJournalLink(path crdt.IPFSPath) {
+	rn.journalLinksLock.Lock()
+	defer rn.journalLinksLock.Unlock()
+
+	delete(rn.journalLinks, path)
 }
 
 func (rn *remoteNamespace) traverseTableNamespaces(tableAddrs []crdt.Link, f api.SearchResultTraverser) error {
@@ -539,26 +1179,22 @@ func (rn *remoteNamespace) namespaceLoader(addrs []crdt.Link) (<-chan api.Search
 	resultch := make(chan api.SearchResult)
 	cancelch := make(chan struct{}, 1)
 
+	send := func(result api.SearchResult) bool {
+		select {
+		case <-rn.stopch:
+			return false
+		case <-cancelch:
+			return false
+		case resultch <- result:
+			return true
+		}
+	}
+
 	go func() {
 		defer close(resultch)
 		for _, a := range addrs {
-			namespace, err := rn.loadNamespace(a.Path())
-
-			if err != nil {
-				log.Error("remoteNamespace.namespaceLoader: %s", err.Error())
-				resultch <- api.SearchResult{NamespaceLoadFailure: true}
-				continue
-			}
-
-			log.Info("Catted namespace from: %s", a.Path())
-			successResult := api.SearchResult{Namespace: namespace}
-			select {
-			case <-rn.stopch:
-				return
-			case <-cancelch:
+			if !rn.sendNamespace(a.Path(), send) {
 				return
-			case resultch <- successResult:
-				break
 			}
 		}
 	}()
@@ -566,6 +1202,72 @@ func (rn *remoteNamespace) namespaceLoader(addrs []crdt.Link) (<-chan api.Search
 	return resultch, cancelch
 }
 
+// sendNamespace returns false when the traversal is over.
+func (rn *remoteNamespace) sendNamespace(namespaceAddr crdt.IPFSPath, send func(api.SearchResult) bool) bool {
+	if streamer, ok := rn.Store.(api.NamespaceStreamStore); ok {
+		return rn.streamNamespace(streamer, namespaceAddr, send)
+	}
+
+	namespace, err := rn.loadNamespace(namespaceAddr)
+
+	if err != nil {
+		log.Error("remoteNamespace.namespaceLoader: %s", err.Error())
+		return send(api.SearchResult{NamespaceLoadFailure: true})
+	}
+
+	log.Info("Catted namespace from: %s", namespaceAddr)
+	return send(api.SearchResult{Namespace: namespace, Path: namespaceAddr})
+}
+
+// Send each row to the searcher while the namespace is still being read.  If the load fails
+// part way, the failure names the namespace so that the searcher can drop the rows it was sent.
+// Namespace signatures cover the whole namespace, so signed namespaces are sent whole.  The
+// signature table is streamed first, so they are recognised by their first row.
+func (rn *remoteNamespace) streamNamespace(streamer api.NamespaceStreamStore, namespaceAddr crdt.IPFSPath, send func(api.SearchResult) bool) bool {
+	cached, cacheErr := rn.NamespaceCache.GetNamespace(namespaceAddr)
+
+	if cacheErr == nil {
+		return send(api.SearchResult{Namespace: cached, Path: namespaceAddr})
+	}
+
+	isFirst := true
+	isSigned := false
+	more := true
+	signed := []crdt.Namespace{}
+	err := streamer.CatNamespaceRows(namespaceAddr, func(row crdt.Namespace) bool {
+		if isFirst {
+			isSigned = row.IsNamespaceSigned()
+			isFirst = false
+		}
+
+		if isSigned {
+			signed = append(signed, row)
+			return true
+		}
+
+		more = send(api.SearchResult{Namespace: row, Source: namespaceAddr})
+		return more
+	})
+
+	if !more {
+		return false
+	}
+
+	if err != nil {
+		log.Error("remoteNamespace.namespaceLoader: %s", err.Error())
+		return send(api.SearchResult{NamespaceLoadFailure: true, Source: namespaceAddr})
+	}
+
+	log.Info("Streamed namespace from: %s", namespaceAddr)
+
+	if isSigned {
+		whole := crdt.JoinAllNamespaces(signed)
+		return send(api.SearchResult{Namespace: whole, Path: namespaceAddr})
+	}
+
+	return true
+}
+
 func (rn *remoteNamespace) loadNamespace(namespaceAddr crdt.IPFSPath) (crdt.Namespace, error) {
 	const failMsg = "remoteNamespace.loadNamespace failed"
 
@@ -749,3 +1451,4 @@ type addIndexRequest struct {
 
 const __DEFAULT_PULSE = time.Second * 10
 const __REMOTE_NAMESPACE_PROCESS_COUNT = 3
+const __VERIFY_CACHE_NAME = "verify"
@@ -26,6 +26,9 @@ type replicator struct {
 	errch    chan<- error
 	stopch   <-chan struct{}
 	keyStore api.KeyStore
+	// summaryStore and summarizer are set when the store can exchange index summaries.
+	summaryStore api.SummaryStore
+	summarizer   *indexSummarizer
 }
 
 // TODO support one-shot replication.
@@ -48,6 +51,12 @@ func Replicate(options ReplicateOptions) (api.Closer, <-chan error) {
 		stopch:   stopch,
 	}
 
+	if summaryStore, ok := options.RemoteStore.(api.SummaryStore); ok {
+		p2p.summaryStore = summaryStore
+		expiry := interval * __FETCHED_TABLE_INTERVALS
+		p2p.summarizer = makeIndexSummarizer(options.RemoteStore, options.KeyStore, expiry)
+	}
+
 	wg := &sync.WaitGroup{}
 	wg.Add(__REPLICATION_PROCESS_COUNT)
 	go func() {
@@ -86,7 +95,7 @@ func (p2p replicator) publishAllTopics() {
 		select {
 		case <-p2p.stopch:
 			log.Info("Stop publishing")
-			break
+			return
 		case <-ticker.C:
 			p2p.publishIndex()
 		}
@@ -122,6 +131,15 @@ func (p2p replicator) publishIndex() {
 		link = crdt.UnsignedLink(head)
 	}
 
+	// Older peers cannot read summaries, so we always publish the bare link too.
+	if p2p.summaryStore != nil {
+		err = p2p.publishSummary(link)
+
+		if err != nil {
+			log.Error("Failed to publish index summary: %s", err.Error())
+		}
+	}
+
 	err = p2p.store.PublishAddr(link, p2p.topics)
 
 	if err != nil {
@@ -132,6 +150,24 @@ func (p2p replicator) publishIndex() {
 	log.Info("Published index at %v", head)
 }
 
+func (p2p replicator) publishSummary(head crdt.Link) error {
+	const failMsg = "replicator.publishSummary failed"
+
+	summary, err := p2p.summarizer.summarize(head)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	err = p2p.summaryStore.PublishSummary(summary, p2p.topics)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	return nil
+}
+
 func (p2p replicator) sendReflectRequest() (api.Response, error) {
 	log.Info("Replicator getting HEAD from API...")
 	failResp := api.RESPONSE_FAIL
@@ -203,14 +239,50 @@ func (batch *subscriptionBatch) update(link crdt.Link) {
 	log.Debug("Appended Link to subscriptionBatch")
 }
 
+// flush sends links that arrived since the last tick, so they are not held until the next link.
+func (batch *subscriptionBatch) flush() {
+	if len(batch.batch) == 0 {
+		return
+	}
+
+	log.Debug("Dispatching subscription batch...")
+	batch.sendRequest()
+}
+
 func (batch *subscriptionBatch) sendRequest() {
 	request := api.Request{Type: api.API_REPLICATE, Replicate: batch.batch}
 	batch.reset()
 	go func() {
-		batch.p2p.api.Call(request)
+		if batch.p2p.summarizer == nil {
+			batch.p2p.api.Call(request)
+			return
+		}
+
+		success := batch.p2p.sendReplicateRequest(request)
+		batch.p2p.summarizer.finishFetch(request.Replicate, success)
 	}()
 }
 
+// sendReplicateRequest waits for the API to replicate, so that the summarizer
+// only remembers tables that were fetched.
+func (p2p replicator) sendReplicateRequest(request api.Request) bool {
+	respch, err := p2p.api.Call(request)
+
+	if err != nil {
+		log.Error("Replication failed (Early API failure): %s", err.Error())
+		return false
+	}
+
+	resp := <-respch
+
+	if resp.Err != nil {
+		log.Error("Replication failed: %s", resp.Err.Error())
+		return false
+	}
+
+	return true
+}
+
 func (batch *subscriptionBatch) stop() {
 	batch.ticker.Stop()
 }
@@ -220,7 +292,7 @@ func (batch *subscriptionBatch) reset() {
 }
 
 func (p2p replicator) subscribeTopic(topic api.PubSubTopic) {
-	headch, errch := p2p.store.SubscribeAddrStream(topic)
+	headch, errch := p2p.subscribeLinks(topic)
 
 	batch := &subscriptionBatch{
 		ticker: time.NewTicker(p2p.interval),
@@ -239,9 +311,11 @@ func (p2p replicator) subscribeTopic(topic api.PubSubTopic) {
 				}
 
 				batch.update(head)
+			case <-batch.ticker.C:
+				batch.flush()
 			case err, present := <-errch:
 				if !present {
-					break
+					return
 				}
 				log.Info("Subscription error: %s", err.Error())
 				return
@@ -254,5 +328,130 @@ func (p2p replicator) subscribeTopic(topic api.PubSubTopic) {
 	}()
 }
 
+func (p2p replicator) subscribeLinks(topic api.PubSubTopic) (<-chan crdt.Link, <-chan error) {
+	if p2p.summaryStore == nil {
+		return p2p.store.SubscribeAddrStream(topic)
+	}
+
+	summarych, summaryErrch := p2p.summaryStore.SubscribeSummaryStream(topic)
+	addrch, addrErrch := p2p.store.SubscribeAddrStream(topic)
+	linkch := make(chan crdt.Link)
+	errch := make(chan error)
+
+	send := func(link crdt.Link) bool {
+		select {
+		case linkch <- link:
+			return true
+		case <-p2p.stopch:
+			return false
+		}
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(4)
+
+	go func() {
+		defer wg.Done()
+
+		for {
+			var summary crdt.IndexSummary
+			select {
+			case next, present := <-summarych:
+				if !present {
+					return
+				}
+				summary = next
+			case <-p2p.stopch:
+				return
+			}
+
+			for _, link := range p2p.summaryLinks(summary) {
+				if !send(link) {
+					return
+				}
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for {
+			var link crdt.Link
+			select {
+			case next, present := <-addrch:
+				if !present {
+					return
+				}
+				link = next
+			case <-p2p.stopch:
+				return
+			}
+
+			if p2p.summarizer.isSummarized(link.Path()) {
+				log.Debug("Skipping bare link with summary: %s", link.Path())
+				continue
+			}
+
+			if !send(link) {
+				return
+			}
+		}
+	}()
+
+	for _, suberrch := range []<-chan error{summaryErrch, addrErrch} {
+		go func(suberrch <-chan error) {
+			defer wg.Done()
+
+			for err := range suberrch {
+				select {
+				case errch <- err:
+				case <-p2p.stopch:
+					return
+				}
+			}
+		}(suberrch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(linkch)
+		close(errch)
+	}()
+
+	return linkch, errch
+}
+
+// isTrustedHead is true if the index link passes the check that the API makes on bare links.
+func (p2p replicator) isTrustedHead(head crdt.Link) bool {
+	return head.IsVerifiedByAny(p2p.keyStore.GetDelegatedPublicKeys(""))
+}
+
+// summaryLinks finds the links we need to replicate from a peer summary.
+func (p2p replicator) summaryLinks(summary crdt.IndexSummary) []crdt.Link {
+	if summary.IsLinkOnly() {
+		return []crdt.Link{summary.Head}
+	}
+
+	head := crdt.NIL_PATH
+	resp, err := p2p.sendReflectRequest()
+
+	if err == nil {
+		head = resp.Path
+	} else {
+		log.Warn("No HEAD to compare with peer summary: %s", err.Error())
+	}
+
+	links, err := p2p.summarizer.differentTables(head, summary, p2p.isTrustedHead(summary.Head))
+
+	if err != nil {
+		log.Error("Failed to compare peer summary, replicating full index: %s", err.Error())
+		return []crdt.Link{summary.Head}
+	}
+
+	return links
+}
+
 const __REPLICATION_PROCESS_COUNT = 2
 const __DEFAULT_REPLICATE_INTERVAL = time.Second * 10
+const __FETCHED_TABLE_INTERVALS = 6
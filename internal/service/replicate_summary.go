@@ -0,0 +1,323 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/log"
+	"github.com/pkg/errors"
+)
+
+// indexSummarizer maintains the Merkle summary of the local HEAD, so that the
+// replicator can publish it and compare it against summaries from peers.
+type indexSummarizer struct {
+	sync.Mutex
+	store    api.RemoteStore
+	keyStore api.KeyStore
+	expiry   time.Duration
+	head     crdt.IPFSPath
+	index    crdt.Index
+	hashes   map[crdt.TableName]crdt.TableHash
+	root     crdt.TableHash
+	// tables are the table summaries we have published, reused while their hash is unchanged.
+	tables map[crdt.TableName]crdt.TableSummary
+	// fetched are table links we have recently replicated.  After joining a peer
+	// table our hash will differ from theirs if we had extra data, so we remember
+	// what we fetched to avoid fetching it again.
+	fetched map[crdt.IPFSPath]time.Time
+	// pending are table links we have asked to replicate, but that have not yet succeeded.
+	pending map[crdt.IPFSPath]time.Time
+	// heads are index paths whose summarized tables we have fetched, so their bare links may be
+	// skipped.
+	heads map[crdt.IPFSPath]time.Time
+	// waiting are index paths from trusted summaries, with the tables we still need to fetch.
+	waiting map[crdt.IPFSPath]summarizedHead
+}
+
+type summarizedHead struct {
+	tables map[crdt.IPFSPath]struct{}
+	noted  time.Time
+}
+
+func makeIndexSummarizer(store api.RemoteStore, keyStore api.KeyStore, expiry time.Duration) *indexSummarizer {
+	return &indexSummarizer{
+		store:    store,
+		keyStore: keyStore,
+		expiry:   expiry,
+		index:    crdt.EmptyIndex(),
+		hashes:   map[crdt.TableName]crdt.TableHash{},
+		tables:   map[crdt.TableName]crdt.TableSummary{},
+		fetched:  map[crdt.IPFSPath]time.Time{},
+		pending:  map[crdt.IPFSPath]time.Time{},
+		heads:    map[crdt.IPFSPath]time.Time{},
+		waiting:  map[crdt.IPFSPath]summarizedHead{},
+	}
+}
+
+// summarize builds the summary of our HEAD.  Each table is added to the store as an
+// Index of its own, so peers can fetch it separately.
+func (summarizer *indexSummarizer) summarize(head crdt.Link) (crdt.IndexSummary, error) {
+	const failMsg = "indexSummarizer.summarize failed"
+
+	summarizer.Lock()
+	defer summarizer.Unlock()
+
+	err := summarizer.loadHead(head.Path())
+
+	if err != nil {
+		return crdt.IndexSummary{}, errors.Wrap(err, failMsg)
+	}
+
+	tableNames := make([]crdt.TableName, 0, len(summarizer.hashes))
+	for table := range summarizer.hashes {
+		tableNames = append(tableNames, table)
+	}
+
+	sort.Sort(byTableName(tableNames))
+
+	summary := crdt.IndexSummary{
+		Head:   head,
+		Root:   summarizer.root,
+		Tables: make([]crdt.TableSummary, 0, len(tableNames)),
+	}
+
+	tables := map[crdt.TableName]crdt.TableSummary{}
+	for _, table := range tableNames {
+		tableSummary, err := summarizer.summarizeTable(table)
+
+		if err != nil {
+			return crdt.IndexSummary{}, errors.Wrap(err, failMsg)
+		}
+
+		tables[table] = tableSummary
+		summary.Tables = append(summary.Tables, tableSummary)
+	}
+
+	summarizer.tables = tables
+	summarizer.heads[head.Path()] = time.Now()
+
+	return summary, nil
+}
+
+func (summarizer *indexSummarizer) summarizeTable(table crdt.TableName) (crdt.TableSummary, error) {
+	const failMsg = "indexSummarizer.summarizeTable failed"
+
+	hash := summarizer.hashes[table]
+
+	if published, present := summarizer.tables[table]; present && published.Hash == hash {
+		return published, nil
+	}
+
+	tableIndex := summarizer.index.TableIndex(table)
+	path, err := summarizer.store.AddIndex(tableIndex)
+
+	if err != nil {
+		return crdt.TableSummary{}, errors.Wrap(err, failMsg)
+	}
+
+	link, err := summarizer.signLink(path)
+
+	if err != nil {
+		return crdt.TableSummary{}, errors.Wrap(err, failMsg)
+	}
+
+	tableSummary := crdt.TableSummary{
+		TableName: table,
+		Hash:      hash,
+		Link:      link,
+	}
+
+	return tableSummary, nil
+}
+
+func (summarizer *indexSummarizer) signLink(path crdt.IPFSPath) (crdt.Link, error) {
+	privKeys := summarizer.keyStore.GetAllPrivateKeys()
+
+	if len(privKeys) == 0 {
+		return crdt.UnsignedLink(path), nil
+	}
+
+	return crdt.SignedLink(path, privKeys)
+}
+
+// differentTables finds the links to peer tables that differ from those at our HEAD.
+// An empty HEAD differs from every table.  If isTrusted, the summary head is noted so that its
+// bare link can be skipped once the tables have been fetched.
+func (summarizer *indexSummarizer) differentTables(head crdt.IPFSPath, summary crdt.IndexSummary, isTrusted bool) ([]crdt.Link, error) {
+	const failMsg = "indexSummarizer.differentTables failed"
+
+	summarizer.Lock()
+	defer summarizer.Unlock()
+
+	if !crdt.IsNilPath(head) {
+		err := summarizer.loadHead(head)
+
+		if err != nil {
+			return nil, errors.Wrap(err, failMsg)
+		}
+	}
+
+	summarizer.expireFetched()
+
+	if isTrusted {
+		summarizer.noteHead(summary)
+	}
+
+	if summary.Root == summarizer.root {
+		log.Debug("Peer index summary matches HEAD")
+		return []crdt.Link{}, nil
+	}
+
+	different := summary.DifferentTables(summarizer.hashes)
+	links := make([]crdt.Link, 0, len(different))
+
+	for _, table := range different {
+		path := table.Link.Path()
+
+		if _, present := summarizer.fetched[path]; present {
+			continue
+		}
+
+		if _, present := summarizer.pending[path]; present {
+			continue
+		}
+
+		summarizer.pending[path] = time.Now()
+		links = append(links, table.Link)
+	}
+
+	log.Info("Peer index summary has %d new tables", len(links))
+
+	return links, nil
+}
+
+// finishFetch records the outcome of replicating links.  Failed links may be fetched again.
+func (summarizer *indexSummarizer) finishFetch(links []crdt.Link, success bool) {
+	summarizer.Lock()
+	defer summarizer.Unlock()
+
+	for _, link := range links {
+		path := link.Path()
+
+		if _, present := summarizer.pending[path]; !present {
+			continue
+		}
+
+		delete(summarizer.pending, path)
+
+		if success {
+			summarizer.fetched[path] = time.Now()
+			summarizer.finishHeads(path)
+		}
+	}
+}
+
+// finishHeads marks the heads whose last outstanding table was fetched.
+func (summarizer *indexSummarizer) finishHeads(table crdt.IPFSPath) {
+	for head, waiting := range summarizer.waiting {
+		delete(waiting.tables, table)
+
+		if len(waiting.tables) == 0 {
+			delete(summarizer.waiting, head)
+			summarizer.heads[head] = time.Now()
+		}
+	}
+}
+
+// noteHead remembers a trusted summary for the index at its head.  The head counts as
+// summarized after every table that differs from our HEAD has been fetched.
+func (summarizer *indexSummarizer) noteHead(summary crdt.IndexSummary) {
+	head := summary.Head.Path()
+	tables := map[crdt.IPFSPath]struct{}{}
+
+	if summary.Root != summarizer.root {
+		for _, table := range summary.DifferentTables(summarizer.hashes) {
+			path := table.Link.Path()
+
+			if _, present := summarizer.fetched[path]; !present {
+				tables[path] = struct{}{}
+			}
+		}
+	}
+
+	if len(tables) == 0 {
+		summarizer.heads[head] = time.Now()
+		return
+	}
+
+	summarizer.waiting[head] = summarizedHead{tables: tables, noted: time.Now()}
+}
+
+// isSummarized is true if we have fetched the tables from a summary of the index at head.  A
+// summary publisher also sends a bare link for older peers, which we need not replicate.
+func (summarizer *indexSummarizer) isSummarized(head crdt.IPFSPath) bool {
+	summarizer.Lock()
+	defer summarizer.Unlock()
+
+	summarizer.expireFetched()
+
+	_, present := summarizer.heads[head]
+	return present
+}
+
+func (summarizer *indexSummarizer) expireFetched() {
+	now := time.Now()
+
+	for _, times := range []map[crdt.IPFSPath]time.Time{summarizer.fetched, summarizer.pending, summarizer.heads} {
+		for path, fetchTime := range times {
+			if now.Sub(fetchTime) > summarizer.expiry {
+				delete(times, path)
+			}
+		}
+	}
+
+	for head, waiting := range summarizer.waiting {
+		if now.Sub(waiting.noted) > summarizer.expiry {
+			delete(summarizer.waiting, head)
+		}
+	}
+}
+
+func (summarizer *indexSummarizer) loadHead(head crdt.IPFSPath) error {
+	const failMsg = "indexSummarizer.loadHead failed"
+
+	if head == summarizer.head {
+		return nil
+	}
+
+	index, err := summarizer.store.CatIndex(head)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	hashes, invalid := crdt.HashIndexTables(index)
+
+	if len(invalid) > 0 {
+		log.Warn("Index summary ignored %d invalid entries", len(invalid))
+	}
+
+	summarizer.head = head
+	summarizer.index = index
+	summarizer.hashes = hashes
+	summarizer.root = crdt.HashRoot(hashes)
+
+	return nil
+}
+
+type byTableName []crdt.TableName
+
+func (tables byTableName) Len() int {
+	return len(tables)
+}
+
+func (tables byTableName) Swap(i, j int) {
+	tables[i], tables[j] = tables[j], tables[i]
+}
+
+func (tables byTableName) Less(i, j int) bool {
+	return tables[i] < tables[j]
+}
@@ -0,0 +1,622 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	pb "github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/log"
+	"github.com/johnny-morrice/godless/proto"
+)
+
+type SnapshotExportOptions struct {
+	Store api.RemoteStore
+	Head  crdt.IPFSPath
+	// Keys are optional.  They sign the snapshot manifest.
+	Keys   []crypto.PrivateKey
+	Output io.Writer
+}
+
+type SnapshotImportOptions struct {
+	Store       api.RemoteStore
+	MemoryImage api.MemoryImage
+	KeyStore    api.KeyStore
+	Input       io.Reader
+	// IsPublic accepts snapshots that are not signed by a known public key.
+	IsPublic bool
+}
+
+type snapshotEntryType uint32
+
+const (
+	__SNAPSHOT_INDEX = snapshotEntryType(iota)
+	__SNAPSHOT_NAMESPACE
+	__SNAPSHOT_BLOB
+)
+
+type snapshotBlob struct {
+	entryType snapshotEntryType
+	path      crdt.IPFSPath
+	data      []byte
+}
+
+// archiveName fails for unknown entry types, since they may come from an untrusted manifest.
+func (blob snapshotBlob) archiveName() (string, error) {
+	switch blob.entryType {
+	case __SNAPSHOT_INDEX:
+		return __SNAPSHOT_INDEX_DIR + string(blob.path), nil
+	case __SNAPSHOT_NAMESPACE:
+		return __SNAPSHOT_NAMESPACE_DIR + string(blob.path), nil
+	case __SNAPSHOT_BLOB:
+		return __SNAPSHOT_BLOB_DIR + string(blob.path), nil
+	default:
+		return "", fmt.Errorf("Unknown snapshot entry type: %v", blob.entryType)
+	}
+}
+
+func (blob snapshotBlob) digest() string {
+	sum := sha256.Sum256(blob.data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ExportSnapshot writes a tar archive containing the index at HEAD, all the
+// namespaces it links to, the large values they refer to, and a signed manifest of their digests.
+func ExportSnapshot(options SnapshotExportOptions) error {
+	const failMsg = "ExportSnapshot failed"
+
+	if crdt.IsNilPath(options.Head) {
+		return errors.New("No HEAD to export")
+	}
+
+	blobs, err := collectSnapshotBlobs(options.Store, options.Head)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	manifest := makeSnapshotManifest(options.Head, blobs)
+
+	err = signSnapshotManifest(manifest, options.Keys)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	err = writeSnapshot(options.Output, manifest, blobs)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	log.Info("Exported snapshot of %d blobs from: %s", len(blobs), options.Head)
+
+	return nil
+}
+
+func collectSnapshotBlobs(store api.RemoteStore, head crdt.IPFSPath) ([]snapshotBlob, error) {
+	const failMsg = "collectSnapshotBlobs failed"
+
+	index, err := store.CatIndex(head)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	indexBuff := &bytes.Buffer{}
+	_, err = crdt.EncodeIndex(index, indexBuff)
+
+	if err != nil {
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	blobs := []snapshotBlob{
+		snapshotBlob{entryType: __SNAPSHOT_INDEX, path: head, data: indexBuff.Bytes()},
+	}
+
+	seen := map[crdt.IPFSPath]struct{}{}
+	for _, table := range index.AllTables() {
+		links, _ := index.GetTableAddrs(table)
+
+		for _, link := range links {
+			path := link.Path()
+
+			if _, present := seen[path]; present {
+				continue
+			}
+
+			seen[path] = struct{}{}
+
+			namespace, err := store.CatNamespace(path)
+
+			if err != nil {
+				return nil, errors.Wrap(err, failMsg)
+			}
+
+			namespaceBuff := &bytes.Buffer{}
+			_, err = crdt.EncodeNamespace(namespace, namespaceBuff)
+
+			if err != nil {
+				return nil, errors.Wrap(err, failMsg)
+			}
+
+			blob := snapshotBlob{entryType: __SNAPSHOT_NAMESPACE, path: path, data: namespaceBuff.Bytes()}
+			blobs = append(blobs, blob)
+
+			for _, ref := range namespace.BlobReferences() {
+				if _, present := seen[ref.Path]; present {
+					continue
+				}
+
+				seen[ref.Path] = struct{}{}
+
+				valueBlob, err := collectSnapshotValue(store, ref)
+
+				if err != nil {
+					return nil, errors.Wrap(err, failMsg)
+				}
+
+				blobs = append(blobs, valueBlob)
+			}
+		}
+	}
+
+	return blobs, nil
+}
+
+// collectSnapshotValue reads the whole of a large value, so it can be imported into a store that
+// chunks differently.
+func collectSnapshotValue(store api.RemoteStore, ref crdt.BlobReference) (snapshotBlob, error) {
+	const failMsg = "collectSnapshotValue failed"
+
+	blobStore, ok := store.(api.BlobStore)
+
+	if !ok {
+		return snapshotBlob{}, fmt.Errorf("Store cannot read blob: %s", ref.Path)
+	}
+
+	reader, err := blobStore.CatBlob(ref.Path)
+
+	if err != nil {
+		return snapshotBlob{}, errors.Wrap(err, failMsg)
+	}
+
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return snapshotBlob{}, errors.Wrap(err, failMsg)
+	}
+
+	return snapshotBlob{entryType: __SNAPSHOT_BLOB, path: ref.Path, data: data}, nil
+}
+
+func makeSnapshotManifest(head crdt.IPFSPath, blobs []snapshotBlob) *proto.SnapshotManifestMessage {
+	manifest := &proto.SnapshotManifestMessage{
+		Version: __SNAPSHOT_VERSION,
+		Head:    string(head),
+		Entries: make([]*proto.SnapshotEntryMessage, len(blobs)),
+	}
+
+	for i, blob := range blobs {
+		manifest.Entries[i] = &proto.SnapshotEntryMessage{
+			Type:   uint32(blob.entryType),
+			Path:   string(blob.path),
+			Digest: blob.digest(),
+		}
+	}
+
+	return manifest
+}
+
+// The signatures cover the manifest without its signatures.
+func snapshotManifestText(manifest *proto.SnapshotManifestMessage) ([]byte, error) {
+	unsigned := *manifest
+	unsigned.Signatures = nil
+	return pb.Marshal(&unsigned)
+}
+
+func signSnapshotManifest(manifest *proto.SnapshotManifestMessage, keys []crypto.PrivateKey) error {
+	const failMsg = "signSnapshotManifest failed"
+
+	text, err := snapshotManifestText(manifest)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	for _, priv := range keys {
+		sig, err := crypto.Sign(priv, text)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		sigText, err := crypto.PrintSignature(sig)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		manifest.Signatures = append(manifest.Signatures, string(sigText))
+	}
+
+	return nil
+}
+
+func isSnapshotManifestVerified(manifest *proto.SnapshotManifestMessage, keys []crypto.PublicKey) bool {
+	text, err := snapshotManifestText(manifest)
+
+	if err != nil {
+		log.Error("Failed to encode snapshot manifest: %s", err.Error())
+		return false
+	}
+
+	for _, sigText := range manifest.Signatures {
+		sig, err := crypto.ParseSignature(crypto.SignatureText(sigText))
+
+		if err != nil {
+			log.Warn("Bad signature in snapshot manifest: %s", err.Error())
+			continue
+		}
+
+		for _, pub := range keys {
+			ok, err := crypto.Verify(pub, text, sig)
+
+			if err == nil && ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func writeSnapshot(w io.Writer, manifest *proto.SnapshotManifestMessage, blobs []snapshotBlob) error {
+	const failMsg = "writeSnapshot failed"
+
+	archive := tar.NewWriter(w)
+
+	manifestBytes, err := pb.Marshal(manifest)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	err = writeSnapshotFile(archive, __SNAPSHOT_MANIFEST_NAME, manifestBytes)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	for _, blob := range blobs {
+		name, err := blob.archiveName()
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+
+		err = writeSnapshotFile(archive, name, blob.data)
+
+		if err != nil {
+			return errors.Wrap(err, failMsg)
+		}
+	}
+
+	return archive.Close()
+}
+
+func writeSnapshotFile(archive *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name: name,
+		Mode: 0600,
+		Size: int64(len(data)),
+	}
+
+	err := archive.WriteHeader(header)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = archive.Write(data)
+
+	return err
+}
+
+// ImportSnapshot verifies a snapshot archive, adds its contents to the store,
+// and joins its index into the MemoryImage.  It returns the path of the imported index.
+func ImportSnapshot(options SnapshotImportOptions) (crdt.IPFSPath, error) {
+	const failMsg = "ImportSnapshot failed"
+
+	manifest, blobs, err := readSnapshot(options.Input)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	if !options.IsPublic {
+		keys := options.KeyStore.GetTrustedPublicKeys()
+		if !isSnapshotManifestVerified(manifest, keys) {
+			return crdt.NIL_PATH, errors.New("Snapshot manifest is not signed by a known public key")
+		}
+	}
+
+	importer := &snapshotImporter{
+		SnapshotImportOptions: options,
+		moved:                 map[crdt.IPFSPath]crdt.IPFSPath{},
+	}
+
+	head, err := importer.importBlobs(crdt.IPFSPath(manifest.Head), blobs)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	return head, nil
+}
+
+func readSnapshot(r io.Reader) (*proto.SnapshotManifestMessage, []snapshotBlob, error) {
+	const failMsg = "readSnapshot failed"
+
+	archive := tar.NewReader(r)
+
+	header, err := archive.Next()
+
+	if err != nil {
+		return nil, nil, errors.Wrap(err, failMsg)
+	}
+
+	if header.Name != __SNAPSHOT_MANIFEST_NAME {
+		return nil, nil, fmt.Errorf("Expected snapshot manifest but found: %s", header.Name)
+	}
+
+	manifestBytes, err := ioutil.ReadAll(archive)
+
+	if err != nil {
+		return nil, nil, errors.Wrap(err, failMsg)
+	}
+
+	manifest := &proto.SnapshotManifestMessage{}
+	err = pb.Unmarshal(manifestBytes, manifest)
+
+	if err != nil {
+		return nil, nil, errors.Wrap(err, failMsg)
+	}
+
+	if manifest.Version != __SNAPSHOT_VERSION {
+		return nil, nil, fmt.Errorf("Unsupported snapshot version: %d", manifest.Version)
+	}
+
+	expected := map[string]*proto.SnapshotEntryMessage{}
+	for _, entry := range manifest.Entries {
+		blob := snapshotBlob{entryType: snapshotEntryType(entry.Type), path: crdt.IPFSPath(entry.Path)}
+		name, err := blob.archiveName()
+
+		if err != nil {
+			return nil, nil, errors.Wrap(err, failMsg)
+		}
+
+		expected[name] = entry
+	}
+
+	blobs := make([]snapshotBlob, 0, len(manifest.Entries))
+	for {
+		header, err = archive.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, nil, errors.Wrap(err, failMsg)
+		}
+
+		entry, present := expected[header.Name]
+
+		if !present {
+			return nil, nil, fmt.Errorf("Snapshot file not in manifest: %s", header.Name)
+		}
+
+		delete(expected, header.Name)
+
+		data, err := ioutil.ReadAll(archive)
+
+		if err != nil {
+			return nil, nil, errors.Wrap(err, failMsg)
+		}
+
+		blob := snapshotBlob{
+			entryType: snapshotEntryType(entry.Type),
+			path:      crdt.IPFSPath(entry.Path),
+			data:      data,
+		}
+
+		if blob.digest() != entry.Digest {
+			return nil, nil, fmt.Errorf("Snapshot digest mismatch for: %s", header.Name)
+		}
+
+		blobs = append(blobs, blob)
+	}
+
+	if len(expected) > 0 {
+		return nil, nil, fmt.Errorf("Snapshot missing %d files", len(expected))
+	}
+
+	return manifest, blobs, nil
+}
+
+type snapshotImporter struct {
+	SnapshotImportOptions
+	moved map[crdt.IPFSPath]crdt.IPFSPath
+}
+
+func (importer *snapshotImporter) importBlobs(head crdt.IPFSPath, blobs []snapshotBlob) (crdt.IPFSPath, error) {
+	const failMsg = "snapshotImporter.importBlobs failed"
+
+	var headIndex *crdt.Index
+
+	for _, blob := range blobs {
+		switch blob.entryType {
+		case __SNAPSHOT_NAMESPACE:
+			err := importer.importNamespace(blob)
+
+			if err != nil {
+				return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+			}
+		case __SNAPSHOT_BLOB:
+			err := importer.importValue(blob)
+
+			if err != nil {
+				return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+			}
+		case __SNAPSHOT_INDEX:
+			if blob.path != head {
+				return crdt.NIL_PATH, fmt.Errorf("Unexpected index in snapshot: %s", blob.path)
+			}
+
+			index, _, err := crdt.DecodeIndex(bytes.NewReader(blob.data))
+
+			if err != nil {
+				return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+			}
+
+			headIndex = &index
+		default:
+			return crdt.NIL_PATH, fmt.Errorf("Unknown snapshot entry type: %d", blob.entryType)
+		}
+	}
+
+	if headIndex == nil {
+		return crdt.NIL_PATH, errors.New("Snapshot has no HEAD index")
+	}
+
+	index, err := importer.relinkIndex(*headIndex)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	path, err := importer.Store.AddIndex(index)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	if path != head {
+		log.Warn("Snapshot index %s was imported to: %s", head, path)
+	}
+
+	err = importer.MemoryImage.JoinIndex(index)
+
+	if err != nil {
+		return crdt.NIL_PATH, errors.Wrap(err, failMsg)
+	}
+
+	return path, nil
+}
+
+func (importer *snapshotImporter) importNamespace(blob snapshotBlob) error {
+	const failMsg = "snapshotImporter.importNamespace failed"
+
+	namespace, _, err := crdt.DecodeNamespace(bytes.NewReader(blob.data))
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	path, err := importer.Store.AddNamespace(namespace)
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if path != blob.path {
+		log.Warn("Snapshot namespace %s was imported to: %s", blob.path, path)
+	}
+
+	importer.moved[blob.path] = path
+
+	return nil
+}
+
+// Blob references are covered by point signatures, so they cannot be rewritten if the value moves.
+func (importer *snapshotImporter) importValue(blob snapshotBlob) error {
+	const failMsg = "snapshotImporter.importValue failed"
+
+	blobStore, ok := importer.Store.(api.BlobStore)
+
+	if !ok {
+		return fmt.Errorf("Store cannot import blob: %s", blob.path)
+	}
+
+	path, err := blobStore.AddBlob(bytes.NewReader(blob.data))
+
+	if err != nil {
+		return errors.Wrap(err, failMsg)
+	}
+
+	if path != blob.path {
+		log.Warn("Snapshot blob %s was imported to: %s", blob.path, path)
+	}
+
+	return nil
+}
+
+// A store that hashes differently to the exporter will put namespaces at new
+// paths.  The original signatures cannot cover these, so we sign them with our own keys,
+// but only where a trusted key signed the original link.  Other links are left unsigned.
+func (importer *snapshotImporter) relinkIndex(index crdt.Index) (crdt.Index, error) {
+	const failMsg = "snapshotImporter.relinkIndex failed"
+
+	trusted := importer.KeyStore.GetTrustedPublicKeys()
+	relinked := crdt.EmptyIndex()
+	for _, table := range index.AllTables() {
+		links, _ := index.GetTableAddrs(table)
+
+		for _, link := range links {
+			path, present := importer.moved[link.Path()]
+
+			if !present {
+				return crdt.EmptyIndex(), fmt.Errorf("Snapshot missing namespace: %s", link.Path())
+			}
+
+			if path == link.Path() {
+				relinked = relinked.JoinTable(table, link)
+				continue
+			}
+
+			if !link.IsVerifiedByAny(trusted) {
+				log.Warn("Snapshot link to %s is not signed by a trusted key, importing unsigned", link.Path())
+				relinked = relinked.JoinTable(table, crdt.UnsignedLink(path))
+				continue
+			}
+
+			signed, err := crdt.SignedLink(path, importer.KeyStore.GetAllPrivateKeys())
+
+			if err != nil {
+				return crdt.EmptyIndex(), errors.Wrap(err, failMsg)
+			}
+
+			relinked = relinked.JoinTable(table, signed)
+		}
+	}
+
+	return relinked, nil
+}
+
+const __SNAPSHOT_VERSION = 1
+const __SNAPSHOT_MANIFEST_NAME = "manifest"
+const __SNAPSHOT_INDEX_DIR = "index/"
+const __SNAPSHOT_NAMESPACE_DIR = "namespace/"
+const __SNAPSHOT_BLOB_DIR = "blob/"
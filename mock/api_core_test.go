@@ -1,7 +1,10 @@
 package mock_godless
 
 import (
+	stdcrypto "crypto"
 	"fmt"
+	"io"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,6 +13,7 @@ import (
 	"github.com/johnny-morrice/godless/cache"
 	"github.com/johnny-morrice/godless/crdt"
 	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/datapeer"
 	"github.com/johnny-morrice/godless/internal/service"
 	"github.com/johnny-morrice/godless/internal/testutil"
 	"github.com/johnny-morrice/godless/log"
@@ -109,6 +113,78 @@ func TestRemoteNamespaceCoreRunQuery(t *testing.T) {
 	testutil.Assert(t, "Unexpected namespace", namespace.Equals(selectResponse.Namespace))
 }
 
+func TestRemoteNamespaceCoreRunQueryBlob(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	peer := datapeer.MakeResidentMemoryDataPeer(options)
+	store := service.MakeContentAddressableRemoteStore(peer)
+
+	headCache := cache.MakeResidentHeadCache()
+	remoteOptions := remoteOptions(store, headCache)
+	remoteOptions.BlobThreshold = 10
+	remote := service.MakeRemoteNamespaceCore(remoteOptions)
+	defer remote.Close()
+
+	const longText = "A driver with a very long name"
+	joinQuery, err := query.Compile(fmt.Sprintf("join cars rows (@key=car10, driver=\"%s\", colour=\"red\")", longText))
+	testutil.AssertNil(t, err)
+	selectQuery, err := query.Compile(fmt.Sprintf("select cars where str_eq(driver, \"%s\")", longText))
+	testutil.AssertNil(t, err)
+
+	joinResponse := makeQueryRequest(remote, joinQuery)
+	testutil.AssertNil(t, joinResponse.Err)
+	err = remote.WriteMemoryImage()
+	testutil.AssertNil(t, err)
+
+	head, err := headCache.GetHead()
+	testutil.AssertNil(t, err)
+	index, err := store.CatIndex(head)
+	testutil.AssertNil(t, err)
+	links, err := index.GetTableAddrs("cars")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, links)
+	stored, err := store.CatNamespace(links[0].Path())
+	testutil.AssertNil(t, err)
+	stored.ForeachEntry(func(table crdt.TableName, row crdt.RowName, entryName crdt.EntryName, entry crdt.Entry) {
+		values := entry.GetValues()
+		testutil.AssertLenEquals(t, 1, values)
+
+		isBlob := crdt.IsBlobReference(values[0].Text())
+		testutil.Assert(t, "Unexpected blob reference", isBlob == (entryName == "driver"))
+	})
+
+	expected := crdt.MakeNamespace(map[crdt.TableName]crdt.Table{
+		"cars": crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"car10": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"driver": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint(longText)}),
+				"colour": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("red")}),
+			}),
+		}),
+	})
+
+	selectResponse := makeQueryRequest(remote, selectQuery)
+	testutil.AssertNil(t, selectResponse.Err)
+	testutil.Assert(t, "Unexpected namespace", expected.Equals(selectResponse.Namespace))
+}
+
+func TestRemoteNamespaceCoreRunQueryReservedText(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	peer := datapeer.MakeResidentMemoryDataPeer(options)
+	store := service.MakeContentAddressableRemoteStore(peer)
+
+	remote := service.MakeRemoteNamespaceCore(remoteOptions(store, cache.MakeResidentHeadCache()))
+	defer remote.Close()
+
+	joinQuery, err := query.Compile("join cars rows (@key=car10, driver=\"!blob:1:QmFake\")")
+	testutil.AssertNil(t, err)
+
+	joinResponse := makeQueryRequest(remote, joinQuery)
+	testutil.AssertNonNil(t, joinResponse.Err)
+}
+
 func makeQueryRequest(core api.Core, query *query.Query) api.Response {
 	request := api.Request{Type: api.API_QUERY, Query: query}
 	command, err := request.MakeCommand()
@@ -175,6 +251,98 @@ func TestRemoteNamespaceCoreReflect(t *testing.T) {
 	testReflectNamespace(t, remote, joinedNamespace)
 }
 
+func TestRemoteNamespaceCoreReflectDiff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := NewMockRemoteStore(ctrl)
+
+	const tableName = "Table"
+	addrOld := crdt.IPFSPath("Addr Old")
+	addrNew := crdt.IPFSPath("Addr New")
+	addrOldIndex := crdt.IPFSPath("Addr Old Index")
+	addrNewIndex := crdt.IPFSPath("Addr New Index")
+
+	makeNamespace := func(point crdt.PointText) crdt.Namespace {
+		return crdt.EmptyNamespace().JoinTable(tableName, crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Row": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint(point)}),
+			}),
+		}))
+	}
+
+	namespaceOld := makeNamespace("Old")
+	namespaceNew := makeNamespace("New")
+
+	oldIndex := crdt.EmptyIndex().JoinTable(tableName, crdt.UnsignedLink(addrOld))
+	newIndex := oldIndex.JoinTable(tableName, crdt.UnsignedLink(addrNew))
+
+	mockStore.EXPECT().CatIndex(addrOldIndex).Return(oldIndex, nil)
+	mockStore.EXPECT().CatIndex(addrNewIndex).Return(newIndex, nil)
+	mockStore.EXPECT().CatNamespace(addrOld).Return(namespaceOld, nil).MinTimes(1)
+	mockStore.EXPECT().CatNamespace(addrNew).Return(namespaceNew, nil)
+
+	remote := makeRemote(mockStore)
+	defer remote.Close()
+
+	request := api.MakeDiffRequest(addrOldIndex, addrNewIndex)
+	command, err := request.MakeCommand()
+	panicOnBadInit(err)
+	command.Run(remote)
+
+	resp := readApiResponse(command)
+
+	testutil.AssertNil(t, resp.Err)
+	testutil.Assert(t, "Unexpected added", namespaceNew.Equals(resp.Diff.Added))
+	testutil.Assert(t, "Unexpected removed", resp.Diff.Removed.IsEmpty())
+}
+
+func TestRemoteNamespaceCoreReflectCacheStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := NewMockRemoteStore(ctrl)
+
+	const tableName = "Table"
+	addrNamespace := crdt.IPFSPath("Addr Namespace")
+	addrIndex := crdt.IPFSPath("Addr Index")
+
+	namespace := crdt.EmptyNamespace().JoinTable(tableName, crdt.MakeTable(map[crdt.RowName]crdt.Row{
+		"Row": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point")}),
+		}),
+	}))
+	index := crdt.EmptyIndex().JoinTable(tableName, crdt.UnsignedLink(addrNamespace))
+
+	mockStore.EXPECT().AddIndex(matchIndex(index)).AnyTimes()
+	mockStore.EXPECT().CatIndex(addrIndex).Return(index, nil).MinTimes(1)
+	mockStore.EXPECT().CatNamespace(addrNamespace).Return(namespace, nil).MinTimes(1)
+
+	headCache := cache.MakeResidentHeadCache()
+	panicOnBadInit(headCache.SetHead(addrIndex))
+
+	memCache := cache.MakeResidentMemoryCache(__UNKNOWN_CACHE_SIZE, __UNKNOWN_CACHE_SIZE)
+	options := remoteOptions(mockStore, headCache)
+	options.IndexCache = memCache
+	options.NamespaceCache = memCache
+	remote := service.MakeRemoteNamespaceCore(options)
+	defer remote.Close()
+
+	testReflectNamespace(t, remote, namespace)
+
+	resp := reflectOnRemote(remote, api.REFLECT_CACHE_STATS)
+	testutil.AssertNil(t, resp.Err)
+	testutil.AssertLenEquals(t, 3, resp.CacheStats)
+
+	stats := map[string]api.CacheStats{}
+	for _, cacheStats := range resp.CacheStats {
+		stats[cacheStats.Name] = cacheStats
+	}
+
+	testutil.Assert(t, "Expected index miss", stats["index"].Misses > 0)
+	testutil.Assert(t, "Expected namespace miss", stats["namespace"].Misses > 0)
+	_, hasVerifyStats := stats["verify"]
+	testutil.Assert(t, "Expected verify cache stats", hasVerifyStats)
+}
+
 // FIXME test error path
 func testReflectHead(t *testing.T, remote api.Core, expected crdt.IPFSPath) {
 	resp := reflectOnRemote(remote, api.REFLECT_HEAD_PATH)
@@ -284,6 +452,131 @@ func TestRemoteNamespaceCoreLoadTraverseSuccess(t *testing.T) {
 	}
 }
 
+func TestRemoteNamespaceCoreLoadTraverseStream(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	peer := datapeer.MakeResidentMemoryDataPeer(options)
+	store := service.MakeContentAddressableRemoteStore(peer)
+
+	const tableName = "Table"
+	namespace := crdt.EmptyNamespace().JoinTable(tableName, crdt.MakeTable(map[crdt.RowName]crdt.Row{
+		"Row A": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"Entry A": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point A")}),
+		}),
+		"Row B": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"Entry B": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point B")}),
+		}),
+	}))
+
+	namespaceAddr, err := store.AddNamespace(namespace)
+	testutil.AssertNil(t, err)
+	index := crdt.EmptyIndex().JoinTable(tableName, crdt.UnsignedLink(namespaceAddr))
+	indexAddr, err := store.AddIndex(index)
+	testutil.AssertNil(t, err)
+
+	remote := loadRemote(store, indexAddr)
+	defer remote.Close()
+
+	results := []crdt.Namespace{}
+	more := true
+	searcher := api.SignedTableSearcher{
+		Tables: []crdt.TableName{tableName},
+		Reader: api.SearchResultLambda(func(result api.SearchResult) api.TraversalUpdate {
+			testutil.Assert(t, "Unexpected load failure", !result.NamespaceLoadFailure && !result.IndexLoadFailure)
+			results = append(results, result.Namespace)
+			return api.TraversalUpdate{More: more}
+		}),
+	}
+
+	err = remote.LoadTraverse(searcher)
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 2, results)
+
+	actual := crdt.EmptyNamespace()
+	for _, row := range results {
+		actual = actual.JoinNamespace(row)
+	}
+
+	testutil.Assert(t, "Unexpected namespace", namespace.Equals(actual))
+
+	results = []crdt.Namespace{}
+	more = false
+
+	err = remote.LoadTraverse(searcher)
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, results)
+}
+
+func TestRemoteNamespaceCoreLoadTraverseStreamFailure(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	peer := datapeer.MakeResidentMemoryDataPeer(options)
+	store := service.MakeContentAddressableRemoteStore(peer).(api.NamespaceStreamStore)
+
+	const tableName = "Table"
+	namespace := crdt.EmptyNamespace().JoinTable(tableName, crdt.MakeTable(map[crdt.RowName]crdt.Row{
+		"Row A": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"Entry A": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point A")}),
+		}),
+		"Row B": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"Entry B": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point B")}),
+		}),
+	}))
+
+	namespaceAddr, err := store.AddNamespace(namespace)
+	testutil.AssertNil(t, err)
+	index := crdt.EmptyIndex().JoinTable(tableName, crdt.UnsignedLink(namespaceAddr))
+	indexAddr, err := store.AddIndex(index)
+	testutil.AssertNil(t, err)
+
+	remote := loadRemote(truncatedStreamStore{store}, indexAddr)
+	defer remote.Close()
+
+	results := []api.SearchResult{}
+	searcher := api.SignedTableSearcher{
+		Tables: []crdt.TableName{tableName},
+		Reader: api.SearchResultLambda(func(result api.SearchResult) api.TraversalUpdate {
+			results = append(results, result)
+			return api.TraversalUpdate{More: true}
+		}),
+	}
+
+	err = remote.LoadTraverse(searcher)
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 2, results)
+	testutil.AssertEquals(t, "Unexpected row source", namespaceAddr, results[0].Source)
+	testutil.Assert(t, "Expected load failure", results[1].NamespaceLoadFailure)
+	testutil.AssertEquals(t, "Unexpected failure source", namespaceAddr, results[1].Source)
+
+	// The select drops the row sent before the failure.
+	selectQuery, err := query.Compile("select Table")
+	testutil.AssertNil(t, err)
+	resp := makeQueryRequest(remote, selectQuery)
+	testutil.AssertNil(t, resp.Err)
+	testutil.Assert(t, "Expected load errors", resp.Msg != api.RESPONSE_QUERY.Msg)
+	testutil.Assert(t, "Expected no rows", resp.Namespace.IsEmpty())
+}
+
+// truncatedStreamStore fails after the first row of each namespace.
+type truncatedStreamStore struct {
+	api.NamespaceStreamStore
+}
+
+func (store truncatedStreamStore) CatNamespaceRows(addr crdt.IPFSPath, f func(row crdt.Namespace) bool) error {
+	err := store.NamespaceStreamStore.CatNamespaceRows(addr, func(row crdt.Namespace) bool {
+		f(row)
+		return false
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return errors.New("Expected error")
+}
+
 func TestRemoteNamespaceCoreLoadTraverseFailure(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -607,3 +900,642 @@ func panicOnBadInit(err error) {
 }
 
 const __UNKNOWN_CACHE_SIZE = -1
+
+func TestRemoteNamespaceCoreJournal(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	peer := &offlineDataPeer{PinningDataPeer: datapeer.MakeResidentMemoryDataPeer(options)}
+	store := service.MakeContentAddressableRemoteStore(peer)
+
+	journal := cache.MakeResidentJoinJournal()
+	headCache := cache.MakeResidentHeadCache()
+	remoteOptions := remoteOptions(store, headCache)
+	remoteOptions.Journal = journal
+	remoteOptions.Pulse = time.Millisecond * 10
+	keyStore := &countingKeyStore{KeyStore: remoteOptions.KeyStore}
+	remoteOptions.KeyStore = keyStore
+	remote := service.MakeRemoteNamespaceCore(remoteOptions)
+	defer remote.Close()
+
+	peer.setUp(false)
+
+	joinA, err := query.Compile("join cars rows (@key=car1, driver=\"A\")")
+	testutil.AssertNil(t, err)
+	joinB, err := query.Compile("join cars rows (@key=car2, driver=\"B\")")
+	testutil.AssertNil(t, err)
+	selectQuery, err := query.Compile("select cars")
+	testutil.AssertNil(t, err)
+
+	for _, join := range []*query.Query{joinA, joinB} {
+		resp := makeQueryRequest(remote, join)
+		testutil.AssertNil(t, resp.Err)
+		testutil.AssertEquals(t, "Unexpected message", api.RESPONSE_PROVISIONAL_MSG, resp.Msg)
+		testutil.Assert(t, "Expected provisional path", api.IsProvisionalPath(resp.Path))
+	}
+
+	expected := crdt.MakeNamespace(map[crdt.TableName]crdt.Table{
+		"cars": crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"car1": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"driver": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("A")}),
+			}),
+			"car2": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"driver": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("B")}),
+			}),
+		}),
+	})
+
+	// Each select reads the private keys to decrypt, but journaled joins are signed only when written.
+	signings := keyStore.count()
+	selectResponse := makeQueryRequest(remote, selectQuery)
+	testutil.AssertNil(t, selectResponse.Err)
+	testutil.Assert(t, "Unexpected journaled namespace", expected.Equals(selectResponse.Namespace))
+	selectResponse = makeQueryRequest(remote, selectQuery)
+	testutil.AssertNil(t, selectResponse.Err)
+	testutil.AssertEquals(t, "Unexpected signing", signings+2, keyStore.count())
+
+	reflectResponse := reflectOnRemote(remote, api.REFLECT_JOURNAL)
+	testutil.AssertNil(t, reflectResponse.Err)
+	testutil.AssertEquals(t, "Unexpected journal depth", 2, reflectResponse.JournalDepth)
+
+	peer.setUp(true)
+
+	timeout := time.After(time.Second * 2)
+	for depth := 2; depth > 0; {
+		select {
+		case <-timeout:
+			t.Fatal("Timed out waiting for journal flush")
+		case <-time.After(remoteOptions.Pulse):
+			depth, err = journal.JournalDepth()
+			testutil.AssertNil(t, err)
+		}
+	}
+
+	joinC, err := query.Compile("join cars rows (@key=car3, driver=\"C\")")
+	testutil.AssertNil(t, err)
+	joinResponse := makeQueryRequest(remote, joinC)
+	testutil.AssertNil(t, joinResponse.Err)
+	testutil.Assert(t, "Unexpected provisional path", !api.IsProvisionalPath(joinResponse.Path))
+
+	testutil.AssertNil(t, remote.WriteMemoryImage())
+	head, err := headCache.GetHead()
+	testutil.AssertNil(t, err)
+	index, err := store.CatIndex(head)
+	testutil.AssertNil(t, err)
+	links, err := index.GetTableAddrs("cars")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 3, links)
+
+	for _, link := range links {
+		testutil.Assert(t, "Unexpected provisional link", !api.IsProvisionalPath(link.Path()))
+	}
+
+	selectResponse = makeQueryRequest(remote, selectQuery)
+	testutil.AssertNil(t, selectResponse.Err)
+	cars, err := selectResponse.Namespace.GetTable("cars")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 3, cars.AllRows())
+}
+
+// countingKeyStore counts requests for private keys.
+type countingKeyStore struct {
+	api.KeyStore
+	sync.Mutex
+	privateKeyCount int
+}
+
+func (keyStore *countingKeyStore) GetAllPrivateKeys() []crypto.PrivateKey {
+	keyStore.Lock()
+	keyStore.privateKeyCount++
+	keyStore.Unlock()
+
+	return keyStore.KeyStore.GetAllPrivateKeys()
+}
+
+func (keyStore *countingKeyStore) count() int {
+	keyStore.Lock()
+	defer keyStore.Unlock()
+	return keyStore.privateKeyCount
+}
+
+// offlineDataPeer can be taken down, to test how joins are handled while IPFS is unreachable.
+type offlineDataPeer struct {
+	api.PinningDataPeer
+	sync.Mutex
+	down bool
+}
+
+func (peer *offlineDataPeer) setUp(isUp bool) {
+	peer.Lock()
+	defer peer.Unlock()
+	peer.down = !isUp
+}
+
+func (peer *offlineDataPeer) IsUp() bool {
+	peer.Lock()
+	defer peer.Unlock()
+	return !peer.down
+}
+
+func (peer *offlineDataPeer) Add(r io.Reader) (string, error) {
+	if !peer.IsUp() {
+		return "", errors.New("offlineDataPeer is down")
+	}
+
+	return peer.PinningDataPeer.Add(r)
+}
+
+func TestRemoteNamespaceCoreRevocation(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	store := service.MakeContentAddressableRemoteStore(datapeer.MakeResidentMemoryDataPeer(options))
+
+	leaked, leakedPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	trusted, trustedPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	leakedHash, err := leakedPub.Hash()
+	testutil.AssertNil(t, err)
+
+	keyStore := &crypto.KeyStore{}
+	testutil.AssertNil(t, keyStore.PutPublicKey(leakedPub))
+	testutil.AssertNil(t, keyStore.PutPublicKey(trustedPub))
+
+	remoteOptions := remoteOptions(store, cache.MakeResidentHeadCache())
+	remoteOptions.KeyStore = keyStore
+	remoteOptions.IsPublicIndex = false
+	remote := service.MakeRemoteNamespaceCore(remoteOptions)
+	defer remote.Close()
+
+	driver, err := crdt.SignedPoint("Mr Blogs", []crypto.PrivateKey{leaked})
+	testutil.AssertNil(t, err)
+	cars := crdt.EmptyNamespace().JoinTable("cars", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+		"car10": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"driver": crdt.MakeEntry([]crdt.Point{driver}),
+		}),
+	}))
+
+	revocation, err := crypto.MakeRevocation(leakedPub, leaked, time.Now(), "Leaked")
+	testutil.AssertNil(t, err)
+	revocations, err := crdt.MakeRevocationNamespace(revocation)
+	testutil.AssertNil(t, err)
+
+	carsLink := addSignedPeerIndex(t, store, cars, leaked)
+	revocationsLink := addSignedPeerIndex(t, store, revocations, trusted)
+	moreCarsLink := addSignedPeerIndex(t, store, cars, leaked)
+
+	selectQuery, err := query.Compile(fmt.Sprintf("select cars signed \"%s\"", leakedHash))
+	testutil.AssertNil(t, err)
+
+	resp := makeSignedReplicateRequest(remote, carsLink)
+	testutil.AssertNil(t, resp.Err)
+	testutil.AssertEquals(t, "Unexpected message", api.RESPONSE_REPLICATE.Msg, resp.Msg)
+
+	resp = makeQueryRequest(remote, selectQuery)
+	testutil.AssertNil(t, resp.Err)
+	testutil.Assert(t, "Expected signed namespace", cars.Equals(resp.Namespace))
+
+	resp = makeSignedReplicateRequest(remote, revocationsLink)
+	testutil.AssertNil(t, resp.Err)
+	testutil.Assert(t, "Expected revoked key", keyStore.IsRevoked(leakedPub))
+
+	resp = makeSignedReplicateRequest(remote, moreCarsLink)
+	testutil.AssertNil(t, resp.Err)
+	testutil.Assert(t, "Expected skipped link", resp.Msg != api.RESPONSE_REPLICATE.Msg)
+
+	resp = makeQueryRequest(remote, selectQuery)
+	testutil.AssertNil(t, resp.Err)
+	testutil.Assert(t, "Expected no trusted data", resp.Namespace.IsEmpty())
+}
+
+func addSignedPeerIndex(t *testing.T, store api.RemoteStore, namespace crdt.Namespace, priv crypto.PrivateKey) crdt.Link {
+	return addMultiSignedPeerIndex(t, store, namespace, priv)
+}
+
+func addMultiSignedPeerIndex(t *testing.T, store api.RemoteStore, namespace crdt.Namespace, keys ...crypto.PrivateKey) crdt.Link {
+	return addPeerIndexSignedBy(t, store, namespace, keys, keys...)
+}
+
+// addPeerIndexSignedBy signs the table links with tableKeys, and the index link with keys.
+func addPeerIndexSignedBy(t *testing.T, store api.RemoteStore, namespace crdt.Namespace, tableKeys []crypto.PrivateKey, keys ...crypto.PrivateKey) crdt.Link {
+	namespaceAddr, err := store.AddNamespace(namespace)
+	testutil.AssertNil(t, err)
+	namespaceLink, err := crdt.SignedLink(namespaceAddr, tableKeys)
+	testutil.AssertNil(t, err)
+
+	index := crdt.EmptyIndex()
+	for _, tableName := range namespace.GetTableNames() {
+		index = index.JoinTable(tableName, namespaceLink)
+	}
+
+	indexAddr, err := store.AddIndex(index)
+	testutil.AssertNil(t, err)
+	indexLink, err := crdt.SignedLink(indexAddr, keys)
+	testutil.AssertNil(t, err)
+	return indexLink
+}
+
+func makeSignedReplicateRequest(core api.Core, link crdt.Link) api.Response {
+	request := api.Request{Type: api.API_REPLICATE, Replicate: []crdt.Link{link}}
+	command, err := request.MakeCommand()
+	panicOnBadInit(err)
+	command.Run(core)
+	return readApiResponse(command)
+}
+
+func TestRemoteNamespaceCoreDelegation(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	store := service.MakeContentAddressableRemoteStore(datapeer.MakeResidentMemoryDataPeer(options))
+
+	root, rootPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	member, memberPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	contractor, contractorPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	stranger, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	keyStore := &crypto.KeyStore{}
+	testutil.AssertNil(t, keyStore.PutPublicKey(rootPub))
+
+	remoteOptions := remoteOptions(store, cache.MakeResidentHeadCache())
+	remoteOptions.KeyStore = keyStore
+	remoteOptions.IsPublicIndex = false
+	remote := service.MakeRemoteNamespaceCore(remoteOptions)
+	defer remote.Close()
+
+	toMember, err := crypto.MakeDelegation(root, memberPub, nil, time.Time{})
+	testutil.AssertNil(t, err)
+	toContractor, err := crypto.MakeDelegation(member, contractorPub, []string{"cars"}, time.Time{})
+	testutil.AssertNil(t, err)
+	delegations, err := crdt.MakeDelegationNamespace(toMember, toContractor)
+	testutil.AssertNil(t, err)
+
+	makeData := func(signer crypto.PrivateKey, tables ...crdt.TableName) crdt.Namespace {
+		point, err := crdt.SignedPoint("Mr Blogs", []crypto.PrivateKey{signer})
+		testutil.AssertNil(t, err)
+
+		namespace := crdt.EmptyNamespace()
+		for _, table := range tables {
+			namespace = namespace.JoinTable(table, crdt.MakeTable(map[crdt.RowName]crdt.Row{
+				"row": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+					"driver": crdt.MakeEntry([]crdt.Point{point}),
+				}),
+			}))
+		}
+
+		return namespace
+	}
+
+	strangerLink := addSignedPeerIndex(t, store, makeData(stranger, "cars"), stranger)
+	resp := makeSignedReplicateRequest(remote, strangerLink)
+	testutil.AssertNil(t, resp.Err)
+	testutil.Assert(t, "Expected skipped link", resp.Msg != api.RESPONSE_REPLICATE.Msg)
+
+	resp = makeSignedReplicateRequest(remote, addSignedPeerIndex(t, store, delegations, root))
+	testutil.AssertNil(t, resp.Err)
+	testutil.AssertLenEquals(t, 2, keyStore.GetAllDelegations())
+
+	memberLink := addSignedPeerIndex(t, store, makeData(member, "drivers"), member)
+	contractorLink := addSignedPeerIndex(t, store, makeData(contractor, "cars", "drivers"), contractor)
+
+	for _, link := range []crdt.Link{memberLink, contractorLink} {
+		resp = makeSignedReplicateRequest(remote, link)
+		testutil.AssertNil(t, resp.Err)
+		testutil.AssertEquals(t, "Unexpected message", api.RESPONSE_REPLICATE.Msg, resp.Msg)
+	}
+
+	resp = reflectOnRemote(remote, api.REFLECT_INDEX)
+	testutil.AssertNil(t, resp.Err)
+
+	// The contractor may only write cars.
+	drivers, err := resp.Index.GetTableAddrs("drivers")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, drivers)
+	cars, err := resp.Index.GetTableAddrs("cars")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, cars)
+
+	resp = reflectOnRemote(remote, api.REFLECT_DUMP_NAMESPACE)
+	testutil.AssertNil(t, resp.Err)
+	table, err := resp.Namespace.GetTable("cars")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, table.AllRows())
+}
+
+func TestRemoteNamespaceCoreWritePolicy(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	store := service.MakeContentAddressableRemoteStore(datapeer.MakeResidentMemoryDataPeer(options))
+
+	keyStore := &crypto.KeyStore{}
+	privs := make([]crypto.PrivateKey, 3)
+	for i := range privs {
+		priv, pub, err := crypto.GenerateKey()
+		testutil.AssertNil(t, err)
+		testutil.AssertNil(t, keyStore.PutPublicKey(pub))
+		privs[i] = priv
+	}
+
+	clerk, manager, commenter := privs[0], privs[1], privs[2]
+
+	policy := crypto.TablePolicy{MinSignatures: 2}
+	for _, priv := range []crypto.PrivateKey{clerk, manager} {
+		hash, err := priv.GetPublicKey().Hash()
+		testutil.AssertNil(t, err)
+		policy.Signers = append(policy.Signers, hash)
+	}
+	testutil.AssertNil(t, keyStore.PutTablePolicy("prices", policy))
+
+	remoteOptions := remoteOptions(store, cache.MakeResidentHeadCache())
+	remoteOptions.KeyStore = keyStore
+	remoteOptions.IsPublicIndex = false
+	remote := service.MakeRemoteNamespaceCore(remoteOptions)
+	defer remote.Close()
+
+	makeData := func(tables ...crdt.TableName) crdt.Namespace {
+		namespace := crdt.EmptyNamespace()
+		for _, table := range tables {
+			namespace = namespace.JoinTable(table, crdt.MakeTable(map[crdt.RowName]crdt.Row{
+				"apple": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+					"cost": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("1")}),
+				}),
+			}))
+		}
+
+		return namespace
+	}
+
+	links := []crdt.Link{
+		addMultiSignedPeerIndex(t, store, makeData("prices", "comments"), commenter),
+		addMultiSignedPeerIndex(t, store, makeData("prices"), clerk),
+		addMultiSignedPeerIndex(t, store, makeData("prices"), clerk, manager),
+	}
+
+	for _, link := range links {
+		resp := makeSignedReplicateRequest(remote, link)
+		testutil.AssertNil(t, resp.Err)
+	}
+
+	resp := reflectOnRemote(remote, api.REFLECT_INDEX)
+	testutil.AssertNil(t, resp.Err)
+
+	// Only the link signed by both the clerk and the manager may write prices.
+	prices, err := resp.Index.GetTableAddrs("prices")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, prices)
+	testutil.AssertLenEquals(t, 2, prices[0].Signatures())
+	comments, err := resp.Index.GetTableAddrs("comments")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, comments)
+}
+
+func TestRemoteNamespaceCoreKeyThreshold(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	store := service.MakeContentAddressableRemoteStore(datapeer.MakeResidentMemoryDataPeer(options))
+
+	keyStore := &crypto.KeyStore{}
+	privs := make([]crypto.PrivateKey, 3)
+	hashes := make([]crypto.PublicKeyHash, 3)
+	for i := range privs {
+		priv, pub, err := crypto.GenerateKey()
+		testutil.AssertNil(t, err)
+		testutil.AssertNil(t, keyStore.PutPublicKey(pub))
+		privs[i] = priv
+		hashes[i], err = pub.Hash()
+		testutil.AssertNil(t, err)
+	}
+
+	testutil.AssertNil(t, keyStore.PutTablePolicy("audits", crypto.TablePolicy{Signers: hashes, MinSignatures: 2}))
+
+	member, memberPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	delegation, err := crypto.MakeDelegation(privs[0], memberPub, nil, time.Time{})
+	testutil.AssertNil(t, err)
+	testutil.AssertNil(t, keyStore.PutDelegation(delegation))
+
+	namespaceCache := &markedNamespaceCache{
+		NamespaceCache: cache.MakeResidentNamespaceCache(16),
+		markers:        map[string]struct{}{},
+	}
+
+	remoteOptions := remoteOptions(store, cache.MakeResidentHeadCache())
+	remoteOptions.KeyStore = keyStore
+	remoteOptions.IsPublicIndex = false
+	remoteOptions.NamespaceCache = namespaceCache
+	remoteOptions.VerifiedMarkers = true
+	remote := service.MakeRemoteNamespaceCore(remoteOptions)
+	defer remote.Close()
+
+	makeAudit := func(row crdt.RowName, signers ...crypto.PrivateKey) crdt.Namespace {
+		point, err := crdt.SignedPoint("Passed", signers)
+		testutil.AssertNil(t, err)
+		return crdt.EmptyNamespace().JoinTable("audits", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			row: crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"result": crdt.MakeEntry([]crdt.Point{point}),
+			}),
+		}))
+	}
+
+	once := makeAudit("once", privs[0])
+	twice := makeAudit("twice", privs[0], privs[1])
+	audits := once.JoinNamespace(twice)
+
+	// The policy needs two signatures on the table link, but the index link needs only one.  The
+	// delegate is not a listed signer.
+	resp := makeSignedReplicateRequest(remote, addMultiSignedPeerIndex(t, store, makeAudit("other", privs[0]), privs[0]))
+	testutil.AssertNil(t, resp.Err)
+
+	resp = makeSignedReplicateRequest(remote, addMultiSignedPeerIndex(t, store, makeAudit("delegated", privs[0], member), privs[0], member))
+	testutil.AssertNil(t, resp.Err)
+
+	resp = makeSignedReplicateRequest(remote, addPeerIndexSignedBy(t, store, audits, privs[:2], privs[0]))
+	testutil.AssertNil(t, resp.Err)
+	testutil.AssertEquals(t, "Unexpected message", api.RESPONSE_REPLICATE.Msg, resp.Msg)
+
+	resp = reflectOnRemote(remote, api.REFLECT_INDEX)
+	testutil.AssertNil(t, resp.Err)
+	auditLinks, err := resp.Index.GetTableAddrs("audits")
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, auditLinks)
+
+	addr, err := store.AddNamespace(audits)
+	testutil.AssertNil(t, err)
+	testutil.AssertNil(t, namespaceCache.SetNamespace(addr, audits))
+
+	anyQuery, err := query.Compile(fmt.Sprintf("select audits signed \"%s\" signed \"%s\"", hashes[0], hashes[1]))
+	testutil.AssertNil(t, err)
+	thresholdQuery, err := query.Compile(fmt.Sprintf("select audits signed 2 of (\"%s\", \"%s\")", hashes[0], hashes[1]))
+	testutil.AssertNil(t, err)
+
+	// The namespace marked as verified by any key is still filtered by the threshold.
+	for i := 0; i < 2; i++ {
+		resp = makeQueryRequest(remote, anyQuery)
+		testutil.AssertNil(t, resp.Err)
+		testutil.Assert(t, "Expected all audits", audits.Equals(resp.Namespace))
+
+		resp = makeQueryRequest(remote, thresholdQuery)
+		testutil.AssertNil(t, resp.Err)
+		testutil.Assert(t, "Expected audits signed twice", twice.Equals(resp.Namespace))
+	}
+}
+
+func TestRemoteNamespaceCoreVerifiedMarkers(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	store := service.MakeContentAddressableRemoteStore(datapeer.MakeResidentMemoryDataPeer(options))
+
+	signer, signerPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	stranger, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	signerHash, err := signerPub.Hash()
+	testutil.AssertNil(t, err)
+
+	keyStore := &crypto.KeyStore{}
+	testutil.AssertNil(t, keyStore.PutPublicKey(signerPub))
+
+	namespaceCache := &markedNamespaceCache{
+		NamespaceCache: cache.MakeResidentNamespaceCache(16),
+		markers:        map[string]struct{}{},
+	}
+
+	remoteOptions := remoteOptions(store, cache.MakeResidentHeadCache())
+	remoteOptions.KeyStore = keyStore
+	remoteOptions.NamespaceCache = namespaceCache
+	remoteOptions.VerifiedMarkers = true
+	remote := service.MakeRemoteNamespaceCore(remoteOptions)
+	defer remote.Close()
+
+	makeCars := func(row crdt.RowName, signer crypto.PrivateKey) crdt.Namespace {
+		driver, err := crdt.SignedPoint("Mr Blogs", []crypto.PrivateKey{signer})
+		testutil.AssertNil(t, err)
+		return crdt.EmptyNamespace().JoinTable("cars", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			row: crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"driver": crdt.MakeEntry([]crdt.Point{driver}),
+			}),
+		}))
+	}
+
+	cars := makeCars("car1", signer)
+
+	// Markers are only used for namespaces loaded whole from the NamespaceCache.
+	for _, namespace := range []crdt.Namespace{cars, makeCars("car2", stranger)} {
+		resp := makeSignedReplicateRequest(remote, addSignedPeerIndex(t, store, namespace, signer))
+		testutil.AssertNil(t, resp.Err)
+
+		addr, err := store.AddNamespace(namespace)
+		testutil.AssertNil(t, err)
+		testutil.AssertNil(t, namespaceCache.SetNamespace(addr, namespace))
+	}
+
+	selectQuery, err := query.Compile(fmt.Sprintf("select cars signed \"%s\"", signerHash))
+	testutil.AssertNil(t, err)
+
+	for i := 0; i < 2; i++ {
+		resp := makeQueryRequest(remote, selectQuery)
+		testutil.AssertNil(t, resp.Err)
+		testutil.Assert(t, "Expected signed namespace", cars.Equals(resp.Namespace))
+	}
+
+	// Only the namespace with no unverified points is marked.
+	testutil.AssertLenEquals(t, 1, namespaceCache.markers)
+	testutil.AssertEquals(t, "Unexpected marker hits", 1, namespaceCache.hits)
+}
+
+func TestRemoteNamespaceCoreSignNamespaces(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	store := service.MakeContentAddressableRemoteStore(datapeer.MakeResidentMemoryDataPeer(options))
+
+	signer, signerPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	stranger, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	signerHash, err := signerPub.Hash()
+	testutil.AssertNil(t, err)
+
+	keyStore := &crypto.KeyStore{}
+	testutil.AssertNil(t, keyStore.PutPrivateKey(signer))
+
+	remoteOptions := remoteOptions(store, cache.MakeResidentHeadCache())
+	remoteOptions.KeyStore = keyStore
+	remoteOptions.SignNamespaces = true
+	remote := service.MakeRemoteNamespaceCore(remoteOptions)
+	defer remote.Close()
+
+	makeCars := func(row crdt.RowName, driver crdt.Point) crdt.Namespace {
+		return crdt.EmptyNamespace().JoinTable("cars", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			row: crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"driver": crdt.MakeEntry([]crdt.Point{driver}),
+			}),
+		}))
+	}
+
+	joinQuery, err := query.Compile(fmt.Sprintf("join cars signed \"%s\" rows (@key=car1, driver=\"Mr Blogs\")", signerHash))
+	testutil.AssertNil(t, err)
+	resp := makeQueryRequest(remote, joinQuery)
+	testutil.AssertNil(t, resp.Err)
+
+	resp = reflectOnRemote(remote, api.REFLECT_INDEX)
+	testutil.AssertNil(t, resp.Err)
+	_, err = resp.Index.GetTableAddrs(crdt.NAMESPACE_SIGNATURE_TABLE)
+	testutil.AssertNonNil(t, err)
+
+	// Namespaces signed by points and namespaces signed as a whole share the index.
+	signedPoint, err := crdt.SignedPoint("Mr Jones", []crypto.PrivateKey{signer})
+	testutil.AssertNil(t, err)
+	pointSigned := makeCars("car2", signedPoint)
+	strangerSigned, err := crdt.SignNamespace(makeCars("car3", crdt.UnsignedPoint("Mr Smith")), []crypto.PrivateKey{stranger})
+	testutil.AssertNil(t, err)
+
+	for _, namespace := range []crdt.Namespace{pointSigned, strangerSigned} {
+		resp = makeSignedReplicateRequest(remote, addSignedPeerIndex(t, store, namespace, signer))
+		testutil.AssertNil(t, resp.Err)
+	}
+
+	selectQuery, err := query.Compile(fmt.Sprintf("select cars signed \"%s\"", signerHash))
+	testutil.AssertNil(t, err)
+	resp = makeQueryRequest(remote, selectQuery)
+	testutil.AssertNil(t, resp.Err)
+
+	expected := makeCars("car1", crdt.UnsignedPoint("Mr Blogs")).JoinNamespace(pointSigned)
+	testutil.Assert(t, "Unexpected signed namespace", expected.Equals(resp.Namespace))
+}
+
+type markedNamespaceCache struct {
+	api.NamespaceCache
+	sync.Mutex
+	markers map[string]struct{}
+	hits    int
+}
+
+func (cache *markedNamespaceCache) IsVerifiedNamespace(addr crdt.IPFSPath, digest string) (bool, error) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	_, present := cache.markers[string(addr)+digest]
+
+	if present {
+		cache.hits++
+	}
+
+	return present, nil
+}
+
+func (cache *markedNamespaceCache) SetVerifiedNamespace(addr crdt.IPFSPath, digest string) error {
+	cache.Lock()
+	defer cache.Unlock()
+
+	cache.markers[string(addr)+digest] = struct{}{}
+	return nil
+}
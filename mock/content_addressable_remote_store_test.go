@@ -2,16 +2,19 @@ package mock_godless
 
 import (
 	"bytes"
+	stdcrypto "crypto"
 	"io"
 	"io/ioutil"
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 
 	"github.com/johnny-morrice/godless/api"
 	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/datapeer"
 	"github.com/johnny-morrice/godless/internal/service"
 	"github.com/johnny-morrice/godless/internal/testutil"
 )
@@ -163,6 +166,75 @@ func TestContentAddressableRemoteStoreCatNamespaceFailure(t *testing.T) {
 	testutil.Assert(t, "Expected zero namespace", namespace.IsEmpty())
 }
 
+func TestContentAddressableRemoteStoreCatNamespaceRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const namespaceAddr = "Namespace addr"
+
+	mock := NewMockDataPeer(ctrl)
+	store := service.MakeContentAddressableRemoteStore(mock).(api.NamespaceStreamStore)
+
+	expected := makeRowsForIPFS()
+	buff := &bytes.Buffer{}
+	invalid, err := crdt.EncodeNamespace(expected, buff)
+	panicOnInvalidNamespace(invalid)
+	panicOnBadInit(err)
+
+	mock.EXPECT().IsUp().Return(true).AnyTimes()
+	mock.EXPECT().Cat(namespaceAddr).Return(ioutil.NopCloser(bytes.NewReader(buff.Bytes())), nil).Times(2)
+
+	actual := crdt.EmptyNamespace()
+	rowCount := 0
+	err = store.CatNamespaceRows(namespaceAddr, func(row crdt.Namespace) bool {
+		rowCount++
+		actual = actual.JoinNamespace(row)
+		return true
+	})
+
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected row count", 3, rowCount)
+	testutil.Assert(t, "Unexpected namespace", expected.Equals(actual))
+
+	rowCount = 0
+	err = store.CatNamespaceRows(namespaceAddr, func(row crdt.Namespace) bool {
+		rowCount++
+		return false
+	})
+
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Expected early stop", 1, rowCount)
+}
+
+func TestContentAddressableRemoteStoreCatNamespaceRowsLegacy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const namespaceAddr = "Namespace addr"
+
+	mock := NewMockDataPeer(ctrl)
+	store := service.MakeContentAddressableRemoteStore(mock).(api.NamespaceStreamStore)
+
+	expected := makeRowsForIPFS()
+	message, invalid := crdt.MakeNamespaceMessage(expected)
+	panicOnInvalidNamespace(invalid)
+	bs, err := proto.Marshal(message)
+	panicOnBadInit(err)
+
+	mock.EXPECT().IsUp().Return(true).AnyTimes()
+	mock.EXPECT().Cat(namespaceAddr).Return(ioutil.NopCloser(bytes.NewReader(bs)), nil)
+
+	rows := []crdt.Namespace{}
+	err = store.CatNamespaceRows(namespaceAddr, func(row crdt.Namespace) bool {
+		rows = append(rows, row)
+		return true
+	})
+
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 1, rows)
+	testutil.Assert(t, "Unexpected namespace", expected.Equals(rows[0]))
+}
+
 func TestContentAddressableRemoteStoreCatIndexSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -399,6 +471,25 @@ func makeIndexReaderForIPFS() io.ReadCloser {
 	return ioutil.NopCloser(buff)
 }
 
+func makeRowsForIPFS() crdt.Namespace {
+	return crdt.MakeNamespace(map[crdt.TableName]crdt.Table{
+		"Hi": crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Hello": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Dude": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Wow")}),
+				"Man":  crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Cool")}),
+			}),
+			"Goodbye": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Dude": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Bye")}),
+			}),
+		}),
+		"Other": crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Hello": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Dude": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Other")}),
+			}),
+		}),
+	})
+}
+
 func makeIndexForIPFS() crdt.Index {
 	return crdt.MakeIndex(map[crdt.TableName]crdt.Link{
 		"Hi": crdt.UnsignedLink("Dude"),
@@ -418,3 +509,39 @@ func makeNamespaceForIPFS() crdt.Namespace {
 func expectedError() error {
 	return errors.New("Expected error")
 }
+
+func TestContentAddressableRemoteStoreBlob(t *testing.T) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	peer := datapeer.MakeResidentMemoryDataPeer(options)
+	store := service.MakeContentAddressableRemoteStore(peer).(api.BlobStore)
+
+	// Spans several chunks.
+	expected := make([]byte, 600*1024)
+	for i := range expected {
+		expected[i] = byte(testutil.Rand().Intn(256))
+	}
+
+	path, err := store.AddBlob(bytes.NewReader(expected))
+	testutil.AssertNil(t, err)
+
+	reader, err := store.CatBlob(path)
+	testutil.AssertNil(t, err)
+	defer reader.Close()
+
+	actual, err := ioutil.ReadAll(reader)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected blob", bytes.Equal(expected, actual))
+
+	empty, err := store.AddBlob(bytes.NewReader(nil))
+	testutil.AssertNil(t, err)
+
+	reader, err = store.CatBlob(empty)
+	testutil.AssertNil(t, err)
+	defer reader.Close()
+
+	actual, err = ioutil.ReadAll(reader)
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 0, actual)
+}
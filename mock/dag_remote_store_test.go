@@ -0,0 +1,236 @@
+package mock_godless
+
+import (
+	stdcrypto "crypto"
+	"testing"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/datapeer"
+	"github.com/johnny-morrice/godless/internal/service"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestDagRemoteStoreRoundTrip(t *testing.T) {
+	priv, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	store, dag := makeDagRemoteStore()
+
+	signed, err := crdt.SignedPoint("Point A", []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+
+	namespace := crdt.MakeNamespace(map[crdt.TableName]crdt.Table{
+		"Table A": crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Row A": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry A": crdt.MakeEntry([]crdt.Point{signed, crdt.UnsignedPoint("Point B")}),
+			}),
+			"Row B": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry B": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("\xff\xfe")}),
+			}),
+		}),
+	})
+
+	namespaceAddr, err := store.AddNamespace(namespace)
+	testutil.AssertNil(t, err)
+
+	actualNamespace, err := store.CatNamespace(namespaceAddr)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected namespace", namespace.Equals(actualNamespace))
+
+	link, err := crdt.SignedLink(namespaceAddr, []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+	index := crdt.EmptyIndex().JoinTable("Table A", link)
+
+	indexAddr, err := store.AddIndex(index)
+	testutil.AssertNil(t, err)
+
+	actualIndex, err := store.CatIndex(indexAddr)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected index", index.Equals(actualIndex))
+
+	_, err = store.CatIndex(namespaceAddr)
+	testutil.AssertNonNil(t, err)
+	_, err = store.CatNamespace(indexAddr)
+	testutil.AssertNonNil(t, err)
+
+	// IPFS can follow links from the index down to a point.
+	var text string
+	err = dag.DagGet(string(indexAddr)+"/tables/0/links/0/namespace/tables/0/link/rows/0/link/entries/0/points/1/text", &text)
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected text", "Point B", text)
+}
+
+func TestDagRemoteStoreSharesRows(t *testing.T) {
+	store, dag := makeDagRemoteStore()
+
+	rowA := crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+		"Entry A": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point A")}),
+	})
+	rowB := crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+		"Entry B": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point B")}),
+	})
+
+	before := crdt.EmptyNamespace().JoinTable("Table", crdt.MakeTable(map[crdt.RowName]crdt.Row{"Row A": rowA}))
+	after := crdt.EmptyNamespace().JoinTable("Table", crdt.MakeTable(map[crdt.RowName]crdt.Row{"Row A": rowA, "Row B": rowB}))
+
+	beforeAddr, err := store.AddNamespace(before)
+	testutil.AssertNil(t, err)
+	afterAddr, err := store.AddNamespace(after)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected different namespaces", beforeAddr != afterAddr)
+
+	type tableNode struct {
+		Rows []struct {
+			Name string            `json:"name"`
+			Link map[string]string `json:"link"`
+		} `json:"rows"`
+	}
+
+	beforeTable := tableNode{}
+	err = dag.DagGet(string(beforeAddr)+"/tables/0/link", &beforeTable)
+	testutil.AssertNil(t, err)
+	afterTable := tableNode{}
+	err = dag.DagGet(string(afterAddr)+"/tables/0/link", &afterTable)
+	testutil.AssertNil(t, err)
+
+	testutil.AssertEquals(t, "Unexpected row", "Row A", afterTable.Rows[0].Name)
+	testutil.AssertEquals(t, "Expected shared row node", beforeTable.Rows[0].Link["/"], afterTable.Rows[0].Link["/"])
+
+	rows := 0
+	err = store.(api.NamespaceStreamStore).CatNamespaceRows(afterAddr, func(row crdt.Namespace) bool {
+		rows++
+		return false
+	})
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected row count", 1, rows)
+}
+
+func TestDagRemoteStoreLinkNames(t *testing.T) {
+	store, _ := makeDagRemoteStore()
+
+	// A map keyed by these names would look like a link.
+	namespace := crdt.MakeNamespace(map[crdt.TableName]crdt.Table{
+		"/": crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"/": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"/": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point")}),
+			}),
+		}),
+	})
+
+	namespaceAddr, err := store.AddNamespace(namespace)
+	testutil.AssertNil(t, err)
+
+	actualNamespace, err := store.CatNamespace(namespaceAddr)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected namespace", namespace.Equals(actualNamespace))
+
+	index := crdt.EmptyIndex().JoinTable("/", crdt.UnsignedLink(namespaceAddr))
+	indexAddr, err := store.AddIndex(index)
+	testutil.AssertNil(t, err)
+
+	actualIndex, err := store.CatIndex(indexAddr)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected index", index.Equals(actualIndex))
+}
+
+func TestDagRemoteStoreReadsLegacy(t *testing.T) {
+	peer, dag := makeDagPeers()
+	legacy := service.MakeContentAddressableRemoteStore(peer)
+	store := service.MakeDagRemoteStore(peer, dag, crdt.CODEC_NONE)
+
+	namespace := crdt.EmptyNamespace().JoinTable("Table", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+		"Row A": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point A")}),
+		}),
+		"Row B": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point B")}),
+		}),
+	}))
+
+	namespaceAddr, err := legacy.AddNamespace(namespace)
+	testutil.AssertNil(t, err)
+	index := crdt.EmptyIndex().JoinTable("Table", crdt.UnsignedLink(namespaceAddr))
+	indexAddr, err := legacy.AddIndex(index)
+	testutil.AssertNil(t, err)
+
+	actualIndex, err := store.CatIndex(indexAddr)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected index", index.Equals(actualIndex))
+
+	actualNamespace, err := store.CatNamespace(namespaceAddr)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected namespace", namespace.Equals(actualNamespace))
+
+	rows := 0
+	err = store.(api.NamespaceStreamStore).CatNamespaceRows(namespaceAddr, func(row crdt.Namespace) bool {
+		rows++
+		return true
+	})
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected row count", 2, rows)
+
+	_, err = store.CatIndex("Not present")
+	testutil.AssertNonNil(t, err)
+}
+
+func TestDagRemoteStoreRowsSignaturesFirst(t *testing.T) {
+	priv, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	peer, dag := makeDagPeers()
+	legacy := service.MakeContentAddressableRemoteStore(peer)
+	store := service.MakeDagRemoteStore(peer, dag, crdt.CODEC_NONE)
+
+	namespace := crdt.EmptyNamespace()
+	// A space sorts before the '!' of the signature table.
+	for _, table := range []crdt.TableName{"Zed", " Early", "Middle"} {
+		namespace = namespace.JoinTable(table, crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Row": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point")}),
+			}),
+		}))
+	}
+
+	signed, err := crdt.SignNamespace(namespace, []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+
+	dagAddr, err := store.AddNamespace(signed)
+	testutil.AssertNil(t, err)
+	legacyAddr, err := legacy.AddNamespace(signed)
+	testutil.AssertNil(t, err)
+
+	expected := []crdt.TableName{crdt.NAMESPACE_SIGNATURE_TABLE, " Early", "Middle", "Zed"}
+
+	for _, addr := range []crdt.IPFSPath{dagAddr, legacyAddr} {
+		tables := []crdt.TableName{}
+		err = store.(api.NamespaceStreamStore).CatNamespaceRows(addr, func(row crdt.Namespace) bool {
+			tables = append(tables, row.GetTableNames()...)
+			return true
+		})
+		testutil.AssertNil(t, err)
+		testutil.AssertEquals(t, "Unexpected table order", expected, tables)
+	}
+}
+
+func TestMakeDagRemoteStoreCodec(t *testing.T) {
+	peer, dag := makeDagPeers()
+	store := service.MakeDagRemoteStore(peer, dag, crdt.CODEC_SNAPPY).(*service.DagRemoteStore)
+	testutil.AssertEquals(t, "Unexpected codec", crdt.CODEC_SNAPPY, store.Codec)
+}
+
+func makeDagRemoteStore() (api.RemoteStore, api.DagStorage) {
+	peer, dag := makeDagPeers()
+	return service.MakeDagRemoteStore(peer, dag, crdt.CODEC_NONE), dag
+}
+
+func makeDagPeers() (api.DataPeer, api.DagStorage) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	peer := datapeer.MakeResidentMemoryDataPeer(options)
+	dag := datapeer.MakeResidentMemoryDagStorage(options)
+
+	return peer, dag
+}
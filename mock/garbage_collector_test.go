@@ -0,0 +1,300 @@
+package mock_godless
+
+import (
+	"bytes"
+	"crypto"
+	"strings"
+	"testing"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/datapeer"
+	"github.com/johnny-morrice/godless/internal/service"
+	"github.com/johnny-morrice/godless/internal/testutil"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	peer, store, fixture := makeGarbageFixture(t)
+
+	options := service.GarbageCollectorOptions{
+		Store:  store,
+		Pinner: peer,
+		Ledger: fixture.ledger,
+		Head:   fixture.head,
+	}
+
+	report, err := service.CollectGarbage(options)
+	testutil.AssertNil(t, err)
+
+	assertPathSet(t, fixture.live, report.Live)
+	assertPathSet(t, []crdt.IPFSPath{}, report.Pinned)
+	assertPathSet(t, []crdt.IPFSPath{fixture.dead}, report.Unpinned)
+	assertPathSet(t, fixture.foreign, report.Ignored)
+
+	expectPins := append(fixture.live, fixture.foreign...)
+	assertPins(t, peer, expectPins)
+	assertRecordedPaths(t, fixture.ledger, fixture.live)
+}
+
+func TestCollectGarbageKeepsRecentBlocks(t *testing.T) {
+	peer, store, fixture := makeGarbageFixture(t)
+
+	var recent crdt.IPFSPath
+	pinner := &hookPinner{
+		PinningPeer: peer,
+		hook: func() {
+			var err error
+			recent, err = store.AddIndex(crdt.EmptyIndex().JoinTable("Recent", crdt.UnsignedLink(fixture.live[1])))
+			testutil.AssertNil(t, err)
+		},
+	}
+
+	options := service.GarbageCollectorOptions{
+		Store:  store,
+		Pinner: pinner,
+		Ledger: fixture.ledger,
+		Head:   fixture.head,
+	}
+
+	report, err := service.CollectGarbage(options)
+	testutil.AssertNil(t, err)
+
+	assertPathSet(t, []crdt.IPFSPath{fixture.dead}, report.Unpinned)
+	assertPathSet(t, []crdt.IPFSPath{recent}, report.Recent)
+
+	expectPins := append(fixture.live, recent)
+	expectPins = append(expectPins, fixture.foreign...)
+	assertPins(t, peer, expectPins)
+}
+
+func TestCollectGarbageKeepsLiveBlobs(t *testing.T) {
+	peer, store, fixture := makeGarbageFixture(t)
+	blobs := store.(api.BlobStore)
+
+	// Spans two chunks.
+	liveBlob, err := blobs.AddBlob(bytes.NewReader(make([]byte, 300*1024)))
+	testutil.AssertNil(t, err)
+	liveChunks, err := blobs.BlobChunks(liveBlob)
+	testutil.AssertNil(t, err)
+	testutil.AssertLenEquals(t, 2, liveChunks)
+
+	deadBlob, err := blobs.AddBlob(strings.NewReader("Dead blob"))
+	testutil.AssertNil(t, err)
+	deadChunks, err := blobs.BlobChunks(deadBlob)
+	testutil.AssertNil(t, err)
+
+	ref := crdt.BlobReference{Path: liveBlob, Size: 300 * 1024}
+	namespace := crdt.MakeNamespace(map[crdt.TableName]crdt.Table{
+		"Blobs": crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Row": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint(ref.PointText())}),
+			}),
+		}),
+	})
+	namespacePath, err := store.AddNamespace(namespace)
+	testutil.AssertNil(t, err)
+
+	headIndex, err := store.CatIndex(fixture.head)
+	testutil.AssertNil(t, err)
+	head, err := store.AddIndex(headIndex.JoinTable("Blobs", crdt.UnsignedLink(namespacePath)))
+	testutil.AssertNil(t, err)
+
+	options := service.GarbageCollectorOptions{
+		Store:  store,
+		Pinner: peer,
+		Ledger: fixture.ledger,
+		Head:   head,
+	}
+
+	report, err := service.CollectGarbage(options)
+	testutil.AssertNil(t, err)
+
+	live := append([]crdt.IPFSPath{head, namespacePath, liveBlob}, liveChunks...)
+	live = append(live, fixture.live[1:]...)
+	assertPathSet(t, live, report.Live)
+
+	unpinned := append([]crdt.IPFSPath{fixture.dead, fixture.head, deadBlob}, deadChunks...)
+	assertPathSet(t, unpinned, report.Unpinned)
+}
+
+// hookPinner runs hook when the collector first lists pins, as if a block were added during the
+// collection.
+type hookPinner struct {
+	api.PinningPeer
+	hook func()
+}
+
+func (pinner *hookPinner) Pins() ([]string, error) {
+	if pinner.hook != nil {
+		pinner.hook()
+		pinner.hook = nil
+	}
+
+	return pinner.PinningPeer.Pins()
+}
+
+func TestCollectGarbageDryRun(t *testing.T) {
+	peer, store, fixture := makeGarbageFixture(t)
+
+	options := service.GarbageCollectorOptions{
+		Store:  store,
+		Pinner: peer,
+		Ledger: fixture.ledger,
+		Head:   fixture.head,
+		DryRun: true,
+	}
+
+	report, err := service.CollectGarbage(options)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected dry run report", report.DryRun)
+
+	assertPathSet(t, []crdt.IPFSPath{fixture.dead}, report.Unpinned)
+
+	expectPins := append(fixture.live, fixture.dead)
+	expectPins = append(expectPins, fixture.foreign...)
+	assertPins(t, peer, expectPins)
+}
+
+func TestCollectGarbageRetainsHistory(t *testing.T) {
+	peer, store, fixture := makeGarbageFixture(t)
+
+	options := service.GarbageCollectorOptions{
+		Store:   store,
+		Pinner:  peer,
+		Ledger:  fixture.ledger,
+		Head:    fixture.head,
+		History: []crdt.IPFSPath{fixture.dead},
+	}
+
+	report, err := service.CollectGarbage(options)
+	testutil.AssertNil(t, err)
+
+	assertPathSet(t, []crdt.IPFSPath{}, report.Unpinned)
+
+	expectPins := append(fixture.live, fixture.dead)
+	expectPins = append(expectPins, fixture.foreign...)
+	assertPins(t, peer, expectPins)
+}
+
+func TestCollectGarbageBadHead(t *testing.T) {
+	peer, store, fixture := makeGarbageFixture(t)
+
+	options := service.GarbageCollectorOptions{
+		Store:  store,
+		Pinner: peer,
+		Ledger: fixture.ledger,
+		Head:   crdt.IPFSPath("Not present"),
+	}
+
+	_, err := service.CollectGarbage(options)
+	testutil.AssertNonNil(t, err)
+
+	expectPins := append(fixture.live, fixture.dead)
+	expectPins = append(expectPins, fixture.foreign...)
+	assertPins(t, peer, expectPins)
+}
+
+type garbageFixture struct {
+	head   crdt.IPFSPath
+	dead   crdt.IPFSPath
+	live   []crdt.IPFSPath
+	ledger api.PinLedger
+	// foreign blocks are pinned but were not added through the ledger.
+	foreign []crdt.IPFSPath
+}
+
+func makeGarbageFixture(t *testing.T) (api.PinningDataPeer, api.RemoteStore, garbageFixture) {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: crypto.SHA1,
+	}
+	raw := datapeer.MakeResidentMemoryDataPeer(options)
+	ledger := datapeer.MakeResidentPinLedger()
+	peer := datapeer.MakeRecordingDataPeer(raw, ledger)
+	store := service.MakeContentAddressableRemoteStore(peer)
+
+	const table = crdt.TableName("Table")
+	namespaceA := crdt.MakeNamespace(map[crdt.TableName]crdt.Table{
+		table: crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Row A": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("A")}),
+			}),
+		}),
+	})
+	namespaceB := crdt.MakeNamespace(map[crdt.TableName]crdt.Table{
+		table: crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Row B": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("B")}),
+			}),
+		}),
+	})
+
+	pathA, err := store.AddNamespace(namespaceA)
+	testutil.AssertNil(t, err)
+	pathB, err := store.AddNamespace(namespaceB)
+	testutil.AssertNil(t, err)
+
+	oldIndex := crdt.EmptyIndex().JoinTable(table, crdt.UnsignedLink(pathA))
+	dead, err := store.AddIndex(oldIndex)
+	testutil.AssertNil(t, err)
+
+	headIndex := oldIndex.JoinTable(table, crdt.UnsignedLink(pathB))
+	head, err := store.AddIndex(headIndex)
+	testutil.AssertNil(t, err)
+
+	junk, err := raw.Add(strings.NewReader("Not godless data"))
+	testutil.AssertNil(t, err)
+
+	// Decodes as godless data, but someone else pinned it.
+	foreignIndex := crdt.EmptyIndex().JoinTable("Foreign", crdt.UnsignedLink(pathA))
+	foreign, err := service.MakeContentAddressableRemoteStore(raw).AddIndex(foreignIndex)
+	testutil.AssertNil(t, err)
+
+	fixture := garbageFixture{
+		head:    head,
+		dead:    dead,
+		live:    []crdt.IPFSPath{head, pathA, pathB},
+		ledger:  ledger,
+		foreign: []crdt.IPFSPath{crdt.IPFSPath(junk), foreign},
+	}
+
+	return peer, store, fixture
+}
+
+func assertPins(t *testing.T, peer api.PinningPeer, expected []crdt.IPFSPath) {
+	pinText, err := peer.Pins()
+	testutil.AssertNil(t, err)
+
+	pins := make([]crdt.IPFSPath, len(pinText))
+	for i, text := range pinText {
+		pins[i] = crdt.IPFSPath(text)
+	}
+
+	assertPathSet(t, expected, pins)
+}
+
+func assertRecordedPaths(t *testing.T, ledger api.PinLedger, expected []crdt.IPFSPath) {
+	records, err := ledger.PinRecords()
+	testutil.AssertNil(t, err)
+
+	paths := make([]crdt.IPFSPath, len(records))
+	for i, record := range records {
+		paths[i] = crdt.IPFSPath(record.Hash)
+	}
+
+	assertPathSet(t, expected, paths)
+}
+
+func assertPathSet(t *testing.T, expected, actual []crdt.IPFSPath) {
+	testutil.AssertLenEquals(t, len(expected), actual)
+
+	set := map[crdt.IPFSPath]struct{}{}
+	for _, path := range actual {
+		set[path] = struct{}{}
+	}
+
+	for _, path := range expected {
+		if _, present := set[path]; !present {
+			t.Errorf("Missing path: %s", path)
+		}
+	}
+}
@@ -196,6 +196,141 @@ func TestRunQueryJoinFailure(t *testing.T) {
 	}
 }
 
+func TestRunQueryJoinEncrypted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer, signerPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	recipient, recipientPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	signerHash, err := signerPub.Hash()
+	testutil.AssertNil(t, err)
+	recipientHash, err := recipientPub.Hash()
+	testutil.AssertNil(t, err)
+
+	joinKeys := &crypto.KeyStore{}
+	testutil.AssertNil(t, joinKeys.PutPrivateKey(signer))
+	testutil.AssertNil(t, joinKeys.PutPublicKey(recipientPub))
+
+	joinQuery := &query.Query{
+		OpCode:     query.JOIN,
+		TableKey:   MAIN_TABLE_KEY,
+		PublicKeys: []crypto.PublicKeyHash{signerHash},
+		Join: query.QueryJoin{
+			Recipients: []crypto.PublicKeyHash{recipientHash},
+			Rows: []query.QueryRowJoin{
+				query.QueryRowJoin{
+					RowKey: "Row A",
+					Entries: map[crdt.EntryName]crdt.PointText{
+						"Entry A": "Point A",
+					},
+				},
+			},
+		},
+	}
+
+	var joined crdt.Table
+	mock := NewMockRemoteNamespace(ctrl)
+	mock.EXPECT().JoinTable(MAIN_TABLE_KEY, gomock.Any()).Return(crdt.IPFSPath("Index Addr"), nil).Do(func(table crdt.TableName, t crdt.Table) {
+		joined = t
+	})
+
+	joiner := eval.MakeNamespaceTreeJoin(mock, joinKeys)
+	joinQuery.Visit(joiner)
+	resp := joiner.RunQuery()
+	testutil.AssertNil(t, resp.Err)
+
+	_, err = joined.GetRow(crdt.ENCRYPTION_KEY_ROW)
+	testutil.AssertNil(t, err)
+
+	joined.ForeachEntry(func(rowName crdt.RowName, entryName crdt.EntryName, entry crdt.Entry) {
+		for _, point := range entry.GetValues() {
+			testutil.Assert(t, "Expected signed point", point.IsVerifiedByAny([]crypto.PublicKey{signerPub}))
+
+			if rowName != crdt.ENCRYPTION_KEY_ROW {
+				testutil.Assert(t, "Expected encrypted point", crdt.IsEncryptedText(point.Text()))
+			}
+		}
+	})
+
+	selectQuery := &query.Query{
+		OpCode:     query.SELECT,
+		TableKey:   MAIN_TABLE_KEY,
+		PublicKeys: []crypto.PublicKeyHash{signerHash},
+		Select: query.QuerySelect{
+			Where: query.QueryWhere{
+				OpCode: query.PREDICATE,
+				Predicate: query.QueryPredicate{
+					OpCode:   query.STR_EQ,
+					Literals: []string{"Point A"},
+					Keys:     []crdt.EntryName{"Entry A"},
+				},
+			},
+		},
+	}
+
+	feedJoined := func(reader api.SearchResultTraverser) {
+		namespace := crdt.MakeNamespace(map[crdt.TableName]crdt.Table{MAIN_TABLE_KEY: joined})
+		reader.ReadSearchResult(api.SearchResult{Namespace: namespace})
+	}
+
+	selectKeys := &crypto.KeyStore{}
+	testutil.AssertNil(t, selectKeys.PutPublicKey(signerPub))
+	testutil.AssertNil(t, selectKeys.PutPrivateKey(recipient))
+
+	mock.EXPECT().LoadTraverse(gomock.Any()).Return(nil).Do(feedJoined).Times(2)
+
+	selector := eval.MakeNamespaceTreeSelect(mock, selectKeys)
+	selectQuery.Visit(selector)
+	resp = selector.RunQuery()
+	testutil.AssertNil(t, resp.Err)
+
+	expected := crdt.MakeNamespace(map[crdt.TableName]crdt.Table{
+		MAIN_TABLE_KEY: crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Row A": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry A": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint("Point A")}),
+			}),
+		}),
+	})
+	testutil.Assert(t, "Unexpected decrypted namespace", expected.Equals(resp.Namespace))
+
+	strangerKeys := &crypto.KeyStore{}
+	testutil.AssertNil(t, strangerKeys.PutPublicKey(signerPub))
+
+	selector = eval.MakeNamespaceTreeSelect(mock, strangerKeys)
+	selectQuery.Visit(selector)
+	resp = selector.RunQuery()
+	testutil.AssertNil(t, resp.Err)
+	testutil.Assert(t, "Expected no matches", resp.Namespace.IsEmpty())
+
+	selectAll := &query.Query{
+		OpCode:     query.SELECT,
+		TableKey:   MAIN_TABLE_KEY,
+		PublicKeys: []crypto.PublicKeyHash{signerHash},
+		Select: query.QuerySelect{
+			Where: query.QueryWhere{OpCode: query.WHERE_NOOP},
+			Limit: 10,
+		},
+	}
+
+	mock.EXPECT().LoadTraverse(gomock.Any()).Return(nil).Do(feedJoined).Times(2)
+
+	for _, keyStore := range []*crypto.KeyStore{selectKeys, strangerKeys} {
+		selector = eval.MakeNamespaceTreeSelect(mock, keyStore)
+		selectAll.Visit(selector)
+		resp = selector.RunQuery()
+		testutil.AssertNil(t, resp.Err)
+
+		table, err := resp.Namespace.GetTable(MAIN_TABLE_KEY)
+		testutil.AssertNil(t, err)
+		testutil.AssertLenEquals(t, 1, table.Rows)
+		_, err = table.GetRow(crdt.ENCRYPTION_KEY_ROW)
+		testutil.AssertNonNil(t, err)
+	}
+}
+
 func TestRunQueryJoinInvalid(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1,6 +1,8 @@
 package mock_godless
 
 import (
+	stdcrypto "crypto"
+	"errors"
 	"testing"
 	"time"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/johnny-morrice/godless/api"
 	"github.com/johnny-morrice/godless/crdt"
 	"github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/datapeer"
 	"github.com/johnny-morrice/godless/internal/service"
 	"github.com/johnny-morrice/godless/internal/testutil"
 )
@@ -304,3 +307,228 @@ func tidyReplicator(t *testing.T, closer api.Closer, errch <-chan error) {
 		testutil.AssertNil(t, err)
 	}
 }
+
+func TestReplicateSummaryFetchesChangedTables(t *testing.T) {
+	// As design stands, replicator is intrinsically a long running process.
+	if testing.Short() {
+		t.SkipNow()
+		return
+	}
+
+	testReplicateSummary(t, api.RESPONSE_REPLICATE)
+}
+
+func TestReplicateSummaryRetriesFailedTables(t *testing.T) {
+	// As design stands, replicator is intrinsically a long running process.
+	if testing.Short() {
+		t.SkipNow()
+		return
+	}
+
+	failure := api.RESPONSE_FAIL
+	failure.Type = api.API_REPLICATE
+	failure.Err = errors.New("Expected error")
+
+	testReplicateSummary(t, failure, api.RESPONSE_REPLICATE)
+}
+
+// testReplicateSummary checks that only the changed table is replicated, once for each response.
+func testReplicateSummary(t *testing.T, replicateResponses ...api.Response) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockApi := NewMockService(ctrl)
+
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	peer := datapeer.MakeResidentMemoryDataPeer(options)
+	store := service.MakeContentAddressableRemoteStore(peer)
+	summaryStore := store.(api.SummaryStore)
+
+	const interval = time.Millisecond * 100
+	const topic = api.PubSubTopic("Topic")
+	topics := []api.PubSubTopic{topic}
+
+	// Older peers read only bare links from the topic.
+	bareLinks, err := peer.PubSubSubscribe(string(topic))
+	testutil.AssertNil(t, err)
+
+	localIndex := crdt.MakeIndex(map[crdt.TableName]crdt.Link{
+		"Cars":    crdt.UnsignedLink("Addr1"),
+		"Animals": crdt.UnsignedLink("Addr2"),
+	})
+	peerIndex := localIndex.JoinTable("Animals", crdt.UnsignedLink("Addr3"))
+
+	localHead, err := store.AddIndex(localIndex)
+	testutil.AssertNil(t, err)
+	peerHead, err := store.AddIndex(peerIndex)
+	testutil.AssertNil(t, err)
+	peerAnimals, err := store.AddIndex(peerIndex.TableIndex("Animals"))
+	testutil.AssertNil(t, err)
+	peerCars, err := store.AddIndex(peerIndex.TableIndex("Cars"))
+	testutil.AssertNil(t, err)
+
+	priv, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	keyStore := &crypto.KeyStore{}
+	err = keyStore.PutPrivateKey(priv)
+	testutil.AssertNil(t, err)
+	privKeys := []crypto.PrivateKey{priv}
+
+	peerAnimalsLink, err := crdt.SignedLink(peerAnimals, privKeys)
+	testutil.AssertNil(t, err)
+	peerCarsLink, err := crdt.SignedLink(peerCars, privKeys)
+	testutil.AssertNil(t, err)
+
+	peerHashes, invalid := crdt.HashIndexTables(peerIndex)
+	testutil.AssertLenEquals(t, 0, invalid)
+
+	peerSummary := crdt.IndexSummary{
+		Head: crdt.UnsignedLink(peerHead),
+		Root: crdt.HashRoot(peerHashes),
+		Tables: []crdt.TableSummary{
+			crdt.TableSummary{TableName: "Animals", Hash: peerHashes["Animals"], Link: peerAnimalsLink},
+			crdt.TableSummary{TableName: "Cars", Hash: peerHashes["Cars"], Link: peerCarsLink},
+		},
+	}
+
+	const headCount = 100
+	headRequest := api.Request{Type: api.API_REFLECT, Reflection: api.REFLECT_HEAD_PATH}
+	headResponse := api.RESPONSE_REFLECT
+	headResponse.Path = localHead
+	headRespch := make(chan api.Response, headCount)
+	for i := 0; i < headCount; i++ {
+		headRespch <- headResponse
+	}
+
+	// Only the changed table should be replicated.
+	replicateRequest := api.Request{Type: api.API_REPLICATE, Replicate: []crdt.Link{peerAnimalsLink}}
+	replicateRespch := make(chan api.Response, len(replicateResponses))
+	for _, resp := range replicateResponses {
+		replicateRespch <- resp
+	}
+
+	mockApi.EXPECT().Call(headRequest).Return(headRespch, nil).MinTimes(1)
+	mockApi.EXPECT().Call(replicateRequest).Return(replicateRespch, nil).Times(len(replicateResponses))
+
+	replicateOptions := service.ReplicateOptions{
+		Topics:      topics,
+		Interval:    interval,
+		KeyStore:    keyStore,
+		RemoteStore: store,
+		API:         mockApi,
+	}
+
+	closer, errch := service.Replicate(replicateOptions)
+	defer tidyReplicator(t, closer, errch)
+
+	timeout := time.NewTimer(interval * 3)
+	ticker := time.NewTicker(interval / 5)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := summaryStore.PublishSummary(peerSummary, topics)
+			testutil.AssertNil(t, err)
+		case <-timeout.C:
+			record, err := bareLinks.Next()
+			testutil.AssertNil(t, err)
+			link, err := crdt.ParseLink(crdt.LinkText(record.Data()))
+			testutil.AssertNil(t, err)
+			testutil.AssertEquals(t, "Unexpected bare link", localHead, link.Path())
+			return
+		}
+	}
+}
+
+func TestReplicateSummaryUntrustedHeadKeepsBareLink(t *testing.T) {
+	// As design stands, replicator is intrinsically a long running process.
+	if testing.Short() {
+		t.SkipNow()
+		return
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockApi := NewMockService(ctrl)
+
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: stdcrypto.SHA1,
+	}
+	peer := datapeer.MakeResidentMemoryDataPeer(options)
+	store := service.MakeContentAddressableRemoteStore(peer)
+	summaryStore := store.(api.SummaryStore)
+
+	const interval = time.Millisecond * 100
+	const topic = api.PubSubTopic("Topic")
+	topics := []api.PubSubTopic{topic}
+
+	localIndex := crdt.MakeIndex(map[crdt.TableName]crdt.Link{
+		"Cars": crdt.UnsignedLink("Addr1"),
+	})
+	peerIndex := localIndex.JoinTable("Cars", crdt.UnsignedLink("Addr2"))
+
+	localHead, err := store.AddIndex(localIndex)
+	testutil.AssertNil(t, err)
+	peerHead, err := store.AddIndex(peerIndex)
+	testutil.AssertNil(t, err)
+
+	priv, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	keyStore := &crypto.KeyStore{}
+	err = keyStore.PutPrivateKey(priv)
+	testutil.AssertNil(t, err)
+
+	peerLink, err := crdt.SignedLink(peerHead, []crypto.PrivateKey{priv})
+	testutil.AssertNil(t, err)
+
+	// A stranger claims the peer HEAD matches ours, without signing it.
+	localHashes, invalid := crdt.HashIndexTables(localIndex)
+	testutil.AssertLenEquals(t, 0, invalid)
+	strangerSummary := crdt.IndexSummary{
+		Head: crdt.UnsignedLink(peerHead),
+		Root: crdt.HashRoot(localHashes),
+		Tables: []crdt.TableSummary{
+			crdt.TableSummary{TableName: "Cars", Hash: localHashes["Cars"], Link: crdt.UnsignedLink(localHead)},
+		},
+	}
+
+	const headCount = 100
+	headRequest := api.Request{Type: api.API_REFLECT, Reflection: api.REFLECT_HEAD_PATH}
+	headResponse := api.RESPONSE_REFLECT
+	headResponse.Path = localHead
+	headRespch := make(chan api.Response, headCount)
+	for i := 0; i < headCount; i++ {
+		headRespch <- headResponse
+	}
+
+	replicateRequest := api.Request{Type: api.API_REPLICATE, Replicate: []crdt.Link{peerLink}}
+	replicateRespch := make(chan api.Response, 1)
+	replicateRespch <- api.RESPONSE_REPLICATE
+
+	mockApi.EXPECT().Call(headRequest).Return(headRespch, nil).MinTimes(1)
+	mockApi.EXPECT().Call(replicateRequest).Return(replicateRespch, nil).Times(1)
+
+	replicateOptions := service.ReplicateOptions{
+		Topics:      topics,
+		Interval:    interval,
+		KeyStore:    keyStore,
+		RemoteStore: store,
+		API:         mockApi,
+	}
+
+	closer, errch := service.Replicate(replicateOptions)
+	defer tidyReplicator(t, closer, errch)
+
+	time.Sleep(interval)
+	err = summaryStore.PublishSummary(strangerSummary, topics)
+	testutil.AssertNil(t, err)
+	time.Sleep(interval)
+
+	err = store.PublishAddr(peerLink, topics)
+	testutil.AssertNil(t, err)
+	time.Sleep(interval * 2)
+}
@@ -0,0 +1,326 @@
+package mock_godless
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto"
+	"io/ioutil"
+	"testing"
+
+	pb "github.com/gogo/protobuf/proto"
+
+	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/cache"
+	"github.com/johnny-morrice/godless/crdt"
+	godlesscrypto "github.com/johnny-morrice/godless/crypto"
+	"github.com/johnny-morrice/godless/datapeer"
+	"github.com/johnny-morrice/godless/internal/service"
+	"github.com/johnny-morrice/godless/internal/testutil"
+	"github.com/johnny-morrice/godless/proto"
+)
+
+func TestSnapshotExportImport(t *testing.T) {
+	keyStore := makeSnapshotKeyStore(t)
+	source, head, expected := makeSnapshotSource(t, keyStore)
+
+	archive := exportSnapshot(t, source, head, keyStore)
+
+	target := makeSnapshotStore(crypto.SHA1)
+	memimg := cache.MakeResidentMemoryImage()
+
+	options := service.SnapshotImportOptions{
+		Store:       target,
+		MemoryImage: memimg,
+		KeyStore:    keyStore,
+		Input:       bytes.NewReader(archive),
+	}
+
+	imported, err := service.ImportSnapshot(options)
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Unexpected index path", head, imported)
+
+	index, err := memimg.GetIndex()
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected index", expected.Equals(index))
+
+	assertSnapshotNamespaces(t, target, index)
+}
+
+func TestSnapshotExportImportBlobs(t *testing.T) {
+	keyStore := makeSnapshotKeyStore(t)
+	source := makeSnapshotStore(crypto.SHA1)
+
+	value := bytes.Repeat([]byte("Large value"), 30*1024)
+	blobPath, err := source.(api.BlobStore).AddBlob(bytes.NewReader(value))
+	testutil.AssertNil(t, err)
+
+	ref := crdt.BlobReference{Path: blobPath, Size: len(value)}
+	namespace := crdt.EmptyNamespace().JoinTable("Blobs", crdt.MakeTable(map[crdt.RowName]crdt.Row{
+		"Row": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+			"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint(ref.PointText())}),
+		}),
+	}))
+	namespacePath, err := source.AddNamespace(namespace)
+	testutil.AssertNil(t, err)
+	head, err := source.AddIndex(crdt.EmptyIndex().JoinTable("Blobs", crdt.UnsignedLink(namespacePath)))
+	testutil.AssertNil(t, err)
+
+	archive := exportSnapshot(t, source, head, keyStore)
+
+	target := makeSnapshotStore(crypto.SHA1)
+	options := service.SnapshotImportOptions{
+		Store:       target,
+		MemoryImage: cache.MakeResidentMemoryImage(),
+		KeyStore:    keyStore,
+		Input:       bytes.NewReader(archive),
+	}
+
+	_, err = service.ImportSnapshot(options)
+	testutil.AssertNil(t, err)
+
+	reader, err := target.(api.BlobStore).CatBlob(blobPath)
+	testutil.AssertNil(t, err)
+	defer reader.Close()
+
+	actual, err := ioutil.ReadAll(reader)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected blob", bytes.Equal(value, actual))
+}
+
+func TestSnapshotImportRelinks(t *testing.T) {
+	keyStore := makeSnapshotKeyStore(t)
+	source, head, _ := makeSnapshotSource(t, keyStore)
+
+	archive := exportSnapshot(t, source, head, keyStore)
+
+	target := makeSnapshotStore(crypto.MD5)
+	memimg := cache.MakeResidentMemoryImage()
+
+	options := service.SnapshotImportOptions{
+		Store:       target,
+		MemoryImage: memimg,
+		KeyStore:    keyStore,
+		Input:       bytes.NewReader(archive),
+	}
+
+	imported, err := service.ImportSnapshot(options)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Expected new index path", imported != head)
+
+	index, err := memimg.GetIndex()
+	testutil.AssertNil(t, err)
+
+	keys := keyStore.GetAllPublicKeys()
+	for _, table := range index.AllTables() {
+		index.ForTable(table, func(link crdt.Link) {
+			testutil.Assert(t, "Expected verified link", link.IsVerifiedByAny(keys))
+		})
+	}
+
+	assertSnapshotNamespaces(t, target, index)
+}
+
+func TestSnapshotImportRelinksUntrusted(t *testing.T) {
+	exporterKeys := makeSnapshotKeyStore(t)
+	source, head, _ := makeSnapshotSource(t, exporterKeys)
+
+	archive := exportSnapshot(t, source, head, exporterKeys)
+
+	importerKeys := makeSnapshotKeyStore(t)
+	memimg := cache.MakeResidentMemoryImage()
+
+	options := service.SnapshotImportOptions{
+		Store:       makeSnapshotStore(crypto.MD5),
+		MemoryImage: memimg,
+		KeyStore:    importerKeys,
+		Input:       bytes.NewReader(archive),
+		IsPublic:    true,
+	}
+
+	_, err := service.ImportSnapshot(options)
+	testutil.AssertNil(t, err)
+
+	index, err := memimg.GetIndex()
+	testutil.AssertNil(t, err)
+
+	keys := importerKeys.GetAllPublicKeys()
+	for _, table := range index.AllTables() {
+		index.ForTable(table, func(link crdt.Link) {
+			testutil.Assert(t, "Unexpected verified link", !link.IsVerifiedByAny(keys))
+			testutil.AssertLenEquals(t, 0, link.Signatures())
+		})
+	}
+}
+
+func TestSnapshotImportUnsigned(t *testing.T) {
+	keyStore := makeSnapshotKeyStore(t)
+	source, head, _ := makeSnapshotSource(t, keyStore)
+
+	archive := exportSnapshot(t, source, head, &godlesscrypto.KeyStore{})
+
+	options := service.SnapshotImportOptions{
+		Store:       makeSnapshotStore(crypto.SHA1),
+		MemoryImage: cache.MakeResidentMemoryImage(),
+		KeyStore:    keyStore,
+		Input:       bytes.NewReader(archive),
+	}
+
+	_, err := service.ImportSnapshot(options)
+	testutil.AssertNonNil(t, err)
+
+	options.Input = bytes.NewReader(archive)
+	options.IsPublic = true
+
+	_, err = service.ImportSnapshot(options)
+	testutil.AssertNil(t, err)
+}
+
+func TestSnapshotImportCorrupt(t *testing.T) {
+	keyStore := makeSnapshotKeyStore(t)
+	source, head, _ := makeSnapshotSource(t, keyStore)
+
+	archive := exportSnapshot(t, source, head, keyStore)
+	corrupt := corruptSnapshot(t, archive)
+
+	options := service.SnapshotImportOptions{
+		Store:       makeSnapshotStore(crypto.SHA1),
+		MemoryImage: cache.MakeResidentMemoryImage(),
+		KeyStore:    keyStore,
+		Input:       bytes.NewReader(corrupt),
+	}
+
+	_, err := service.ImportSnapshot(options)
+	testutil.AssertNonNil(t, err)
+}
+
+func TestSnapshotImportUnknownEntryType(t *testing.T) {
+	manifest := &proto.SnapshotManifestMessage{
+		Version: 1,
+		Head:    "Head",
+		Entries: []*proto.SnapshotEntryMessage{{Type: 99, Path: "Path", Digest: "Digest"}},
+	}
+
+	manifestBytes, err := pb.Marshal(manifest)
+	testutil.AssertNil(t, err)
+
+	buff := &bytes.Buffer{}
+	writer := tar.NewWriter(buff)
+	err = writer.WriteHeader(&tar.Header{Name: "manifest", Mode: 0600, Size: int64(len(manifestBytes))})
+	testutil.AssertNil(t, err)
+	_, err = writer.Write(manifestBytes)
+	testutil.AssertNil(t, err)
+	testutil.AssertNil(t, writer.Close())
+
+	options := service.SnapshotImportOptions{
+		Store:       makeSnapshotStore(crypto.SHA1),
+		MemoryImage: cache.MakeResidentMemoryImage(),
+		KeyStore:    makeSnapshotKeyStore(t),
+		Input:       bytes.NewReader(buff.Bytes()),
+		IsPublic:    true,
+	}
+
+	_, err = service.ImportSnapshot(options)
+	testutil.AssertNonNil(t, err)
+}
+
+func makeSnapshotKeyStore(t *testing.T) api.KeyStore {
+	keyStore := &godlesscrypto.KeyStore{}
+	priv, _, err := godlesscrypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	err = keyStore.PutPrivateKey(priv)
+	testutil.AssertNil(t, err)
+	return keyStore
+}
+
+func makeSnapshotStore(hash crypto.Hash) api.RemoteStore {
+	options := datapeer.ResidentMemoryStorageOptions{
+		Hash: hash,
+	}
+	peer := datapeer.MakeResidentMemoryDataPeer(options)
+	return service.MakeContentAddressableRemoteStore(peer)
+}
+
+func makeSnapshotSource(t *testing.T, keyStore api.KeyStore) (api.RemoteStore, crdt.IPFSPath, crdt.Index) {
+	store := makeSnapshotStore(crypto.SHA1)
+	keys := keyStore.GetAllPrivateKeys()
+
+	index := crdt.EmptyIndex()
+	for _, table := range []crdt.TableName{"Cars", "Boats"} {
+		namespace := crdt.EmptyNamespace().JoinTable(table, crdt.MakeTable(map[crdt.RowName]crdt.Row{
+			"Row": crdt.MakeRow(map[crdt.EntryName]crdt.Entry{
+				"Entry": crdt.MakeEntry([]crdt.Point{crdt.UnsignedPoint(crdt.PointText(table))}),
+			}),
+		}))
+
+		path, err := store.AddNamespace(namespace)
+		testutil.AssertNil(t, err)
+
+		link, err := crdt.SignedLink(path, keys)
+		testutil.AssertNil(t, err)
+
+		index = index.JoinTable(table, link)
+	}
+
+	head, err := store.AddIndex(index)
+	testutil.AssertNil(t, err)
+
+	return store, head, index
+}
+
+func exportSnapshot(t *testing.T, store api.RemoteStore, head crdt.IPFSPath, keyStore api.KeyStore) []byte {
+	buff := &bytes.Buffer{}
+
+	options := service.SnapshotExportOptions{
+		Store:  store,
+		Head:   head,
+		Keys:   keyStore.GetAllPrivateKeys(),
+		Output: buff,
+	}
+
+	err := service.ExportSnapshot(options)
+	testutil.AssertNil(t, err)
+
+	return buff.Bytes()
+}
+
+func corruptSnapshot(t *testing.T, archive []byte) []byte {
+	reader := tar.NewReader(bytes.NewReader(archive))
+	buff := &bytes.Buffer{}
+	writer := tar.NewWriter(buff)
+
+	for {
+		header, err := reader.Next()
+
+		if err != nil {
+			break
+		}
+
+		data, err := ioutil.ReadAll(reader)
+		testutil.AssertNil(t, err)
+
+		if header.Name != "manifest" {
+			data[len(data)-1]++
+		}
+
+		err = writer.WriteHeader(header)
+		testutil.AssertNil(t, err)
+		_, err = writer.Write(data)
+		testutil.AssertNil(t, err)
+	}
+
+	err := writer.Close()
+	testutil.AssertNil(t, err)
+
+	return buff.Bytes()
+}
+
+func assertSnapshotNamespaces(t *testing.T, store api.RemoteStore, index crdt.Index) {
+	for _, table := range index.AllTables() {
+		index.ForTable(table, func(link crdt.Link) {
+			namespace, err := store.CatNamespace(link.Path())
+			testutil.AssertNil(t, err)
+			_, err = namespace.GetTable(table)
+			testutil.AssertNil(t, err)
+		})
+	}
+}
@@ -6,10 +6,12 @@ import (
 	gohttp "net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 
 	"github.com/johnny-morrice/godless/api"
+	"github.com/johnny-morrice/godless/crypto"
 	"github.com/johnny-morrice/godless/http"
 	"github.com/johnny-morrice/godless/internal/testutil"
 	"github.com/johnny-morrice/godless/log"
@@ -55,6 +57,179 @@ func TestWebServiceGetApiRequestHandler(t *testing.T) {
 	testutil.AssertNonNil(t, err)
 }
 
+func TestWebServiceRejectsLargeRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const SIZE = 50
+	request := api.GenRequest(testutil.Rand(), SIZE)
+	buff := &bytes.Buffer{}
+	err := api.EncodeRequest(request, buff)
+	testutil.AssertNil(t, err)
+
+	options := http.WebServiceOptions{
+		Api:             NewMockService(ctrl),
+		MaxRequestBytes: int64(buff.Len() - 1),
+		Endpoints: http.Endpoints{
+			CommandEndpoint: TEST_COMMAND_ENDPOINT,
+		},
+	}
+
+	webService := http.MakeWebService(options)
+	defer webService.Close()
+
+	resp, err := webApiCall(webService.GetApiRequestHandler(), request)
+	testutil.AssertNil(t, err)
+	testutil.AssertNonNil(t, resp.Err)
+}
+
+func TestWebServiceSignedResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serverKey, serverPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	_, strangerPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	const SIZE = 50
+	request := api.GenRequest(testutil.Rand(), SIZE)
+	expected := api.GenResponse(testutil.Rand(), SIZE)
+	expected.Err = nil
+	expected.Head = "Head"
+
+	mock := NewMockService(ctrl)
+	mock.EXPECT().Call(matchRequest(request)).DoAndReturn(func(api.Request) (<-chan api.Response, error) {
+		return respondWith(expected), nil
+	}).Times(2)
+
+	options := http.WebServiceOptions{
+		Api:        mock,
+		SigningKey: &serverKey,
+		Endpoints: http.Endpoints{
+			CommandEndpoint: TEST_COMMAND_ENDPOINT,
+		},
+	}
+
+	webService := http.MakeWebService(options)
+	defer webService.Close()
+
+	server := httptest.NewServer(webService.GetApiRequestHandler())
+	defer server.Close()
+
+	makeClient := func(serverKey crypto.PublicKey) api.Client {
+		client, err := http.MakeClient(http.ClientOptions{
+			ServerAddr: server.URL,
+			ServerKey:  &serverKey,
+			Endpoints: http.Endpoints{
+				CommandEndpoint: TEST_COMMAND_ENDPOINT,
+			},
+		})
+		panicOnBadInit(err)
+		return client
+	}
+
+	actual, err := makeClient(serverPub).Send(request)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected api.Response", expected.Equals(actual))
+
+	_, err = makeClient(strangerPub).Send(request)
+	testutil.AssertNonNil(t, err)
+}
+
+func TestWebServiceRequestAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	allowed, allowedPub, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+	stranger, _, err := crypto.GenerateKey()
+	testutil.AssertNil(t, err)
+
+	const SIZE = 50
+	request := api.GenRequest(testutil.Rand(), SIZE)
+	expected := api.GenResponse(testutil.Rand(), SIZE)
+	expected.Err = nil
+
+	mock := NewMockService(ctrl)
+	mock.EXPECT().Call(matchRequest(request)).DoAndReturn(func(api.Request) (<-chan api.Response, error) {
+		return respondWith(expected), nil
+	}).Times(2)
+
+	options := http.WebServiceOptions{
+		Api:         mock,
+		AllowedKeys: []crypto.PublicKey{allowedPub},
+		Endpoints: http.Endpoints{
+			CommandEndpoint: TEST_COMMAND_ENDPOINT,
+		},
+	}
+
+	webService := http.MakeWebService(options)
+	defer webService.Close()
+
+	server := httptest.NewServer(webService.GetApiRequestHandler())
+	defer server.Close()
+
+	makeClient := func(signingKey *crypto.PrivateKey) api.Client {
+		client, err := http.MakeClient(http.ClientOptions{
+			ServerAddr: server.URL,
+			SigningKey: signingKey,
+			Endpoints: http.Endpoints{
+				CommandEndpoint: TEST_COMMAND_ENDPOINT,
+			},
+		})
+		panicOnBadInit(err)
+		return client
+	}
+
+	actual, err := makeClient(&allowed).Send(request)
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected api.Response", expected.Equals(actual))
+
+	_, err = makeClient(&stranger).Send(request)
+	testutil.AssertNonNil(t, err)
+
+	_, err = makeClient(nil).Send(request)
+	testutil.AssertNonNil(t, err)
+
+	buff := &bytes.Buffer{}
+	testutil.AssertNil(t, api.EncodeRequest(request, buff))
+	body := buff.Bytes()
+
+	postSigned := func(auth http.RequestAuth) int {
+		sigText, err := crypto.PrintSignature(auth.Signature)
+		testutil.AssertNil(t, err)
+
+		post, err := gohttp.NewRequest("POST", server.URL+TEST_COMMAND_ENDPOINT, bytes.NewReader(body))
+		testutil.AssertNil(t, err)
+		post.Header.Set(http.CONTENT_TYPE, http.MIME_PROTO)
+		post.Header.Set(http.AUTH_KEY_HEADER, string(auth.KeyHash))
+		post.Header.Set(http.AUTH_TIMESTAMP_HEADER, fmt.Sprint(auth.Timestamp.Unix()))
+		post.Header.Set(http.AUTH_NONCE_HEADER, auth.Nonce)
+		post.Header.Set(http.AUTH_SIGNATURE_HEADER, string(sigText))
+
+		resp, err := gohttp.DefaultClient.Do(post)
+		testutil.AssertNil(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	auth, err := http.SignRequest(body, allowed, time.Now())
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Expected success", http.WEB_API_SUCCESS, postSigned(auth))
+	testutil.AssertEquals(t, "Expected replay rejected", http.WEB_API_UNAUTHORIZED, postSigned(auth))
+
+	stale, err := http.SignRequest(body, allowed, time.Now().Add(-time.Hour))
+	testutil.AssertNil(t, err)
+	testutil.AssertEquals(t, "Expected stale request rejected", http.WEB_API_UNAUTHORIZED, postSigned(stale))
+}
+
+func respondWith(resp api.Response) <-chan api.Response {
+	respch := make(chan api.Response, 1)
+	respch <- resp
+	return respch
+}
+
 func webApiCall(handler gohttp.Handler, request api.Request) (api.Response, error) {
 	buff := &bytes.Buffer{}
 	err := api.EncodeRequest(request, buff)
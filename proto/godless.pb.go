@@ -17,6 +17,8 @@ It has these top-level messages:
 	APIRequestMessage
 	ReplicateMessage
 	APIResponseMessage
+	CacheStatsMessage
+	NamespaceDiffMessage
 	QueryMessage
 	QueryJoinMessage
 	QueryRowJoinMessage
@@ -24,6 +26,14 @@ It has these top-level messages:
 	QuerySelectMessage
 	QueryWhereMessage
 	QueryPredicateMessage
+	BlobManifestMessage
+	SnapshotManifestMessage
+	SnapshotEntryMessage
+	IndexSummaryMessage
+	TableSummaryMessage
+	GossipHelloMessage
+	GossipAuthMessage
+	GossipMessage
 */
 package proto
 
@@ -199,6 +209,7 @@ type APIRequestMessage struct {
 	Reflection uint32            `protobuf:"varint,2,opt,name=reflection" json:"reflection,omitempty"`
 	Query      *QueryMessage     `protobuf:"bytes,3,opt,name=query" json:"query,omitempty"`
 	Replicate  *ReplicateMessage `protobuf:"bytes,4,opt,name=replicate" json:"replicate,omitempty"`
+	Diff       []string          `protobuf:"bytes,5,rep,name=diff" json:"diff,omitempty"`
 }
 
 func (m *APIRequestMessage) Reset()                    { *m = APIRequestMessage{} }
@@ -234,6 +245,13 @@ func (m *APIRequestMessage) GetReplicate() *ReplicateMessage {
 	return nil
 }
 
+func (m *APIRequestMessage) GetDiff() []string {
+	if m != nil {
+		return m.Diff
+	}
+	return nil
+}
+
 type ReplicateMessage struct {
 	Links []*LinkMessage `protobuf:"bytes,1,rep,name=links" json:"links,omitempty"`
 }
@@ -251,12 +269,18 @@ func (m *ReplicateMessage) GetLinks() []*LinkMessage {
 }
 
 type APIResponseMessage struct {
-	Message   string            `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
-	Error     string            `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
-	Type      uint32            `protobuf:"varint,3,opt,name=type" json:"type,omitempty"`
-	Path      string            `protobuf:"bytes,4,opt,name=path" json:"path,omitempty"`
-	Namespace *NamespaceMessage `protobuf:"bytes,5,opt,name=namespace" json:"namespace,omitempty"`
-	Index     *IndexMessage     `protobuf:"bytes,6,opt,name=index" json:"index,omitempty"`
+	Message      string                `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
+	Error        string                `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	Type         uint32                `protobuf:"varint,3,opt,name=type" json:"type,omitempty"`
+	Path         string                `protobuf:"bytes,4,opt,name=path" json:"path,omitempty"`
+	Namespace    *NamespaceMessage     `protobuf:"bytes,5,opt,name=namespace" json:"namespace,omitempty"`
+	Index        *IndexMessage         `protobuf:"bytes,6,opt,name=index" json:"index,omitempty"`
+	Diff         *NamespaceDiffMessage `protobuf:"bytes,7,opt,name=diff" json:"diff,omitempty"`
+	CacheStats   []*CacheStatsMessage  `protobuf:"bytes,8,rep,name=cacheStats" json:"cacheStats,omitempty"`
+	JournalDepth uint64                `protobuf:"varint,9,opt,name=journalDepth" json:"journalDepth,omitempty"`
+	RequestHash  string                `protobuf:"bytes,10,opt,name=requestHash" json:"requestHash,omitempty"`
+	Head         string                `protobuf:"bytes,11,opt,name=head" json:"head,omitempty"`
+	Signature    string                `protobuf:"bytes,12,opt,name=signature" json:"signature,omitempty"`
 }
 
 func (m *APIResponseMessage) Reset()                    { *m = APIResponseMessage{} }
@@ -306,6 +330,136 @@ func (m *APIResponseMessage) GetIndex() *IndexMessage {
 	return nil
 }
 
+func (m *APIResponseMessage) GetDiff() *NamespaceDiffMessage {
+	if m != nil {
+		return m.Diff
+	}
+	return nil
+}
+
+func (m *APIResponseMessage) GetCacheStats() []*CacheStatsMessage {
+	if m != nil {
+		return m.CacheStats
+	}
+	return nil
+}
+
+func (m *APIResponseMessage) GetJournalDepth() uint64 {
+	if m != nil {
+		return m.JournalDepth
+	}
+	return 0
+}
+
+func (m *APIResponseMessage) GetRequestHash() string {
+	if m != nil {
+		return m.RequestHash
+	}
+	return ""
+}
+
+func (m *APIResponseMessage) GetHead() string {
+	if m != nil {
+		return m.Head
+	}
+	return ""
+}
+
+func (m *APIResponseMessage) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+type CacheStatsMessage struct {
+	Name      string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Hits      uint64 `protobuf:"varint,2,opt,name=hits" json:"hits,omitempty"`
+	Misses    uint64 `protobuf:"varint,3,opt,name=misses" json:"misses,omitempty"`
+	Evictions uint64 `protobuf:"varint,4,opt,name=evictions" json:"evictions,omitempty"`
+	Items     int64  `protobuf:"varint,5,opt,name=items" json:"items,omitempty"`
+	Bytes     int64  `protobuf:"varint,6,opt,name=bytes" json:"bytes,omitempty"`
+	MaxBytes  int64  `protobuf:"varint,7,opt,name=maxBytes" json:"maxBytes,omitempty"`
+}
+
+func (m *CacheStatsMessage) Reset()                    { *m = CacheStatsMessage{} }
+func (m *CacheStatsMessage) String() string            { return proto1.CompactTextString(m) }
+func (*CacheStatsMessage) ProtoMessage()               {}
+func (*CacheStatsMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *CacheStatsMessage) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CacheStatsMessage) GetHits() uint64 {
+	if m != nil {
+		return m.Hits
+	}
+	return 0
+}
+
+func (m *CacheStatsMessage) GetMisses() uint64 {
+	if m != nil {
+		return m.Misses
+	}
+	return 0
+}
+
+func (m *CacheStatsMessage) GetEvictions() uint64 {
+	if m != nil {
+		return m.Evictions
+	}
+	return 0
+}
+
+func (m *CacheStatsMessage) GetItems() int64 {
+	if m != nil {
+		return m.Items
+	}
+	return 0
+}
+
+func (m *CacheStatsMessage) GetBytes() int64 {
+	if m != nil {
+		return m.Bytes
+	}
+	return 0
+}
+
+func (m *CacheStatsMessage) GetMaxBytes() int64 {
+	if m != nil {
+		return m.MaxBytes
+	}
+	return 0
+}
+
+type NamespaceDiffMessage struct {
+	Added   *NamespaceMessage `protobuf:"bytes,1,opt,name=added" json:"added,omitempty"`
+	Removed *NamespaceMessage `protobuf:"bytes,2,opt,name=removed" json:"removed,omitempty"`
+}
+
+func (m *NamespaceDiffMessage) Reset()                    { *m = NamespaceDiffMessage{} }
+func (m *NamespaceDiffMessage) String() string            { return proto1.CompactTextString(m) }
+func (*NamespaceDiffMessage) ProtoMessage()               {}
+func (*NamespaceDiffMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *NamespaceDiffMessage) GetAdded() *NamespaceMessage {
+	if m != nil {
+		return m.Added
+	}
+	return nil
+}
+
+func (m *NamespaceDiffMessage) GetRemoved() *NamespaceMessage {
+	if m != nil {
+		return m.Removed
+	}
+	return nil
+}
+
 type QueryMessage struct {
 	OpCode    uint32              `protobuf:"varint,1,opt,name=opCode" json:"opCode,omitempty"`
 	Table     string              `protobuf:"bytes,2,opt,name=table" json:"table,omitempty"`
@@ -317,7 +471,7 @@ type QueryMessage struct {
 func (m *QueryMessage) Reset()                    { *m = QueryMessage{} }
 func (m *QueryMessage) String() string            { return proto1.CompactTextString(m) }
 func (*QueryMessage) ProtoMessage()               {}
-func (*QueryMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*QueryMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 func (m *QueryMessage) GetOpCode() uint32 {
 	if m != nil {
@@ -355,13 +509,14 @@ func (m *QueryMessage) GetKeyHashes() []string {
 }
 
 type QueryJoinMessage struct {
-	Rows []*QueryRowJoinMessage `protobuf:"bytes,1,rep,name=rows" json:"rows,omitempty"`
+	Rows       []*QueryRowJoinMessage `protobuf:"bytes,1,rep,name=rows" json:"rows,omitempty"`
+	Recipients []string               `protobuf:"bytes,2,rep,name=recipients" json:"recipients,omitempty"`
 }
 
 func (m *QueryJoinMessage) Reset()                    { *m = QueryJoinMessage{} }
 func (m *QueryJoinMessage) String() string            { return proto1.CompactTextString(m) }
 func (*QueryJoinMessage) ProtoMessage()               {}
-func (*QueryJoinMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*QueryJoinMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
 func (m *QueryJoinMessage) GetRows() []*QueryRowJoinMessage {
 	if m != nil {
@@ -370,6 +525,13 @@ func (m *QueryJoinMessage) GetRows() []*QueryRowJoinMessage {
 	return nil
 }
 
+func (m *QueryJoinMessage) GetRecipients() []string {
+	if m != nil {
+		return m.Recipients
+	}
+	return nil
+}
+
 type QueryRowJoinMessage struct {
 	Row     string                      `protobuf:"bytes,1,opt,name=row" json:"row,omitempty"`
 	Entries []*QueryRowJoinEntryMessage `protobuf:"bytes,2,rep,name=entries" json:"entries,omitempty"`
@@ -378,7 +540,7 @@ type QueryRowJoinMessage struct {
 func (m *QueryRowJoinMessage) Reset()                    { *m = QueryRowJoinMessage{} }
 func (m *QueryRowJoinMessage) String() string            { return proto1.CompactTextString(m) }
 func (*QueryRowJoinMessage) ProtoMessage()               {}
-func (*QueryRowJoinMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*QueryRowJoinMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 func (m *QueryRowJoinMessage) GetRow() string {
 	if m != nil {
@@ -402,7 +564,7 @@ type QueryRowJoinEntryMessage struct {
 func (m *QueryRowJoinEntryMessage) Reset()                    { *m = QueryRowJoinEntryMessage{} }
 func (m *QueryRowJoinEntryMessage) String() string            { return proto1.CompactTextString(m) }
 func (*QueryRowJoinEntryMessage) ProtoMessage()               {}
-func (*QueryRowJoinEntryMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*QueryRowJoinEntryMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
 
 func (m *QueryRowJoinEntryMessage) GetEntry() string {
 	if m != nil {
@@ -419,14 +581,15 @@ func (m *QueryRowJoinEntryMessage) GetPoint() string {
 }
 
 type QuerySelectMessage struct {
-	Limit uint32             `protobuf:"varint,1,opt,name=limit" json:"limit,omitempty"`
-	Where *QueryWhereMessage `protobuf:"bytes,2,opt,name=where" json:"where,omitempty"`
+	Limit        uint32             `protobuf:"varint,1,opt,name=limit" json:"limit,omitempty"`
+	Where        *QueryWhereMessage `protobuf:"bytes,2,opt,name=where" json:"where,omitempty"`
+	KeyThreshold uint32             `protobuf:"varint,3,opt,name=keyThreshold" json:"keyThreshold,omitempty"`
 }
 
 func (m *QuerySelectMessage) Reset()                    { *m = QuerySelectMessage{} }
 func (m *QuerySelectMessage) String() string            { return proto1.CompactTextString(m) }
 func (*QuerySelectMessage) ProtoMessage()               {}
-func (*QuerySelectMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*QuerySelectMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
 
 func (m *QuerySelectMessage) GetLimit() uint32 {
 	if m != nil {
@@ -442,6 +605,13 @@ func (m *QuerySelectMessage) GetWhere() *QueryWhereMessage {
 	return nil
 }
 
+func (m *QuerySelectMessage) GetKeyThreshold() uint32 {
+	if m != nil {
+		return m.KeyThreshold
+	}
+	return 0
+}
+
 type QueryWhereMessage struct {
 	OpCode    uint32                 `protobuf:"varint,1,opt,name=opCode" json:"opCode,omitempty"`
 	Predicate *QueryPredicateMessage `protobuf:"bytes,2,opt,name=predicate" json:"predicate,omitempty"`
@@ -451,7 +621,7 @@ type QueryWhereMessage struct {
 func (m *QueryWhereMessage) Reset()                    { *m = QueryWhereMessage{} }
 func (m *QueryWhereMessage) String() string            { return proto1.CompactTextString(m) }
 func (*QueryWhereMessage) ProtoMessage()               {}
-func (*QueryWhereMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*QueryWhereMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
 func (m *QueryWhereMessage) GetOpCode() uint32 {
 	if m != nil {
@@ -484,7 +654,7 @@ type QueryPredicateMessage struct {
 func (m *QueryPredicateMessage) Reset()                    { *m = QueryPredicateMessage{} }
 func (m *QueryPredicateMessage) String() string            { return proto1.CompactTextString(m) }
 func (*QueryPredicateMessage) ProtoMessage()               {}
-func (*QueryPredicateMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*QueryPredicateMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 func (m *QueryPredicateMessage) GetOpCode() uint32 {
 	if m != nil {
@@ -514,6 +684,262 @@ func (m *QueryPredicateMessage) GetUserow() bool {
 	return false
 }
 
+type BlobManifestMessage struct {
+	Size   uint64   `protobuf:"varint,1,opt,name=size" json:"size,omitempty"`
+	Chunks []string `protobuf:"bytes,2,rep,name=chunks" json:"chunks,omitempty"`
+}
+
+func (m *BlobManifestMessage) Reset()                    { *m = BlobManifestMessage{} }
+func (m *BlobManifestMessage) String() string            { return proto1.CompactTextString(m) }
+func (*BlobManifestMessage) ProtoMessage()               {}
+func (*BlobManifestMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *BlobManifestMessage) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *BlobManifestMessage) GetChunks() []string {
+	if m != nil {
+		return m.Chunks
+	}
+	return nil
+}
+
+type SnapshotManifestMessage struct {
+	Version    uint32                  `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Head       string                  `protobuf:"bytes,2,opt,name=head" json:"head,omitempty"`
+	Entries    []*SnapshotEntryMessage `protobuf:"bytes,3,rep,name=entries" json:"entries,omitempty"`
+	Signatures []string                `protobuf:"bytes,4,rep,name=signatures" json:"signatures,omitempty"`
+}
+
+func (m *SnapshotManifestMessage) Reset()                    { *m = SnapshotManifestMessage{} }
+func (m *SnapshotManifestMessage) String() string            { return proto1.CompactTextString(m) }
+func (*SnapshotManifestMessage) ProtoMessage()               {}
+func (*SnapshotManifestMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *SnapshotManifestMessage) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *SnapshotManifestMessage) GetHead() string {
+	if m != nil {
+		return m.Head
+	}
+	return ""
+}
+
+func (m *SnapshotManifestMessage) GetEntries() []*SnapshotEntryMessage {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *SnapshotManifestMessage) GetSignatures() []string {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+type SnapshotEntryMessage struct {
+	Type   uint32 `protobuf:"varint,1,opt,name=type" json:"type,omitempty"`
+	Path   string `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
+	Digest string `protobuf:"bytes,3,opt,name=digest" json:"digest,omitempty"`
+}
+
+func (m *SnapshotEntryMessage) Reset()                    { *m = SnapshotEntryMessage{} }
+func (m *SnapshotEntryMessage) String() string            { return proto1.CompactTextString(m) }
+func (*SnapshotEntryMessage) ProtoMessage()               {}
+func (*SnapshotEntryMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *SnapshotEntryMessage) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *SnapshotEntryMessage) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *SnapshotEntryMessage) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+type IndexSummaryMessage struct {
+	Head   *LinkMessage           `protobuf:"bytes,1,opt,name=head" json:"head,omitempty"`
+	Root   string                 `protobuf:"bytes,2,opt,name=root" json:"root,omitempty"`
+	Tables []*TableSummaryMessage `protobuf:"bytes,3,rep,name=tables" json:"tables,omitempty"`
+}
+
+func (m *IndexSummaryMessage) Reset()                    { *m = IndexSummaryMessage{} }
+func (m *IndexSummaryMessage) String() string            { return proto1.CompactTextString(m) }
+func (*IndexSummaryMessage) ProtoMessage()               {}
+func (*IndexSummaryMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *IndexSummaryMessage) GetHead() *LinkMessage {
+	if m != nil {
+		return m.Head
+	}
+	return nil
+}
+
+func (m *IndexSummaryMessage) GetRoot() string {
+	if m != nil {
+		return m.Root
+	}
+	return ""
+}
+
+func (m *IndexSummaryMessage) GetTables() []*TableSummaryMessage {
+	if m != nil {
+		return m.Tables
+	}
+	return nil
+}
+
+type TableSummaryMessage struct {
+	Table string       `protobuf:"bytes,1,opt,name=table" json:"table,omitempty"`
+	Hash  string       `protobuf:"bytes,2,opt,name=hash" json:"hash,omitempty"`
+	Link  *LinkMessage `protobuf:"bytes,3,opt,name=link" json:"link,omitempty"`
+}
+
+func (m *TableSummaryMessage) Reset()                    { *m = TableSummaryMessage{} }
+func (m *TableSummaryMessage) String() string            { return proto1.CompactTextString(m) }
+func (*TableSummaryMessage) ProtoMessage()               {}
+func (*TableSummaryMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *TableSummaryMessage) GetTable() string {
+	if m != nil {
+		return m.Table
+	}
+	return ""
+}
+
+func (m *TableSummaryMessage) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *TableSummaryMessage) GetLink() *LinkMessage {
+	if m != nil {
+		return m.Link
+	}
+	return nil
+}
+
+type GossipHelloMessage struct {
+	PublicKey   string `protobuf:"bytes,1,opt,name=publicKey" json:"publicKey,omitempty"`
+	ExchangeKey []byte `protobuf:"bytes,2,opt,name=exchangeKey" json:"exchangeKey,omitempty"`
+}
+
+func (m *GossipHelloMessage) Reset()                    { *m = GossipHelloMessage{} }
+func (m *GossipHelloMessage) String() string            { return proto1.CompactTextString(m) }
+func (*GossipHelloMessage) ProtoMessage()               {}
+func (*GossipHelloMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *GossipHelloMessage) GetPublicKey() string {
+	if m != nil {
+		return m.PublicKey
+	}
+	return ""
+}
+
+func (m *GossipHelloMessage) GetExchangeKey() []byte {
+	if m != nil {
+		return m.ExchangeKey
+	}
+	return nil
+}
+
+type GossipAuthMessage struct {
+	Signature string `protobuf:"bytes,1,opt,name=signature" json:"signature,omitempty"`
+}
+
+func (m *GossipAuthMessage) Reset()                    { *m = GossipAuthMessage{} }
+func (m *GossipAuthMessage) String() string            { return proto1.CompactTextString(m) }
+func (*GossipAuthMessage) ProtoMessage()               {}
+func (*GossipAuthMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *GossipAuthMessage) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+type GossipMessage struct {
+	Type    uint32 `protobuf:"varint,1,opt,name=type" json:"type,omitempty"`
+	Id      string `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+	Topic   string `protobuf:"bytes,3,opt,name=topic" json:"topic,omitempty"`
+	Data    []byte `protobuf:"bytes,4,opt,name=data" json:"data,omitempty"`
+	Hash    string `protobuf:"bytes,5,opt,name=hash" json:"hash,omitempty"`
+	Request uint64 `protobuf:"varint,6,opt,name=request" json:"request,omitempty"`
+}
+
+func (m *GossipMessage) Reset()                    { *m = GossipMessage{} }
+func (m *GossipMessage) String() string            { return proto1.CompactTextString(m) }
+func (*GossipMessage) ProtoMessage()               {}
+func (*GossipMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *GossipMessage) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *GossipMessage) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *GossipMessage) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *GossipMessage) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *GossipMessage) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *GossipMessage) GetRequest() uint64 {
+	if m != nil {
+		return m.Request
+	}
+	return 0
+}
+
 func init() {
 	proto1.RegisterType((*NamespaceMessage)(nil), "proto.NamespaceMessage")
 	proto1.RegisterType((*NamespaceEntryMessage)(nil), "proto.NamespaceEntryMessage")
@@ -524,6 +950,8 @@ func init() {
 	proto1.RegisterType((*APIRequestMessage)(nil), "proto.APIRequestMessage")
 	proto1.RegisterType((*ReplicateMessage)(nil), "proto.ReplicateMessage")
 	proto1.RegisterType((*APIResponseMessage)(nil), "proto.APIResponseMessage")
+	proto1.RegisterType((*CacheStatsMessage)(nil), "proto.CacheStatsMessage")
+	proto1.RegisterType((*NamespaceDiffMessage)(nil), "proto.NamespaceDiffMessage")
 	proto1.RegisterType((*QueryMessage)(nil), "proto.QueryMessage")
 	proto1.RegisterType((*QueryJoinMessage)(nil), "proto.QueryJoinMessage")
 	proto1.RegisterType((*QueryRowJoinMessage)(nil), "proto.QueryRowJoinMessage")
@@ -531,54 +959,95 @@ func init() {
 	proto1.RegisterType((*QuerySelectMessage)(nil), "proto.QuerySelectMessage")
 	proto1.RegisterType((*QueryWhereMessage)(nil), "proto.QueryWhereMessage")
 	proto1.RegisterType((*QueryPredicateMessage)(nil), "proto.QueryPredicateMessage")
+	proto1.RegisterType((*BlobManifestMessage)(nil), "proto.BlobManifestMessage")
+	proto1.RegisterType((*SnapshotManifestMessage)(nil), "proto.SnapshotManifestMessage")
+	proto1.RegisterType((*SnapshotEntryMessage)(nil), "proto.SnapshotEntryMessage")
+	proto1.RegisterType((*IndexSummaryMessage)(nil), "proto.IndexSummaryMessage")
+	proto1.RegisterType((*TableSummaryMessage)(nil), "proto.TableSummaryMessage")
+	proto1.RegisterType((*GossipHelloMessage)(nil), "proto.GossipHelloMessage")
+	proto1.RegisterType((*GossipAuthMessage)(nil), "proto.GossipAuthMessage")
+	proto1.RegisterType((*GossipMessage)(nil), "proto.GossipMessage")
 }
 
 func init() { proto1.RegisterFile("godless.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 702 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0x96, 0x63, 0x3b, 0xad, 0xa7, 0xad, 0x94, 0x6e, 0x5b, 0x6a, 0xaa, 0x0a, 0x22, 0x9f, 0x82,
-	0x90, 0x22, 0x51, 0x04, 0x12, 0x88, 0x03, 0x2d, 0x02, 0xd1, 0x0a, 0x50, 0x59, 0x0e, 0x48, 0x70,
-	0x72, 0x93, 0xa1, 0x35, 0x71, 0xbc, 0xae, 0x77, 0xa3, 0x34, 0x37, 0xde, 0x83, 0x27, 0xe0, 0x15,
-	0x78, 0x08, 0x9e, 0x09, 0xed, 0x9f, 0xbd, 0xf9, 0x3b, 0x79, 0x67, 0xe6, 0xf3, 0xcc, 0xec, 0xf7,
-	0xcd, 0x0e, 0xec, 0x5c, 0xb3, 0x61, 0x8e, 0x9c, 0xf7, 0xcb, 0x8a, 0x09, 0x46, 0x42, 0xf5, 0x49,
-	0x2e, 0xa0, 0xf3, 0x29, 0x1d, 0x23, 0x2f, 0xd3, 0x01, 0x7e, 0x44, 0xce, 0xd3, 0x6b, 0x24, 0xcf,
-	0x61, 0x03, 0x0b, 0x51, 0x65, 0xc8, 0x63, 0xaf, 0xeb, 0xf7, 0xb6, 0x4e, 0x8e, 0xf5, 0x3f, 0xfd,
-	0x1a, 0xf9, 0xb6, 0x10, 0xd5, 0xcc, 0xc0, 0xa9, 0x05, 0x27, 0xbf, 0x3c, 0x38, 0x58, 0x09, 0x21,
-	0xfb, 0x10, 0x8a, 0xf4, 0x2a, 0xc7, 0xd8, 0xeb, 0x7a, 0xbd, 0x88, 0x6a, 0x83, 0x74, 0xc0, 0xaf,
-	0xd8, 0x34, 0x6e, 0x29, 0x9f, 0x3c, 0x4a, 0x9c, 0x4c, 0x36, 0x8b, 0x7d, 0x8d, 0x53, 0x06, 0x79,
-	0x04, 0x61, 0xc9, 0xb2, 0x42, 0xc4, 0x41, 0xd7, 0xeb, 0x6d, 0x9d, 0xec, 0x99, 0x6e, 0x2e, 0xa5,
-	0xcf, 0x36, 0xa1, 0x11, 0xc9, 0x6b, 0xd8, 0x76, 0xdd, 0x84, 0x40, 0x20, 0xf0, 0x4e, 0x98, 0xba,
-	0xea, 0x4c, 0x8e, 0x21, 0xe2, 0xd9, 0x75, 0x91, 0x8a, 0x49, 0x85, 0xa6, 0x78, 0xe3, 0x48, 0xce,
-	0x60, 0xfb, 0xbc, 0x18, 0xe2, 0x9d, 0xcd, 0x70, 0xb2, 0x48, 0x46, 0x6c, 0xca, 0x2b, 0xd4, 0x6a,
-	0x22, 0xbe, 0xc3, 0xee, 0x52, 0x74, 0x0d, 0x07, 0x04, 0x82, 0x3c, 0x2b, 0x46, 0xa6, 0x0f, 0x75,
-	0x9e, 0x6f, 0xd0, 0x5f, 0x6c, 0xf0, 0x14, 0xb6, 0x3e, 0x64, 0xc5, 0xc8, 0xb9, 0xa1, 0x4a, 0xe0,
-	0x39, 0x09, 0x1e, 0x00, 0xd4, 0x78, 0x1e, 0xb7, 0xba, 0x7e, 0x2f, 0xa2, 0x8e, 0x27, 0xf9, 0xe3,
-	0xc1, 0xee, 0xe9, 0xe5, 0x39, 0xc5, 0xdb, 0x09, 0xf2, 0x39, 0xae, 0x66, 0xa5, 0xee, 0x6f, 0x87,
-	0xaa, 0xb3, 0xcc, 0x54, 0xe1, 0x8f, 0x1c, 0x07, 0x22, 0x63, 0x85, 0x6a, 0x72, 0x87, 0x3a, 0x1e,
-	0x29, 0xcd, 0xed, 0x04, 0x8d, 0x60, 0x8d, 0x34, 0x9f, 0xa5, 0xaf, 0x96, 0x46, 0x21, 0xc8, 0x33,
-	0x88, 0x2a, 0x2c, 0xf3, 0x6c, 0x90, 0x0a, 0x34, 0x4a, 0x1e, 0x1a, 0x38, 0xb5, 0x7e, 0xfb, 0x4b,
-	0x83, 0x4c, 0x5e, 0x41, 0x67, 0x31, 0x4c, 0x7a, 0x10, 0xca, 0x7b, 0x5a, 0x45, 0x88, 0x49, 0xe3,
-	0xd0, 0x42, 0x35, 0x20, 0xf9, 0xe7, 0x01, 0x51, 0x37, 0xe5, 0x25, 0x2b, 0x78, 0x9d, 0x20, 0x86,
-	0x8d, 0xb1, 0x3e, 0x1a, 0xde, 0xac, 0xa9, 0x26, 0xb0, 0xaa, 0x58, 0x65, 0x04, 0xd1, 0x46, 0x4d,
-	0x8d, 0xef, 0x50, 0x43, 0x20, 0x28, 0x53, 0x71, 0xa3, 0xae, 0x12, 0x51, 0x75, 0x96, 0x77, 0x2c,
-	0xec, 0x03, 0x88, 0xc3, 0xb9, 0x3b, 0x2e, 0xbe, 0x32, 0xda, 0x20, 0x25, 0x8b, 0x99, 0x9c, 0x97,
-	0xb8, 0x3d, 0xc7, 0xa2, 0x3b, 0x87, 0x54, 0x23, 0x92, 0xbf, 0x1e, 0x6c, 0xbb, 0xec, 0x92, 0x7b,
-	0xd0, 0x66, 0xe5, 0x1b, 0x36, 0xb4, 0xba, 0x19, 0xab, 0x19, 0xb7, 0x96, 0x3b, 0x6e, 0x8f, 0x21,
-	0xf8, 0xc9, 0xb2, 0xc2, 0xc8, 0x75, 0xe8, 0xca, 0x75, 0xc1, 0xb2, 0xc2, 0x16, 0x53, 0x20, 0xf2,
-	0x04, 0xda, 0x1c, 0xa5, 0xd2, 0x46, 0xae, 0xfb, 0x2e, 0xfc, 0x8b, 0x8a, 0xd8, 0x1f, 0x0c, 0x50,
-	0x8e, 0xee, 0x08, 0x67, 0xef, 0x53, 0x7e, 0x83, 0x3c, 0x0e, 0xd5, 0xe0, 0x35, 0x8e, 0xe4, 0x0c,
-	0x3a, 0x8b, 0xa5, 0x48, 0x1f, 0x82, 0x8a, 0x4d, 0xad, 0x94, 0x47, 0x6e, 0x09, 0xca, 0xa6, 0x73,
-	0x4d, 0x49, 0x5c, 0x72, 0x05, 0x7b, 0x2b, 0x82, 0x76, 0x97, 0x78, 0xcd, 0x2e, 0x79, 0xd1, 0x3c,
-	0xdc, 0x96, 0xca, 0xfd, 0x70, 0x45, 0xee, 0xd5, 0xef, 0xf7, 0x1d, 0xc4, 0xeb, 0x40, 0xcd, 0x8a,
-	0xf2, 0xdc, 0x15, 0xb5, 0x6f, 0x57, 0x94, 0x61, 0x5b, 0x6f, 0xa3, 0x6f, 0x40, 0x96, 0xb9, 0x92,
-	0xd8, 0x3c, 0x1b, 0x67, 0xc2, 0x08, 0xa6, 0x0d, 0xd2, 0x87, 0x70, 0x7a, 0x83, 0x66, 0x23, 0x35,
-	0x5b, 0x46, 0xfd, 0xff, 0x55, 0x06, 0xea, 0x41, 0x50, 0xb0, 0xe4, 0xb7, 0x07, 0xbb, 0x4b, 0xc1,
-	0xb5, 0xd3, 0xf0, 0x12, 0xa2, 0xb2, 0xc2, 0xa1, 0x7e, 0x7c, 0xba, 0xc2, 0xb1, 0x5b, 0xe1, 0xd2,
-	0x06, 0xeb, 0xe9, 0xac, 0xe1, 0x72, 0x03, 0x0e, 0xf2, 0x74, 0xc2, 0x91, 0xc7, 0xfe, 0xdc, 0x06,
-	0x5c, 0xee, 0xcd, 0x02, 0x93, 0x29, 0x1c, 0xac, 0xcc, 0xbb, 0xb6, 0x41, 0x02, 0xc1, 0x08, 0x67,
-	0x76, 0x59, 0xa9, 0x33, 0x39, 0x82, 0xcd, 0x3c, 0x13, 0x58, 0xa5, 0xb9, 0xae, 0x1c, 0xd1, 0xda,
-	0x96, 0x79, 0x26, 0x1c, 0xa5, 0xe4, 0x72, 0x36, 0x37, 0xa9, 0xb1, 0xae, 0xda, 0xaa, 0xb5, 0xa7,
-	0xff, 0x03, 0x00, 0x00, 0xff, 0xff, 0xa6, 0xc9, 0x83, 0x0d, 0xee, 0x06, 0x00, 0x00,
+	// 1219 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x56, 0x4f, 0x6f, 0x1b, 0x45,
+	0x14, 0xd7, 0x7a, 0xd7, 0x76, 0xfd, 0xe2, 0xa0, 0x64, 0x92, 0xb6, 0x4b, 0x89, 0xc0, 0xda, 0x43,
+	0x65, 0x84, 0x08, 0x6a, 0x50, 0x11, 0x20, 0x0e, 0xa4, 0x2d, 0xd0, 0x16, 0x8a, 0xc2, 0x24, 0x82,
+	0x03, 0xa7, 0xb5, 0xf7, 0xd9, 0x3b, 0xf5, 0x7a, 0x77, 0xbb, 0x33, 0x4e, 0x62, 0x0e, 0x88, 0x0b,
+	0x27, 0x3e, 0x02, 0x12, 0xdf, 0x81, 0x23, 0x48, 0x7c, 0x37, 0x34, 0xff, 0xf6, 0x8f, 0xb3, 0xce,
+	0x69, 0xdf, 0x7b, 0xf3, 0x9b, 0xf7, 0xde, 0xbc, 0xbf, 0x0b, 0xbb, 0xf3, 0x2c, 0x4a, 0x90, 0xf3,
+	0xe3, 0xbc, 0xc8, 0x44, 0x46, 0xba, 0xea, 0x13, 0xbc, 0x84, 0xbd, 0xef, 0xc3, 0x25, 0xf2, 0x3c,
+	0x9c, 0xe2, 0x2b, 0xe4, 0x3c, 0x9c, 0x23, 0xf9, 0x04, 0xfa, 0x98, 0x8a, 0x82, 0x21, 0xf7, 0x9d,
+	0x91, 0x3b, 0xde, 0x39, 0x39, 0xd2, 0x77, 0x8e, 0x4b, 0xe4, 0x57, 0xa9, 0x28, 0xd6, 0x06, 0x4e,
+	0x2d, 0x38, 0xf8, 0xcd, 0x81, 0xbb, 0xad, 0x10, 0x72, 0x08, 0x5d, 0x11, 0x4e, 0x12, 0xf4, 0x9d,
+	0x91, 0x33, 0x1e, 0x50, 0xcd, 0x90, 0x3d, 0x70, 0x8b, 0xec, 0xca, 0xef, 0x28, 0x99, 0x24, 0x25,
+	0x4e, 0x2a, 0x5b, 0xfb, 0xae, 0xc6, 0x29, 0x86, 0xbc, 0x0f, 0xdd, 0x3c, 0x63, 0xa9, 0xf0, 0xbd,
+	0x91, 0x33, 0xde, 0x39, 0x39, 0x30, 0xde, 0x9c, 0x49, 0x99, 0x75, 0x42, 0x23, 0x82, 0x2f, 0x61,
+	0x58, 0x17, 0x13, 0x02, 0x9e, 0xc0, 0x6b, 0x61, 0xec, 0x2a, 0x9a, 0x1c, 0xc1, 0x80, 0xb3, 0x79,
+	0x1a, 0x8a, 0x55, 0x81, 0xc6, 0x78, 0x25, 0x08, 0x9e, 0xc0, 0xf0, 0x45, 0x1a, 0xe1, 0xb5, 0xd5,
+	0x70, 0xb2, 0x19, 0x0c, 0xdf, 0x98, 0x57, 0xa8, 0xf6, 0x40, 0xfc, 0x0c, 0xfb, 0x37, 0x4e, 0xb7,
+	0xc4, 0x80, 0x80, 0x97, 0xb0, 0x74, 0x61, 0xfc, 0x50, 0x74, 0xd3, 0x41, 0x77, 0xd3, 0xc1, 0x53,
+	0xd8, 0xf9, 0x8e, 0xa5, 0x8b, 0xda, 0x0b, 0x95, 0x02, 0xa7, 0xa6, 0xe0, 0x5d, 0x80, 0x12, 0xcf,
+	0xfd, 0xce, 0xc8, 0x1d, 0x0f, 0x68, 0x4d, 0x12, 0xfc, 0xe7, 0xc0, 0xfe, 0xe9, 0xd9, 0x0b, 0x8a,
+	0x6f, 0x56, 0xc8, 0x1b, 0xb1, 0x5a, 0xe7, 0xda, 0xbf, 0x5d, 0xaa, 0x68, 0xa9, 0xa9, 0xc0, 0x59,
+	0x82, 0x53, 0xc1, 0xb2, 0x54, 0x39, 0xb9, 0x4b, 0x6b, 0x12, 0x99, 0x9a, 0x37, 0x2b, 0x34, 0x09,
+	0xab, 0x52, 0xf3, 0x83, 0x94, 0x95, 0xa9, 0x51, 0x08, 0xf2, 0x18, 0x06, 0x05, 0xe6, 0x09, 0x9b,
+	0x86, 0x02, 0x4d, 0x26, 0xef, 0x1b, 0x38, 0xb5, 0x72, 0x7b, 0xa5, 0x42, 0x4a, 0xaf, 0x22, 0x36,
+	0x9b, 0xf9, 0x5d, 0xf5, 0x0a, 0x45, 0x07, 0x5f, 0xc0, 0xde, 0xe6, 0x15, 0x32, 0x86, 0xae, 0x7c,
+	0xbb, 0xcd, 0x12, 0x31, 0xaa, 0x6b, 0xa1, 0xa2, 0x1a, 0x10, 0xfc, 0xed, 0x02, 0x51, 0xaf, 0xe7,
+	0x79, 0x96, 0xf2, 0x52, 0x81, 0x0f, 0xfd, 0xa5, 0x26, 0x4d, 0x2c, 0xfb, 0xcb, 0x2a, 0x73, 0x58,
+	0x14, 0x59, 0x61, 0x92, 0xa4, 0x99, 0x32, 0x5c, 0x6e, 0x2d, 0x5c, 0x04, 0xbc, 0x3c, 0x14, 0xb1,
+	0x7a, 0xde, 0x80, 0x2a, 0x5a, 0xbe, 0x3b, 0xb5, 0x4d, 0xe1, 0x77, 0x1b, 0xef, 0xde, 0xec, 0x3c,
+	0x5a, 0x21, 0x65, 0x64, 0x99, 0xac, 0x21, 0xbf, 0xd7, 0x88, 0x6c, 0xbd, 0x36, 0xa9, 0x46, 0x90,
+	0x8f, 0x4c, 0x88, 0xfa, 0x0a, 0xf9, 0xce, 0xa6, 0xf2, 0x67, 0x6c, 0x36, 0xb3, 0x37, 0x14, 0x90,
+	0x7c, 0x0a, 0x30, 0x0d, 0xa7, 0x31, 0x9e, 0x8b, 0x50, 0x70, 0xff, 0x4e, 0xa3, 0xac, 0x9f, 0x96,
+	0x07, 0xf6, 0x4e, 0x0d, 0x4b, 0x02, 0x18, 0xbe, 0xce, 0x56, 0x45, 0x1a, 0x26, 0xcf, 0x30, 0x17,
+	0xb1, 0x3f, 0x18, 0x39, 0x63, 0x8f, 0x36, 0x64, 0x64, 0x04, 0x3b, 0x85, 0xae, 0xac, 0xe7, 0x21,
+	0x8f, 0x7d, 0x50, 0xb1, 0xa8, 0x8b, 0x64, 0x98, 0x62, 0x0c, 0x23, 0x7f, 0x47, 0x87, 0x49, 0xd2,
+	0xcd, 0xa2, 0x1f, 0x6e, 0x16, 0xfd, 0x3f, 0x0e, 0xec, 0xdf, 0xf0, 0x4c, 0xea, 0x91, 0x01, 0xb3,
+	0xb5, 0x2f, 0x69, 0xa5, 0x9b, 0x09, 0xae, 0x72, 0xe5, 0x51, 0x45, 0x93, 0x7b, 0xd0, 0x5b, 0x32,
+	0xce, 0x91, 0xab, 0x64, 0x79, 0xd4, 0x70, 0xd2, 0x26, 0x5e, 0x32, 0x55, 0xc9, 0x5c, 0xe5, 0xcc,
+	0xa3, 0x95, 0x40, 0xa6, 0x9d, 0x09, 0x5c, 0x72, 0x95, 0x34, 0x97, 0x6a, 0x46, 0x4a, 0x27, 0x6b,
+	0x81, 0x5c, 0xe5, 0xc5, 0xa5, 0x9a, 0x21, 0x0f, 0xe0, 0xce, 0x32, 0xbc, 0x7e, 0xa2, 0x0e, 0xfa,
+	0xea, 0xa0, 0xe4, 0x83, 0x6b, 0x38, 0x6c, 0xcb, 0x05, 0xf9, 0x10, 0xba, 0x61, 0x14, 0x61, 0xe4,
+	0x3b, 0xb7, 0x17, 0x85, 0x46, 0x91, 0x47, 0xd0, 0x2f, 0x70, 0x99, 0x5d, 0x62, 0xe4, 0x77, 0x6e,
+	0xbf, 0x60, 0x71, 0xc1, 0xbf, 0x0e, 0x0c, 0xeb, 0xad, 0x28, 0x03, 0x91, 0xe5, 0x4f, 0xb3, 0xc8,
+	0x36, 0xb9, 0xe1, 0xaa, 0xd9, 0xd4, 0xa9, 0xcf, 0xa6, 0x0f, 0xc0, 0x7b, 0x9d, 0xb1, 0xd4, 0x77,
+	0x1b, 0xe6, 0x94, 0xc2, 0x97, 0x19, 0x4b, 0xcb, 0x9a, 0x92, 0x20, 0xf2, 0x08, 0x7a, 0x1c, 0xe5,
+	0x58, 0x30, 0xbd, 0xfd, 0x76, 0x1d, 0x7e, 0xae, 0x4e, 0xec, 0x05, 0x03, 0x94, 0xe1, 0x5f, 0xe0,
+	0x5a, 0x56, 0x04, 0x72, 0xd3, 0xdf, 0x95, 0x20, 0x98, 0xc0, 0xde, 0xa6, 0x29, 0x72, 0x0c, 0x5e,
+	0x91, 0x5d, 0xd9, 0x1e, 0x7f, 0x50, 0x37, 0x41, 0xb3, 0xab, 0x86, 0x53, 0x12, 0xa7, 0xc7, 0xd7,
+	0x94, 0xe5, 0x0c, 0x53, 0x51, 0x0e, 0xc2, 0x4a, 0x12, 0x4c, 0xe0, 0xa0, 0xe5, 0xb2, 0x5d, 0x4c,
+	0x4e, 0xb5, 0x98, 0x3e, 0xab, 0xb6, 0x40, 0x47, 0xd9, 0x7e, 0xaf, 0xc5, 0x76, 0xfb, 0x32, 0xf8,
+	0x1a, 0xfc, 0x6d, 0xa0, 0x6a, 0xdf, 0x39, 0xf5, 0x7d, 0x77, 0x68, 0xf7, 0x9d, 0xc9, 0x86, 0x62,
+	0x82, 0x5f, 0x81, 0xdc, 0x8c, 0xa5, 0xc4, 0x26, 0x6c, 0xc9, 0x84, 0x49, 0xa8, 0x66, 0xc8, 0x31,
+	0x74, 0xaf, 0x62, 0x34, 0xeb, 0xad, 0xea, 0x6d, 0x75, 0xff, 0x27, 0x79, 0x50, 0xd6, 0x96, 0x82,
+	0xc9, 0xb6, 0x5e, 0xe0, 0xfa, 0x22, 0x2e, 0x90, 0xc7, 0x59, 0x12, 0x99, 0x99, 0xd6, 0x90, 0x05,
+	0x7f, 0x3a, 0xb0, 0x7f, 0x43, 0xc1, 0xd6, 0x8a, 0xfa, 0x1c, 0x06, 0x79, 0x81, 0x91, 0x9e, 0xf6,
+	0xda, 0x8b, 0xa3, 0xba, 0x17, 0x67, 0xf6, 0xb0, 0x1c, 0x7d, 0x25, 0x5c, 0xae, 0xdc, 0x69, 0x12,
+	0xae, 0x74, 0xbf, 0xba, 0xb7, 0xfa, 0x6f, 0x81, 0xc1, 0x15, 0xdc, 0x6d, 0xd5, 0xbb, 0xd5, 0x41,
+	0x02, 0xde, 0x02, 0xd7, 0xb6, 0x28, 0x14, 0x2d, 0xbb, 0x38, 0x61, 0x02, 0x8b, 0x30, 0xd1, 0x96,
+	0x07, 0xb4, 0xe4, 0xa5, 0x9e, 0x15, 0x47, 0x59, 0x16, 0xb2, 0xbe, 0xef, 0x50, 0xc3, 0x05, 0xa7,
+	0x70, 0xf0, 0x24, 0xc9, 0x26, 0xaf, 0xc2, 0x94, 0xcd, 0x9a, 0xcb, 0x94, 0xb3, 0x5f, 0xb4, 0x51,
+	0x8f, 0x2a, 0x5a, 0xaa, 0x98, 0xc6, 0xab, 0x74, 0x61, 0x8d, 0x1a, 0x2e, 0xf8, 0xcb, 0x81, 0xfb,
+	0xe7, 0x69, 0x98, 0xf3, 0x38, 0x13, 0x9b, 0x7a, 0x7c, 0xe8, 0x5f, 0x62, 0xc1, 0xe5, 0xf6, 0xd5,
+	0xfe, 0x5b, 0xb6, 0x1c, 0xa2, 0x9d, 0xda, 0x10, 0x7d, 0x5c, 0x95, 0xa9, 0x8e, 0x9c, 0x5d, 0x06,
+	0x56, 0x7d, 0x6b, 0x89, 0x6e, 0xfc, 0x2f, 0x78, 0x37, 0xfe, 0x17, 0x7e, 0x84, 0xc3, 0x36, 0x05,
+	0xad, 0x7f, 0x0c, 0x76, 0x05, 0x76, 0x6a, 0x2b, 0xf0, 0x1e, 0xf4, 0x22, 0x36, 0x47, 0x2e, 0xcc,
+	0xdf, 0x8c, 0xe1, 0x82, 0xdf, 0x1d, 0x38, 0x50, 0x0b, 0xed, 0x7c, 0xb5, 0x5c, 0x86, 0x95, 0xde,
+	0x87, 0xe6, 0x69, 0x7a, 0x30, 0xb6, 0xad, 0x72, 0xfd, 0x5c, 0x22, 0xc7, 0x41, 0x66, 0xfb, 0x44,
+	0xd1, 0xe4, 0x04, 0x7a, 0x6a, 0x7a, 0xd9, 0x08, 0xd8, 0x21, 0x71, 0x21, 0x85, 0x4d, 0x3b, 0xd4,
+	0x20, 0x83, 0x39, 0x1c, 0xb4, 0x1c, 0x6f, 0xff, 0x63, 0x8b, 0xe5, 0x5e, 0xb3, 0x71, 0x97, 0x0b,
+	0xed, 0xa1, 0xf9, 0x09, 0x73, 0xb7, 0x3b, 0x2c, 0xcf, 0x83, 0x0b, 0x20, 0xdf, 0x64, 0x9c, 0xb3,
+	0xfc, 0x39, 0x26, 0x49, 0x66, 0xed, 0x1c, 0xc1, 0x20, 0x5f, 0x4d, 0x12, 0x36, 0xfd, 0x16, 0xed,
+	0x24, 0xa8, 0x04, 0x72, 0x9d, 0xe2, 0xf5, 0x34, 0x0e, 0xd3, 0x39, 0xca, 0x73, 0x69, 0x76, 0x48,
+	0xeb, 0xa2, 0xe0, 0x11, 0xec, 0x6b, 0xad, 0xa7, 0x2b, 0x11, 0xd7, 0x94, 0x56, 0xfb, 0xd4, 0xd9,
+	0xdc, 0xa7, 0x7f, 0x38, 0xb0, 0xab, 0xef, 0xdc, 0x96, 0xcb, 0xb7, 0xa0, 0xc3, 0x6c, 0x81, 0x75,
+	0x58, 0xa4, 0x02, 0x92, 0xe5, 0x6c, 0x6a, 0x7f, 0xcf, 0x15, 0x23, 0x6f, 0x46, 0xa1, 0x08, 0x55,
+	0x5f, 0x0c, 0xa9, 0xa2, 0xcb, 0x20, 0x75, 0x6b, 0x41, 0xf2, 0xa1, 0x6f, 0x7e, 0x02, 0xd4, 0xee,
+	0xf4, 0xa8, 0x65, 0x27, 0x3d, 0x15, 0xaf, 0x8f, 0xff, 0x1f, 0x00, 0xd0, 0x04, 0xab, 0xf1, 0xa3,
+	0x0c, 0x00, 0x00,
 }
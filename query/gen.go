@@ -4,6 +4,7 @@ import (
 	"math/rand"
 
 	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
 	"github.com/johnny-morrice/godless/internal/testutil"
 )
 
@@ -95,11 +96,20 @@ func genQueryPredicate(rand *rand.Rand, size int) QueryPredicate {
 func genQueryJoin(rand *rand.Rand, size int) QueryJoin {
 	const ROW_SCALE = 1.0
 	const ENTRY_SCALE = 0.2
+	const RECIPIENT_SCALE = 0.1
 	const MAX_STR_LEN = 10
 	rowCount := testutil.GenCountRange(rand, 1, size, ROW_SCALE)
 
 	gen := QueryJoin{Rows: make([]QueryRowJoin, rowCount)}
 
+	if rand.Float32() > 0.5 {
+		recipientCount := testutil.GenCountRange(rand, 1, size, RECIPIENT_SCALE)
+		for i := 0; i < recipientCount; i++ {
+			hash := testutil.RandStr(rand, __KEY_SYMS, 1, MAX_STR_LEN)
+			gen.Recipients = append(gen.Recipients, crypto.PublicKeyHash(hash))
+		}
+	}
+
 	for i := 0; i < rowCount; i++ {
 		gen.Rows[i] = QueryRowJoin{Entries: map[crdt.EntryName]crdt.PointText{}}
 		row := &gen.Rows[i]
@@ -78,6 +78,12 @@ type QueryVisitor interface {
 
 type QueryJoin struct {
 	Rows []QueryRowJoin `json:",omitempty"`
+	// Recipients of the encrypted join.  Empty for a plain text join.
+	Recipients []crypto.PublicKeyHash `json:",omitempty"`
+}
+
+func (join QueryJoin) IsEncrypted() bool {
+	return len(join.Recipients) > 0
 }
 
 func (join QueryJoin) IsEmpty() bool {
@@ -89,6 +95,16 @@ func (join QueryJoin) equals(other QueryJoin) bool {
 		return false
 	}
 
+	if len(join.Recipients) != len(other.Recipients) {
+		return false
+	}
+
+	for i, hash := range join.Recipients {
+		if !hash.Equals(other.Recipients[i]) {
+			return false
+		}
+	}
+
 	for i, myJoin := range join.Rows {
 		theirJoin := other.Rows[i]
 		if !myJoin.equals(theirJoin) {
@@ -134,6 +150,8 @@ func (join QueryRowJoin) equals(other QueryRowJoin) bool {
 type QuerySelect struct {
 	Where QueryWhere `json:",omitempty"`
 	Limit uint32     `json:",omitempty"`
+	// KeyThreshold is optional.  Results must be signed by at least this many of the query keys.
+	KeyThreshold uint32 `json:",omitempty"`
 }
 
 func (querySelect QuerySelect) IsEmpty() bool {
@@ -25,6 +25,9 @@ const (
 	ruleSelectKey
 	ruleLimit
 	ruleCryptoKey
+	ruleSignedKey
+	ruleKeyThreshold
+	ruleEncryptKey
 	ruleWhere
 	ruleWhereClause
 	ruleAndClause
@@ -62,6 +65,8 @@ const (
 	ruleAction16
 	ruleAction17
 	ruleAction18
+	ruleAction19
+	ruleAction20
 )
 
 var rul3s = [...]string{
@@ -77,6 +82,9 @@ var rul3s = [...]string{
 	"SelectKey",
 	"Limit",
 	"CryptoKey",
+	"SignedKey",
+	"KeyThreshold",
+	"EncryptKey",
 	"Where",
 	"WhereClause",
 	"AndClause",
@@ -114,6 +122,8 @@ var rul3s = [...]string{
 	"Action16",
 	"Action17",
 	"Action18",
+	"Action19",
+	"Action20",
 }
 
 type token32 struct {
@@ -230,7 +240,7 @@ type QueryParser struct {
 
 	Buffer string
 	buffer []rune
-	rules  [49]func() bool
+	rules  [54]func() bool
 	parse  func(rule ...int) error
 	reset  func()
 	Pretty bool
@@ -343,22 +353,26 @@ func (p *QueryParser) Execute() {
 		case ruleAction9:
 			p.AddCryptoKey(buffer[begin:end])
 		case ruleAction10:
-			p.PushWhere()
+			p.SetKeyThreshold(buffer[begin:end])
 		case ruleAction11:
-			p.PopWhere()
+			p.AddEncryptionKey(buffer[begin:end])
 		case ruleAction12:
-			p.SetWhereCommand("and")
+			p.PushWhere()
 		case ruleAction13:
-			p.SetWhereCommand("or")
+			p.PopWhere()
 		case ruleAction14:
-			p.InitPredicate()
+			p.SetWhereCommand("and")
 		case ruleAction15:
-			p.SetPredicateCommand(buffer[begin:end])
+			p.SetWhereCommand("or")
 		case ruleAction16:
-			p.UsePredicateRowKey()
+			p.InitPredicate()
 		case ruleAction17:
-			p.AddPredicateKey(buffer[begin:end])
+			p.SetPredicateCommand(buffer[begin:end])
 		case ruleAction18:
+			p.UsePredicateRowKey()
+		case ruleAction19:
+			p.AddPredicateKey(buffer[begin:end])
+		case ruleAction20:
 			p.AddPredicateLiteral(buffer[begin:end])
 
 		}
@@ -530,24 +544,8 @@ func (p *QueryParser) Init() {
 											}
 											{
 												position13 := position
-												{
-													position14 := position
-													if c := buffer[position]; c < rune('1') || c > rune('9') {
-														goto l9
-													}
-													position++
-												l15:
-													{
-														position16, tokenIndex16 := position, tokenIndex
-														if c := buffer[position]; c < rune('0') || c > rune('9') {
-															goto l16
-														}
-														position++
-														goto l15
-													l16:
-														position, tokenIndex = position16, tokenIndex16
-													}
-													add(rulePositiveInteger, position14)
+												if !_rules[rulePositiveInteger]() {
+													goto l9
 												}
 												add(rulePegText, position13)
 											}
@@ -647,9 +645,94 @@ func (p *QueryParser) Init() {
 							if !_rules[ruleMustSpacing]() {
 								goto l25
 							}
-							if !_rules[ruleCryptoKey]() {
-								goto l25
+							{
+								switch buffer[position] {
+								case 'e':
+									{
+										position136 := position
+										if buffer[position] != rune('e') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('n') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('c') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('r') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('y') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('p') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('t') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('e') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('d') {
+											goto l25
+										}
+										position++
+										if !_rules[ruleMustSpacing]() {
+											goto l25
+										}
+										if buffer[position] != rune('f') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('o') {
+											goto l25
+										}
+										position++
+										if buffer[position] != rune('r') {
+											goto l25
+										}
+										position++
+										if !_rules[ruleMustSpacing]() {
+											goto l25
+										}
+										if buffer[position] != rune('"') {
+											goto l25
+										}
+										position++
+										{
+											position137 := position
+											if !_rules[ruleAlphanumeric]() {
+												goto l25
+											}
+											add(rulePegText, position137)
+										}
+										if buffer[position] != rune('"') {
+											goto l25
+										}
+										position++
+										{
+											add(ruleAction11, position)
+										}
+										add(ruleEncryptKey, position136)
+									}
+									break
+								default:
+									if !_rules[ruleCryptoKey]() {
+										goto l25
+									}
+									break
+								}
 							}
+
 							goto l24
 						l25:
 							position, tokenIndex = position25, tokenIndex25
@@ -728,7 +811,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex = position0, tokenIndex0
 			return false
 		},
-		/* 1 Join <- <('j' 'o' 'i' 'n' MustSpacing JoinKey (MustSpacing CryptoKey)* MustSpacing ('r' 'o' 'w' 's') MustSpacing JoinRow (Spacing ',' Spacing JoinRow)* Spacing)> */
+		/* 1 Join <- <('j' 'o' 'i' 'n' MustSpacing JoinKey (MustSpacing ((&('e') EncryptKey) | (&('s') CryptoKey)))* MustSpacing ('r' 'o' 'w' 's') MustSpacing JoinRow (Spacing ',' Spacing JoinRow)* Spacing)> */
 		nil,
 		/* 2 JoinKey <- <(<Key> Action2)> */
 		nil,
@@ -924,7 +1007,7 @@ func (p *QueryParser) Init() {
 		nil,
 		/* 9 Limit <- <('l' 'i' 'm' 'i' 't' MustSpacing <PositiveInteger> Action8)> */
 		nil,
-		/* 10 CryptoKey <- <('s' 'i' 'g' 'n' 'e' 'd' MustSpacing '"' <Alphanumeric> '"' Action9)> */
+		/* 10 CryptoKey <- <('s' 'i' 'g' 'n' 'e' 'd' MustSpacing ((&('"') SignedKey) | (&('1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') KeyThreshold)))> */
 		func() bool {
 			position57, tokenIndex57 := position, tokenIndex
 			{
@@ -956,40 +1039,133 @@ func (p *QueryParser) Init() {
 				if !_rules[ruleMustSpacing]() {
 					goto l57
 				}
+				{
+					switch buffer[position] {
+					case '"':
+						if !_rules[ruleSignedKey]() {
+							goto l57
+						}
+						break
+					default:
+						{
+							position138 := position
+							{
+								position139 := position
+								if !_rules[rulePositiveInteger]() {
+									goto l57
+								}
+								add(rulePegText, position139)
+							}
+							{
+								add(ruleAction10, position)
+							}
+							if !_rules[ruleMustSpacing]() {
+								goto l57
+							}
+							if buffer[position] != rune('o') {
+								goto l57
+							}
+							position++
+							if buffer[position] != rune('f') {
+								goto l57
+							}
+							position++
+							if !_rules[ruleSpacing]() {
+								goto l57
+							}
+							if buffer[position] != rune('(') {
+								goto l57
+							}
+							position++
+							if !_rules[ruleSpacing]() {
+								goto l57
+							}
+							if !_rules[ruleSignedKey]() {
+								goto l57
+							}
+						l140:
+							{
+								position141, tokenIndex141 := position, tokenIndex
+								if !_rules[ruleSpacing]() {
+									goto l141
+								}
+								if buffer[position] != rune(',') {
+									goto l141
+								}
+								position++
+								if !_rules[ruleSpacing]() {
+									goto l141
+								}
+								if !_rules[ruleSignedKey]() {
+									goto l141
+								}
+								goto l140
+							l141:
+								position, tokenIndex = position141, tokenIndex141
+							}
+							if !_rules[ruleSpacing]() {
+								goto l57
+							}
+							if buffer[position] != rune(')') {
+								goto l57
+							}
+							position++
+							add(ruleKeyThreshold, position138)
+						}
+						break
+					}
+				}
+
+				add(ruleCryptoKey, position58)
+			}
+			return true
+		l57:
+			position, tokenIndex = position57, tokenIndex57
+			return false
+		},
+		/* 11 SignedKey <- <('"' <Alphanumeric> '"' Action9)> */
+		func() bool {
+			position142, tokenIndex142 := position, tokenIndex
+			{
+				position143 := position
 				if buffer[position] != rune('"') {
-					goto l57
+					goto l142
 				}
 				position++
 				{
-					position59 := position
+					position144 := position
 					if !_rules[ruleAlphanumeric]() {
-						goto l57
+						goto l142
 					}
-					add(rulePegText, position59)
+					add(rulePegText, position144)
 				}
 				if buffer[position] != rune('"') {
-					goto l57
+					goto l142
 				}
 				position++
 				{
 					add(ruleAction9, position)
 				}
-				add(ruleCryptoKey, position58)
+				add(ruleSignedKey, position143)
 			}
 			return true
-		l57:
-			position, tokenIndex = position57, tokenIndex57
+		l142:
+			position, tokenIndex = position142, tokenIndex142
 			return false
 		},
-		/* 11 Where <- <('w' 'h' 'e' 'r' 'e' MustSpacing WhereClause)> */
+		/* 12 KeyThreshold <- <(<PositiveInteger> Action10 MustSpacing ('o' 'f') Spacing '(' Spacing SignedKey (Spacing ',' Spacing SignedKey)* Spacing ')')> */
+		nil,
+		/* 13 EncryptKey <- <('e' 'n' 'c' 'r' 'y' 'p' 't' 'e' 'd' MustSpacing ('f' 'o' 'r') MustSpacing '"' <Alphanumeric> '"' Action11)> */
 		nil,
-		/* 12 WhereClause <- <(Action10 ((&('s') PredicateClause) | (&('o') OrClause) | (&('a') AndClause)) Action11)> */
+		/* 14 Where <- <('w' 'h' 'e' 'r' 'e' MustSpacing WhereClause)> */
+		nil,
+		/* 15 WhereClause <- <(Action12 ((&('s') PredicateClause) | (&('o') OrClause) | (&('a') AndClause)) Action13)> */
 		func() bool {
 			position62, tokenIndex62 := position, tokenIndex
 			{
 				position63 := position
 				{
-					add(ruleAction10, position)
+					add(ruleAction12, position)
 				}
 				{
 					switch buffer[position] {
@@ -997,7 +1173,7 @@ func (p *QueryParser) Init() {
 						{
 							position66 := position
 							{
-								add(ruleAction14, position)
+								add(ruleAction16, position)
 							}
 							{
 								position68 := position
@@ -1065,7 +1241,7 @@ func (p *QueryParser) Init() {
 									add(rulePegText, position69)
 								}
 								{
-									add(ruleAction15, position)
+									add(ruleAction17, position)
 								}
 								add(rulePredicate, position68)
 							}
@@ -1121,7 +1297,7 @@ func (p *QueryParser) Init() {
 							}
 							position++
 							{
-								add(ruleAction13, position)
+								add(ruleAction15, position)
 							}
 							if !_rules[ruleSpacing]() {
 								goto l62
@@ -1182,7 +1358,7 @@ func (p *QueryParser) Init() {
 							}
 							position++
 							{
-								add(ruleAction12, position)
+								add(ruleAction14, position)
 							}
 							if !_rules[ruleSpacing]() {
 								goto l62
@@ -1231,7 +1407,7 @@ func (p *QueryParser) Init() {
 				}
 
 				{
-					add(ruleAction11, position)
+					add(ruleAction13, position)
 				}
 				add(ruleWhereClause, position63)
 			}
@@ -1240,15 +1416,15 @@ func (p *QueryParser) Init() {
 			position, tokenIndex = position62, tokenIndex62
 			return false
 		},
-		/* 13 AndClause <- <('a' 'n' 'd' Action12 Spacing '(' Spacing WhereClause Spacing (',' Spacing WhereClause Spacing)* ')')> */
+		/* 16 AndClause <- <('a' 'n' 'd' Action14 Spacing '(' Spacing WhereClause Spacing (',' Spacing WhereClause Spacing)* ')')> */
 		nil,
-		/* 14 OrClause <- <('o' 'r' Action13 Spacing '(' Spacing WhereClause Spacing (',' Spacing WhereClause Spacing)* ')')> */
+		/* 17 OrClause <- <('o' 'r' Action15 Spacing '(' Spacing WhereClause Spacing (',' Spacing WhereClause Spacing)* ')')> */
 		nil,
-		/* 15 PredicateClause <- <(Action14 Predicate Spacing '(' Spacing PredicateValue (',' Spacing PredicateValue Spacing)* ')')> */
+		/* 18 PredicateClause <- <(Action16 Predicate Spacing '(' Spacing PredicateValue (',' Spacing PredicateValue Spacing)* ')')> */
 		nil,
-		/* 16 Predicate <- <(<(('s' 't' 'r' '_' 'e' 'q') / ('s' 't' 'r' '_' 'n' 'e' 'q'))> Action15)> */
+		/* 19 Predicate <- <(<(('s' 't' 'r' '_' 'e' 'q') / ('s' 't' 'r' '_' 'n' 'e' 'q'))> Action17)> */
 		nil,
-		/* 17 PredicateValue <- <(PredicateRowKey / PredicateKey / PredicateLiteralValue)> */
+		/* 20 PredicateValue <- <(PredicateRowKey / PredicateKey / PredicateLiteralValue)> */
 		func() bool {
 			position88, tokenIndex88 := position, tokenIndex
 			{
@@ -1274,7 +1450,7 @@ func (p *QueryParser) Init() {
 						}
 						position++
 						{
-							add(ruleAction16, position)
+							add(ruleAction18, position)
 						}
 						add(rulePredicateRowKey, position92)
 					}
@@ -1317,7 +1493,7 @@ func (p *QueryParser) Init() {
 						}
 					l96:
 						{
-							add(ruleAction17, position)
+							add(ruleAction19, position)
 						}
 						add(rulePredicateKey, position95)
 					}
@@ -1342,7 +1518,7 @@ func (p *QueryParser) Init() {
 						}
 						position++
 						{
-							add(ruleAction18, position)
+							add(ruleAction20, position)
 						}
 						add(rulePredicateLiteralValue, position101)
 					}
@@ -1355,13 +1531,13 @@ func (p *QueryParser) Init() {
 			position, tokenIndex = position88, tokenIndex88
 			return false
 		},
-		/* 18 PredicateRowKey <- <('@' 'k' 'e' 'y' Action16)> */
+		/* 21 PredicateRowKey <- <('@' 'k' 'e' 'y' Action18)> */
 		nil,
-		/* 19 PredicateKey <- <((<Key> / ('@' '"' <Literal> '"')) Action17)> */
+		/* 22 PredicateKey <- <((<Key> / ('@' '"' <Literal> '"')) Action19)> */
 		nil,
-		/* 20 PredicateLiteralValue <- <('"' <Literal> '"' Action18)> */
+		/* 23 PredicateLiteralValue <- <('"' <Literal> '"' Action20)> */
 		nil,
-		/* 21 Literal <- <(Escape / (!'"' .))*> */
+		/* 24 Literal <- <(Escape / (!'"' .))*> */
 		func() bool {
 			{
 				position108 := position
@@ -1463,9 +1639,34 @@ func (p *QueryParser) Init() {
 			}
 			return true
 		},
-		/* 22 PositiveInteger <- <([1-9] [0-9]*)> */
-		nil,
-		/* 23 Key <- <Alphanumeric> */
+		/* 25 PositiveInteger <- <([1-9] [0-9]*)> */
+		func() bool {
+			position14, tokenIndex14 := position, tokenIndex
+			{
+				position15 := position
+				if c := buffer[position]; c < rune('1') || c > rune('9') {
+					goto l14
+				}
+				position++
+			l16:
+				{
+					position17, tokenIndex17 := position, tokenIndex
+					if c := buffer[position]; c < rune('0') || c > rune('9') {
+						goto l17
+					}
+					position++
+					goto l16
+				l17:
+					position, tokenIndex = position17, tokenIndex17
+				}
+				add(rulePositiveInteger, position15)
+			}
+			return true
+		l14:
+			position, tokenIndex = position14, tokenIndex14
+			return false
+		},
+		/* 26 Key <- <Alphanumeric> */
 		func() bool {
 			position117, tokenIndex117 := position, tokenIndex
 			{
@@ -1480,7 +1681,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex = position117, tokenIndex117
 			return false
 		},
-		/* 24 Alphanumeric <- <((&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') [0-9]) | (&('A' | 'B' | 'C' | 'D' | 'E' | 'F' | 'G' | 'H' | 'I' | 'J' | 'K' | 'L' | 'M' | 'N' | 'O' | 'P' | 'Q' | 'R' | 'S' | 'T' | 'U' | 'V' | 'W' | 'X' | 'Y' | 'Z') [A-Z]) | (&('a' | 'b' | 'c' | 'd' | 'e' | 'f' | 'g' | 'h' | 'i' | 'j' | 'k' | 'l' | 'm' | 'n' | 'o' | 'p' | 'q' | 'r' | 's' | 't' | 'u' | 'v' | 'w' | 'x' | 'y' | 'z') [a-z]))+> */
+		/* 27 Alphanumeric <- <((&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') [0-9]) | (&('A' | 'B' | 'C' | 'D' | 'E' | 'F' | 'G' | 'H' | 'I' | 'J' | 'K' | 'L' | 'M' | 'N' | 'O' | 'P' | 'Q' | 'R' | 'S' | 'T' | 'U' | 'V' | 'W' | 'X' | 'Y' | 'Z') [A-Z]) | (&('a' | 'b' | 'c' | 'd' | 'e' | 'f' | 'g' | 'h' | 'i' | 'j' | 'k' | 'l' | 'm' | 'n' | 'o' | 'p' | 'q' | 'r' | 's' | 't' | 'u' | 'v' | 'w' | 'x' | 'y' | 'z') [a-z]))+> */
 		func() bool {
 			position119, tokenIndex119 := position, tokenIndex
 			{
@@ -1545,9 +1746,9 @@ func (p *QueryParser) Init() {
 			position, tokenIndex = position119, tokenIndex119
 			return false
 		},
-		/* 25 Escape <- <('\\' ((&('v') 'v') | (&('t') 't') | (&('r') 'r') | (&('n') 'n') | (&('f') 'f') | (&('b') 'b') | (&('a') 'a') | (&('\\') '\\') | (&('"') '"')))> */
+		/* 28 Escape <- <('\\' ((&('v') 'v') | (&('t') 't') | (&('r') 'r') | (&('n') 'n') | (&('f') 'f') | (&('b') 'b') | (&('a') 'a') | (&('\\') '\\') | (&('"') '"')))> */
 		nil,
-		/* 26 MustSpacing <- <((&('\n') '\n') | (&('\t') '\t') | (&(' ') ' '))+> */
+		/* 29 MustSpacing <- <((&('\n') '\n') | (&('\t') '\t') | (&(' ') ' '))+> */
 		func() bool {
 			position126, tokenIndex126 := position, tokenIndex
 			{
@@ -1612,7 +1813,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex = position126, tokenIndex126
 			return false
 		},
-		/* 27 Spacing <- <((&('\n') '\n') | (&('\t') '\t') | (&(' ') ' '))*> */
+		/* 30 Spacing <- <((&('\n') '\n') | (&('\t') '\t') | (&(' ') ' '))*> */
 		func() bool {
 			{
 				position133 := position
@@ -1650,44 +1851,48 @@ func (p *QueryParser) Init() {
 			}
 			return true
 		},
-		/* 29 Action0 <- <{ p.AddSelect() }> */
+		/* 32 Action0 <- <{ p.AddSelect() }> */
+		nil,
+		/* 33 Action1 <- <{ p.AddJoin() }> */
+		nil,
 		nil,
-		/* 30 Action1 <- <{ p.AddJoin() }> */
+		/* 35 Action2 <- <{ p.SetTableName(buffer[begin:end]) }> */
 		nil,
+		/* 36 Action3 <- <{ p.AddJoinRow() }> */
 		nil,
-		/* 32 Action2 <- <{ p.SetTableName(buffer[begin:end]) }> */
+		/* 37 Action4 <- <{ p.SetJoinRowKey(buffer[begin:end]) }> */
 		nil,
-		/* 33 Action3 <- <{ p.AddJoinRow() }> */
+		/* 38 Action5 <- <{ p.SetJoinKey(buffer[begin:end]) }> */
 		nil,
-		/* 34 Action4 <- <{ p.SetJoinRowKey(buffer[begin:end]) }> */
+		/* 39 Action6 <- <{ p.SetJoinValue(buffer[begin:end]) }> */
 		nil,
-		/* 35 Action5 <- <{ p.SetJoinKey(buffer[begin:end]) }> */
+		/* 40 Action7 <- <{ p.SetTableName(buffer[begin:end]) }> */
 		nil,
-		/* 36 Action6 <- <{ p.SetJoinValue(buffer[begin:end]) }> */
+		/* 41 Action8 <- <{ p.SetLimit(buffer[begin:end])}> */
 		nil,
-		/* 37 Action7 <- <{ p.SetTableName(buffer[begin:end]) }> */
+		/* 42 Action9 <- <{ p.AddCryptoKey(buffer[begin:end]) }> */
 		nil,
-		/* 38 Action8 <- <{ p.SetLimit(buffer[begin:end])}> */
+		/* 43 Action10 <- <{ p.SetKeyThreshold(buffer[begin:end]) }> */
 		nil,
-		/* 39 Action9 <- <{ p.AddCryptoKey(buffer[begin:end]) }> */
+		/* 44 Action11 <- <{ p.AddEncryptionKey(buffer[begin:end]) }> */
 		nil,
-		/* 40 Action10 <- <{ p.PushWhere() }> */
+		/* 45 Action12 <- <{ p.PushWhere() }> */
 		nil,
-		/* 41 Action11 <- <{ p.PopWhere() }> */
+		/* 46 Action13 <- <{ p.PopWhere() }> */
 		nil,
-		/* 42 Action12 <- <{ p.SetWhereCommand("and") }> */
+		/* 47 Action14 <- <{ p.SetWhereCommand("and") }> */
 		nil,
-		/* 43 Action13 <- <{ p.SetWhereCommand("or") }> */
+		/* 48 Action15 <- <{ p.SetWhereCommand("or") }> */
 		nil,
-		/* 44 Action14 <- <{ p.InitPredicate() }> */
+		/* 49 Action16 <- <{ p.InitPredicate() }> */
 		nil,
-		/* 45 Action15 <- <{ p.SetPredicateCommand(buffer[begin:end]) }> */
+		/* 50 Action17 <- <{ p.SetPredicateCommand(buffer[begin:end]) }> */
 		nil,
-		/* 46 Action16 <- <{ p.UsePredicateRowKey() }> */
+		/* 51 Action18 <- <{ p.UsePredicateRowKey() }> */
 		nil,
-		/* 47 Action17 <- <{ p.AddPredicateKey(buffer[begin:end]) }> */
+		/* 52 Action19 <- <{ p.AddPredicateKey(buffer[begin:end]) }> */
 		nil,
-		/* 48 Action18 <- <{ p.AddPredicateLiteral(buffer[begin:end])}> */
+		/* 53 Action20 <- <{ p.AddPredicateLiteral(buffer[begin:end])}> */
 		nil,
 	}
 	p.rules = _rules
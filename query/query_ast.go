@@ -25,6 +25,10 @@ func (ast *QueryAST) AddCryptoKey(publicKey string) {
 	ast.PublicKeys = append(ast.PublicKeys, publicKey)
 }
 
+func (ast *QueryAST) AddEncryptionKey(publicKey string) {
+	ast.Join.Recipients = append(ast.Join.Recipients, publicKey)
+}
+
 func (ast *QueryAST) AddJoin() {
 	ast.Command = "join"
 }
@@ -121,6 +125,10 @@ func (ast *QueryAST) SetLimit(limit string) {
 	ast.Select.Limit = limit
 }
 
+func (ast *QueryAST) SetKeyThreshold(threshold string) {
+	ast.Select.KeyThreshold = threshold
+}
+
 func (ast *QueryAST) Compile() (*Query, error) {
 	query := &Query{}
 
@@ -135,6 +143,10 @@ func (ast *QueryAST) Compile() (*Query, error) {
 		query.OpCode = SELECT
 		query.Select = qselect
 	case "join":
+		if ast.Select.KeyThreshold != "" {
+			return nil, errors.New("Key threshold is only allowed in select")
+		}
+
 		qjoin, err := ast.Join.Compile()
 
 		if err != nil {
@@ -155,11 +167,16 @@ func (ast *QueryAST) Compile() (*Query, error) {
 		query.PublicKeys[i] = crypto.PublicKeyHash(k)
 	}
 
+	if int(query.Select.KeyThreshold) > len(query.PublicKeys) {
+		return nil, fmt.Errorf("Key threshold %d is more than the %d keys", query.Select.KeyThreshold, len(query.PublicKeys))
+	}
+
 	return query, nil
 }
 
 type QueryJoinAST struct {
-	Rows []*QueryRowJoinAST `json:",omitempty"`
+	Rows       []*QueryRowJoinAST `json:",omitempty"`
+	Recipients []string           `json:",omitempty"`
 }
 
 func (ast *QueryJoinAST) Compile() (QueryJoin, error) {
@@ -182,6 +199,10 @@ func (ast *QueryJoinAST) Compile() (QueryJoin, error) {
 		Rows: rows,
 	}
 
+	for _, hash := range ast.Recipients {
+		qjoin.Recipients = append(qjoin.Recipients, crypto.PublicKeyHash(hash))
+	}
+
 	return qjoin, nil
 }
 
@@ -191,8 +212,9 @@ type QueryRowJoinAST struct {
 }
 
 type QuerySelectAST struct {
-	Where *QueryWhereAST `json:",omitempty"`
-	Limit string
+	Where        *QueryWhereAST `json:",omitempty"`
+	Limit        string
+	KeyThreshold string `json:",omitempty"`
 }
 
 func (ast *QuerySelectAST) Compile() (QuerySelect, error) {
@@ -208,6 +230,16 @@ func (ast *QuerySelectAST) Compile() (QuerySelect, error) {
 		qselect.Limit = uint32(limit)
 	}
 
+	if ast.KeyThreshold != "" {
+		threshold, converr := strconv.ParseUint(ast.KeyThreshold, __BASE_10, __BITS_32)
+
+		if converr != nil {
+			return QuerySelect{}, errors.Wrap(converr, "BUG convert key threshold failed")
+		}
+
+		qselect.KeyThreshold = uint32(threshold)
+	}
+
 	if ast.Where != nil {
 		where, err := ast.Where.Compile()
 
@@ -119,8 +119,9 @@ func MakeQueryMessage(query *Query) *proto.QueryMessage {
 
 func MakeQuerySelectMessage(querySelect QuerySelect) *proto.QuerySelectMessage {
 	message := &proto.QuerySelectMessage{
-		Limit: querySelect.Limit,
-		Where: MakeQueryWhereMessage(querySelect.Where),
+		Limit:        querySelect.Limit,
+		Where:        MakeQueryWhereMessage(querySelect.Where),
+		KeyThreshold: querySelect.KeyThreshold,
 	}
 
 	return message
@@ -179,6 +180,10 @@ func MakeQueryJoinMessage(join QueryJoin) *proto.QueryJoinMessage {
 		message.Rows[i] = MakeQueryRowJoinMessage(r)
 	}
 
+	for _, hash := range join.Recipients {
+		message.Recipients = append(message.Recipients, string(hash))
+	}
+
 	return message
 }
 
@@ -265,6 +270,10 @@ func (decoder *queryMessageDecoder) VisitTableKey(table string) {
 
 func (decoder *queryMessageDecoder) VisitJoin(message *proto.QueryJoinMessage) {
 	decoder.Query.Join.Rows = make([]QueryRowJoin, len(message.Rows))
+
+	for _, hash := range message.Recipients {
+		decoder.Query.Join.Recipients = append(decoder.Query.Join.Recipients, crypto.PublicKeyHash(hash))
+	}
 }
 
 func (decoder *queryMessageDecoder) LeaveJoin(*proto.QueryJoinMessage) {
@@ -277,6 +286,7 @@ func (decoder *queryMessageDecoder) VisitRowJoin(position int, message *proto.Qu
 
 func (decoder *queryMessageDecoder) VisitSelect(message *proto.QuerySelectMessage) {
 	decoder.Query.Select.Limit = message.Limit
+	decoder.Query.Select.KeyThreshold = message.KeyThreshold
 }
 
 func (decoder *queryMessageDecoder) LeaveSelect(*proto.QuerySelectMessage) {
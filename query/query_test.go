@@ -7,6 +7,8 @@ import (
 	"testing"
 	"testing/quick"
 
+	"github.com/johnny-morrice/godless/crdt"
+	"github.com/johnny-morrice/godless/crypto"
 	"github.com/johnny-morrice/godless/internal/testutil"
 	"github.com/johnny-morrice/godless/log"
 	"github.com/pkg/errors"
@@ -82,6 +84,69 @@ func queryParseOk(expected *Query) bool {
 	return same
 }
 
+func TestParseEncryptedJoin(t *testing.T) {
+	source := `join books signed "signer" encrypted for "alice" encrypted for "bob" rows (@key=dune, author="Herbert")`
+
+	actual, err := Compile(source)
+	testutil.AssertNil(t, err)
+
+	expected := &Query{
+		OpCode:     JOIN,
+		TableKey:   "books",
+		PublicKeys: []crypto.PublicKeyHash{crypto.PublicKeyHash("signer")},
+		Join: QueryJoin{
+			Recipients: []crypto.PublicKeyHash{crypto.PublicKeyHash("alice"), crypto.PublicKeyHash("bob")},
+			Rows: []QueryRowJoin{
+				QueryRowJoin{
+					RowKey: "dune",
+					Entries: map[crdt.EntryName]crdt.PointText{
+						"author": "Herbert",
+					},
+				},
+			},
+		},
+	}
+
+	testutil.Assert(t, "Unexpected query", expected.Equals(actual))
+	testutil.AssertEquals(t, "Unexpected recipients", expected.Join.Recipients, actual.Join.Recipients)
+	testutil.Assert(t, "Expected encrypted join", actual.Join.IsEncrypted())
+
+	_, err = Compile(`select books encrypted for "alice"`)
+	testutil.AssertNonNil(t, err)
+}
+
+func TestParseKeyThreshold(t *testing.T) {
+	source := `select books signed 2 of ("alice", "bob","carol") where str_eq(author, "Herbert") limit 10`
+
+	actual, err := Compile(source)
+	testutil.AssertNil(t, err)
+
+	expectedKeys := []crypto.PublicKeyHash{crypto.PublicKeyHash("alice"), crypto.PublicKeyHash("bob"), crypto.PublicKeyHash("carol")}
+	testutil.AssertEquals(t, "Unexpected keys", expectedKeys, actual.PublicKeys)
+	testutil.AssertEquals(t, "Unexpected threshold", uint32(2), actual.Select.KeyThreshold)
+	testutil.AssertNil(t, actual.Validate())
+
+	reparsed, err := Compile(prettyQuery(actual))
+	testutil.AssertNil(t, err)
+	testutil.Assert(t, "Unexpected pretty printed query", actual.Equals(reparsed))
+	testutil.AssertEquals(t, "Unexpected pretty printed keys", expectedKeys, reparsed.PublicKeys)
+
+	decoded := querySerializationPass(actual)
+	testutil.Assert(t, "Unexpected decoded query", actual.Equals(decoded))
+
+	invalid := []string{
+		`select books signed 4 of ("alice", "bob", "carol")`,
+		`select books signed 0 of ("alice")`,
+		`select books signed 2 of ()`,
+		`join books signed 1 of ("alice") rows (@key=dune, author="Herbert")`,
+	}
+
+	for _, source := range invalid {
+		_, err = Compile(source)
+		testutil.AssertNonNil(t, err)
+	}
+}
+
 func queryEncodeOk(expected *Query) bool {
 	actual := querySerializationPass(expected)
 	same := expected.Equals(actual)
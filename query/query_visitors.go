@@ -28,12 +28,40 @@ type queryPrinter struct {
 	ErrorCollectVisitor
 	output    io.Writer
 	tabIndent int
+	keys      []crypto.PublicKeyHash
 }
 
+// VisitPublicKeyHash collects the keys, which are written once the select threshold is known.
 func (printer *queryPrinter) VisitPublicKeyHash(hash crypto.PublicKeyHash) {
-	printer.write(" signed \"")
-	printer.write(string(hash))
-	printer.write("\"")
+	printer.keys = append(printer.keys, hash)
+}
+
+func (printer *queryPrinter) writeKeys(threshold uint32) {
+	if threshold == 0 {
+		for _, hash := range printer.keys {
+			printer.write(" signed \"")
+			printer.write(string(hash))
+			printer.write("\"")
+		}
+
+		return
+	}
+
+	printer.write(" signed ")
+	printer.write(threshold)
+	printer.write(" of (")
+
+	for i, hash := range printer.keys {
+		if i > 0 {
+			printer.write(", ")
+		}
+
+		printer.write("\"")
+		printer.write(string(hash))
+		printer.write("\"")
+	}
+
+	printer.write(")")
 }
 
 func (printer *queryPrinter) VisitOpCode(opCode QueryOpCode) {
@@ -54,10 +82,18 @@ func (printer *queryPrinter) VisitTableKey(table crdt.TableName) {
 }
 
 func (printer *queryPrinter) VisitJoin(join *QueryJoin) {
+	printer.writeKeys(0)
+
 	if join.IsEmpty() {
 		return
 	}
 
+	for _, hash := range join.Recipients {
+		printer.write(" encrypted for \"")
+		printer.write(string(hash))
+		printer.write("\"")
+	}
+
 	printer.write(" rows")
 	printer.indent(1)
 }
@@ -110,6 +146,8 @@ func (printer *queryPrinter) VisitRowJoin(position int, row *QueryRowJoin) {
 }
 
 func (printer *queryPrinter) VisitSelect(querySelect *QuerySelect) {
+	printer.writeKeys(querySelect.KeyThreshold)
+
 	if querySelect.IsEmpty() {
 		return
 	}
@@ -323,6 +361,7 @@ func (visitor *queryFlattener) Equals(other *queryFlattener) bool {
 	ok := visitor.opCode == other.opCode
 	ok = ok && visitor.tableName == other.tableName
 	ok = ok && visitor.slct.Limit == other.slct.Limit
+	ok = ok && visitor.slct.KeyThreshold == other.slct.KeyThreshold
 	ok = ok && len(visitor.allClauses) == len(other.allClauses)
 
 	if !ok {
@@ -348,10 +387,11 @@ type queryValidator struct {
 	NoDebugVisitor
 	ErrorCollectVisitor
 	NoJoinVisitor
+	keyCount int
 }
 
 func (visitor *queryValidator) VisitPublicKeyHash(hash crypto.PublicKeyHash) {
-
+	visitor.keyCount++
 }
 
 func (visitor *queryValidator) VisitOpCode(opCode QueryOpCode) {
@@ -370,7 +410,10 @@ func (visitor *queryValidator) VisitTableKey(tableKey crdt.TableName) {
 	}
 }
 
-func (visitor *queryValidator) VisitSelect(*QuerySelect) {
+func (visitor *queryValidator) VisitSelect(querySelect *QuerySelect) {
+	if int(querySelect.KeyThreshold) > visitor.keyCount {
+		visitor.CollectError(fmt.Errorf("Key threshold %d is more than the %d keys", querySelect.KeyThreshold, visitor.keyCount))
+	}
 }
 
 func (visitor *queryValidator) LeaveSelect(*QuerySelect) {